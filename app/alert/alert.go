@@ -0,0 +1,250 @@
+// Package alert tracks the problems the tracker has found until they go away.
+package alert
+
+import (
+	"log/slog"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Severity string
+
+const (
+	Warning  Severity = "warning"
+	Critical Severity = "critical"
+)
+
+// Alert is a problem identified by Key. Firing the same key again updates the
+// alert instead of raising a new one.
+type Alert struct {
+	Key      string            `json:"key"`
+	Severity Severity          `json:"severity"`
+	Summary  string            `json:"summary"`
+	Tenant   string            `json:"tenant,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Since    time.Time         `json:"since"`
+	// Route names who should hear about the alert, for alerts that escalate.
+	Route        string           `json:"route,omitempty"`
+	Acknowledged *Acknowledgement `json:"acknowledged,omitempty"`
+}
+
+// Acknowledgement records who took ownership of an alert. It stops the alert
+// escalating until it resolves.
+type Acknowledgement struct {
+	By string    `json:"by"`
+	At time.Time `json:"at"`
+}
+
+// Key identifies the alert of kind about subject, scoped to tenant when set.
+func Key(kind, tenant, subject string) string {
+	if tenant == "" {
+		return kind + ":" + subject
+	}
+	return kind + ":" + tenant + "/" + subject
+}
+
+// Alert states reported to a Watcher.
+const (
+	Firing    = "firing"
+	Escalated = "escalated"
+	Resolved  = "resolved"
+)
+
+// Watcher hears about every alert that starts firing, escalates or resolves.
+// It is called with the manager locked, so it must not call back into it and
+// should hand the alert off rather than block.
+type Watcher func(state string, a Alert)
+
+// Muter reports whether a should be held back, such as during a
+// maintenance window.
+type Muter func(a Alert) bool
+
+// Labeler returns extra labels for a, such as the labels configured for the
+// hostname it's about.
+type Labeler func(a Alert) map[string]string
+
+type Manager struct {
+	log    *slog.Logger
+	mu     sync.Mutex
+	active map[string]Alert
+	watch  Watcher
+	mute   Muter
+	label  Labeler
+}
+
+func NewManager(log *slog.Logger) *Manager {
+	return &Manager{log: log, active: make(map[string]Alert)}
+}
+
+// Watch makes w hear about every change in the alerts from now on.
+func (m *Manager) Watch(w Watcher) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.watch = w
+}
+
+// Mute holds back the alerts mute reports from now on, replacing any
+// earlier Muter.
+func (m *Manager) Mute(mute Muter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mute = mute
+}
+
+// Label adds the labels label returns to every alert fired from now on,
+// replacing any earlier Labeler. Labels the alert already has win.
+func (m *Manager) Label(label Labeler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.label = label
+}
+
+func (m *Manager) notify(state string, a Alert) {
+	if m.watch != nil {
+		m.watch(state, a)
+	}
+}
+
+// Fire raises a, keeping the start time of an alert already active under the
+// same key. An acknowledged or muted alert also keeps its severity and
+// route, and a muted alert that isn't active isn't raised. It reports
+// whether the alert is new.
+func (m *Manager) Fire(a Alert) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.label != nil {
+		a.Labels = addLabels(a.Labels, m.label(a))
+	}
+	muted := m.mute != nil && m.mute(a)
+	current, ok := m.active[a.Key]
+	if !ok && muted {
+		m.log.Debug("alert muted",
+			"key", a.Key,
+			"summary", a.Summary,
+		)
+		return false
+	}
+	if ok {
+		a.Since = current.Since
+		a.Acknowledged = current.Acknowledged
+		if a.Acknowledged != nil || muted {
+			a.Severity, a.Route = current.Severity, current.Route
+		}
+	}
+	m.active[a.Key] = a
+	switch {
+	case !ok:
+		m.log.Warn("alert firing",
+			"key", a.Key,
+			"severity", a.Severity,
+			"route", a.Route,
+			"summary", a.Summary,
+		)
+		m.notify(Firing, a)
+	case a.Severity != current.Severity || a.Route != current.Route:
+		m.log.Warn("alert escalated",
+			"key", a.Key,
+			"severity", a.Severity,
+			"route", a.Route,
+			"summary", a.Summary,
+		)
+		m.notify(Escalated, a)
+	}
+	return !ok
+}
+
+// addLabels returns labels with extra added, leaving labels untouched and
+// keeping its values where both have the same name.
+func addLabels(labels, extra map[string]string) map[string]string {
+	if len(extra) == 0 {
+		return labels
+	}
+	merged := maps.Clone(extra)
+	maps.Copy(merged, labels)
+	return merged
+}
+
+// Acknowledge marks the alert under key as owned by by, stopping its
+// escalation. It returns the updated alert, or false if none is active.
+func (m *Manager) Acknowledge(key, by string, at time.Time) (Alert, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.active[key]
+	if !ok {
+		return Alert{}, false
+	}
+	if a.Acknowledged == nil {
+		a.Acknowledged = &Acknowledgement{By: by, At: at}
+		m.active[key] = a
+		m.log.Info("alert acknowledged",
+			"key", key,
+			"by", by,
+		)
+	}
+	return a, true
+}
+
+// Get returns the active alert under key.
+func (m *Manager) Get(key string) (Alert, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.active[key]
+	return a, ok
+}
+
+// Resolve clears the alert under key and reports whether one was active.
+func (m *Manager) Resolve(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.active[key]
+	if !ok {
+		return false
+	}
+	delete(m.active, key)
+	m.log.Info("alert resolved",
+		"key", key,
+		"summary", a.Summary,
+	)
+	m.notify(Resolved, a)
+	return true
+}
+
+// Restore makes alerts active again after a restart, without notifying
+// watchers, which heard about them before. Alerts already active are kept.
+func (m *Manager) Restore(alerts []Alert) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, a := range alerts {
+		if _, ok := m.active[a.Key]; !ok {
+			m.active[a.Key] = a
+		}
+	}
+	if len(alerts) > 0 {
+		m.log.Info("alerts restored", "count", len(alerts))
+	}
+}
+
+// Set fires a when firing is true and resolves it otherwise.
+func (m *Manager) Set(firing bool, a Alert) {
+	if firing {
+		m.Fire(a)
+	} else {
+		m.Resolve(a.Key)
+	}
+}
+
+// Active returns the firing alerts sorted by key.
+func (m *Manager) Active() []Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	alerts := make([]Alert, 0, len(m.active))
+	for _, a := range m.active {
+		alerts = append(alerts, a)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Key < alerts[j].Key
+	})
+	return alerts
+}
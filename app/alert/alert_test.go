@@ -0,0 +1,184 @@
+package alert
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManager(t *testing.T) {
+	m := NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	first := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	if !m.Fire(Alert{Key: "b", Severity: Warning, Summary: "slow", Since: first}) {
+		t.Error("Expected first Fire() to raise a new alert")
+	}
+	if m.Fire(Alert{Key: "b", Severity: Critical, Summary: "down", Since: first.Add(time.Hour)}) {
+		t.Error("Expected repeated Fire() to update the existing alert")
+	}
+	m.Fire(Alert{Key: "a", Severity: Warning, Since: first})
+
+	active := m.Active()
+	if len(active) != 2 || active[0].Key != "a" || active[1].Key != "b" {
+		t.Fatalf("Active() = %+v, want alerts a and b", active)
+	}
+	if active[1].Severity != Critical || !active[1].Since.Equal(first) {
+		t.Errorf("updated alert = %+v, want critical since %v", active[1], first)
+	}
+
+	if !m.Resolve("b") {
+		t.Error("Expected Resolve() of an active alert to report true")
+	}
+	if m.Resolve("b") {
+		t.Error("Expected Resolve() of a resolved alert to report false")
+	}
+	if got := m.Active(); len(got) != 1 {
+		t.Errorf("Active() after resolve = %+v", got)
+	}
+}
+
+func TestManagerSet(t *testing.T) {
+	m := NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	a := Alert{Key: "crl-stale:http://crl.example.com", Severity: Warning}
+
+	m.Set(true, a)
+	if got := m.Active(); len(got) != 1 {
+		t.Fatalf("Active() after Set(true) = %+v", got)
+	}
+	m.Set(false, a)
+	if got := m.Active(); len(got) != 0 {
+		t.Errorf("Active() after Set(false) = %+v", got)
+	}
+}
+
+func TestKey(t *testing.T) {
+	if got := Key("caa-mismatch", "", "example.com"); got != "caa-mismatch:example.com" {
+		t.Errorf("Key() without tenant = %q", got)
+	}
+	if got := Key("caa-mismatch", "payments", "example.com"); got != "caa-mismatch:payments/example.com" {
+		t.Errorf("Key() with tenant = %q", got)
+	}
+}
+
+func TestManagerAcknowledge(t *testing.T) {
+	m := NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	key := "expiry:example.com"
+
+	if _, ok := m.Acknowledge(key, "ops", now); ok {
+		t.Error("Expected Acknowledge() of an inactive alert to report false")
+	}
+	m.Fire(Alert{Key: key, Severity: Warning, Route: "team", Since: now})
+	a, ok := m.Acknowledge(key, "ops", now)
+	if !ok || a.Acknowledged == nil || a.Acknowledged.By != "ops" {
+		t.Fatalf("Acknowledge() = %+v, %v", a, ok)
+	}
+
+	// acknowledged alerts stop escalating
+	m.Fire(Alert{Key: key, Severity: Critical, Route: "page", Summary: "expires in 6 days", Since: now})
+	got, _ := m.Get(key)
+	if got.Severity != Warning || got.Route != "team" || got.Acknowledged == nil {
+		t.Errorf("escalated acknowledged alert = %+v, want warning to team", got)
+	}
+	if got.Summary != "expires in 6 days" {
+		t.Errorf("Summary = %q, want the latest summary", got.Summary)
+	}
+
+	// a resolved alert escalates normally when it fires again
+	m.Resolve(key)
+	m.Fire(Alert{Key: key, Severity: Critical, Route: "page", Since: now})
+	if got, _ := m.Get(key); got.Severity != Critical || got.Acknowledged != nil {
+		t.Errorf("refired alert = %+v, want unacknowledged critical", got)
+	}
+}
+
+func TestManagerWatch(t *testing.T) {
+	m := NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	var states []string
+	m.Watch(func(state string, a Alert) { states = append(states, state+" "+a.Key) })
+
+	m.Fire(Alert{Key: "a", Severity: Warning})
+	m.Fire(Alert{Key: "a", Severity: Warning, Summary: "unchanged"})
+	m.Fire(Alert{Key: "a", Severity: Critical})
+	m.Resolve("a")
+	m.Resolve("a")
+
+	want := []string{"firing a", "escalated a", "resolved a"}
+	if strings.Join(states, ",") != strings.Join(want, ",") {
+		t.Errorf("watched %v, want %v", states, want)
+	}
+}
+
+func TestManagerRestore(t *testing.T) {
+	m := NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	var states []string
+	m.Watch(func(state string, a Alert) { states = append(states, state+" "+a.Key) })
+	since := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	m.Restore([]Alert{{Key: "a", Severity: Warning, Since: since}, {Key: "b", Severity: Critical, Since: since}})
+	m.Fire(Alert{Key: "a", Severity: Warning, Since: since.Add(time.Hour)})
+	m.Resolve("b")
+
+	want := []string{"resolved b"}
+	if strings.Join(states, ",") != strings.Join(want, ",") {
+		t.Errorf("watched %v, want %v", states, want)
+	}
+	if a, ok := m.Get("a"); !ok || !a.Since.Equal(since) {
+		t.Errorf("Get(a) = %+v, %v, want the restored alert since %v", a, ok, since)
+	}
+}
+
+func TestManagerMute(t *testing.T) {
+	m := NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	var states []string
+	m.Watch(func(state string, a Alert) { states = append(states, state+" "+a.Key) })
+	m.Fire(Alert{Key: "expiry:old.example.com", Severity: Warning, Labels: map[string]string{"hostname": "old.example.com"}})
+	m.Mute(func(a Alert) bool { return a.Labels["hostname"] == "old.example.com" })
+
+	// muted alerts aren't raised, and active ones don't escalate
+	m.Fire(Alert{Key: "verify:old.example.com", Severity: Critical, Labels: map[string]string{"hostname": "old.example.com"}})
+	m.Fire(Alert{Key: "expiry:old.example.com", Severity: Critical, Labels: map[string]string{"hostname": "old.example.com"}})
+	m.Fire(Alert{Key: "expiry:www.example.com", Severity: Warning, Labels: map[string]string{"hostname": "www.example.com"}})
+	if _, ok := m.Get("verify:old.example.com"); ok {
+		t.Error("Expected a muted alert not to be raised")
+	}
+	if got, _ := m.Get("expiry:old.example.com"); got.Severity != Warning {
+		t.Errorf("muted active alert = %+v, want it kept at warning", got)
+	}
+
+	// once unmuted they fire again
+	m.Mute(nil)
+	m.Fire(Alert{Key: "verify:old.example.com", Severity: Critical, Labels: map[string]string{"hostname": "old.example.com"}})
+
+	want := []string{"firing expiry:old.example.com", "firing expiry:www.example.com", "firing verify:old.example.com"}
+	if strings.Join(states, ",") != strings.Join(want, ",") {
+		t.Errorf("watched %v, want %v", states, want)
+	}
+}
+
+func TestManagerLabel(t *testing.T) {
+	m := NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	m.Label(func(a Alert) map[string]string {
+		if a.Labels["hostname"] == "pay.example.com" {
+			return map[string]string{"team": "payments", "hostname": "ignored"}
+		}
+		return nil
+	})
+
+	own := map[string]string{"hostname": "pay.example.com"}
+	m.Fire(Alert{Key: "expiry:pay.example.com", Severity: Warning, Labels: own})
+	m.Fire(Alert{Key: "expiry:www.example.com", Severity: Warning, Labels: map[string]string{"hostname": "www.example.com"}})
+
+	got, _ := m.Get("expiry:pay.example.com")
+	if got.Labels["team"] != "payments" || got.Labels["hostname"] != "pay.example.com" {
+		t.Errorf("labeled alert = %+v, want team payments and its own hostname", got.Labels)
+	}
+	if len(own) != 1 {
+		t.Errorf("Fire() changed the caller's labels to %v", own)
+	}
+	if got, _ := m.Get("expiry:www.example.com"); len(got.Labels) != 1 {
+		t.Errorf("unlabeled alert = %+v, want only its hostname", got.Labels)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"fmt"
+	"time"
+)
+
+// checkALPN raises an alert for every scanned endpoint of a hostname with
+// an expected ALPN protocol that selected another one, or none.
+func checkALPN(snapshot store.Snapshot, alpn cfg.ALPN, alerts *alert.Manager, now time.Time) {
+	for _, c := range snapshot.Certificates {
+		want, ok := alpn.Expect[c.Hostname]
+		if c.Index != 0 || !ok || c.Deferred {
+			continue
+		}
+		endpoint := string(c.Hostname) + "@" + c.IPAddress.String()
+		got := c.Connection.ALPN
+		if got == "" {
+			got = "none"
+		}
+		alerts.Set(c.Connection.ALPN != want, alert.Alert{
+			Key:      alert.Key("alpn-mismatch", c.Tenant, endpoint),
+			Severity: alert.Warning,
+			Summary:  fmt.Sprintf("%s negotiated ALPN protocol %s, want %s", endpoint, got, want),
+			Tenant:   c.Tenant,
+			Labels:   map[string]string{"hostname": string(c.Hostname), "ipAddress": c.IPAddress.String()},
+			Since:    now,
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"context"
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckALPN(t *testing.T) {
+	now := time.Now()
+	alpn := cfg.ALPN{Expect: map[cfg.Hostname]string{"grpc.example.com": "h2"}}
+	tests := []struct {
+		name       string
+		hostname   cfg.Hostname
+		selected   string
+		wantFiring bool
+	}{
+		{name: "negotiated h2", hostname: "grpc.example.com", selected: "h2"},
+		{name: "fell back to http/1.1", hostname: "grpc.example.com", selected: "http/1.1", wantFiring: true},
+		{name: "no protocol selected", hostname: "grpc.example.com", wantFiring: true},
+		{name: "nothing expected", hostname: "www.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := alert.NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+			snapshot := store.Snapshot{Certificates: []store.Certificate{{
+				Hostname:   tt.hostname,
+				IPAddress:  net.ParseIP("192.0.2.1"),
+				Connection: store.Connection{ALPN: tt.selected},
+			}}}
+			checkALPN(snapshot, alpn, alerts, now)
+			if _, firing := alerts.Get("alpn-mismatch:" + string(tt.hostname) + "@192.0.2.1"); firing != tt.wantFiring {
+				t.Errorf("firing = %v, want %v", firing, tt.wantFiring)
+			}
+		})
+	}
+}
+
+func TestScanRecordsALPN(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.TLS = &tls.Config{NextProtos: []string{"h2", "http/1.1"}}
+	server.StartTLS()
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	for _, offered := range [][]string{{"h2", "http/1.1"}, {"http/1.1"}, nil} {
+		target := scanTarget{
+			Hostname:   cfg.Hostname(net.JoinHostPort("example.com", port)),
+			ServerName: "example.com",
+			ALPN:       offered,
+			IPAddress:  net.ParseIP(host),
+		}
+		result, state, err := scan(context.Background(), target, cfg.Duration(5*time.Second))
+		if state == nil {
+			t.Fatalf("scan() error = %v", err)
+		}
+		want := ""
+		if len(offered) > 0 {
+			want = offered[0]
+		}
+		if got := result.Chain[0].Connection.ALPN; got != want {
+			t.Errorf("offering %v, ALPN = %q, want %q", offered, got, want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// HandleAlerts serves the alerts currently firing in m, and lets admins
+// acknowledge them.
+func (s *Server) HandleAlerts(m *alert.Manager) {
+	s.Handle("GET /api/v1/alerts", cfg.ScopeRead, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		alerts := m.Active()
+		tenant := tenantOf(r)
+		if tenant == "" {
+			writeJSON(w, http.StatusOK, alerts)
+			return
+		}
+		scoped := []alert.Alert{}
+		for _, a := range alerts {
+			if a.Tenant == tenant {
+				scoped = append(scoped, a)
+			}
+		}
+		writeJSON(w, http.StatusOK, scoped)
+	}))
+	s.Handle("POST /api/v1/alerts/ack", cfg.ScopeAdmin, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Key string `json:"key"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Key == "" {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		// tenant tokens can't see other tenants' alerts, let alone acknowledge them
+		if a, ok := m.Get(body.Key); !ok || (tenantOf(r) != "" && a.Tenant != tenantOf(r)) {
+			writeError(w, http.StatusNotFound, "no such alert")
+			return
+		}
+		token, _ := r.Context().Value(tokenKey).(cfg.APIToken)
+		a, ok := m.Acknowledge(body.Key, token.Name, time.Now())
+		if !ok {
+			writeError(w, http.StatusNotFound, "no such alert")
+			return
+		}
+		writeJSON(w, http.StatusOK, a)
+	}))
+}
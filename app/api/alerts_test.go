@@ -0,0 +1,89 @@
+package api
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAlertsEndpoint(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := New(cfg.API{
+		Tokens: []cfg.APIToken{
+			{Name: "payments", Token: "payments-token", Scope: cfg.ScopeRead, Tenant: "payments"},
+			{Name: "platform", Token: "platform-token", Scope: cfg.ScopeRead},
+		},
+	}, log)
+	m := alert.NewManager(log)
+	s.HandleAlerts(m)
+
+	w := request(s, "GET", "/api/v1/alerts", "platform-token")
+	if body := w.Body.String(); body != "[]\n" {
+		t.Errorf("alerts with nothing firing = %q, want an empty list", body)
+	}
+
+	m.Fire(alert.Alert{Key: "ocsp-unreachable:http://ocsp.example.com", Severity: alert.Critical, Since: time.Now()})
+	m.Fire(alert.Alert{Key: "expiry:pay.example.com", Severity: alert.Warning, Tenant: "payments", Since: time.Now()})
+
+	for token, want := range map[string]int{"payments-token": 1, "platform-token": 2} {
+		w := request(s, "GET", "/api/v1/alerts", token)
+		var got []alert.Alert
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != want {
+			t.Errorf("%s sees %d alerts, want %d", token, len(got), want)
+		}
+	}
+}
+
+func TestAcknowledgeAlert(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := New(cfg.API{
+		Tokens: []cfg.APIToken{
+			{Name: "dashboard", Token: "read-token", Scope: cfg.ScopeRead},
+			{Name: "ops", Token: "admin-token", Scope: cfg.ScopeAdmin},
+			{Name: "web", Token: "web-token", Scope: cfg.ScopeAdmin, Tenant: "web"},
+		},
+	}, log)
+	m := alert.NewManager(log)
+	s.HandleAlerts(m)
+	m.Fire(alert.Alert{Key: "expiry:payments/pay.example.com", Severity: alert.Warning, Tenant: "payments", Since: time.Now()})
+
+	tests := []struct {
+		name  string
+		token string
+		body  string
+		want  int
+	}{
+		{name: "read token denied", token: "read-token", body: `{"key":"expiry:payments/pay.example.com"}`, want: http.StatusForbidden},
+		{name: "other tenant can't see it", token: "web-token", body: `{"key":"expiry:payments/pay.example.com"}`, want: http.StatusNotFound},
+		{name: "unknown alert", token: "admin-token", body: `{"key":"expiry:nope"}`, want: http.StatusNotFound},
+		{name: "missing key", token: "admin-token", body: `{}`, want: http.StatusBadRequest},
+		{name: "admin acknowledges", token: "admin-token", body: `{"key":"expiry:payments/pay.example.com"}`, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/v1/alerts/ack", strings.NewReader(tt.body))
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("POST /api/v1/alerts/ack status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+
+	a, _ := m.Get("expiry:payments/pay.example.com")
+	if a.Acknowledged == nil || a.Acknowledged.By != "ops" {
+		t.Errorf("alert after acknowledgement = %+v, want acknowledged by ops", a)
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"crypto/rand"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+)
+
+type Server struct {
+	config     cfg.API
+	log        *slog.Logger
+	mux        *http.ServeMux
+	latest     atomic.Pointer[store.Snapshot]
+	progress   atomic.Pointer[Progress]
+	sessionKey []byte
+	oidc       *provider
+}
+
+func New(config cfg.API, log *slog.Logger) *Server {
+	s := &Server{
+		config:     config,
+		log:        log,
+		mux:        http.NewServeMux(),
+		sessionKey: make([]byte, 32),
+	}
+	// sessions don't survive a restart; users just log in again
+	rand.Read(s.sessionKey)
+	s.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	if config.OIDC.Issuer != "" {
+		s.oidc = newProvider(config.OIDC)
+		redirect, _ := url.Parse(config.OIDC.RedirectURL)
+		s.mux.HandleFunc("GET /auth/login", s.login)
+		s.mux.HandleFunc("GET "+redirect.Path, s.callback)
+		s.mux.HandleFunc("GET /auth/logout", s.logout)
+	}
+	s.Handle("GET /api/v1/snapshot", cfg.ScopeRead, http.HandlerFunc(s.snapshot))
+	s.Handle("GET /api/v1/progress", cfg.ScopeRead, http.HandlerFunc(s.scanProgress))
+	return s
+}
+
+// Handle registers h behind bearer token authentication requiring scope.
+func (s *Server) Handle(pattern string, scope cfg.Scope, h http.Handler) {
+	s.mux.Handle(pattern, s.authenticate(scope, h))
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.config.Listen)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// Serve answers requests on ln, such as a socket passed by systemd, with
+// TLS when configured.
+func (s *Server) Serve(ln net.Listener) error {
+	if len(s.config.Tokens) == 0 && s.oidc == nil {
+		s.log.Warn("no API tokens configured; authenticated endpoints will reject every request")
+	}
+	srv := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if s.config.TLS.CertFile == "" {
+		s.log.Warn("API listening without TLS", "address", ln.Addr().String())
+		return srv.Serve(ln)
+	}
+	tlsConfig, err := tlsConfig(s.config.TLS)
+	if err != nil {
+		ln.Close()
+		return err
+	}
+	srv.TLSConfig = tlsConfig
+	s.log.Info("API listening",
+		"address", ln.Addr().String(),
+		"clientCertificates", s.config.TLS.ClientCAFile != "",
+	)
+	// certificates come from TLSConfig.GetCertificate
+	return srv.ServeTLS(ln, "", "")
+}
+
+// SetSnapshot publishes the results of the latest scan cycle.
+func (s *Server) SetSnapshot(snapshot store.Snapshot) {
+	s.latest.Store(&snapshot)
+}
+
+func (s *Server) snapshot(w http.ResponseWriter, r *http.Request) {
+	latest := s.latest.Load()
+	if latest == nil {
+		writeError(w, http.StatusServiceUnavailable, "no scan has completed yet")
+		return
+	}
+	tenant := tenantOf(r)
+	if tenant == "" {
+		writeJSON(w, http.StatusOK, latest)
+		return
+	}
+	scoped := scope(*latest, tenant, "")
+	writeJSON(w, http.StatusOK, scoped)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestServer() *Server {
+	return New(cfg.API{
+		Tokens: []cfg.APIToken{
+			{Name: "dashboard", Token: "read-token", Scope: cfg.ScopeRead},
+			{Name: "ops", Token: "admin-token", Scope: cfg.ScopeAdmin},
+		},
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func request(s *Server, method, path, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func TestAuthentication(t *testing.T) {
+	s := newTestServer()
+	s.Handle("POST /api/v1/admin-only", cfg.ScopeAdmin, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	s.SetSnapshot(store.Snapshot{Time: time.Now()})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{name: "health needs no token", method: "GET", path: "/healthz", want: http.StatusNoContent},
+		{name: "missing token", method: "GET", path: "/api/v1/snapshot", want: http.StatusUnauthorized},
+		{name: "unknown token", method: "GET", path: "/api/v1/snapshot", token: "guess", want: http.StatusUnauthorized},
+		{name: "read token reads", method: "GET", path: "/api/v1/snapshot", token: "read-token", want: http.StatusOK},
+		{name: "admin token reads", method: "GET", path: "/api/v1/snapshot", token: "admin-token", want: http.StatusOK},
+		{name: "read token denied admin", method: "POST", path: "/api/v1/admin-only", token: "read-token", want: http.StatusForbidden},
+		{name: "admin token allowed admin", method: "POST", path: "/api/v1/admin-only", token: "admin-token", want: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(s, tt.method, tt.path, tt.token)
+			if w.Code != tt.want {
+				t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, w.Code, tt.want)
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected WWW-Authenticate header on 401")
+			}
+		})
+	}
+}
+
+func TestSnapshotEndpoint(t *testing.T) {
+	s := newTestServer()
+
+	if w := request(s, "GET", "/api/v1/snapshot", "read-token"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status before first scan = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	s.SetSnapshot(store.Snapshot{
+		Time:         time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		Certificates: []store.Certificate{{Hostname: "example.com", SHA256Fingerprint: "aa"}},
+	})
+	w := request(s, "GET", "/api/v1/snapshot", "read-token")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var got store.Snapshot
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Certificates) != 1 || got.Certificates[0].Hostname != "example.com" {
+		t.Errorf("snapshot = %+v", got)
+	}
+}
+
+func TestSnapshotScopedToTenant(t *testing.T) {
+	s := New(cfg.API{
+		Tokens: []cfg.APIToken{
+			{Name: "payments", Token: "payments-token", Scope: cfg.ScopeRead, Tenant: "payments"},
+			{Name: "platform", Token: "platform-token", Scope: cfg.ScopeRead},
+		},
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.SetSnapshot(store.Snapshot{
+		Time: time.Now(),
+		Certificates: []store.Certificate{
+			{Tenant: "payments", Hostname: "pay.example.com"},
+			{Tenant: "web", Hostname: "www.example.com"},
+		},
+	})
+
+	for token, want := range map[string]int{"payments-token": 1, "platform-token": 2} {
+		w := request(s, "GET", "/api/v1/snapshot", token)
+		var got store.Snapshot
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Certificates) != want {
+			t.Errorf("%s sees %d certificates, want %d", token, len(got.Certificates), want)
+		}
+		for _, c := range got.Certificates {
+			if token == "payments-token" && c.Tenant != "payments" {
+				t.Errorf("payments token sees %s of tenant %q", c.Hostname, c.Tenant)
+			}
+		}
+	}
+}
+
+func TestProgressEndpoint(t *testing.T) {
+	s := newTestServer()
+
+	if w := request(s, "GET", "/api/v1/progress", "read-token"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status before first scan = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	s.SetProgress(Progress{Running: true, Started: time.Now(), Done: 250, Total: 1000})
+	w := request(s, "GET", "/api/v1/progress", "read-token")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var got Progress
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Running || got.Done != 250 || got.Total != 1000 {
+		t.Errorf("progress = %+v", got)
+	}
+}
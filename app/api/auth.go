@@ -0,0 +1,62 @@
+package api
+
+import (
+	"cert-tracker/cfg"
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+type contextKey int
+
+const tokenKey contextKey = iota
+
+// authenticate rejects requests without a bearer token or login session granting scope.
+func (s *Server) authenticate(scope cfg.Scope, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := s.lookupToken(r)
+		if !ok && s.oidc != nil {
+			if sess, found := s.readSession(r); found {
+				token, ok = cfg.APIToken{Name: sess.Name, Scope: sess.Scope, Tenant: sess.Tenant}, true
+			}
+		}
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="cert-tracker"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		if !token.Scope.Allows(scope) {
+			s.log.Warn("API request denied",
+				"principal", token.Name,
+				"path", r.URL.Path,
+				"requiredScope", scope,
+			)
+			writeError(w, http.StatusForbidden, "token lacks the "+string(scope)+" scope")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenKey, token)))
+	})
+}
+
+func (s *Server) lookupToken(r *http.Request) (cfg.APIToken, bool) {
+	presented, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || presented == "" {
+		return cfg.APIToken{}, false
+	}
+	// compare against every token so timing doesn't reveal which one matched
+	var match cfg.APIToken
+	found := false
+	for _, t := range s.config.Tokens {
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(t.Token)) == 1 {
+			match, found = t, true
+		}
+	}
+	return match, found
+}
+
+// tenantOf returns the tenant the request is restricted to, or "" for all tenants.
+func tenantOf(r *http.Request) string {
+	token, _ := r.Context().Value(tokenKey).(cfg.APIToken)
+	return token.Tenant
+}
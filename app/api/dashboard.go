@@ -0,0 +1,47 @@
+package api
+
+import (
+	"cert-tracker/cfg"
+	_ "embed"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+//go:embed dashboard.html
+var dashboardHTML string
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(dashboardHTML))
+
+type dashboardPage struct {
+	Time time.Time
+	Rows []*Target
+}
+
+// HandleDashboard serves an HTML overview of the latest scan at /,
+// coloring expiry dates within warning and critical.
+func (s *Server) HandleDashboard(warning, critical time.Duration) {
+	authenticated := s.authenticate(cfg.ScopeRead, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		latest := s.latest.Load()
+		if latest == nil {
+			http.Error(w, "no scan has completed yet", http.StatusServiceUnavailable)
+			return
+		}
+		page := dashboardPage{Time: latest.Time, Rows: summarize(*latest, tenantOf(r), warning, critical)}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := dashboardTemplate.Execute(w, page); err != nil {
+			s.log.Error("cannot render dashboard", "error", err)
+		}
+	}))
+	s.mux.Handle("GET /{$}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// browsers can't present a bearer token, so send them to log in
+		if _, ok := s.lookupToken(r); !ok && s.oidc != nil {
+			if _, found := s.readSession(r); !found {
+				http.Redirect(w, r, "/auth/login?next="+url.QueryEscape(r.URL.Path), http.StatusFound)
+				return
+			}
+		}
+		authenticated.ServeHTTP(w, r)
+	}))
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDashboard(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	leaf := func(hostname cfg.Hostname, ip string, notAfter time.Duration) store.Certificate {
+		return store.Certificate{Hostname: hostname, IPAddress: net.ParseIP(ip), NotAfter: now.Add(notAfter)}
+	}
+	snapshot := store.Snapshot{
+		Time: now,
+		Certificates: []store.Certificate{
+			leaf("ok.example.com", "192.0.2.1", 90*day),
+			leaf("soon.example.com", "192.0.2.2", 20*day),
+			leaf("soon.example.com", "192.0.2.3", 5*day),
+			leaf("gone.example.com", "192.0.2.4", -day),
+			{Hostname: "ok.example.com", Index: 1, IPAddress: net.ParseIP("192.0.2.1"), NotAfter: now.Add(-day)},
+		},
+		Failures: []store.Failure{
+			{Hostname: "down.example.com", IPAddress: net.ParseIP("192.0.2.5"), Error: "connection refused"},
+		},
+	}
+
+	page := dashboardPage{Rows: summarize(snapshot, "", 30*day, 7*day)}
+	want := map[cfg.Hostname]string{
+		"down.example.com": "unknown",
+		"gone.example.com": "expired",
+		"ok.example.com":   "ok",
+		"soon.example.com": "critical",
+	}
+	if len(page.Rows) != len(want) {
+		t.Fatalf("dashboard() has %d rows, want %d", len(page.Rows), len(want))
+	}
+	for _, r := range page.Rows {
+		if r.Status != want[r.Hostname] {
+			t.Errorf("%s status = %s, want %s", r.Hostname, r.Status, want[r.Hostname])
+		}
+	}
+	if down := page.Rows[0]; len(down.Errors) != 1 || !strings.Contains(down.Errors[0], "connection refused") {
+		t.Errorf("down.example.com errors = %v", down.Errors)
+	}
+	if soon := page.Rows[3]; len(soon.Addresses) != 2 || soon.DaysLeft != 5 {
+		t.Errorf("soon.example.com = %+v, want both addresses and the earliest expiry", soon)
+	}
+
+	s := newTestServer()
+	s.HandleDashboard(30*day, 7*day)
+	if w := request(s, "GET", "/", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	s.SetSnapshot(snapshot)
+	w := request(s, "GET", "/", "read-token")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `class="expired"`) {
+		t.Errorf("GET / = %d %s", w.Code, w.Body)
+	}
+}
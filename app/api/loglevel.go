@@ -0,0 +1,44 @@
+package api
+
+import (
+	"cert-tracker/cfg"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+type logLevel struct {
+	Level string `json:"level"`
+}
+
+// HandleLogLevel lets readers see and admins change level without a restart.
+func (s *Server) HandleLogLevel(level *slog.LevelVar) {
+	s.Handle("GET /api/v1/loglevel", cfg.ScopeRead, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, logLevel{Level: level.Level().String()})
+	}))
+	s.Handle("PUT /api/v1/loglevel", cfg.ScopeAdmin, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the level is shared by every tenant
+		if tenantOf(r) != "" {
+			writeError(w, http.StatusForbidden, "tenant tokens cannot change the log level")
+			return
+		}
+		var body logLevel
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(body.Level)); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		token, _ := r.Context().Value(tokenKey).(cfg.APIToken)
+		s.log.Info("log level changed",
+			"from", level.Level(),
+			"to", l,
+			"principal", token.Name,
+		)
+		level.Set(l)
+		writeJSON(w, http.StatusOK, logLevel{Level: l.String()})
+	}))
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"cert-tracker/cfg"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogLevel(t *testing.T) {
+	s := New(cfg.API{
+		Tokens: []cfg.APIToken{
+			{Name: "dashboard", Token: "read-token", Scope: cfg.ScopeRead},
+			{Name: "ops", Token: "admin-token", Scope: cfg.ScopeAdmin},
+			{Name: "payments", Token: "tenant-token", Scope: cfg.ScopeAdmin, Tenant: "payments"},
+		},
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	level := new(slog.LevelVar)
+	s.HandleLogLevel(level)
+
+	put := func(token, body string) int {
+		r := httptest.NewRequest("PUT", "/api/v1/loglevel", strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		body  string
+		want  int
+		level slog.Level
+	}{
+		{name: "read token denied", token: "read-token", body: `{"level":"debug"}`, want: http.StatusForbidden, level: slog.LevelInfo},
+		{name: "tenant token denied", token: "tenant-token", body: `{"level":"debug"}`, want: http.StatusForbidden, level: slog.LevelInfo},
+		{name: "unknown level", token: "admin-token", body: `{"level":"verbose"}`, want: http.StatusBadRequest, level: slog.LevelInfo},
+		{name: "admin lowers level", token: "admin-token", body: `{"level":"debug"}`, want: http.StatusOK, level: slog.LevelDebug},
+		{name: "admin raises level", token: "admin-token", body: `{"level":"WARN"}`, want: http.StatusOK, level: slog.LevelWarn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := put(tt.token, tt.body); got != tt.want {
+				t.Errorf("PUT /api/v1/loglevel status = %d, want %d", got, tt.want)
+			}
+			if level.Level() != tt.level {
+				t.Errorf("level = %v, want %v", level.Level(), tt.level)
+			}
+		})
+	}
+
+	w := request(s, "GET", "/api/v1/loglevel", "read-token")
+	var got logLevel
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Level != "WARN" {
+		t.Errorf("GET /api/v1/loglevel = %q, want WARN", got.Level)
+	}
+}
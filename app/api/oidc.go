@@ -0,0 +1,336 @@
+package api
+
+import (
+	"cert-tracker/cfg"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	loginCookie  = "cert-tracker-login"
+	loginTimeout = 10 * time.Minute
+)
+
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// provider discovers the OIDC endpoints on first use so an identity provider
+// outage doesn't stop the tracker from starting.
+type provider struct {
+	config cfg.OIDC
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *providerMetadata
+	keys     map[string]crypto.PublicKey
+}
+
+type loginState struct {
+	State string `json:"state"`
+	Nonce string `json:"nonce"`
+	Next  string `json:"next"`
+}
+
+func newProvider(config cfg.OIDC) *provider {
+	return &provider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *provider) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (p *provider) discover(ctx context.Context) (*providerMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+	var m providerMetadata
+	wellKnown := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, wellKnown, &m); err != nil {
+		return nil, err
+	}
+	if m.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("provider issuer %q does not match configured issuer %q", m.Issuer, p.config.Issuer)
+	}
+	p.metadata = &m
+	return p.metadata, nil
+}
+
+// key returns the signing key, refetching the key set when kid is unknown
+// so provider key rotation is picked up.
+func (p *provider) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	var set jwks
+	if err := p.getJSON(ctx, metadata.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	p.keys = make(map[string]crypto.PublicKey)
+	for _, k := range set.Keys {
+		if pub, err := k.publicKey(); err == nil {
+			p.keys[k.Kid] = pub
+		}
+	}
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("no signing key with id %q", kid)
+}
+
+func (p *provider) exchange(ctx context.Context, code string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {p.config.RedirectURL},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(string(p.config.ClientSecret)))
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint: %s", resp.Status)
+	}
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return "", err
+	}
+	if tokens.IDToken == "" {
+		return "", errors.New("token response has no id_token")
+	}
+	return tokens.IDToken, nil
+}
+
+func (p *provider) verify(ctx context.Context, idToken, nonce string) (map[string]any, error) {
+	claims, err := verifyJWT(idToken, func(kid string) (crypto.PublicKey, error) {
+		return p.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims["iss"] != p.config.Issuer {
+		return nil, errors.New("ID token issuer mismatch")
+	}
+	if !audienceContains(claims["aud"], p.config.ClientID) {
+		return nil, errors.New("ID token audience mismatch")
+	}
+	if exp, ok := claims["exp"].(float64); !ok || time.Now().Unix() > int64(exp) {
+		return nil, errors.New("ID token expired")
+	}
+	if claims["nonce"] != nonce {
+		return nil, errors.New("ID token nonce mismatch")
+	}
+	return claims, nil
+}
+
+func audienceContains(aud any, clientID string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientID
+	case []any:
+		return slices.Contains(v, any(clientID))
+	}
+	return false
+}
+
+// groups returns the groups the user's claims list.
+func (p *provider) groups(claims map[string]any) []string {
+	var groups []string
+	if list, ok := claims[p.config.GroupsClaim].([]any); ok {
+		for _, g := range list {
+			if s, ok := g.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+	}
+	return groups
+}
+
+// scope maps the user's groups to an API scope, or "" when they have none.
+func (p *provider) scope(claims map[string]any) cfg.Scope {
+	groups := p.groups(claims)
+	inAny := func(allowed []string) bool {
+		return slices.ContainsFunc(groups, func(g string) bool { return slices.Contains(allowed, g) })
+	}
+	switch {
+	case inAny(p.config.AdminGroups):
+		return cfg.ScopeAdmin
+	case len(p.config.ReadGroups) == 0 || inAny(p.config.ReadGroups):
+		return cfg.ScopeRead
+	}
+	return ""
+}
+
+// tenant maps the user's groups to the tenant they're restricted to, or ""
+// for every tenant. It reports false when TenantGroups is set and the
+// groups map to no tenant, or to more than one.
+func (p *provider) tenant(claims map[string]any) (string, bool) {
+	if len(p.config.TenantGroups) == 0 {
+		return "", true
+	}
+	var tenants []string
+	for _, g := range p.groups(claims) {
+		if t, ok := p.config.TenantGroups[g]; ok && !slices.Contains(tenants, t) {
+			tenants = append(tenants, t)
+		}
+	}
+	switch {
+	case slices.Contains(tenants, cfg.AllTenants):
+		return "", true
+	case len(tenants) == 1:
+		return tenants[0], true
+	}
+	return "", false
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// safeNext only allows local redirects after login.
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	metadata, err := s.oidc.discover(r.Context())
+	if err != nil {
+		s.log.Error("OIDC discovery failed", "error", err)
+		writeError(w, http.StatusBadGateway, "identity provider unavailable")
+		return
+	}
+	state := loginState{
+		State: randomString(),
+		Nonce: randomString(),
+		Next:  safeNext(r.URL.Query().Get("next")),
+	}
+	value, err := s.sign(state)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.setCookie(w, loginCookie, value, loginTimeout)
+
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "invalid authorization endpoint")
+		return
+	}
+	q := authURL.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", s.config.OIDC.ClientID)
+	q.Set("redirect_uri", s.config.OIDC.RedirectURL)
+	q.Set("scope", "openid profile email "+s.config.OIDC.GroupsClaim)
+	q.Set("state", state.State)
+	q.Set("nonce", state.Nonce)
+	authURL.RawQuery = q.Encode()
+	http.Redirect(w, r, authURL.String(), http.StatusFound)
+}
+
+func (s *Server) callback(w http.ResponseWriter, r *http.Request) {
+	var state loginState
+	cookie, err := r.Cookie(loginCookie)
+	if err != nil || !s.verify(cookie.Value, &state) || r.URL.Query().Get("state") != state.State {
+		writeError(w, http.StatusBadRequest, "invalid login state")
+		return
+	}
+	s.setCookie(w, loginCookie, "", -1)
+	if msg := r.URL.Query().Get("error"); msg != "" {
+		writeError(w, http.StatusUnauthorized, "login failed: "+msg)
+		return
+	}
+
+	idToken, err := s.oidc.exchange(r.Context(), r.URL.Query().Get("code"))
+	if err != nil {
+		s.log.Warn("OIDC code exchange failed", "error", err)
+		writeError(w, http.StatusBadGateway, "cannot exchange authorization code")
+		return
+	}
+	claims, err := s.oidc.verify(r.Context(), idToken, state.Nonce)
+	if err != nil {
+		s.log.Warn("OIDC ID token rejected", "error", err)
+		writeError(w, http.StatusUnauthorized, "invalid ID token")
+		return
+	}
+
+	subject, _ := claims["sub"].(string)
+	name, _ := claims["email"].(string)
+	scope := s.oidc.scope(claims)
+	if scope == "" {
+		s.log.Warn("OIDC login denied", "subject", subject, "name", name)
+		writeError(w, http.StatusForbidden, "not a member of an authorized group")
+		return
+	}
+	tenant, ok := s.oidc.tenant(claims)
+	if !ok {
+		s.log.Warn("OIDC login denied; no single tenant", "subject", subject, "name", name)
+		writeError(w, http.StatusForbidden, "not a member of exactly one tenant group")
+		return
+	}
+	if err := s.startSession(w, session{Subject: subject, Name: name, Scope: scope, Tenant: tenant}); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.log.Info("OIDC login", "subject", subject, "name", name, "scope", scope, "tenant", tenant)
+	http.Redirect(w, r, state.Next, http.StatusFound)
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	s.setCookie(w, sessionCookie, "", -1)
+	http.Redirect(w, r, "/", http.StatusFound)
+}
@@ -0,0 +1,317 @@
+package api
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+)
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// fakeProvider is a minimal OpenID Connect provider that issues an ID token
+// with the configured groups for any authorization code.
+type fakeProvider struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	groups []string
+	nonce  string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &fakeProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(providerMetadata{
+			Issuer:                p.URL,
+			AuthorizationEndpoint: p.URL + "/authorize",
+			TokenEndpoint:         p.URL + "/token",
+			JWKSURI:               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwks{Keys: []jwk{{
+			Kty: "RSA",
+			Kid: "test",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, _ := r.BasicAuth(); id != "tracker" || secret != "secret" {
+			http.Error(w, "bad client", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"id_token": signRS256(t, key, "test", map[string]any{
+				"iss":    p.URL,
+				"aud":    "tracker",
+				"sub":    "user-1",
+				"email":  "user@example.com",
+				"exp":    time.Now().Add(time.Hour).Unix(),
+				"nonce":  p.nonce,
+				"groups": p.groups,
+			}),
+		})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// loginAs runs the authorization code flow and returns the final callback response.
+func loginAs(t *testing.T, s *Server, p *fakeProvider, groups []string) *httptest.ResponseRecorder {
+	t.Helper()
+	p.groups = groups
+
+	w := request(s, "GET", "/auth/login?next=/api/v1/snapshot", "")
+	if w.Code != http.StatusFound {
+		t.Fatalf("login status = %d, want redirect", w.Code)
+	}
+	authURL, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.nonce = authURL.Query().Get("nonce")
+
+	r := httptest.NewRequest("GET", "/auth/callback?code=abc&state="+authURL.Query().Get("state"), nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	callback := httptest.NewRecorder()
+	s.ServeHTTP(callback, r)
+	return callback
+}
+
+func newOIDCServer(p *fakeProvider) *Server {
+	return New(cfg.API{
+		OIDC: cfg.OIDC{
+			Issuer:       p.URL,
+			ClientID:     "tracker",
+			ClientSecret: "secret",
+			RedirectURL:  "https://tracker.example.com/auth/callback",
+			GroupsClaim:  "groups",
+			ReadGroups:   []string{"sre"},
+			AdminGroups:  []string{"platform-admins"},
+		},
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestOIDCLogin(t *testing.T) {
+	p := newFakeProvider(t)
+	s := newOIDCServer(p)
+	s.SetSnapshot(store.Snapshot{Time: time.Now()})
+
+	callback := loginAs(t, s, p, []string{"sre"})
+	if callback.Code != http.StatusFound {
+		t.Fatalf("callback status = %d, body %s", callback.Code, callback.Body)
+	}
+	if got := callback.Header().Get("Location"); got != "/api/v1/snapshot" {
+		t.Errorf("callback redirect = %q, want original path", got)
+	}
+
+	var sessionCookies []*http.Cookie
+	for _, c := range callback.Result().Cookies() {
+		if c.Name == sessionCookie {
+			sessionCookies = append(sessionCookies, c)
+		}
+	}
+	if len(sessionCookies) != 1 {
+		t.Fatalf("Expected a session cookie, got %v", callback.Result().Cookies())
+	}
+	if !sessionCookies[0].HttpOnly || !sessionCookies[0].Secure {
+		t.Error("Expected HttpOnly and Secure session cookie")
+	}
+
+	r := httptest.NewRequest("GET", "/api/v1/snapshot", nil)
+	r.AddCookie(sessionCookies[0])
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("snapshot with session status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	tampered := *sessionCookies[0]
+	tampered.Value = "x" + tampered.Value
+	r = httptest.NewRequest("GET", "/api/v1/snapshot", nil)
+	r.AddCookie(&tampered)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("snapshot with tampered session status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestOIDCGroupMapping(t *testing.T) {
+	p := newFakeProvider(t)
+	s := newOIDCServer(p)
+
+	tests := []struct {
+		name   string
+		groups []string
+		want   int
+	}{
+		{name: "reader", groups: []string{"sre"}, want: http.StatusFound},
+		{name: "admin", groups: []string{"platform-admins"}, want: http.StatusFound},
+		{name: "no authorized group", groups: []string{"marketing"}, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := loginAs(t, s, p, tt.groups).Code; got != tt.want {
+				t.Errorf("callback status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOIDCTenantMapping(t *testing.T) {
+	p := newFakeProvider(t)
+	config := newOIDCServer(p).config
+	config.OIDC.ReadGroups = []string{"sre", "payments-team", "web-team", "auditors"}
+	config.OIDC.TenantGroups = map[string]string{"sre": cfg.AllTenants, "payments-team": "payments", "web-team": "web"}
+	s := New(config, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.SetSnapshot(store.Snapshot{Time: time.Now(), Certificates: []store.Certificate{
+		{Tenant: "payments", Hostname: "pay.example.com"},
+		{Tenant: "web", Hostname: "www.example.com"},
+	}})
+
+	tests := []struct {
+		name        string
+		groups      []string
+		wantStatus  int
+		wantTenants []string
+	}{
+		{name: "one tenant", groups: []string{"payments-team"}, wantStatus: http.StatusFound, wantTenants: []string{"payments"}},
+		{name: "every tenant", groups: []string{"sre", "web-team"}, wantStatus: http.StatusFound, wantTenants: []string{"payments", "web"}},
+		{name: "no tenant group", groups: []string{"auditors"}, wantStatus: http.StatusForbidden},
+		{name: "several tenants", groups: []string{"payments-team", "web-team"}, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callback := loginAs(t, s, p, tt.groups)
+			if callback.Code != tt.wantStatus {
+				t.Fatalf("callback status = %d, want %d", callback.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusFound {
+				return
+			}
+			r := httptest.NewRequest("GET", "/api/v1/snapshot", nil)
+			for _, c := range callback.Result().Cookies() {
+				r.AddCookie(c)
+			}
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, r)
+			var got store.Snapshot
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("snapshot status = %d, body %s", w.Code, w.Body)
+			}
+			var tenants []string
+			for _, c := range got.Certificates {
+				tenants = append(tenants, c.Tenant)
+			}
+			if !slices.Equal(tenants, tt.wantTenants) {
+				t.Errorf("snapshot tenants = %v, want %v", tenants, tt.wantTenants)
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackRejectsForgedState(t *testing.T) {
+	p := newFakeProvider(t)
+	s := newOIDCServer(p)
+
+	w := request(s, "GET", "/auth/callback?code=abc&state=forged", "")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("callback without login cookie status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestSafeNext(t *testing.T) {
+	for input, want := range map[string]string{
+		"/dashboard":          "/dashboard",
+		"":                    "/",
+		"https://evil.test/":  "/",
+		"//evil.test/":        "/",
+		"/\\evil.test":        "/",
+		"/api/v1/snapshot?x=": "/api/v1/snapshot?x=",
+	} {
+		if got := safeNext(input); got != want {
+			t.Errorf("safeNext(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestVerifyJWT(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(alg string, claims map[string]any) string {
+		header, _ := json.Marshal(map[string]string{"alg": alg, "kid": "ec"})
+		payload, _ := json.Marshal(claims)
+		input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+		digest := sha256.Sum256([]byte(input))
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+	keyFor := func(kid string) (crypto.PublicKey, error) { return &ecKey.PublicKey, nil }
+
+	claims, err := verifyJWT(sign("ES256", map[string]any{"sub": "user-1"}), keyFor)
+	if err != nil {
+		t.Fatalf("verifyJWT() error = %v", err)
+	}
+	if claims["sub"] != "user-1" {
+		t.Errorf("claims = %v", claims)
+	}
+
+	if _, err := verifyJWT(sign("none", nil), keyFor); err == nil {
+		t.Error("Expected alg none to be rejected")
+	}
+	if _, err := verifyJWT("not.a.jwt.at.all", keyFor); err == nil {
+		t.Error("Expected malformed token to be rejected")
+	}
+
+	token := sign("ES256", map[string]any{"sub": "user-1"})
+	forged := token[:len(token)-4] + "AAAA"
+	if _, err := verifyJWT(forged, keyFor); err == nil {
+		t.Error("Expected forged signature to be rejected")
+	}
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+// Progress describes the scan cycle in flight, or the last one once Running is false.
+type Progress struct {
+	Running  bool      `json:"running"`
+	Started  time.Time `json:"started"`
+	Done     int       `json:"done"`
+	Total    int       `json:"total"`
+	ETA      time.Time `json:"eta,omitzero"`
+	Finished time.Time `json:"finished,omitzero"`
+	// Stretch is how many cycles low-priority targets wait between scans,
+	// more than 1 when cycles take nearly the whole scan interval.
+	Stretch int `json:"stretch"`
+}
+
+// SetProgress publishes how far the current scan cycle has got.
+func (s *Server) SetProgress(p Progress) {
+	s.progress.Store(&p)
+}
+
+func (s *Server) scanProgress(w http.ResponseWriter, r *http.Request) {
+	p := s.progress.Load()
+	if p == nil {
+		writeError(w, http.StatusServiceUnavailable, "no scan has started yet")
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"cert-tracker/cfg"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	sessionCookie  = "cert-tracker-session"
+	sessionTimeout = 8 * time.Hour
+)
+
+type session struct {
+	Subject string    `json:"sub"`
+	Name    string    `json:"name,omitempty"`
+	Scope   cfg.Scope `json:"scope"`
+	Tenant  string    `json:"tenant,omitempty"`
+	Expires int64     `json:"exp"`
+}
+
+// sign encodes v with an HMAC so it can be stored in a cookie without server state.
+func (s *Server) sign(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload)), nil
+}
+
+func (s *Server) verify(value string, v any) bool {
+	payload, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return false
+	}
+	got, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(got, s.mac(payload)) {
+		return false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+func (s *Server) mac(payload string) []byte {
+	h := hmac.New(sha256.New, s.sessionKey)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+func (s *Server) setCookie(w http.ResponseWriter, name, value string, maxAge time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   s.config.TLS.CertFile != "" || strings.HasPrefix(s.config.OIDC.RedirectURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (s *Server) startSession(w http.ResponseWriter, sess session) error {
+	sess.Expires = time.Now().Add(sessionTimeout).Unix()
+	value, err := s.sign(sess)
+	if err != nil {
+		return err
+	}
+	s.setCookie(w, sessionCookie, value, sessionTimeout)
+	return nil
+}
+
+func (s *Server) readSession(r *http.Request) (session, bool) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return session{}, false
+	}
+	var sess session
+	if !s.verify(cookie.Value, &sess) || time.Now().Unix() > sess.Expires {
+		return session{}, false
+	}
+	return sess, true
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// ErrUnknownTarget is returned by a scan function for hostnames that
+// aren't monitored.
+var ErrUnknownTarget = errors.New("no such target")
+
+// Target summarizes one monitored hostname in the latest scan.
+type Target struct {
+	Tenant    string       `json:"tenant,omitempty"`
+	Hostname  cfg.Hostname `json:"hostname"`
+	Addresses []string     `json:"addresses"`
+	// NotAfter is the earliest leaf certificate expiry among Addresses.
+	NotAfter time.Time `json:"notAfter,omitzero"`
+	DaysLeft int       `json:"daysLeft"`
+	// Status is ok, warning, critical or expired by NotAfter, or unknown
+	// when no certificate was captured.
+	Status string   `json:"status"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// HandleTargets serves the monitored hostnames and their certificates,
+// judging expiry against warning and critical.
+func (s *Server) HandleTargets(warning, critical time.Duration) {
+	s.Handle("GET /api/v1/targets", cfg.ScopeRead, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		latest := s.latest.Load()
+		if latest == nil {
+			writeError(w, http.StatusServiceUnavailable, "no scan has completed yet")
+			return
+		}
+		targets := summarize(*latest, tenantOf(r), warning, critical)
+		if targets == nil {
+			targets = []*Target{}
+		}
+		writeJSON(w, http.StatusOK, targets)
+	}))
+	s.Handle("GET /api/v1/targets/{host}/certs", cfg.ScopeRead, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hostname, err := cfg.ParseHostname(r.PathValue("host"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid hostname")
+			return
+		}
+		latest := s.latest.Load()
+		if latest == nil {
+			writeError(w, http.StatusServiceUnavailable, "no scan has completed yet")
+			return
+		}
+		scoped := scope(*latest, tenantOf(r), hostname)
+		if len(scoped.Certificates) == 0 && len(scoped.Failures) == 0 {
+			writeError(w, http.StatusNotFound, ErrUnknownTarget.Error())
+			return
+		}
+		if scoped.Certificates == nil {
+			scoped.Certificates = []store.Certificate{}
+		}
+		writeJSON(w, http.StatusOK, scoped.Certificates)
+	}))
+}
+
+// HandleScan lets admins scan a single hostname right away. scan returns
+// the hostname's results, which replace its part of the latest snapshot.
+func (s *Server) HandleScan(scan func(ctx context.Context, hostname cfg.Hostname) (store.Snapshot, error)) {
+	s.Handle("POST /api/v1/scan", cfg.ScopeAdmin, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Hostname string `json:"hostname"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Hostname == "" {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		hostname, err := cfg.ParseHostname(body.Hostname)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid hostname")
+			return
+		}
+		// tenant tokens may only scan their own hostnames
+		if tenant := tenantOf(r); tenant != "" {
+			latest := s.latest.Load()
+			if latest == nil {
+				writeError(w, http.StatusNotFound, ErrUnknownTarget.Error())
+				return
+			}
+			if scoped := scope(*latest, tenant, hostname); len(scoped.Certificates) == 0 && len(scoped.Failures) == 0 {
+				writeError(w, http.StatusNotFound, ErrUnknownTarget.Error())
+				return
+			}
+		}
+		scanned, err := scan(r.Context(), hostname)
+		switch {
+		case errors.Is(err, ErrUnknownTarget):
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		case err != nil:
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		s.replace(hostname, scanned)
+		writeJSON(w, http.StatusOK, scope(scanned, tenantOf(r), hostname))
+	}))
+}
+
+// replace swaps hostname's certificates and failures in the latest
+// snapshot for those in scanned.
+func (s *Server) replace(hostname cfg.Hostname, scanned store.Snapshot) {
+	for {
+		latest := s.latest.Load()
+		var next store.Snapshot
+		if latest != nil {
+			next = *latest
+		}
+		next.Certificates = slices.DeleteFunc(slices.Clone(next.Certificates), func(c store.Certificate) bool {
+			return c.Hostname == hostname
+		})
+		next.Failures = slices.DeleteFunc(slices.Clone(next.Failures), func(f store.Failure) bool {
+			return f.Hostname == hostname
+		})
+		next.Certificates = append(next.Certificates, scanned.Certificates...)
+		next.Failures = append(next.Failures, scanned.Failures...)
+		if next.Time.IsZero() {
+			next.Time = scanned.Time
+		}
+		// a scan cycle may have published meanwhile
+		if s.latest.CompareAndSwap(latest, &next) {
+			return
+		}
+	}
+}
+
+// scope returns the certificates and failures of snapshot belonging to
+// tenant, or to any tenant if it's "", and to hostname unless it's "".
+func scope(snapshot store.Snapshot, tenant string, hostname cfg.Hostname) store.Snapshot {
+	scoped := store.Snapshot{Time: snapshot.Time}
+	for _, c := range snapshot.Certificates {
+		if (tenant == "" || c.Tenant == tenant) && (hostname == "" || c.Hostname == hostname) {
+			scoped.Certificates = append(scoped.Certificates, c)
+		}
+	}
+	for _, f := range snapshot.Failures {
+		if (tenant == "" || f.Tenant == tenant) && (hostname == "" || f.Hostname == hostname) {
+			scoped.Failures = append(scoped.Failures, f)
+		}
+	}
+	return scoped
+}
+
+// summarize groups the leaf certificates and failures of snapshot by tenant
+// and hostname.
+func summarize(snapshot store.Snapshot, tenant string, warning, critical time.Duration) []*Target {
+	snapshot = scope(snapshot, tenant, "")
+	type key struct {
+		tenant   string
+		hostname cfg.Hostname
+	}
+	byKey := map[key]*Target{}
+	target := func(t string, h cfg.Hostname) *Target {
+		k := key{t, h}
+		if byKey[k] == nil {
+			byKey[k] = &Target{Tenant: t, Hostname: h, Status: "unknown"}
+		}
+		return byKey[k]
+	}
+	for _, c := range snapshot.Certificates {
+		if c.Index != 0 {
+			continue
+		}
+		t := target(c.Tenant, c.Hostname)
+		if c.IPAddress != nil {
+			t.Addresses = append(t.Addresses, c.IPAddress.String())
+		}
+		// the address closest to expiry decides the status
+		if t.NotAfter.IsZero() || c.NotAfter.Before(t.NotAfter) {
+			t.NotAfter = c.NotAfter
+		}
+	}
+	for _, f := range snapshot.Failures {
+		t := target(f.Tenant, f.Hostname)
+		if f.IPAddress == nil {
+			t.Errors = append(t.Errors, f.Error)
+			continue
+		}
+		t.Addresses = append(t.Addresses, f.IPAddress.String())
+		t.Errors = append(t.Errors, f.IPAddress.String()+": "+f.Error)
+	}
+
+	var targets []*Target
+	for _, t := range byKey {
+		slices.Sort(t.Addresses)
+		t.Addresses = slices.Compact(t.Addresses)
+		if !t.NotAfter.IsZero() {
+			remaining := t.NotAfter.Sub(snapshot.Time)
+			t.DaysLeft = int(remaining / (24 * time.Hour))
+			switch {
+			case remaining <= 0:
+				t.Status = "expired"
+			case remaining <= critical:
+				t.Status = "critical"
+			case remaining <= warning:
+				t.Status = "warning"
+			default:
+				t.Status = "ok"
+			}
+		}
+		targets = append(targets, t)
+	}
+	slices.SortFunc(targets, func(a, b *Target) int {
+		return cmp.Or(cmp.Compare(a.Hostname, b.Hostname), cmp.Compare(a.Tenant, b.Tenant))
+	})
+	return targets
+}
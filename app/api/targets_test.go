@@ -0,0 +1,119 @@
+package api
+
+import (
+	"bytes"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTargetsEndpoints(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	s := newTestServer()
+	s.HandleTargets(30*24*time.Hour, 7*24*time.Hour)
+	s.SetSnapshot(store.Snapshot{
+		Time: now,
+		Certificates: []store.Certificate{
+			{Hostname: "example.com", IPAddress: net.ParseIP("192.0.2.1"), NotAfter: now.Add(90 * 24 * time.Hour)},
+			{Hostname: "example.com", IPAddress: net.ParseIP("192.0.2.1"), Index: 1},
+			{Hostname: "example.com:8443", IPAddress: net.ParseIP("192.0.2.1"), NotAfter: now.Add(-time.Hour)},
+		},
+	})
+
+	w := request(s, "GET", "/api/v1/targets", "read-token")
+	var targets []Target
+	if err := json.NewDecoder(w.Body).Decode(&targets); err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 || targets[0].Status != "ok" || targets[1].Status != "expired" {
+		t.Errorf("targets = %+v", targets)
+	}
+
+	tests := []struct {
+		path      string
+		wantCode  int
+		wantCerts int
+	}{
+		{path: "/api/v1/targets/example.com/certs", wantCode: http.StatusOK, wantCerts: 2},
+		{path: "/api/v1/targets/example.com:443/certs", wantCode: http.StatusOK, wantCerts: 2},
+		{path: "/api/v1/targets/example.com:8443/certs", wantCode: http.StatusOK, wantCerts: 1},
+		{path: "/api/v1/targets/other.example.com/certs", wantCode: http.StatusNotFound},
+		{path: "/api/v1/targets/not_a_host/certs", wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := request(s, "GET", tt.path, "read-token")
+		if w.Code != tt.wantCode {
+			t.Errorf("GET %s status = %d, want %d", tt.path, w.Code, tt.wantCode)
+			continue
+		}
+		if tt.wantCode != http.StatusOK {
+			continue
+		}
+		var certs []store.Certificate
+		if err := json.NewDecoder(w.Body).Decode(&certs); err != nil {
+			t.Fatal(err)
+		}
+		if len(certs) != tt.wantCerts {
+			t.Errorf("GET %s returned %d certificates, want %d", tt.path, len(certs), tt.wantCerts)
+		}
+	}
+}
+
+func TestScanEndpoint(t *testing.T) {
+	s := New(cfg.API{
+		Tokens: []cfg.APIToken{
+			{Name: "ops", Token: "admin-token", Scope: cfg.ScopeAdmin},
+			{Name: "payments", Token: "payments-token", Scope: cfg.ScopeAdmin, Tenant: "payments"},
+		},
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.SetSnapshot(store.Snapshot{
+		Certificates: []store.Certificate{
+			{Hostname: "example.com", SHA256Fingerprint: "old"},
+			{Hostname: "other.example.com", SHA256Fingerprint: "other"},
+		},
+	})
+	var scanned []cfg.Hostname
+	s.HandleScan(func(ctx context.Context, hostname cfg.Hostname) (store.Snapshot, error) {
+		scanned = append(scanned, hostname)
+		if hostname != "example.com" {
+			return store.Snapshot{}, ErrUnknownTarget
+		}
+		return store.Snapshot{Certificates: []store.Certificate{{Hostname: "example.com", SHA256Fingerprint: "new"}}}, nil
+	})
+
+	scan := func(token, hostname string) int {
+		body, _ := json.Marshal(map[string]string{"hostname": hostname})
+		r := httptest.NewRequest("POST", "/api/v1/scan", bytes.NewReader(body))
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		return w.Code
+	}
+	if code := scan("admin-token", "unknown.example.com"); code != http.StatusNotFound {
+		t.Errorf("scan of unknown hostname = %d, want %d", code, http.StatusNotFound)
+	}
+	if code := scan("payments-token", "example.com"); code != http.StatusNotFound {
+		t.Errorf("scan of another tenant's hostname = %d, want %d", code, http.StatusNotFound)
+	}
+	if code := scan("admin-token", "example.com"); code != http.StatusOK {
+		t.Fatalf("scan = %d, want %d", code, http.StatusOK)
+	}
+	if len(scanned) != 2 {
+		t.Errorf("scanned %v, want the tenant's request refused before scanning", scanned)
+	}
+	fingerprints := map[string]bool{}
+	for _, c := range s.latest.Load().Certificates {
+		fingerprints[c.SHA256Fingerprint] = true
+	}
+	if len(fingerprints) != 2 || !fingerprints["new"] || !fingerprints["other"] {
+		t.Errorf("latest snapshot has %v, want the new certificate and the other hostname's", fingerprints)
+	}
+}
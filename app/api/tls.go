@@ -0,0 +1,87 @@
+package api
+
+import (
+	"cert-tracker/cfg"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// keyPair reloads the serving certificate when either file changes on disk,
+// so renewing it doesn't need a restart.
+type keyPair struct {
+	certFile, keyFile string
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modified time.Time
+}
+
+func (k *keyPair) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	modified, err := latestModTime(k.certFile, k.keyFile)
+	if err != nil && k.cert != nil {
+		// keep serving the old pair while a renewal is being written
+		return k.cert, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if k.cert != nil && !modified.After(k.modified) {
+		return k.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(k.certFile, k.keyFile)
+	if err != nil {
+		if k.cert != nil {
+			return k.cert, nil
+		}
+		return nil, err
+	}
+	k.cert, k.modified = &cert, modified
+	return k.cert, nil
+}
+
+func latestModTime(paths ...string) (time.Time, error) {
+	var latest time.Time
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func tlsConfig(c cfg.TLS) (*tls.Config, error) {
+	pair := &keyPair{certFile: c.CertFile, keyFile: c.KeyFile}
+	// fail at startup rather than on the first handshake
+	if _, err := pair.getCertificate(nil); err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: pair.getCertificate,
+	}
+	if c.ClientCAFile == "" {
+		return config, nil
+	}
+	pem, err := os.ReadFile(c.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no certificates found in clientCAFile")
+	}
+	config.ClientCAs = pool
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	return config, nil
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"cert-tracker/cfg"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeKeyPair writes a self-signed certificate and key and returns their paths.
+func writeKeyPair(t *testing.T, dir, commonName string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, commonName+".crt")
+	keyFile := filepath.Join(dir, commonName+".key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeKeyPair(t, dir, "server")
+	caFile, _ := writeKeyPair(t, dir, "client-ca")
+
+	tests := []struct {
+		name       string
+		config     cfg.TLS
+		wantErr    bool
+		clientAuth tls.ClientAuthType
+	}{
+		{
+			name:       "server certificate only",
+			config:     cfg.TLS{CertFile: certFile, KeyFile: keyFile},
+			clientAuth: tls.NoClientCert,
+		},
+		{
+			name:       "client certificates required",
+			config:     cfg.TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile},
+			clientAuth: tls.RequireAndVerifyClientCert,
+		},
+		{
+			name:    "missing key file",
+			config:  cfg.TLS{CertFile: certFile, KeyFile: filepath.Join(dir, "missing.key")},
+			wantErr: true,
+		},
+		{
+			name:    "client CA without certificates",
+			config:  cfg.TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := tlsConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tlsConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if config.ClientAuth != tt.clientAuth {
+				t.Errorf("ClientAuth = %v, want %v", config.ClientAuth, tt.clientAuth)
+			}
+			if config.MinVersion != tls.VersionTLS12 {
+				t.Errorf("MinVersion = %x, want TLS 1.2", config.MinVersion)
+			}
+		})
+	}
+}
+
+func TestKeyPairReloadsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeKeyPair(t, dir, "server")
+	pair := &keyPair{certFile: certFile, keyFile: keyFile}
+
+	first, err := pair.getCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// replace the pair and make sure the new modification time is later
+	writeKeyPair(t, dir, "server")
+	later := time.Now().Add(time.Minute)
+	for _, f := range []string{certFile, keyFile} {
+		if err := os.Chtimes(f, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	second, err := pair.getCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Leaf.SerialNumber.Cmp(second.Leaf.SerialNumber) == 0 {
+		t.Error("Expected renewed certificate to be served")
+	}
+
+	// a half-written renewal keeps the previous pair in service
+	if err := os.WriteFile(keyFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	evenLater := later.Add(time.Minute)
+	os.Chtimes(keyFile, evenLater, evenLater)
+	third, err := pair.getCertificate(nil)
+	if err != nil {
+		t.Fatalf("Expected previous certificate while renewal is broken, got %v", err)
+	}
+	if third != second {
+		t.Error("Expected previous certificate while renewal is broken")
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const defaultPort = "443"
+
+type burnInResult struct {
+	Handshakes int
+	// successful handshake latencies, sorted
+	Latencies []time.Duration
+	Errors    map[string]int
+}
+
+func burnInCommand(args []string) int {
+	flags := flag.NewFlagSet("burnin", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: cert-tracker burnin [flags] host[:port]")
+		flags.PrintDefaults()
+	}
+	n := flags.Int("n", 100, "number of handshakes")
+	concurrency := flags.Int("concurrency", 10, "handshakes in flight at once")
+	timeout := flags.Duration("timeout", 5*time.Second, "timeout for each handshake")
+	serverName := flags.String("sni", "", "server name to send, defaults to the host")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 || *n < 1 || *concurrency < 1 {
+		flags.Usage()
+		return 2
+	}
+
+	address := flags.Arg(0)
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host, address = address, net.JoinHostPort(address, defaultPort)
+	}
+	if *serverName == "" {
+		*serverName = host
+	}
+
+	result := burnIn(address, *serverName, *n, *concurrency, *timeout)
+	if err := printBurnIn(os.Stdout, result); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if len(result.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// burnIn performs n handshakes against address, at most concurrency at a time.
+func burnIn(address, serverName string, n, concurrency int, timeout time.Duration) burnInResult {
+	result := burnInResult{Handshakes: n, Errors: make(map[string]int)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for range n {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			latency, err := handshake(address, serverName, timeout)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Errors[err.Error()]++
+				return
+			}
+			result.Latencies = append(result.Latencies, latency)
+		}()
+	}
+	wg.Wait()
+	slices.Sort(result.Latencies)
+	return result
+}
+
+// handshake measures connecting to address and completing a TLS handshake.
+func handshake(address, serverName string, timeout time.Duration) (time.Duration, error) {
+	start := time.Now()
+	conn, err := tls.DialWithDialer(
+		&net.Dialer{Timeout: timeout},
+		"tcp",
+		address,
+		&tls.Config{
+			InsecureSkipVerify: true,
+			ServerName:         serverName,
+		})
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	conn.Close()
+	return latency, nil
+}
+
+// percentile uses the nearest-rank method on sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+func printBurnIn(w io.Writer, r burnInResult) error {
+	failed := r.Handshakes - len(r.Latencies)
+	fmt.Fprintf(w, "%d handshakes, %d failed (%.1f%%)\n\n",
+		r.Handshakes,
+		failed,
+		100*float64(failed)/float64(r.Handshakes),
+	)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MIN\tP50\tP90\tP99\tMAX")
+	if len(r.Latencies) > 0 {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			r.Latencies[0].Round(time.Microsecond),
+			percentile(r.Latencies, 50).Round(time.Microsecond),
+			percentile(r.Latencies, 90).Round(time.Microsecond),
+			percentile(r.Latencies, 99).Round(time.Microsecond),
+			r.Latencies[len(r.Latencies)-1].Round(time.Microsecond),
+		)
+	}
+	if len(r.Errors) > 0 {
+		messages := make([]string, 0, len(r.Errors))
+		for m := range r.Errors {
+			messages = append(messages, m)
+		}
+		sort.Slice(messages, func(i, j int) bool {
+			return r.Errors[messages[i]] > r.Errors[messages[j]]
+		})
+		fmt.Fprintln(tw, "\nCOUNT\tERROR")
+		for _, m := range messages {
+			fmt.Fprintf(tw, "%d\t%s\n", r.Errors[m], m)
+		}
+	}
+	return tw.Flush()
+}
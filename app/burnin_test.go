@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{p: 0, want: 1 * time.Millisecond},
+		{p: 50, want: 50 * time.Millisecond},
+		{p: 90, want: 90 * time.Millisecond},
+		{p: 99, want: 99 * time.Millisecond},
+		{p: 100, want: 100 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := percentile(latencies, tt.p); got != tt.want {
+			t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile(nil) = %v, want 0", got)
+	}
+}
+
+func TestBurnIn(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "https://")
+
+	result := burnIn(address, "example.com", 20, 4, 5*time.Second)
+	if len(result.Errors) != 0 {
+		t.Fatalf("burnIn() errors = %v", result.Errors)
+	}
+	if len(result.Latencies) != 20 {
+		t.Errorf("burnIn() recorded %d latencies, want 20", len(result.Latencies))
+	}
+
+	server.Close()
+	result = burnIn(address, "example.com", 5, 2, time.Second)
+	if len(result.Latencies) != 0 || len(result.Errors) == 0 {
+		t.Errorf("burnIn() against a closed server = %+v, want only errors", result)
+	}
+
+	var out strings.Builder
+	if err := printBurnIn(&out, result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "5 handshakes, 5 failed (100.0%)") {
+		t.Errorf("Expected failure summary, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/dns"
+	"cert-tracker/store"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// caaIdentities maps the issuer organization of public CAs, lowercased, to
+// the domains they recognize in CAA records.
+var caaIdentities = map[string][]string{
+	"let's encrypt":                {"letsencrypt.org"},
+	"digicert inc":                 {"digicert.com", "symantec.com", "geotrust.com", "thawte.com", "rapidssl.com"},
+	"sectigo limited":              {"sectigo.com", "comodoca.com", "comodo.com", "usertrust.com"},
+	"zerossl":                      {"sectigo.com", "zerossl.com"},
+	"globalsign nv-sa":             {"globalsign.com"},
+	"amazon":                       {"amazon.com", "amazontrust.com", "awstrust.com", "amazonaws.com"},
+	"google trust services":        {"pki.goog"},
+	"google trust services llc":    {"pki.goog"},
+	"godaddy.com, inc.":            {"godaddy.com", "starfieldtech.com"},
+	"starfield technologies, inc.": {"starfieldtech.com", "godaddy.com"},
+	"entrust, inc.":                {"entrust.net", "affirmtrust.com"},
+	"ssl corp":                     {"ssl.com"},
+	"buypass as-983163327":         {"buypass.com"},
+	"microsoft corporation":        {"microsoft.com"},
+}
+
+// issuerIdentities returns the CAA identities of the CA that issued leaf.
+func issuerIdentities(leaf *x509.Certificate, configured map[string][]string) (string, []string) {
+	if len(leaf.Issuer.Organization) == 0 {
+		return leaf.Issuer.CommonName, nil
+	}
+	org := leaf.Issuer.Organization[0]
+	for name, ids := range configured {
+		if strings.EqualFold(name, org) {
+			return org, ids
+		}
+	}
+	return org, caaIdentities[strings.ToLower(org)]
+}
+
+// caaPolicy summarizes the records governing hostname, found at domain.
+func caaPolicy(hostname cfg.Hostname, records []dns.CAA, domain string) store.CAAPolicy {
+	policy := store.CAAPolicy{Hostname: hostname, Domain: domain, Problems: dns.Misconfigurations(records)}
+	for _, r := range records {
+		issuer := r.Issuer()
+		if issuer == "" {
+			issuer = ";"
+		}
+		switch r.Tag {
+		case "issue":
+			policy.Issue = append(policy.Issue, issuer)
+		case "issuewild":
+			policy.IssueWild = append(policy.IssueWild, issuer)
+		case "iodef":
+			policy.Iodef = append(policy.Iodef, r.Value)
+		}
+	}
+	return policy
+}
+
+// checkCAA reports the CAA policy of every hostname, raising an alert for
+// every hostname serving a certificate from a CA its CAA records don't
+// authorize, and a warning for records CAs can't make sense of.
+func checkCAA(client dns.Client, config cfg.CAA, targets []scanTarget, handshakes map[cfg.Hostname]*tls.ConnectionState, alerts *alert.Manager, now time.Time) []store.CAAPolicy {
+	tenants := tenantsByHostname(targets)
+	var policies []store.CAAPolicy
+	for _, hostname := range sortedHostnames(handshakes) {
+		leaf := handshakes[hostname].PeerCertificates[0]
+		records, domain, err := client.LookupCAA(hostname.Host())
+		// keep the alert state until a lookup succeeds
+		if err != nil {
+			log.Warn("cannot look up CAA records", "hostname", hostname, "error", err)
+			continue
+		}
+		policy := caaPolicy(hostname, records, domain)
+		issuer, identities := issuerIdentities(leaf, config.Identities)
+		policy.Issuer = issuer
+		for _, tenant := range tenants[hostname] {
+			alerts.Set(len(policy.Problems) > 0, alert.Alert{
+				Key:      alert.Key("caa-invalid", tenant, string(hostname)),
+				Severity: alert.Warning,
+				Summary:  fmt.Sprintf("the CAA records of %s are misconfigured: %s", domain, strings.Join(policy.Problems, "; ")),
+				Tenant:   tenant,
+				Labels:   map[string]string{"hostname": string(hostname), "domain": domain},
+				Since:    now,
+			})
+		}
+		if len(identities) == 0 {
+			log.Debug("unknown CAA identity; add it to caa.identities", "hostname", hostname, "issuer", issuer)
+			policies = append(policies, policy)
+			continue
+		}
+		wildcard := !slices.ContainsFunc(leaf.DNSNames, func(name string) bool {
+			return strings.EqualFold(name, hostname.Host())
+		})
+		authorized := dns.Authorizes(records, identities, wildcard)
+		policy.Status = "unauthorized"
+		if authorized {
+			policy.Status = "authorized"
+		}
+		policies = append(policies, policy)
+		for _, tenant := range tenants[hostname] {
+			alerts.Set(!authorized, alert.Alert{
+				Key:      alert.Key("caa-mismatch", tenant, string(hostname)),
+				Severity: alert.Critical,
+				Summary:  fmt.Sprintf("%s serves a certificate from %s, which the CAA records of %s don't authorize", hostname, issuer, domain),
+				Tenant:   tenant,
+				Labels:   map[string]string{"hostname": string(hostname), "issuer": issuer},
+				Since:    now,
+			})
+		}
+	}
+	return policies
+}
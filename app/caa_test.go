@@ -0,0 +1,57 @@
+package main
+
+import (
+	"cert-tracker/dns"
+	"cert-tracker/store"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestIssuerIdentities(t *testing.T) {
+	configured := map[string][]string{"Example Corp": {"pki.example.com"}}
+
+	tests := []struct {
+		name       string
+		issuer     pkix.Name
+		wantIssuer string
+		want       []string
+	}{
+		{name: "known CA", issuer: pkix.Name{Organization: []string{"Let's Encrypt"}, CommonName: "R3"}, wantIssuer: "Let's Encrypt", want: []string{"letsencrypt.org"}},
+		{name: "configured CA", issuer: pkix.Name{Organization: []string{"example corp"}}, wantIssuer: "example corp", want: []string{"pki.example.com"}},
+		{name: "unknown CA", issuer: pkix.Name{Organization: []string{"Nobody"}}, wantIssuer: "Nobody"},
+		{name: "no organization", issuer: pkix.Name{CommonName: "internal-ca"}, wantIssuer: "internal-ca"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer, got := issuerIdentities(&x509.Certificate{Issuer: tt.issuer}, configured)
+			if issuer != tt.wantIssuer || !slices.Equal(got, tt.want) {
+				t.Errorf("issuerIdentities() = %q, %v, want %q, %v", issuer, got, tt.wantIssuer, tt.want)
+			}
+		})
+	}
+}
+
+func TestCAAPolicy(t *testing.T) {
+	records := []dns.CAA{
+		{Tag: "issue", Value: "LetsEncrypt.org; validationmethods=dns-01"},
+		{Tag: "issue", Value: "digicert.com"},
+		{Tag: "issuewild", Value: ";"},
+		{Tag: "iodef", Value: "mailto:security@example.com"},
+		{Critical: true, Tag: "future", Value: "x"},
+	}
+	want := store.CAAPolicy{
+		Hostname:  "www.example.com",
+		Domain:    "example.com",
+		Issue:     []string{"letsencrypt.org", "digicert.com"},
+		IssueWild: []string{";"},
+		Iodef:     []string{"mailto:security@example.com"},
+		Problems:  []string{`unknown critical property "future" forbids all issuance`},
+	}
+	if got := caaPolicy("www.example.com", records, "example.com"); !reflect.DeepEqual(got, want) {
+		t.Errorf("caaPolicy() = %+v, want %+v", got, want)
+	}
+}
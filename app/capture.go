@@ -0,0 +1,103 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// capture records the TLS sessions of the hostnames being debugged; nil when
+// debug capture is off.
+var capture *debugCapture
+
+type debugCapture struct {
+	hostnames []cfg.Hostname
+	// keyLog receives the session secrets, or is nil
+	keyLog io.Writer
+	// dir holds the transcripts, or is empty
+	dir string
+}
+
+func newDebugCapture(config cfg.DebugCapture) (*debugCapture, error) {
+	c := &debugCapture{hostnames: config.Hostnames, dir: config.TranscriptDir}
+	if config.KeyLogFile != "" {
+		f, err := os.OpenFile(config.KeyLogFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		c.keyLog = f
+	}
+	if c.dir != "" {
+		if err := os.MkdirAll(c.dir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *debugCapture) matches(hostname cfg.Hostname) bool {
+	return c != nil && slices.Contains(c.hostnames, hostname)
+}
+
+var fileSafe = strings.NewReplacer(":", "_", "/", "_")
+
+// record tees conn into a new transcript file when transcripts are on.
+func (c *debugCapture) record(conn net.Conn, hostname cfg.Hostname, ipAddress net.IP, now time.Time) (net.Conn, error) {
+	if c.dir == "" {
+		return conn, nil
+	}
+	name := fmt.Sprintf("%s_%s_%s.txt",
+		fileSafe.Replace(string(hostname)),
+		fileSafe.Replace(ipAddress.String()),
+		now.UTC().Format("20060102T150405.000000000Z"),
+	)
+	f, err := os.OpenFile(filepath.Join(c.dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &transcriptConn{Conn: conn, transcript: f}, nil
+}
+
+// transcriptConn writes every chunk read or written as a hex dump marked I
+// for inbound or O for outbound, the form text2pcap -D reads.
+type transcriptConn struct {
+	net.Conn
+	mu         sync.Mutex
+	transcript *os.File
+}
+
+func (c *transcriptConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.dump("I", b[:n])
+	return n, err
+}
+
+func (c *transcriptConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.dump("O", b[:n])
+	return n, err
+}
+
+func (c *transcriptConn) Close() error {
+	c.mu.Lock()
+	c.transcript.Close()
+	c.mu.Unlock()
+	return c.Conn.Close()
+}
+
+func (c *transcriptConn) dump(direction string, chunk []byte) {
+	if len(chunk) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(c.transcript, "%s %s", direction, hex.Dump(chunk))
+}
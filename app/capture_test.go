@@ -0,0 +1,63 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebugCapture(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "https://"))
+	hostname := cfg.Hostname(net.JoinHostPort("localhost", port))
+
+	dir := t.TempDir()
+	keyLogFile := filepath.Join(dir, "keys.log")
+	transcripts := filepath.Join(dir, "transcripts")
+	c, err := newDebugCapture(cfg.DebugCapture{Hostnames: []cfg.Hostname{hostname}, KeyLogFile: keyLogFile, TranscriptDir: transcripts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	capture = c
+	defer func() { capture = nil }()
+
+	conn, _, err := dialTLS(context.Background(), scanTarget{Hostname: hostname, ServerName: hostname.Host(), IPAddress: net.ParseIP("127.0.0.1")}, cfg.Duration(5*time.Second), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	// a hostname not being debugged isn't captured
+	conn, _, err = dialTLS(context.Background(), scanTarget{Hostname: cfg.Hostname("127.0.0.1:" + port), ServerName: "127.0.0.1", IPAddress: net.ParseIP("127.0.0.1")}, cfg.Duration(5*time.Second), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	keys, err := os.ReadFile(keyLogFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(keys), "CLIENT_HANDSHAKE_TRAFFIC_SECRET "); n != 1 {
+		t.Errorf("key log has %d handshakes, want 1:\n%s", n, keys)
+	}
+	entries, err := os.ReadDir(transcripts)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("transcripts = %v, %v; want one", entries, err)
+	}
+	transcript, err := os.ReadFile(filepath.Join(transcripts, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the ClientHello goes out first, then the server's reply comes in
+	if !strings.HasPrefix(string(transcript), "O 00000000  16 03 01") || !strings.Contains(string(transcript), "\nI 00000000  16 03 03") {
+		t.Errorf("transcript doesn't start with a ClientHello and ServerHello:\n%.300s", transcript)
+	}
+}
@@ -0,0 +1,87 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ALPN advertises Protocols in every handshake, or those in Targets for the
+// hostnames listed there, and records the protocol the server selects.
+// Expect alerts when a target doesn't select the protocol given for it,
+// e.g. a gRPC endpoint that doesn't negotiate h2; a target listed only
+// there advertises just that protocol.
+type ALPN struct {
+	Protocols []string              `json:"protocols"`
+	Targets   map[Hostname][]string `json:"targets"`
+	Expect    map[Hostname]string   `json:"expect"`
+}
+
+func (a *ALPN) UnmarshalJSON(data []byte) error {
+	type plain ALPN
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if err := checkProtocols(p.Protocols); err != nil {
+		return err
+	}
+	targets, err := normalizeHostnameKeys(p.Targets, nil)
+	if err != nil {
+		return fmt.Errorf("alpn targets %w", err)
+	}
+	for target, protocols := range targets {
+		if len(protocols) == 0 {
+			return fmt.Errorf("alpn targets %q needs protocols", target)
+		}
+		if err := checkProtocols(protocols); err != nil {
+			return err
+		}
+	}
+	expect, err := normalizeHostnameKeys(p.Expect, nil)
+	if err != nil {
+		return fmt.Errorf("alpn expect %w", err)
+	}
+	for _, protocol := range expect {
+		if err := checkProtocols([]string{protocol}); err != nil {
+			return err
+		}
+	}
+	if len(targets) > 0 {
+		p.Targets = targets
+	}
+	if len(expect) > 0 {
+		p.Expect = expect
+	}
+	*a = ALPN(p)
+	for hostname, protocol := range a.Expect {
+		if !slices.Contains(a.For(hostname), protocol) {
+			return fmt.Errorf("alpn expects %q from %q but doesn't advertise it", protocol, hostname)
+		}
+	}
+	return nil
+}
+
+func checkProtocols(protocols []string) error {
+	for _, protocol := range protocols {
+		if protocol == "" || len(protocol) > 255 {
+			return errors.New("alpn protocols must be 1 to 255 bytes long")
+		}
+	}
+	return nil
+}
+
+// For returns the protocols to advertise when connecting to h, or none.
+func (a ALPN) For(h Hostname) []string {
+	if protocols, ok := a.Targets[h]; ok {
+		return protocols
+	}
+	if len(a.Protocols) > 0 {
+		return a.Protocols
+	}
+	if protocol, ok := a.Expect[h]; ok {
+		return []string{protocol}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package cfg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestALPN_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    ALPN
+		wantErr bool
+	}{
+		{name: "empty", input: `{}`, want: ALPN{}},
+		{name: "protocols", input: `{"protocols": ["h2", "http/1.1"]}`, want: ALPN{Protocols: []string{"h2", "http/1.1"}}},
+		{
+			name:  "targets normalized",
+			input: `{"targets": {"grpc.example.com:443": ["h2"]}, "expect": {"grpc.example.com": "h2"}}`,
+			want:  ALPN{Targets: map[Hostname][]string{"grpc.example.com": {"h2"}}, Expect: map[Hostname]string{"grpc.example.com": "h2"}},
+		},
+		{name: "expect alone", input: `{"expect": {"grpc.example.com": "h2"}}`, want: ALPN{Expect: map[Hostname]string{"grpc.example.com": "h2"}}},
+		{name: "invalid - empty protocol", input: `{"protocols": [""]}`, wantErr: true},
+		{name: "invalid - target without protocols", input: `{"targets": {"grpc.example.com": []}}`, wantErr: true},
+		{name: "invalid - expected protocol not advertised", input: `{"protocols": ["http/1.1"], "expect": {"grpc.example.com": "h2"}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ALPN
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ALPN.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ALPN.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestALPN_For(t *testing.T) {
+	a := ALPN{
+		Protocols: []string{"http/1.1"},
+		Targets:   map[Hostname][]string{"grpc.example.com": {"h2"}},
+	}
+	if got := a.For("grpc.example.com"); !reflect.DeepEqual(got, []string{"h2"}) {
+		t.Errorf("For(grpc.example.com) = %v, want [h2]", got)
+	}
+	if got := a.For("www.example.com"); !reflect.DeepEqual(got, []string{"http/1.1"}) {
+		t.Errorf("For(www.example.com) = %v, want [http/1.1]", got)
+	}
+	if got := (ALPN{Expect: map[Hostname]string{"grpc.example.com": "h2"}}).For("grpc.example.com"); !reflect.DeepEqual(got, []string{"h2"}) {
+		t.Errorf("For() = %v, want the expected protocol", got)
+	}
+}
@@ -0,0 +1,138 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+type Scope string
+
+const (
+	ScopeRead  Scope = "read"
+	ScopeAdmin Scope = "admin"
+)
+
+// Secret is redacted when marshaled, so logging the config doesn't leak it.
+type Secret string
+
+// APIToken grants Scope over every tenant, or only over Tenant when it is set.
+type APIToken struct {
+	Name   string `json:"name"`
+	Token  Secret `json:"token"`
+	Scope  Scope  `json:"scope"`
+	Tenant string `json:"tenant"`
+}
+
+// TLS serves the API over HTTPS when CertFile and KeyFile are set. Setting
+// ClientCAFile additionally requires clients to present a certificate it signed.
+type TLS struct {
+	CertFile     string `json:"certFile"`
+	KeyFile      string `json:"keyFile"`
+	ClientCAFile string `json:"clientCAFile"`
+}
+
+// OIDC enables browser login through an OpenID Connect provider. Members of
+// AdminGroups get the admin scope and members of ReadGroups the read scope;
+// with no ReadGroups every authenticated user can read. TenantGroups maps
+// groups to the tenant their members are restricted to, or to AllTenants;
+// it's required once tenants are configured.
+type OIDC struct {
+	Issuer       string            `json:"issuer"`
+	ClientID     string            `json:"clientID"`
+	ClientSecret Secret            `json:"clientSecret"`
+	RedirectURL  string            `json:"redirectURL"`
+	GroupsClaim  string            `json:"groupsClaim"`
+	ReadGroups   []string          `json:"readGroups"`
+	AdminGroups  []string          `json:"adminGroups"`
+	TenantGroups map[string]string `json:"tenantGroups"`
+}
+
+// AllTenants in OIDC TenantGroups gives a group's members every tenant.
+const AllTenants = "*"
+
+type API struct {
+	Listen string     `json:"listen"`
+	Tokens []APIToken `json:"tokens"`
+	TLS    TLS        `json:"tls"`
+	OIDC   OIDC       `json:"oidc"`
+}
+
+func (s *Scope) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	switch Scope(str) {
+	case ScopeRead, ScopeAdmin:
+		*s = Scope(str)
+		return nil
+	}
+	return fmt.Errorf("unknown scope %q; use %q or %q", str, ScopeRead, ScopeAdmin)
+}
+
+// Allows reports whether a token with scope s may use an endpoint requiring scope.
+func (s Scope) Allows(scope Scope) bool {
+	return s == ScopeAdmin || s == scope
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	if s == "" {
+		return json.Marshal("")
+	}
+	return json.Marshal("[REDACTED]")
+}
+
+func (t *APIToken) UnmarshalJSON(data []byte) error {
+	type plain APIToken
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.Token == "" {
+		return fmt.Errorf("API token %q has no token value", p.Name)
+	}
+	if p.Scope == "" {
+		p.Scope = ScopeRead
+	}
+	*t = APIToken(p)
+	return nil
+}
+
+func (t *TLS) UnmarshalJSON(data []byte) error {
+	type plain TLS
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if (p.CertFile == "") != (p.KeyFile == "") {
+		return errors.New("TLS needs both certFile and keyFile")
+	}
+	if p.ClientCAFile != "" && p.CertFile == "" {
+		return errors.New("TLS clientCAFile requires certFile and keyFile")
+	}
+	*t = TLS(p)
+	return nil
+}
+
+func (o *OIDC) UnmarshalJSON(data []byte) error {
+	type plain OIDC
+	p := plain{GroupsClaim: "groups"}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.Issuer == "" {
+		*o = OIDC(p)
+		return nil
+	}
+	if p.ClientID == "" {
+		return errors.New("OIDC needs clientID")
+	}
+	redirect, err := url.Parse(p.RedirectURL)
+	if err != nil || !redirect.IsAbs() || redirect.Path == "" {
+		return fmt.Errorf("OIDC redirectURL %q must be an absolute URL with a path", p.RedirectURL)
+	}
+	*o = OIDC(p)
+	return nil
+}
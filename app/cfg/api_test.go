@@ -0,0 +1,132 @@
+package cfg
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAPIToken_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    APIToken
+		wantErr bool
+	}{
+		{
+			name:  "admin token",
+			input: `{"name": "ops", "token": "s3cret", "scope": "admin"}`,
+			want:  APIToken{Name: "ops", Token: "s3cret", Scope: ScopeAdmin},
+		},
+		{
+			name:  "scope defaults to read",
+			input: `{"name": "grafana", "token": "s3cret"}`,
+			want:  APIToken{Name: "grafana", Token: "s3cret", Scope: ScopeRead},
+		},
+		{
+			name:    "invalid - unknown scope",
+			input:   `{"name": "ops", "token": "s3cret", "scope": "root"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid - empty token",
+			input:   `{"name": "ops", "token": ""}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got APIToken
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("APIToken.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("APIToken.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScope_Allows(t *testing.T) {
+	if !ScopeAdmin.Allows(ScopeRead) {
+		t.Error("Expected admin to allow read")
+	}
+	if ScopeRead.Allows(ScopeAdmin) {
+		t.Error("Expected read not to allow admin")
+	}
+}
+
+func TestSecretRedactedWhenMarshaled(t *testing.T) {
+	data, err := json.Marshal(API{Tokens: []APIToken{{Name: "ops", Token: "s3cret", Scope: ScopeAdmin}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "s3cret") {
+		t.Errorf("Expected token to be redacted, got %s", data)
+	}
+}
+
+func TestTLS_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "disabled", input: `{}`},
+		{name: "server only", input: `{"certFile": "tls.crt", "keyFile": "tls.key"}`},
+		{name: "mutual TLS", input: `{"certFile": "tls.crt", "keyFile": "tls.key", "clientCAFile": "ca.crt"}`},
+		{name: "invalid - missing key", input: `{"certFile": "tls.crt"}`, wantErr: true},
+		{name: "invalid - client CA only", input: `{"clientCAFile": "ca.crt"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got TLS
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TLS.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOIDC_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "disabled", input: `{}`, want: "groups"},
+		{
+			name:  "custom groups claim",
+			input: `{"issuer": "https://sso.example.com", "clientID": "tracker", "redirectURL": "https://tracker.example.com/auth/callback", "groupsClaim": "roles"}`,
+			want:  "roles",
+		},
+		{
+			name:    "invalid - missing client ID",
+			input:   `{"issuer": "https://sso.example.com", "redirectURL": "https://tracker.example.com/auth/callback"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid - relative redirect URL",
+			input:   `{"issuer": "https://sso.example.com", "clientID": "tracker", "redirectURL": "/auth/callback"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got OIDC
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OIDC.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.GroupsClaim != tt.want {
+				t.Errorf("GroupsClaim = %q, want %q", got.GroupsClaim, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package cfg
+
+// CAA compares each hostname's CAA policy with the issuer of the certificate
+// it serves. Identities maps issuer organizations to the domains they use in
+// CAA records, for CAs the tracker doesn't already know.
+type CAA struct {
+	Enabled    bool                `json:"enabled"`
+	Identities map[string][]string `json:"identities"`
+}
@@ -0,0 +1,32 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// DebugCapture records the TLS sessions with Hostnames for debugging: the
+// session secrets go to KeyLogFile in SSLKEYLOGFILE format, and the raw bytes
+// of every connection to a transcript in TranscriptDir.
+type DebugCapture struct {
+	Hostnames     []Hostname `json:"hostnames"`
+	KeyLogFile    string     `json:"keyLogFile"`
+	TranscriptDir string     `json:"transcriptDir"`
+}
+
+func (d *DebugCapture) UnmarshalJSON(data []byte) error {
+	type plain DebugCapture
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	outputs := p.KeyLogFile != "" || p.TranscriptDir != ""
+	if len(p.Hostnames) > 0 && !outputs {
+		return errors.New("debugCapture needs a keyLogFile or transcriptDir")
+	}
+	if outputs && len(p.Hostnames) == 0 {
+		return errors.New("debugCapture hostnames must not be empty")
+	}
+	*d = DebugCapture(p)
+	return nil
+}
@@ -0,0 +1,38 @@
+package cfg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDebugCapture_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    DebugCapture
+		wantErr bool
+	}{
+		{name: "disabled", input: `{}`, want: DebugCapture{}},
+		{
+			name:  "key log",
+			input: `{"hostnames": ["example.com:8443"], "keyLogFile": "/tmp/keys.log"}`,
+			want:  DebugCapture{Hostnames: []Hostname{"example.com:8443"}, KeyLogFile: "/tmp/keys.log"},
+		},
+		{name: "invalid - no output", input: `{"hostnames": ["example.com"]}`, wantErr: true},
+		{name: "invalid - no hostnames", input: `{"transcriptDir": "/tmp/tls"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got DebugCapture
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DebugCapture.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DebugCapture.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
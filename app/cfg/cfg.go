@@ -3,18 +3,30 @@ package cfg
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
-const configFilePath = "config.json"
+const defaultConfigFile = "config.json"
+
+// configFilePath is the config file Load reads, set with --config.
+var configFilePath = defaultConfigFile
 
 type Hostname string
 type Duration time.Duration
+type Retention struct {
+	Observations Duration `json:"observations"`
+	Changes      Duration `json:"changes"`
+}
 type Params struct {
 	DNSresolvers []net.IP   `json:"dnsResolvers"`
 	Hostnames    []Hostname `json:"hostnames"`
@@ -22,6 +34,253 @@ type Params struct {
 	ScanInterval Duration   `json:"scanInterval"`
 	LogLevel     slog.Level `json:"logLevel"`
 	LogAddSource bool       `json:"logAddSource"`
+	LogFormat    LogFormat  `json:"logFormat"`
+	LogOutput    LogOutput  `json:"logOutput"`
+	StoreDir     string     `json:"storeDir"`
+	Retention    Retention  `json:"retention"`
+	API          API        `json:"api"`
+	Tenants      []Tenant   `json:"tenants"`
+	ConfigDir    string     `json:"configDir"`
+
+	AddressFamily  AddressFamily  `json:"addressFamily"`
+	SNI            SNI            `json:"sni"`
+	ALPN           ALPN           `json:"alpn"`
+	OCSPResponders OCSPResponders `json:"ocspResponders"`
+	CRLs           CRLs           `json:"crls"`
+	OCSPStatus     OCSPStatus     `json:"ocspStatus"`
+	CAA            CAA            `json:"caa"`
+	DANE           DANE           `json:"dane"`
+	CTPolicy       CTPolicy       `json:"ctPolicy"`
+	CTMonitor      CTMonitor      `json:"ctMonitor"`
+	DeepScan       DeepScan       `json:"deepScan"`
+	DNSCache       DNSCache       `json:"dnsCache"`
+	SplitHorizon   SplitHorizon   `json:"splitHorizon"`
+	Expiry         Expiry         `json:"expiry"`
+	ChainSize      ChainSize      `json:"chainSize"`
+	Watchdog       Watchdog       `json:"watchdog"`
+	LeaderElection LeaderElection `json:"leaderElection"`
+	StepCA         StepCA         `json:"stepCA"`
+	Route53        Route53        `json:"route53"`
+	Cloudflare     Cloudflare     `json:"cloudflare"`
+	Metrics        Metrics        `json:"metrics"`
+	DebugCapture   DebugCapture   `json:"debugCapture"`
+	Notifications  Notifications  `json:"notifications"`
+	Report         Report         `json:"report"`
+	Export         Export         `json:"export"`
+	Files          Files          `json:"files"`
+	Wildcards      Wildcards      `json:"wildcards"`
+	Results        Results        `json:"results"`
+	ChainChecks    ChainChecks    `json:"chainChecks"`
+	KeyAudit       KeyAudit       `json:"keyAudit"`
+	Spread         Spread         `json:"spread"`
+	Summary        Summary        `json:"summary"`
+	Pins           Pins           `json:"pins"`
+	StateCache     StateCache     `json:"stateCache"`
+	ControlPipe    ControlPipe    `json:"controlPipe"`
+
+	ValidityPolicies []ValidityPolicy `json:"validityPolicies"`
+	PrivateCAs       []PrivateCA      `json:"privateCAs"`
+	Dialers          []Dialer         `json:"dialers"`
+	Maintenance      []Maintenance    `json:"maintenance"`
+	Labels           []TargetLabels   `json:"labels"`
+}
+
+const defaultPort = "443"
+
+// starttlsSchemes maps the URL schemes of protocols that upgrade a plaintext
+// connection with STARTTLS to their default ports. Hostnames of such targets
+// keep the scheme, e.g. smtp://mail.example.com:587.
+var starttlsSchemes = map[string]string{
+	"smtp":     "25",
+	"imap":     "143",
+	"pop3":     "110",
+	"ldap":     "389",
+	"postgres": "5432",
+}
+
+// quicScheme marks targets scanned with a QUIC handshake over UDP, such as
+// quic://cdn.example.com, whose certificates can differ from those served
+// over TCP on the same port.
+const quicScheme = "quic"
+
+// tlsSchemes maps the URL schemes of protocols spoken over implicit TLS to
+// their default ports.
+var tlsSchemes = map[string]string{
+	"https":  "443",
+	"ldaps":  "636",
+	"imaps":  "993",
+	"pop3s":  "995",
+	"smtps":  "465",
+	"ftps":   "990",
+	"ircs":   "6697",
+	"wss":    "443",
+	"sips":   "5061",
+	"mqtts":  "8883",
+	"amqps":  "5671",
+	"rediss": "6380",
+}
+
+// ParseHostname accepts a hostname with an optional port, e.g.
+// "example.com:8443", or a URL with a TLS scheme such as
+// "https://example.com:8443/login". The hostname is lowercased and the
+// default port 443 dropped so every spelling names the same target.
+func ParseHostname(s string) (Hostname, error) {
+	if strings.Contains(s, "://") {
+		return parseURL(s)
+	}
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		host, port = s, defaultPort
+	} else if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+		return "", fmt.Errorf("invalid port in %q", s)
+	}
+	host = strings.ToLower(host)
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	// a wildcard stands for the subdomains found by discovery
+	domain, _ := strings.CutPrefix(host, "*.")
+	if err := validate.Var(domain, "hostname_rfc1123"); err != nil {
+		return "", fmt.Errorf("%q is not a valid hostname", host)
+	}
+	if err := validate.Var(host, "ip"); err == nil {
+		return "", errors.New("IP address found in config hostnames")
+	}
+	if port == defaultPort {
+		return Hostname(host), nil
+	}
+	return Hostname(net.JoinHostPort(host, port)), nil
+}
+
+func parseURL(s string) (Hostname, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if port, ok := starttlsSchemes[scheme]; ok {
+		return parseKeptScheme(scheme, port, u)
+	}
+	if scheme == quicScheme {
+		return parseKeptScheme(scheme, defaultPort, u)
+	}
+	port, ok := tlsSchemes[scheme]
+	if !ok {
+		return "", fmt.Errorf("%q: scheme %q doesn't use TLS; use a TLS scheme such as https, a STARTTLS one such as smtp, or quic", s, u.Scheme)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	return ParseHostname(net.JoinHostPort(u.Hostname(), port))
+}
+
+// parseKeptScheme keeps the scheme and drops its default port.
+func parseKeptScheme(scheme, defaultPort string, u *url.URL) (Hostname, error) {
+	port := defaultPort
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	if _, err := ParseHostname(net.JoinHostPort(u.Hostname(), port)); err != nil {
+		return "", err
+	}
+	address := strings.ToLower(u.Hostname())
+	if port != defaultPort {
+		address = net.JoinHostPort(address, port)
+	}
+	return Hostname(scheme + "://" + address), nil
+}
+
+// Host returns the hostname without its port.
+func (h Hostname) Host() string {
+	_, address := h.split()
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+	return address
+}
+
+// Port returns the port to connect to: the one given, or else the default
+// for the protocol, 443 for plain TLS.
+func (h Hostname) Port() string {
+	scheme, address := h.split()
+	if _, port, err := net.SplitHostPort(address); err == nil {
+		return port
+	}
+	if port, ok := starttlsSchemes[scheme]; ok {
+		return port
+	}
+	return defaultPort
+}
+
+// Protocol returns the STARTTLS protocol spoken before the handshake, "quic"
+// for a handshake over QUIC, or "tls" when the handshake starts right away.
+func (h Hostname) Protocol() string {
+	if scheme, _ := h.split(); scheme != "" {
+		return scheme
+	}
+	return "tls"
+}
+
+// Wildcard reports whether h is a pattern such as *.example.com, standing
+// for the subdomains discovery finds rather than a host to connect to.
+func (h Hostname) Wildcard() bool {
+	return strings.HasPrefix(h.Host(), "*.")
+}
+
+// Covers reports whether name is h, or a subdomain of it at any depth when
+// h is a wildcard, on the same port and protocol.
+func (h Hostname) Covers(name Hostname) bool {
+	if !h.Wildcard() || name.Wildcard() {
+		return h == name
+	}
+	return h.Port() == name.Port() && h.Protocol() == name.Protocol() &&
+		strings.HasSuffix(name.Host(), strings.TrimPrefix(h.Host(), "*"))
+}
+
+// WithHost returns h with its host replaced, keeping its port and protocol,
+// to make a target of a name discovered under a wildcard.
+func (h Hostname) WithHost(host string) (Hostname, error) {
+	scheme, address := h.split()
+	if _, port, err := net.SplitHostPort(address); err == nil {
+		host = net.JoinHostPort(host, port)
+	}
+	if scheme != "" {
+		host = scheme + "://" + host
+	}
+	return ParseHostname(host)
+}
+
+func (h Hostname) split() (scheme, address string) {
+	if scheme, address, ok := strings.Cut(string(h), "://"); ok {
+		return scheme, address
+	}
+	return "", string(h)
+}
+
+// AddressFamily limits scans to "ipv4" or "ipv6" addresses, or allows
+// "both", the default.
+type AddressFamily string
+
+func (f *AddressFamily) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "ipv4", "ipv6", "both":
+		*f = AddressFamily(s)
+		return nil
+	}
+	return fmt.Errorf("addressFamily %q must be ipv4, ipv6 or both", s)
+}
+
+// Allows reports whether ip is in the family.
+func (f AddressFamily) Allows(ip net.IP) bool {
+	switch f {
+	case "ipv4":
+		return ip.To4() != nil
+	case "ipv6":
+		return ip.To4() == nil
+	}
+	return true
 }
 
 func (h *Hostname) UnmarshalJSON(data []byte) error {
@@ -30,16 +289,55 @@ func (h *Hostname) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	validate := validator.New(validator.WithRequiredStructEnabled())
-	if err := validate.Var(s, "hostname_rfc1123"); err != nil {
+	hostname, err := ParseHostname(s)
+	if err != nil {
 		return err
 	}
-	if err := validate.Var(s, "ip"); err == nil {
-		return errors.New("IP address found in config hostnames")
+	*h = hostname
+	return nil
+}
+
+// normalizeHostnameKeys parses the keys of m as hostnames. Map keys skip
+// Hostname.UnmarshalJSON, so without this "https://example.com:443" and
+// "example.com" would be different entries. The values of keys naming the
+// same target are combined with merge, or are an error without it.
+func normalizeHostnameKeys[V any](m map[Hostname]V, merge func(a, b V) V) (map[Hostname]V, error) {
+	normalized := make(map[Hostname]V, len(m))
+	for key, v := range m {
+		hostname, err := ParseHostname(string(key))
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", key, err)
+		}
+		if existing, ok := normalized[hostname]; ok {
+			if merge == nil {
+				return nil, fmt.Errorf("%q is listed more than once", hostname)
+			}
+			v = merge(existing, v)
+		}
+		normalized[hostname] = v
 	}
+	return normalized, nil
+}
 
-	*h = Hostname(s)
-	return nil
+// ParseDuration extends time.ParseDuration with a leading day unit, e.g. "30d" or "1d12h".
+func ParseDuration(s string) (time.Duration, error) {
+	days, rest, found := strings.Cut(s, "d")
+	if !found {
+		return time.ParseDuration(s)
+	}
+	n, err := strconv.ParseUint(days, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("time: invalid duration %q", s)
+	}
+	dur := time.Duration(n) * 24 * time.Hour
+	if rest == "" {
+		return dur, nil
+	}
+	extra, err := time.ParseDuration(rest)
+	if err != nil {
+		return 0, fmt.Errorf("time: invalid duration %q", s)
+	}
+	return dur + extra, nil
 }
 
 func (d *Duration) UnmarshalJSON(data []byte) error {
@@ -47,23 +345,106 @@ func (d *Duration) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &s); err != nil {
 		return err
 	}
-	dur, err := time.ParseDuration(s)
+	dur, err := ParseDuration(s)
 	*d = Duration(dur)
 	return err
 }
 
 func loadFile(configFilePath string, p *Params) error {
-	data, err := os.ReadFile(configFilePath)
+	data, err := loadData(configFilePath)
 	if err != nil {
 		return err
 	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	return p.validate()
+}
 
-	err = json.Unmarshal(data, &p)
-	return err
+// loadData layers the config file, its overlay and fragments, and the
+// overrides into one JSON document.
+func loadData(configFilePath string) ([]byte, error) {
+	data, err := readBase(configFilePath)
+	if err != nil {
+		return nil, err
+	}
+	if profile := os.Getenv(profileEnv); profile != "" {
+		data, err = applyOverlay(data, overlayPath(configFilePath, profile))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, err = applyFragments(data, configFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return applyOverrides(data)
+}
+
+// validate checks constraints that span more than one field, and sets the
+// defaults of blocks that apply even when left out.
+func (p *Params) validate() error {
+	if p.Wildcards.Refresh == 0 {
+		p.Wildcards = defaultWildcards()
+	}
+	if p.Results.Sinks == nil {
+		p.Results = defaultResults()
+	}
+	if p.Summary.Soonest == 0 {
+		p.Summary = defaultSummary()
+	}
+	if p.LogOutput.Destination == "" {
+		p.LogOutput = defaultLogOutput()
+	}
+	if slices.Contains(p.Wildcards.Sources, "route53") && !p.Route53.Enabled {
+		return errors.New("wildcards route53 source needs route53 enabled")
+	}
+	if slices.Contains(p.Wildcards.Sources, "cloudflare") && !p.Cloudflare.Enabled {
+		return errors.New("wildcards cloudflare source needs cloudflare enabled")
+	}
+	tenants := make(map[string]bool)
+	for _, t := range p.Tenants {
+		if tenants[t.Name] {
+			return fmt.Errorf("duplicate tenant %q", t.Name)
+		}
+		tenants[t.Name] = true
+	}
+	policies := make(map[string]bool)
+	for _, v := range p.ValidityPolicies {
+		if policies[v.Name] {
+			return fmt.Errorf("duplicate validity policy %q", v.Name)
+		}
+		policies[v.Name] = true
+	}
+	if p.Spread.Enabled && p.Spread.Window >= p.ScanInterval {
+		return errors.New("spread window must be shorter than scanInterval")
+	}
+	if p.SplitHorizon.Enabled && len(p.DNSresolvers) < 2 {
+		return errors.New("splitHorizon needs at least two dnsResolvers")
+	}
+	if p.LeaderElection.Enabled && p.StoreDir == "" {
+		return errors.New("leaderElection needs a shared storeDir")
+	}
+	for _, token := range p.API.Tokens {
+		if token.Tenant != "" && !tenants[token.Tenant] {
+			return fmt.Errorf("API token %q references unknown tenant %q", token.Name, token.Tenant)
+		}
+	}
+	if p.API.OIDC.Issuer != "" && len(p.Tenants) > 0 && len(p.API.OIDC.TenantGroups) == 0 {
+		return errors.New("OIDC needs tenantGroups when tenants are configured")
+	}
+	for group, tenant := range p.API.OIDC.TenantGroups {
+		if tenant != AllTenants && !tenants[tenant] {
+			return fmt.Errorf("OIDC tenant group %q references unknown tenant %q", group, tenant)
+		}
+	}
+	return nil
 }
 
 func Load() (Params, error){
 	var Current Params
-	err := loadFile(configFilePath, &Current)
+	err := loadFile(findConfigFile(configFilePath), &Current)
 	return Current, err
 }
@@ -34,6 +34,132 @@ func TestHostname_UnmarshalJSON(t *testing.T) {
 			want:    Hostname("my-server.example.com"),
 			wantErr: false,
 		},
+		{
+			name:    "valid hostname with port",
+			input:   `"example.com:8443"`,
+			want:    Hostname("example.com:8443"),
+			wantErr: false,
+		},
+		{
+			name:    "mixed case lowercased",
+			input:   `"WWW.Example.com"`,
+			want:    Hostname("www.example.com"),
+			wantErr: false,
+		},
+		{
+			name:    "mixed case with port lowercased",
+			input:   `"*.Example.COM:8443"`,
+			want:    Hostname("*.example.com:8443"),
+			wantErr: false,
+		},
+		{
+			name:    "default port dropped",
+			input:   `"example.com:443"`,
+			want:    Hostname("example.com"),
+			wantErr: false,
+		},
+		{
+			name:    "wildcard",
+			input:   `"*.example.com"`,
+			want:    Hostname("*.example.com"),
+			wantErr: false,
+		},
+		{
+			name:    "invalid - wildcard inside a name",
+			input:   `"api.*.example.com"`,
+			want:    Hostname(""),
+			wantErr: true,
+		},
+		{
+			name:    "invalid - port out of range",
+			input:   `"example.com:65536"`,
+			want:    Hostname(""),
+			wantErr: true,
+		},
+		{
+			name:    "invalid - port zero",
+			input:   `"example.com:0"`,
+			want:    Hostname(""),
+			wantErr: true,
+		},
+		{
+			name:    "invalid - empty port",
+			input:   `"example.com:"`,
+			want:    Hostname(""),
+			wantErr: true,
+		},
+		{
+			name:    "invalid - bad host with port",
+			input:   `"-example.com:8443"`,
+			want:    Hostname(""),
+			wantErr: true,
+		},
+		{
+			name:    "invalid - IP address with port",
+			input:   `"192.168.1.1:8443"`,
+			want:    Hostname(""),
+			wantErr: true,
+		},
+		{
+			name:    "https URL",
+			input:   `"https://example.com:8443/login?next=/"`,
+			want:    Hostname("example.com:8443"),
+			wantErr: false,
+		},
+		{
+			name:    "https URL default port",
+			input:   `"https://Example.com/"`,
+			want:    Hostname("example.com"),
+			wantErr: false,
+		},
+		{
+			name:    "https URL mixed case",
+			input:   `"HTTPS://WWW.Example.com:8443/Login"`,
+			want:    Hostname("www.example.com:8443"),
+			wantErr: false,
+		},
+		{
+			name:    "ldaps URL",
+			input:   `"ldaps://ldap.example.com"`,
+			want:    Hostname("ldap.example.com:636"),
+			wantErr: false,
+		},
+		{
+			name:    "smtp URL",
+			input:   `"smtp://Mail.example.com:587"`,
+			want:    Hostname("smtp://mail.example.com:587"),
+			wantErr: false,
+		},
+		{
+			name:    "imap URL default port",
+			input:   `"imap://mail.example.com:143"`,
+			want:    Hostname("imap://mail.example.com"),
+			wantErr: false,
+		},
+		{
+			name:    "quic URL",
+			input:   `"quic://CDN.example.com:443"`,
+			want:    Hostname("quic://cdn.example.com"),
+			wantErr: false,
+		},
+		{
+			name:    "invalid - smtp URL with IP address",
+			input:   `"smtp://192.168.1.1"`,
+			want:    Hostname(""),
+			wantErr: true,
+		},
+		{
+			name:    "invalid - http URL",
+			input:   `"http://example.com"`,
+			want:    Hostname(""),
+			wantErr: true,
+		},
+		{
+			name:    "invalid - URL with IP address",
+			input:   `"https://192.168.1.1/"`,
+			want:    Hostname(""),
+			wantErr: true,
+		},
 		{
 			name:    "invalid - IP address",
 			input:   `"192.168.1.1"`,
@@ -95,6 +221,91 @@ func TestHostname_UnmarshalJSON(t *testing.T) {
 	}
 }
 
+func TestHostname_HostPort(t *testing.T) {
+	tests := []struct {
+		hostname Hostname
+		host     string
+		port     string
+		protocol string
+	}{
+		{hostname: "example.com", host: "example.com", port: "443", protocol: "tls"},
+		{hostname: "example.com:8443", host: "example.com", port: "8443", protocol: "tls"},
+		{hostname: "smtp://mail.example.com", host: "mail.example.com", port: "25", protocol: "smtp"},
+		{hostname: "smtp://mail.example.com:587", host: "mail.example.com", port: "587", protocol: "smtp"},
+		{hostname: "quic://cdn.example.com", host: "cdn.example.com", port: "443", protocol: "quic"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.hostname.Protocol(); got != tt.protocol {
+			t.Errorf("Hostname(%q).Protocol() = %q, want %q", tt.hostname, got, tt.protocol)
+		}
+		if got := tt.hostname.Host(); got != tt.host {
+			t.Errorf("Hostname(%q).Host() = %q, want %q", tt.hostname, got, tt.host)
+		}
+		if got := tt.hostname.Port(); got != tt.port {
+			t.Errorf("Hostname(%q).Port() = %q, want %q", tt.hostname, got, tt.port)
+		}
+	}
+}
+
+func TestHostname_Covers(t *testing.T) {
+	tests := []struct {
+		hostname Hostname
+		name     Hostname
+		want     bool
+	}{
+		{hostname: "example.com", name: "example.com", want: true},
+		{hostname: "example.com", name: "api.example.com", want: false},
+		{hostname: "*.example.com", name: "api.example.com", want: true},
+		{hostname: "*.example.com", name: "v1.api.example.com", want: true},
+		{hostname: "*.example.com", name: "example.com", want: false},
+		{hostname: "*.example.com", name: "badexample.com", want: false},
+		{hostname: "*.example.com", name: "api.example.com:8443", want: false},
+		{hostname: "*.example.com:8443", name: "api.example.com:8443", want: true},
+		{hostname: "smtp://*.example.com", name: "smtp://mail.example.com", want: true},
+		{hostname: "smtp://*.example.com", name: "mail.example.com", want: false},
+		{hostname: "*.example.com", name: "*.example.com", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.hostname.Covers(tt.name); got != tt.want {
+			t.Errorf("Hostname(%q).Covers(%q) = %v, want %v", tt.hostname, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAddressFamily(t *testing.T) {
+	v4, v6 := net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1")
+	tests := []struct {
+		input   string
+		allowV4 bool
+		allowV6 bool
+		wantErr bool
+	}{
+		{input: `"ipv4"`, allowV4: true},
+		{input: `"ipv6"`, allowV6: true},
+		{input: `"both"`, allowV4: true, allowV6: true},
+		{input: `"ipv5"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var f AddressFamily
+		err := json.Unmarshal([]byte(tt.input), &f)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("AddressFamily.UnmarshalJSON(%s) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if tt.wantErr {
+			continue
+		}
+		if f.Allows(v4) != tt.allowV4 || f.Allows(v6) != tt.allowV6 {
+			t.Errorf("AddressFamily(%s) allows IPv4 %v, IPv6 %v", tt.input, f.Allows(v4), f.Allows(v6))
+		}
+	}
+	if !AddressFamily("").Allows(v6) {
+		t.Error("the default address family should allow IPv6")
+	}
+}
+
 func TestDuration_UnmarshalJSON(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -126,6 +337,30 @@ func TestDuration_UnmarshalJSON(t *testing.T) {
 			want:    Duration(1*time.Hour + 30*time.Minute + 45*time.Second),
 			wantErr: false,
 		},
+		{
+			name:    "days",
+			input:   `"30d"`,
+			want:    Duration(30 * 24 * time.Hour),
+			wantErr: false,
+		},
+		{
+			name:    "days and hours",
+			input:   `"1d12h"`,
+			want:    Duration(36 * time.Hour),
+			wantErr: false,
+		},
+		{
+			name:    "invalid - non-numeric days",
+			input:   `"xd"`,
+			want:    Duration(0),
+			wantErr: true,
+		},
+		{
+			name:    "invalid - bad remainder after days",
+			input:   `"1dfoo"`,
+			want:    Duration(0),
+			wantErr: true,
+		},
 		{
 			name:    "invalid - no unit",
 			input:   `"30"`,
@@ -0,0 +1,8 @@
+package cfg
+
+// ChainChecks looks for misconfigured chains: missing intermediates, proven
+// by fetching them through the certificate's AIA URLs, chains served out of
+// order, expired intermediates and intermediates served twice.
+type ChainChecks struct {
+	Enabled bool `json:"enabled"`
+}
@@ -0,0 +1,26 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ChainSize flags endpoints whose certificate chain is larger than MaxBytes,
+// which costs clients on slow links extra round trips.
+type ChainSize struct {
+	Enabled  bool `json:"enabled"`
+	MaxBytes int  `json:"maxBytes"`
+}
+
+func (c *ChainSize) UnmarshalJSON(data []byte) error {
+	type plain ChainSize
+	p := plain{MaxBytes: 8192}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.MaxBytes <= 0 {
+		return errors.New("chainSize maxBytes must be positive")
+	}
+	*c = ChainSize(p)
+	return nil
+}
@@ -0,0 +1,32 @@
+package cfg
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestChainSize_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    ChainSize
+		wantErr bool
+	}{
+		{name: "default limit", input: `{"enabled": true}`, want: ChainSize{Enabled: true, MaxBytes: 8192}},
+		{name: "custom limit", input: `{"enabled": true, "maxBytes": 4096}`, want: ChainSize{Enabled: true, MaxBytes: 4096}},
+		{name: "invalid - zero limit", input: `{"enabled": true, "maxBytes": 0}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ChainSize
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ChainSize.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ChainSize.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
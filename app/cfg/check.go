@@ -0,0 +1,139 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Problem is one thing wrong with the config, at a path such as
+// hostnames[2] or tenants[0].hostnames[1], or "" for the whole config.
+type Problem struct {
+	Path string
+	Err  error
+}
+
+func (p Problem) String() string {
+	if p.Path == "" {
+		return p.Err.Error()
+	}
+	return p.Path + ": " + p.Err.Error()
+}
+
+// Check loads the config like Load, but instead of stopping at the first
+// error it reports every entry that's invalid, unknown settings, which
+// would otherwise be ignored, and hostnames listed twice. The config is
+// returned when there are no problems.
+func Check() (Params, []Problem) {
+	path := findConfigFile(configFilePath)
+	data, err := loadData(path)
+	if err != nil {
+		return Params{}, []Problem{{Err: err}}
+	}
+	var raw any
+	if err := decode(data, &raw); err != nil {
+		return Params{}, []Problem{{Err: fmt.Errorf("%s: %w", path, err)}}
+	}
+	problems := checkValue("", raw, reflect.TypeFor[Params]())
+	if len(problems) > 0 {
+		return Params{}, problems
+	}
+	var p Params
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Params{}, []Problem{{Err: err}}
+	}
+	if err := p.validate(); err != nil {
+		return Params{}, []Problem{{Err: err}}
+	}
+	return p, nil
+}
+
+// checkValue unmarshals raw into a value of type t and, when that fails,
+// looks into its fields and elements for the entries at fault.
+func checkValue(path string, raw any, t reflect.Type) []Problem {
+	var problems []Problem
+	switch v := raw.(type) {
+	case map[string]any:
+		if t.Kind() != reflect.Struct {
+			break
+		}
+		fields := jsonFields(t)
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			field, ok := fields[strings.ToLower(k)]
+			if path == "" && k == "$schema" {
+				// points editors at the schema
+				continue
+			}
+			if !ok {
+				problems = append(problems, Problem{joinPath(path, k), errors.New("unknown setting")})
+				continue
+			}
+			problems = append(problems, checkValue(joinPath(path, k), v[k], field)...)
+		}
+	case []any:
+		if t.Kind() != reflect.Slice {
+			break
+		}
+		seen := make(map[Hostname]int)
+		for i, item := range v {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			problems = append(problems, checkValue(itemPath, item, t.Elem())...)
+			if s, ok := item.(string); ok && t.Elem() == reflect.TypeFor[Hostname]() {
+				hostname, err := ParseHostname(s)
+				if err != nil {
+					continue
+				}
+				if first, ok := seen[hostname]; ok {
+					problems = append(problems, Problem{itemPath, fmt.Errorf("%s is already listed as %s[%d]", hostname, path, first)})
+					continue
+				}
+				seen[hostname] = i
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	// the entry's own rules, once its parts are fine
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return []Problem{{path, err}}
+	}
+	if err := json.Unmarshal(encoded, reflect.New(t).Interface()); err != nil {
+		return []Problem{{path, err}}
+	}
+	return nil
+}
+
+// jsonFields maps the lowercased JSON names of t's fields to their types,
+// since encoding/json matches names case-insensitively.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = f.Type
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
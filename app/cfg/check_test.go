@@ -0,0 +1,61 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "valid", content: `{"$schema": "./config.schema.json", "hostnames": ["example.com"], "timeout": "5s", "API": {"listen": ":8080"}}`},
+		{name: "syntax error", content: `{"hostnames": [`, want: []string{""}},
+		{
+			name: "every bad entry",
+			content: `{
+				"hostnames": ["example.com", "bad host", "example.com:443", "example.org"],
+				"scanIntervall": "10m",
+				"timeout": "soon",
+				"tenants": [
+					{"name": "web", "hostnames": ["www.example.com", "http://www.example.com"]},
+					{"hostnames": ["shop.example.com"]}
+				],
+				"expiry": {"enabled": true, "escalation": [{"within": "7d", "severity": "loud"}]}
+			}`,
+			want: []string{
+				"expiry",
+				"hostnames[1]",
+				"hostnames[2]",
+				"scanIntervall",
+				"tenants[0].hostnames[1]",
+				"tenants[1]",
+				"timeout",
+			},
+		},
+		{name: "cross-field rule", content: `{"leaderElection": {"enabled": true}}`, want: []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			os.WriteFile(path, []byte(tt.content), 0o644)
+			configFilePath = path
+			t.Cleanup(func() { configFilePath = defaultConfigFile })
+
+			_, problems := Check()
+			var got []string
+			for _, p := range problems {
+				got = append(got, p.Path)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Check() problems = %v, want at %q", problems, tt.want)
+			}
+		})
+	}
+}
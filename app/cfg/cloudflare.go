@@ -0,0 +1,37 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Cloudflare discovers hostnames from the DNS records of Cloudflare zones,
+// like Route53. With CheckOrigins, the origins behind proxied records are
+// scanned too, every Refresh, and compared with the edge.
+type Cloudflare struct {
+	Enabled      bool     `json:"enabled"`
+	Zones        []string `json:"zones"`
+	Types        []string `json:"types"`
+	Include      []string `json:"include"`
+	Exclude      []string `json:"exclude"`
+	AddTargets   bool     `json:"addTargets"`
+	Refresh      Duration `json:"refresh"`
+	CheckOrigins bool     `json:"checkOrigins"`
+}
+
+func (c *Cloudflare) UnmarshalJSON(data []byte) error {
+	type plain Cloudflare
+	p := plain{Types: []string{"A", "AAAA", "CNAME"}, Refresh: Duration(time.Hour)}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.Enabled && len(p.Zones) == 0 {
+		return errors.New("cloudflare zones must not be empty")
+	}
+	if p.Refresh <= 0 {
+		return errors.New("cloudflare refresh must be positive")
+	}
+	*c = Cloudflare(p)
+	return nil
+}
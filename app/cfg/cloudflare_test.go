@@ -0,0 +1,54 @@
+package cfg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCloudflare_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Cloudflare
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			input: `{"enabled": true, "zones": ["023e105f4ecef8ad9ca31a8372d0c353"]}`,
+			want:  Cloudflare{Enabled: true, Zones: []string{"023e105f4ecef8ad9ca31a8372d0c353"}, Types: []string{"A", "AAAA", "CNAME"}, Refresh: Duration(time.Hour)},
+		},
+		{
+			name:  "filtered",
+			input: `{"enabled": true, "zones": ["023e105f4ecef8ad9ca31a8372d0c353"], "types": ["A"], "include": ["*.example.com"], "addTargets": true, "refresh": "15m"}`,
+			want: Cloudflare{
+				Enabled: true, Zones: []string{"023e105f4ecef8ad9ca31a8372d0c353"}, Types: []string{"A"},
+				Include: []string{"*.example.com"}, AddTargets: true, Refresh: Duration(15 * time.Minute),
+			},
+		},
+		{
+			name:  "origin checks",
+			input: `{"enabled": true, "zones": ["023e105f4ecef8ad9ca31a8372d0c353"], "checkOrigins": true}`,
+			want: Cloudflare{
+				Enabled: true, Zones: []string{"023e105f4ecef8ad9ca31a8372d0c353"}, Types: []string{"A", "AAAA", "CNAME"},
+				Refresh: Duration(time.Hour), CheckOrigins: true,
+			},
+		},
+		{name: "invalid - no zones", input: `{"enabled": true}`, wantErr: true},
+		{name: "invalid - zero refresh", input: `{"enabled": true, "zones": ["023e105f4ecef8ad9ca31a8372d0c353"], "refresh": "0"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Cloudflare
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Cloudflare.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Cloudflare.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
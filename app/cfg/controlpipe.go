@@ -0,0 +1,8 @@
+package cfg
+
+// ControlPipe creates a named pipe at Path, where writing a "scan" line
+// starts a scan cycle right away, as SIGUSR1 does. It suits deploy scripts
+// that can write to a file shared with the tracker but can't signal it.
+type ControlPipe struct {
+	Path string `json:"path"`
+}
@@ -0,0 +1,20 @@
+package cfg
+
+import "encoding/json"
+
+// CTPolicy checks publicly trusted certificates against Chrome's CT policy,
+// using the log list at LogListURL to tell which logs count.
+type CTPolicy struct {
+	Enabled    bool   `json:"enabled"`
+	LogListURL string `json:"logListURL"`
+}
+
+func (c *CTPolicy) UnmarshalJSON(data []byte) error {
+	type plain CTPolicy
+	p := plain{LogListURL: "https://www.gstatic.com/ct/log_list/v3/log_list.json"}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*c = CTPolicy(p)
+	return nil
+}
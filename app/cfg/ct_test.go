@@ -0,0 +1,23 @@
+package cfg
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCTPolicy_UnmarshalJSON(t *testing.T) {
+	var got CTPolicy
+	if err := json.Unmarshal([]byte(`{"enabled": true}`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Enabled || got.LogListURL != "https://www.gstatic.com/ct/log_list/v3/log_list.json" {
+		t.Errorf("CTPolicy.UnmarshalJSON() = %+v, want Chrome's log list by default", got)
+	}
+
+	if err := json.Unmarshal([]byte(`{"enabled": true, "logListURL": "https://mirror.example.com/log_list.json"}`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.LogListURL != "https://mirror.example.com/log_list.json" {
+		t.Errorf("CTPolicy.UnmarshalJSON() = %+v, want the configured log list", got)
+	}
+}
@@ -0,0 +1,46 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CTMonitor searches CT logs for certificates issued for Domains, at most
+// once per Interval, and reports those never served to the scanner. Grace
+// allows for the time between issuance and deployment. Without Domains, the
+// hosts of the monitored hostnames are searched.
+type CTMonitor struct {
+	Enabled           bool     `json:"enabled"`
+	Domains           []string `json:"domains"`
+	IncludeSubdomains bool     `json:"includeSubdomains"`
+	Interval          Duration `json:"interval"`
+	Grace             Duration `json:"grace"`
+	Endpoint          string   `json:"endpoint"`
+}
+
+func (c *CTMonitor) UnmarshalJSON(data []byte) error {
+	type plain CTMonitor
+	p := plain{
+		Interval: Duration(24 * time.Hour),
+		Grace:    Duration(48 * time.Hour),
+		Endpoint: "https://crt.sh",
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	for _, domain := range p.Domains {
+		if !isDomain(domain) {
+			return fmt.Errorf("ctMonitor domain %q must be a hostname without a port", domain)
+		}
+	}
+	if p.Interval <= 0 {
+		return errors.New("ctMonitor interval must be positive")
+	}
+	if p.Grace < 0 {
+		return errors.New("ctMonitor grace must not be negative")
+	}
+	*c = CTMonitor(p)
+	return nil
+}
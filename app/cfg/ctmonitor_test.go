@@ -0,0 +1,49 @@
+package cfg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCTMonitor_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    CTMonitor
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			input: `{"enabled": true}`,
+			want:  CTMonitor{Enabled: true, Interval: Duration(24 * time.Hour), Grace: Duration(48 * time.Hour), Endpoint: "https://crt.sh"},
+		},
+		{
+			name:  "domains",
+			input: `{"enabled": true, "domains": ["example.com"], "includeSubdomains": true, "interval": "6h", "grace": "0s"}`,
+			want: CTMonitor{
+				Enabled:           true,
+				Domains:           []string{"example.com"},
+				IncludeSubdomains: true,
+				Interval:          Duration(6 * time.Hour),
+				Endpoint:          "https://crt.sh",
+			},
+		},
+		{name: "invalid - domain with port", input: `{"domains": ["example.com:8443"]}`, wantErr: true},
+		{name: "invalid - zero interval", input: `{"interval": "0s"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got CTMonitor
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CTMonitor.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CTMonitor.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package cfg
+
+// DANE checks the certificates served by hostnames that publish TLSA
+// records against them. Only records the resolver authenticated with DNSSEC
+// count as valid, so the resolver should validate.
+type DANE struct {
+	Enabled bool `json:"enabled"`
+}
@@ -0,0 +1,29 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// DeepScan runs slower, more intrusive probes against every endpoint at most
+// once per Interval. Protocols also probes which TLS versions each endpoint
+// accepts.
+type DeepScan struct {
+	Enabled   bool     `json:"enabled"`
+	Interval  Duration `json:"interval"`
+	Protocols bool     `json:"protocols"`
+}
+
+func (d *DeepScan) UnmarshalJSON(data []byte) error {
+	type plain DeepScan
+	p := plain{Interval: Duration(24 * time.Hour)}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.Interval <= 0 {
+		return errors.New("deepScan interval must be positive")
+	}
+	*d = DeepScan(p)
+	return nil
+}
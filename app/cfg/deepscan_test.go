@@ -0,0 +1,34 @@
+package cfg
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDeepScan_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    DeepScan
+		wantErr bool
+	}{
+		{name: "daily by default", input: `{"enabled": true}`, want: DeepScan{Enabled: true, Interval: Duration(24 * time.Hour)}},
+		{name: "weekly", input: `{"enabled": true, "interval": "7d"}`, want: DeepScan{Enabled: true, Interval: Duration(7 * 24 * time.Hour)}},
+		{name: "protocols", input: `{"enabled": true, "protocols": true}`, want: DeepScan{Enabled: true, Interval: Duration(24 * time.Hour), Protocols: true}},
+		{name: "invalid - zero interval", input: `{"enabled": true, "interval": "0s"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got DeepScan
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeepScan.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("DeepScan.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
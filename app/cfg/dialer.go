@@ -0,0 +1,38 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+)
+
+// Dialer overrides how Hostnames, which may be wildcards, are connected
+// to. Timeout replaces the global timeout, Source or Interface picks the
+// local address connections come from, and KeepAlive sets the TCP
+// keep-alive period, negative to turn keep-alives off.
+type Dialer struct {
+	Hostnames []Hostname `json:"hostnames"`
+	Timeout   Duration   `json:"timeout"`
+	Source    net.IP     `json:"source"`
+	Interface string     `json:"interface"`
+	KeepAlive Duration   `json:"keepAlive"`
+}
+
+func (d *Dialer) UnmarshalJSON(data []byte) error {
+	type plain Dialer
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if len(p.Hostnames) == 0 {
+		return errors.New("dialer needs hostnames")
+	}
+	if p.Timeout < 0 {
+		return errors.New("dialer timeout must not be negative")
+	}
+	if p.Source != nil && p.Interface != "" {
+		return errors.New("dialer takes a source or an interface, not both")
+	}
+	*d = Dialer(p)
+	return nil
+}
@@ -0,0 +1,42 @@
+package cfg
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDialer_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Dialer
+		wantErr bool
+	}{
+		{name: "timeout", input: `{"hostnames": ["*.corp.example.com"], "timeout": "60s"}`, want: Dialer{Hostnames: []Hostname{"*.corp.example.com"}, Timeout: Duration(time.Minute)}},
+		{
+			name:  "source and keep-alive",
+			input: `{"hostnames": ["vpn.example.com"], "source": "10.8.0.2", "keepAlive": "-1s"}`,
+			want:  Dialer{Hostnames: []Hostname{"vpn.example.com"}, Source: net.ParseIP("10.8.0.2"), KeepAlive: Duration(-time.Second)},
+		},
+		{name: "interface", input: `{"hostnames": ["vpn.example.com"], "interface": "tun0"}`, want: Dialer{Hostnames: []Hostname{"vpn.example.com"}, Interface: "tun0"}},
+		{name: "invalid - no hostnames", input: `{"timeout": "60s"}`, wantErr: true},
+		{name: "invalid - negative timeout", input: `{"hostnames": ["vpn.example.com"], "timeout": "-1s"}`, wantErr: true},
+		{name: "invalid - source and interface", input: `{"hostnames": ["vpn.example.com"], "source": "10.8.0.2", "interface": "tun0"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Dialer
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Dialer.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Dialer.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// DNSCache reuses resolutions across scan cycles for their TTL, clamped to
+// MinTTL and MaxTTL.
+type DNSCache struct {
+	Enabled bool     `json:"enabled"`
+	MinTTL  Duration `json:"minTTL"`
+	MaxTTL  Duration `json:"maxTTL"`
+}
+
+func (c *DNSCache) UnmarshalJSON(data []byte) error {
+	type plain DNSCache
+	p := plain{MinTTL: Duration(30 * time.Second), MaxTTL: Duration(time.Hour)}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.MinTTL < 0 {
+		return errors.New("dnsCache minTTL must not be negative")
+	}
+	if p.MaxTTL < p.MinTTL {
+		return errors.New("dnsCache maxTTL must be at least minTTL")
+	}
+	*c = DNSCache(p)
+	return nil
+}
@@ -0,0 +1,34 @@
+package cfg
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDNSCache_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    DNSCache
+		wantErr bool
+	}{
+		{name: "defaults", input: `{"enabled": true}`, want: DNSCache{Enabled: true, MinTTL: Duration(30 * time.Second), MaxTTL: Duration(time.Hour)}},
+		{name: "custom bounds", input: `{"enabled": true, "minTTL": "0s", "maxTTL": "5m"}`, want: DNSCache{Enabled: true, MaxTTL: Duration(5 * time.Minute)}},
+		{name: "invalid - negative floor", input: `{"enabled": true, "minTTL": "-1s"}`, wantErr: true},
+		{name: "invalid - ceiling below floor", input: `{"enabled": true, "minTTL": "10m", "maxTTL": "5m"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got DNSCache
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DNSCache.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("DNSCache.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
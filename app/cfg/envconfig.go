@@ -0,0 +1,36 @@
+package cfg
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// configEnv holds the whole config, as JSON or base64 encoded JSON, so a
+// container can run without a config file mounted. It replaces the file;
+// overlays, fragments and overrides still apply on top.
+const configEnv = "CERTTRACKER_CONFIG"
+
+// readBase reads the config from CERTTRACKER_CONFIG when it is set, or else
+// from the file at path.
+func readBase(path string) ([]byte, error) {
+	s := strings.TrimSpace(os.Getenv(configEnv))
+	if s == "" {
+		return readConfig(path)
+	}
+	data := []byte(s)
+	if !strings.HasPrefix(s, "{") {
+		decoded, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: neither JSON nor base64: %w", configEnv, err)
+		}
+		data = decoded
+	}
+	var v map[string]any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("%s: want a JSON object: %w", configEnv, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,50 @@
+package cfg
+
+import (
+	"encoding/base64"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadFromEnvironment(t *testing.T) {
+	const config = `{"hostnames": ["example.com"], "timeout": "30s", "scanInterval": "15m"}`
+	encoded := base64.StdEncoding.EncodeToString([]byte(config))
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "JSON", value: config},
+		{name: "indented JSON", value: "\n  " + config + "\n"},
+		{name: "base64", value: encoded},
+		{name: "wrapped base64", value: encoded[:40] + "\n" + encoded[40:] + "\n"},
+		{name: "not base64", value: "hostnames: [example.com]", wantErr: true},
+		{name: "base64 of something else", value: base64.StdEncoding.EncodeToString([]byte("hostnames: [example.com]")), wantErr: true},
+		{name: "broken JSON", value: `{"hostnames": [`, wantErr: true},
+	}
+
+	// no file exists at the configured path
+	configFilePath = filepath.Join(t.TempDir(), "config.json")
+	t.Cleanup(func() { configFilePath = defaultConfigFile })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(configEnv, tt.value)
+			t.Setenv("CERTTRACKER_TIMEOUT", "5s")
+			p, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(p.Hostnames) != 1 || p.ScanInterval != Duration(15*time.Minute) {
+				t.Errorf("Load() = %+v, want the config from %s", p, configEnv)
+			}
+			if p.Timeout != Duration(5*time.Second) {
+				t.Errorf("Timeout = %v, want the override over %s", time.Duration(p.Timeout), configEnv)
+			}
+		})
+	}
+}
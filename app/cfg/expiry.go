@@ -0,0 +1,61 @@
+package cfg
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Expiry alerts on leaf certificates nearing expiry. The alert escalates
+// through each step as expiry gets closer, until someone acknowledges it.
+type Expiry struct {
+	Enabled    bool             `json:"enabled"`
+	Escalation []EscalationStep `json:"escalation"`
+}
+
+// EscalationStep applies once a certificate expires Within the duration.
+type EscalationStep struct {
+	Within   Duration `json:"within"`
+	Severity string   `json:"severity"`
+	Route    string   `json:"route"`
+}
+
+func (e *Expiry) UnmarshalJSON(data []byte) error {
+	type plain Expiry
+	p := plain{Escalation: []EscalationStep{
+		{Within: Duration(30 * 24 * time.Hour), Severity: "warning", Route: "team"},
+		{Within: Duration(14 * 24 * time.Hour), Severity: "warning", Route: "manager"},
+		{Within: Duration(7 * 24 * time.Hour), Severity: "critical", Route: "page"},
+	}}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if len(p.Escalation) == 0 {
+		return errors.New("expiry escalation needs at least one step")
+	}
+	if err := sortEscalation(p.Escalation); err != nil {
+		return err
+	}
+	*e = Expiry(p)
+	return nil
+}
+
+// sortEscalation checks steps and puts the widest window first, so later
+// steps escalate.
+func sortEscalation(steps []EscalationStep) error {
+	for _, step := range steps {
+		if step.Within <= 0 {
+			return errors.New("expiry escalation within must be positive")
+		}
+		if step.Severity != "warning" && step.Severity != "critical" {
+			return fmt.Errorf("expiry escalation severity %q must be warning or critical", step.Severity)
+		}
+	}
+	slices.SortFunc(steps, func(a, b EscalationStep) int {
+		return cmp.Compare(b.Within, a.Within)
+	})
+	return nil
+}
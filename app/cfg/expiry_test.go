@@ -0,0 +1,46 @@
+package cfg
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExpiry_UnmarshalJSON(t *testing.T) {
+	const day = Duration(24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		input      string
+		wantWithin []Duration
+		wantErr    bool
+	}{
+		{name: "default escalation", input: `{"enabled": true}`, wantWithin: []Duration{30 * day, 14 * day, 7 * day}},
+		{
+			name:       "sorted widest first",
+			input:      `{"enabled": true, "escalation": [{"within": "3d", "severity": "critical", "route": "page"}, {"within": "21d", "severity": "warning", "route": "team"}]}`,
+			wantWithin: []Duration{21 * day, 3 * day},
+		},
+		{name: "invalid - no steps", input: `{"enabled": true, "escalation": []}`, wantErr: true},
+		{name: "invalid - unknown severity", input: `{"escalation": [{"within": "7d", "severity": "page"}]}`, wantErr: true},
+		{name: "invalid - zero window", input: `{"escalation": [{"within": "0s", "severity": "warning"}]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Expiry
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expiry.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var within []Duration
+			for _, step := range got.Escalation {
+				within = append(within, step.Within)
+			}
+			if !tt.wantErr && !slices.Equal(within, tt.wantWithin) {
+				t.Errorf("Expiry.UnmarshalJSON() windows = %v, want %v", within, tt.wantWithin)
+			}
+		})
+	}
+}
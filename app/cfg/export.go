@@ -0,0 +1,50 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+var exportFormats = []string{"json", "jsonl"}
+
+// Export uploads every scan cycle's snapshot to Bucket in S3-compatible
+// object storage, under Prefix. Endpoint defaults to AWS S3 in Region.
+// Format "json" uploads the snapshot as one document and "jsonl" one line
+// per certificate. Uploads older than Retention are deleted, when set.
+type Export struct {
+	Enabled   bool     `json:"enabled"`
+	Endpoint  string   `json:"endpoint"`
+	Region    string   `json:"region"`
+	Bucket    string   `json:"bucket"`
+	Prefix    string   `json:"prefix"`
+	Format    string   `json:"format"`
+	Retention Duration `json:"retention"`
+}
+
+func (e *Export) UnmarshalJSON(data []byte) error {
+	type plain Export
+	p := plain{Region: "us-east-1", Format: "json"}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.Enabled && p.Bucket == "" {
+		return errors.New("export bucket must not be empty")
+	}
+	if p.Endpoint != "" {
+		u, err := url.Parse(p.Endpoint)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("export endpoint %q must be an http or https URL", p.Endpoint)
+		}
+	}
+	if !slices.Contains(exportFormats, p.Format) {
+		return fmt.Errorf("export format %q must be one of %v", p.Format, exportFormats)
+	}
+	if p.Retention < 0 {
+		return errors.New("export retention must not be negative")
+	}
+	*e = Export(p)
+	return nil
+}
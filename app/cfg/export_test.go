@@ -0,0 +1,45 @@
+package cfg
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestExport_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Export
+		wantErr bool
+	}{
+		{name: "defaults", input: `{}`, want: Export{Region: "us-east-1", Format: "json"}},
+		{
+			name:  "S3",
+			input: `{"enabled": true, "region": "eu-west-1", "bucket": "scans", "prefix": "cert-tracker/", "format": "jsonl", "retention": "90d"}`,
+			want:  Export{Enabled: true, Region: "eu-west-1", Bucket: "scans", Prefix: "cert-tracker/", Format: "jsonl", Retention: Duration(90 * 24 * time.Hour)},
+		},
+		{
+			name:  "compatible endpoint",
+			input: `{"enabled": true, "endpoint": "http://minio.internal:9000", "bucket": "scans"}`,
+			want:  Export{Enabled: true, Endpoint: "http://minio.internal:9000", Region: "us-east-1", Bucket: "scans", Format: "json"},
+		},
+		{name: "invalid - no bucket", input: `{"enabled": true}`, wantErr: true},
+		{name: "invalid - endpoint", input: `{"enabled": true, "bucket": "scans", "endpoint": "minio.internal"}`, wantErr: true},
+		{name: "invalid - parquet", input: `{"enabled": true, "bucket": "scans", "format": "parquet"}`, wantErr: true},
+		{name: "invalid - negative retention", input: `{"retention": "-1h"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Export
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Export.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Export.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
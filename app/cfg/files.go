@@ -0,0 +1,33 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// Files reads certificates from local PEM or DER files each cycle. Paths
+// are files, directories, whose files are read but not their
+// subdirectories, or glob patterns such as /etc/ssl/private/*.pem.
+type Files struct {
+	Paths []string `json:"paths"`
+}
+
+func (f *Files) UnmarshalJSON(data []byte) error {
+	type plain Files
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	for _, path := range p.Paths {
+		if path == "" {
+			return errors.New("files paths must not be empty")
+		}
+		if _, err := filepath.Match(path, ""); err != nil {
+			return fmt.Errorf("files path %q: %w", path, err)
+		}
+	}
+	*f = Files(p)
+	return nil
+}
@@ -0,0 +1,34 @@
+package cfg
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestFiles_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Files
+		wantErr bool
+	}{
+		{name: "disabled", input: `{}`, want: Files{}},
+		{name: "files, directories and globs", input: `{"paths": ["/etc/ssl/certs/site.pem", "/var/run/secrets/tls", "/etc/ssl/private/*.pem"]}`, want: Files{Paths: []string{"/etc/ssl/certs/site.pem", "/var/run/secrets/tls", "/etc/ssl/private/*.pem"}}},
+		{name: "invalid - empty path", input: `{"paths": [""]}`, wantErr: true},
+		{name: "invalid - pattern", input: `{"paths": ["/etc/ssl/[*.pem"]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Files
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Files.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got.Paths, tt.want.Paths) {
+				t.Errorf("Files.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configExtensions are the config file formats Load reads, besides JSON.
+var configExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// findConfigFile falls back to config.yaml, config.yml or config.toml when
+// the default config.json doesn't exist.
+func findConfigFile(path string) string {
+	if path != defaultConfigFile {
+		return path
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		return path
+	}
+	for _, ext := range configExtensions[1:] {
+		candidate := strings.TrimSuffix(path, filepath.Ext(path)) + ext
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return path
+}
+
+// readConfig reads a config file and returns it as JSON, so YAML and TOML
+// files go through the same merging and validation as JSON ones.
+func readConfig(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &v)
+	case ".toml":
+		err = toml.Unmarshal(data, &v)
+	default:
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if v == nil {
+		// an empty YAML document
+		v = map[string]any{}
+	}
+	converted, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return converted, nil
+}
@@ -0,0 +1,61 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLoadFileFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr bool
+	}{
+		{name: "json", file: "config.json", content: `{"hostnames": ["example.com", "example.org:8443"], "timeout": "30s", "api": {"listen": ":8080"}}`},
+		{name: "yaml", file: "config.yaml", content: `
+# targets
+hostnames:
+  - example.com
+  - example.org:8443
+timeout: 30s
+api:
+  listen: ":8080"
+`},
+		{name: "yml", file: "config.yml", content: "hostnames: [example.com, \"example.org:8443\"]\ntimeout: 30s\napi: {listen: \":8080\"}\n"},
+		{name: "toml", file: "config.toml", content: `
+hostnames = ["example.com", "example.org:8443"]
+timeout = "30s"
+
+[api]
+listen = ":8080"
+`},
+		{name: "invalid - yaml syntax", file: "config.yaml", content: "hostnames: [example.com\n", wantErr: true},
+		{name: "invalid - yaml hostname", file: "config.yaml", content: "hostnames: [\"bad host\"]\n", wantErr: true},
+		{name: "invalid - toml duration", file: "config.toml", content: `timeout = "soon"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			os.WriteFile(path, []byte(tt.content), 0o644)
+			var p Params
+			err := loadFile(path, &p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !slices.Equal(p.Hostnames, []Hostname{"example.com", "example.org:8443"}) {
+				t.Errorf("Hostnames = %v", p.Hostnames)
+			}
+			if p.Timeout != Duration(30*time.Second) || p.API.Listen != ":8080" {
+				t.Errorf("Timeout = %v, API.Listen = %q", time.Duration(p.Timeout), p.API.Listen)
+			}
+		})
+	}
+}
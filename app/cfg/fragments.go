@@ -0,0 +1,88 @@
+package cfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// applyFragments merges every JSON, YAML or TOML file in the directory
+// named by configDir into base, in name order. A relative configDir is
+// relative to the config file.
+func applyFragments(base []byte, configFilePath string) ([]byte, error) {
+	var b any
+	if err := decode(base, &b); err != nil {
+		return nil, err
+	}
+	root, ok := b.(map[string]any)
+	if !ok {
+		return base, nil
+	}
+	dir, _ := root["configDir"].(string)
+	if dir == "" {
+		return base, nil
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(configFilePath), dir)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("configDir: %w", err)
+	}
+	var paths []string
+	for _, ext := range configExtensions {
+		matches, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		data, err := readConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		var f any
+		if err := decode(data, &f); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if b, err = mergeFragment(b, f, ""); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return json.Marshal(b)
+}
+
+// mergeFragment adds fragment to base. Objects merge recursively and arrays
+// are concatenated, so fragments can each add their own targets. A value
+// that's already set differently is an error, since no file owns it.
+func mergeFragment(base, fragment any, path string) (any, error) {
+	if base == nil {
+		return fragment, nil
+	}
+	switch f := fragment.(type) {
+	case map[string]any:
+		if b, ok := base.(map[string]any); ok {
+			for k, v := range f {
+				merged, err := mergeFragment(b[k], v, strings.TrimPrefix(path+"."+k, "."))
+				if err != nil {
+					return nil, err
+				}
+				b[k] = merged
+			}
+			return b, nil
+		}
+	case []any:
+		if b, ok := base.([]any); ok {
+			return append(b, f...), nil
+		}
+	}
+	if !reflect.DeepEqual(base, fragment) {
+		return nil, fmt.Errorf("%s is already set to a different value", path)
+	}
+	return base, nil
+}
@@ -0,0 +1,85 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadFileWithFragments(t *testing.T) {
+	tests := []struct {
+		name          string
+		fragments     map[string]string
+		wantHostnames []Hostname
+		wantTenants   int
+		wantErr       bool
+	}{
+		{
+			name: "targets from every file",
+			fragments: map[string]string{
+				"payments.json": `{"tenants": [{"name": "payments", "hostnames": ["pay.example.com"]}]}`,
+				"web.json":      `{"hostnames": ["www.example.com"], "timeout": "30s"}`,
+				"notes.txt":     `not json`,
+			},
+			wantHostnames: []Hostname{"example.com", "www.example.com"},
+			wantTenants:   1,
+		},
+		{
+			name:          "empty directory",
+			fragments:     map[string]string{},
+			wantHostnames: []Hostname{"example.com"},
+		},
+		{
+			name:      "conflicting setting",
+			fragments: map[string]string{"web.json": `{"timeout": "5s"}`},
+			wantErr:   true,
+		},
+		{
+			name:      "invalid fragment",
+			fragments: map[string]string{"web.json": `{"hostnames": [`},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			base := filepath.Join(dir, "config.json")
+			os.WriteFile(base, []byte(`{
+				"hostnames": ["example.com"],
+				"timeout": "30s",
+				"configDir": "conf.d"
+			}`), 0o644)
+			os.Mkdir(filepath.Join(dir, "conf.d"), 0o755)
+			for name, content := range tt.fragments {
+				os.WriteFile(filepath.Join(dir, "conf.d", name), []byte(content), 0o644)
+			}
+
+			var p Params
+			err := loadFile(base, &p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !slices.Equal(p.Hostnames, tt.wantHostnames) {
+				t.Errorf("Hostnames = %v, want %v", p.Hostnames, tt.wantHostnames)
+			}
+			if len(p.Tenants) != tt.wantTenants {
+				t.Errorf("Tenants = %+v, want %d", p.Tenants, tt.wantTenants)
+			}
+		})
+	}
+}
+
+func TestLoadFileMissingConfigDir(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(base, []byte(`{"hostnames": ["example.com"], "configDir": "conf.d"}`), 0o644)
+
+	var p Params
+	if err := loadFile(base, &p); err == nil {
+		t.Error("Expected error for missing configDir")
+	}
+}
@@ -0,0 +1,30 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// KeyAudit warns about weak keys and signatures anywhere in a served chain:
+// RSA keys under MinRSABits, ECDSA keys on curves under MinECDSABits, DSA
+// keys, and signatures using one of WeakHashes. Self-signed roots' own
+// signatures aren't judged, since clients trust them by key.
+type KeyAudit struct {
+	Enabled      bool     `json:"enabled"`
+	MinRSABits   int      `json:"minRSABits"`
+	MinECDSABits int      `json:"minECDSABits"`
+	WeakHashes   []string `json:"weakHashes"`
+}
+
+func (k *KeyAudit) UnmarshalJSON(data []byte) error {
+	type plain KeyAudit
+	p := plain{MinRSABits: 2048, MinECDSABits: 256, WeakHashes: []string{"MD2", "MD5", "SHA1"}}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.MinRSABits < 0 || p.MinECDSABits < 0 {
+		return errors.New("keyAudit minimum key sizes must not be negative")
+	}
+	*k = KeyAudit(p)
+	return nil
+}
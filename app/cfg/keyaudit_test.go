@@ -0,0 +1,33 @@
+package cfg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestKeyAudit_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    KeyAudit
+		wantErr bool
+	}{
+		{name: "defaults", input: `{"enabled": true}`, want: KeyAudit{Enabled: true, MinRSABits: 2048, MinECDSABits: 256, WeakHashes: []string{"MD2", "MD5", "SHA1"}}},
+		{name: "stricter", input: `{"enabled": true, "minRSABits": 3072, "minECDSABits": 384, "weakHashes": ["MD5", "SHA1", "SHA224"]}`, want: KeyAudit{Enabled: true, MinRSABits: 3072, MinECDSABits: 384, WeakHashes: []string{"MD5", "SHA1", "SHA224"}}},
+		{name: "invalid - negative", input: `{"minRSABits": -1}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got KeyAudit
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("KeyAudit.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("KeyAudit.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
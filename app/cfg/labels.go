@@ -0,0 +1,45 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// TargetLabels attaches Labels, such as team or env, to the targets one of
+// Hostnames, which may be wildcards, covers. Targets covered by several
+// entries get the labels of all of them, later entries winning.
+type TargetLabels struct {
+	Hostnames []Hostname        `json:"hostnames"`
+	Labels    map[string]string `json:"labels"`
+}
+
+func (l *TargetLabels) UnmarshalJSON(data []byte) error {
+	type plain TargetLabels
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if len(p.Hostnames) == 0 {
+		return errors.New("labels need hostnames")
+	}
+	if len(p.Labels) == 0 {
+		return errors.New("labels need at least one label")
+	}
+	if err := checkLabelNames("label", p.Labels); err != nil {
+		return err
+	}
+	*l = TargetLabels(p)
+	return nil
+}
+
+// checkLabelNames makes sure labels can be used in metrics and Alertmanager
+// as they are.
+func checkLabelNames(what string, labels map[string]string) error {
+	for name := range labels {
+		if !labelName.MatchString(name) {
+			return fmt.Errorf("%s %q must be a Prometheus label name", what, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package cfg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTargetLabels_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    TargetLabels
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			input: `{"hostnames": ["pay.example.com", "*.checkout.example.com"], "labels": {"team": "payments", "env": "prod"}}`,
+			want: TargetLabels{
+				Hostnames: []Hostname{"pay.example.com", "*.checkout.example.com"},
+				Labels:    map[string]string{"team": "payments", "env": "prod"},
+			},
+		},
+		{name: "invalid - no hostnames", input: `{"labels": {"team": "payments"}}`, wantErr: true},
+		{name: "invalid - no labels", input: `{"hostnames": ["example.com"]}`, wantErr: true},
+		{name: "invalid - bad label name", input: `{"hostnames": ["example.com"], "labels": {"cost-center": "42"}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got TargetLabels
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TargetLabels.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TargetLabels.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// LeaderElection lets replicas sharing a storeDir take turns: the holder of
+// a lease in the store scans and alerts while the others stand by.
+type LeaderElection struct {
+	Enabled       bool     `json:"enabled"`
+	LeaseDuration Duration `json:"leaseDuration"`
+}
+
+func (l *LeaderElection) UnmarshalJSON(data []byte) error {
+	type plain LeaderElection
+	p := plain{LeaseDuration: Duration(30 * time.Second)}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.LeaseDuration < Duration(3*time.Second) {
+		return errors.New("leaderElection leaseDuration must be at least 3s")
+	}
+	*l = LeaderElection(p)
+	return nil
+}
@@ -0,0 +1,44 @@
+package cfg
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLeaderElection_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    LeaderElection
+		wantErr bool
+	}{
+		{name: "default lease", input: `{"enabled": true}`, want: LeaderElection{Enabled: true, LeaseDuration: Duration(30 * time.Second)}},
+		{name: "custom lease", input: `{"enabled": true, "leaseDuration": "2m"}`, want: LeaderElection{Enabled: true, LeaseDuration: Duration(2 * time.Minute)}},
+		{name: "invalid - too short", input: `{"enabled": true, "leaseDuration": "1s"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got LeaderElection
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LeaderElection.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("LeaderElection.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFileLeaderElectionNeedsStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"leaderElection": {"enabled": true}}`), 0o644)
+	var p Params
+	if err := loadFile(path, &p); err == nil {
+		t.Error("Expected error for leader election without storeDir")
+	}
+}
@@ -0,0 +1,78 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// LogFormat is how log records are written: "json", the default, or "text"
+// for key=value lines that are easier to read in a terminal.
+type LogFormat string
+
+func (f *LogFormat) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "json", "text":
+		*f = LogFormat(s)
+		return nil
+	}
+	return fmt.Errorf("logFormat %q must be json or text", s)
+}
+
+var logDestinations = []string{"stdout", "file", "syslog"}
+
+// LogOutput is where logs go: "stdout", "file" or "syslog". File logs are
+// written to Path, which is rotated once it grows past MaxSize megabytes or,
+// when MaxAge is set, gets older than that, keeping Backups rotated files.
+// Syslog logs go to the local daemon, or to Syslog when it names a
+// udp:// or tcp:// server, tagged with Tag.
+type LogOutput struct {
+	Destination string   `json:"destination"`
+	Path        string   `json:"path"`
+	MaxSize     int      `json:"maxSize"`
+	MaxAge      Duration `json:"maxAge"`
+	Backups     int      `json:"backups"`
+	Syslog      string   `json:"syslog"`
+	Tag         string   `json:"tag"`
+}
+
+func defaultLogOutput() LogOutput {
+	return LogOutput{Destination: "stdout", MaxSize: 100, Backups: 5, Tag: "cert-tracker"}
+}
+
+func (l *LogOutput) UnmarshalJSON(data []byte) error {
+	type plain LogOutput
+	p := plain(defaultLogOutput())
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if !slices.Contains(logDestinations, p.Destination) {
+		return fmt.Errorf("logOutput destination %q must be one of %v", p.Destination, logDestinations)
+	}
+	if p.Destination == "file" && p.Path == "" {
+		return errors.New("logOutput file destination needs a path")
+	}
+	if p.MaxSize <= 0 {
+		return errors.New("logOutput maxSize must be positive")
+	}
+	if p.MaxAge < 0 {
+		return errors.New("logOutput maxAge must not be negative")
+	}
+	if p.Backups < 0 {
+		return errors.New("logOutput backups must not be negative")
+	}
+	if p.Syslog != "" {
+		u, err := url.Parse(p.Syslog)
+		if err != nil || (u.Scheme != "udp" && u.Scheme != "tcp") || u.Host == "" {
+			return fmt.Errorf("logOutput syslog %q must be a udp:// or tcp:// address", p.Syslog)
+		}
+	}
+	*l = LogOutput(p)
+	return nil
+}
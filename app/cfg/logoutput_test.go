@@ -0,0 +1,72 @@
+package cfg
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestLogOutput_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    LogOutput
+		wantErr bool
+	}{
+		{name: "defaults", input: `{}`, want: LogOutput{Destination: "stdout", MaxSize: 100, Backups: 5, Tag: "cert-tracker"}},
+		{
+			name:  "rotating file",
+			input: `{"destination": "file", "path": "/var/log/cert-tracker.log", "maxSize": 10, "maxAge": "1d", "backups": 3}`,
+			want:  LogOutput{Destination: "file", Path: "/var/log/cert-tracker.log", MaxSize: 10, MaxAge: Duration(24 * time.Hour), Backups: 3, Tag: "cert-tracker"},
+		},
+		{
+			name:  "remote syslog",
+			input: `{"destination": "syslog", "syslog": "udp://syslog.internal:514", "tag": "certs"}`,
+			want:  LogOutput{Destination: "syslog", MaxSize: 100, Backups: 5, Syslog: "udp://syslog.internal:514", Tag: "certs"},
+		},
+		{name: "invalid - unknown destination", input: `{"destination": "journald"}`, wantErr: true},
+		{name: "invalid - file without path", input: `{"destination": "file"}`, wantErr: true},
+		{name: "invalid - zero max size", input: `{"maxSize": 0}`, wantErr: true},
+		{name: "invalid - negative backups", input: `{"backups": -1}`, wantErr: true},
+		{name: "invalid - syslog scheme", input: `{"destination": "syslog", "syslog": "syslog.internal:514"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got LogOutput
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LogOutput.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("LogOutput.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogFormat_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    LogFormat
+		wantErr bool
+	}{
+		{input: `"json"`, want: "json"},
+		{input: `"text"`, want: "text"},
+		{input: `"console"`, wantErr: true},
+		{input: `"TEXT"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var got LogFormat
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LogFormat.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("LogFormat.UnmarshalJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Maintenance mutes alerts about Hostnames, which may be wildcards, from
+// From until Until, so planned renewals and hosts being decommissioned
+// don't page anyone. Without a From the alerts are snoozed right away.
+// Times are RFC 3339 or dates, which start at midnight UTC.
+type Maintenance struct {
+	Hostnames []Hostname `json:"hostnames"`
+	From      time.Time  `json:"from"`
+	Until     time.Time  `json:"until"`
+	Reason    string     `json:"reason"`
+}
+
+// Covers reports whether the window is open at t.
+func (m Maintenance) Covers(t time.Time) bool {
+	return !t.Before(m.From) && t.Before(m.Until)
+}
+
+func (m *Maintenance) UnmarshalJSON(data []byte) error {
+	var p struct {
+		Hostnames []Hostname `json:"hostnames"`
+		From      string     `json:"from"`
+		Until     string     `json:"until"`
+		Reason    string     `json:"reason"`
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if len(p.Hostnames) == 0 {
+		return errors.New("maintenance needs hostnames")
+	}
+	if p.Until == "" {
+		return errors.New("maintenance needs an until time")
+	}
+	until, err := parseTime(p.Until)
+	if err != nil {
+		return fmt.Errorf("maintenance until: %w", err)
+	}
+	var from time.Time
+	if p.From != "" {
+		if from, err = parseTime(p.From); err != nil {
+			return fmt.Errorf("maintenance from: %w", err)
+		}
+		if !from.Before(until) {
+			return errors.New("maintenance from must be before until")
+		}
+	}
+	*m = Maintenance{Hostnames: p.Hostnames, From: from, Until: until, Reason: p.Reason}
+	return nil
+}
+
+// parseTime reads an RFC 3339 time, or a date as midnight UTC.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
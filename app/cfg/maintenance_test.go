@@ -0,0 +1,67 @@
+package cfg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMaintenance_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Maintenance
+		wantErr bool
+	}{
+		{
+			name:  "snooze until a date",
+			input: `{"hostnames": ["old.example.com"], "until": "2025-08-01", "reason": "decommissioned"}`,
+			want:  Maintenance{Hostnames: []Hostname{"old.example.com"}, Until: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), Reason: "decommissioned"},
+		},
+		{
+			name:  "window",
+			input: `{"hostnames": ["*.example.com"], "from": "2025-07-12T22:00:00Z", "until": "2025-07-13T02:00:00Z"}`,
+			want: Maintenance{
+				Hostnames: []Hostname{"*.example.com"},
+				From:      time.Date(2025, 7, 12, 22, 0, 0, 0, time.UTC),
+				Until:     time.Date(2025, 7, 13, 2, 0, 0, 0, time.UTC),
+			},
+		},
+		{name: "invalid - no hostnames", input: `{"until": "2025-08-01"}`, wantErr: true},
+		{name: "invalid - no until", input: `{"hostnames": ["example.com"]}`, wantErr: true},
+		{name: "invalid - bad time", input: `{"hostnames": ["example.com"], "until": "August 1st"}`, wantErr: true},
+		{name: "invalid - ends before it starts", input: `{"hostnames": ["example.com"], "from": "2025-08-02", "until": "2025-08-01"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Maintenance
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Maintenance.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Maintenance.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaintenance_Covers(t *testing.T) {
+	m := Maintenance{From: time.Date(2025, 7, 12, 22, 0, 0, 0, time.UTC), Until: time.Date(2025, 7, 13, 2, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{at: m.From.Add(-time.Minute), want: false},
+		{at: m.From, want: true},
+		{at: m.Until.Add(-time.Minute), want: true},
+		{at: m.Until, want: false},
+	}
+	for _, tt := range tests {
+		if got := m.Covers(tt.at); got != tt.want {
+			t.Errorf("Covers(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
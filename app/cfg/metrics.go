@@ -0,0 +1,29 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Metrics exports scan results for Prometheus, served at /metrics on the
+// Listen address. Textfile is a file in node_exporter's textfile collector
+// directory, rewritten after each cycle, for hosts that mustn't listen.
+type Metrics struct {
+	Listen   string `json:"listen"`
+	Textfile string `json:"textfile"`
+}
+
+func (m *Metrics) UnmarshalJSON(data []byte) error {
+	type plain Metrics
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	// node_exporter ignores other files
+	if p.Textfile != "" && !strings.HasSuffix(p.Textfile, ".prom") {
+		return errors.New("metrics textfile must end in .prom")
+	}
+	*m = Metrics(p)
+	return nil
+}
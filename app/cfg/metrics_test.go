@@ -0,0 +1,37 @@
+package cfg
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMetrics_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Metrics
+		wantErr bool
+	}{
+		{name: "disabled", input: `{}`, want: Metrics{}},
+		{
+			name:  "textfile",
+			input: `{"textfile": "/var/lib/node_exporter/textfile_collector/cert_tracker.prom"}`,
+			want:  Metrics{Textfile: "/var/lib/node_exporter/textfile_collector/cert_tracker.prom"},
+		},
+		{name: "listener", input: `{"listen": ":9464"}`, want: Metrics{Listen: ":9464"}},
+		{name: "invalid - not a .prom file", input: `{"textfile": "/tmp/cert_tracker.txt"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Metrics
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Metrics.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Metrics.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
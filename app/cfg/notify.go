@@ -0,0 +1,288 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"text/template"
+	"time"
+)
+
+// eventKinds are the events notifications can be limited to.
+var eventKinds = []string{"alert.firing", "alert.escalated", "alert.resolved", "certificate.rotated"}
+
+// Notifications are where alerts and certificate events are sent as they
+// happen. Each destination with Match only gets the events whose labels
+// include all of Match's, such as an alert about a target labelled with a
+// team; the rest get every event.
+type Notifications struct {
+	Webhooks []Webhook `json:"webhooks"`
+	Slack    []Slack   `json:"slack"`
+	Email    []Email   `json:"email"`
+	// PagerDuty and Opsgenie open incidents rather than send messages.
+	PagerDuty []PagerDuty `json:"pagerDuty"`
+	Opsgenie  []Opsgenie  `json:"opsgenie"`
+	// Alertmanager hands alerts to Prometheus Alertmanager for routing.
+	Alertmanager []Alertmanager `json:"alertmanager"`
+}
+
+// Webhook receives events as JSON POSTs. With a Secret, each request is
+// signed with HMAC-SHA256. Events limits which kinds are sent; all are by
+// default.
+type Webhook struct {
+	URL     string            `json:"url"`
+	Secret  Secret            `json:"secret"`
+	Events  []string          `json:"events"`
+	Retries int               `json:"retries"`
+	Match   map[string]string `json:"match"`
+}
+
+func (w *Webhook) UnmarshalJSON(data []byte) error {
+	type plain Webhook
+	p := plain{Retries: 3}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	u, err := url.Parse(p.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("webhook url %q must be an http or https URL", p.URL)
+	}
+	if err := checkEvents(p.Events); err != nil {
+		return err
+	}
+	if err := checkLabelNames("match label", p.Match); err != nil {
+		return err
+	}
+	if p.Retries < 0 {
+		return errors.New("webhook retries must not be negative")
+	}
+	*w = Webhook(p)
+	return nil
+}
+
+// Slack posts events as messages, through an incoming WebhookURL or, with a
+// bot Token, to Channel. Template is a Go text/template for the message
+// text; a default showing the hostname, days to expiry, issuer and
+// fingerprint is used when it's empty.
+type Slack struct {
+	WebhookURL Secret            `json:"webhookUrl"`
+	Token      Secret            `json:"token"`
+	Channel    string            `json:"channel"`
+	Template   string            `json:"template"`
+	Events     []string          `json:"events"`
+	Match      map[string]string `json:"match"`
+}
+
+func (s *Slack) UnmarshalJSON(data []byte) error {
+	type plain Slack
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	switch {
+	case (p.WebhookURL == "") == (p.Token == ""):
+		return errors.New("slack needs either a webhookUrl or a token")
+	case p.Token != "" && p.Channel == "":
+		return errors.New("slack token needs a channel")
+	case p.WebhookURL != "":
+		// the URL is the credential, so it stays out of the error
+		if u, err := url.Parse(string(p.WebhookURL)); err != nil || u.Scheme != "https" || u.Host == "" {
+			return errors.New("slack webhookUrl must be an https URL")
+		}
+	}
+	if err := checkEvents(p.Events); err != nil {
+		return err
+	}
+	if err := checkLabelNames("match label", p.Match); err != nil {
+		return err
+	}
+	if _, err := template.New("").Parse(p.Template); err != nil {
+		return fmt.Errorf("slack template: %w", err)
+	}
+	*s = Slack(p)
+	return nil
+}
+
+// Email sends events through the SMTP server at SMTPServer, port 587
+// unless given, from From to every address in To. Username and Password
+// authenticate when set, which needs TLS. Subject and Template are Go
+// text/templates for the subject and body, with defaults when empty.
+type Email struct {
+	SMTPServer string            `json:"smtpServer"`
+	Username   string            `json:"username"`
+	Password   Secret            `json:"password"`
+	From       string            `json:"from"`
+	To         []string          `json:"to"`
+	Subject    string            `json:"subject"`
+	Template   string            `json:"template"`
+	Events     []string          `json:"events"`
+	Match      map[string]string `json:"match"`
+}
+
+func (e *Email) UnmarshalJSON(data []byte) error {
+	type plain Email
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.SMTPServer == "" {
+		return errors.New("email needs an smtpServer")
+	}
+	if _, _, err := net.SplitHostPort(p.SMTPServer); err != nil {
+		p.SMTPServer = net.JoinHostPort(p.SMTPServer, "587")
+	}
+	if _, err := mail.ParseAddress(p.From); err != nil {
+		return fmt.Errorf("email from %q: %w", p.From, err)
+	}
+	if len(p.To) == 0 {
+		return errors.New("email needs at least one to address")
+	}
+	for _, to := range p.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("email to %q: %w", to, err)
+		}
+	}
+	if err := checkEvents(p.Events); err != nil {
+		return err
+	}
+	if err := checkLabelNames("match label", p.Match); err != nil {
+		return err
+	}
+	if _, err := template.New("").Parse(p.Subject); err != nil {
+		return fmt.Errorf("email subject: %w", err)
+	}
+	if _, err := template.New("").Parse(p.Template); err != nil {
+		return fmt.Errorf("email template: %w", err)
+	}
+	*e = Email(p)
+	return nil
+}
+
+// incidentAlerts are the alert kinds that can open incidents.
+var incidentAlerts = []string{
+	"alpn-mismatch", "caa-invalid", "caa-mismatch", "chain-duplicate", "chain-expired-intermediate",
+	"chain-incomplete", "chain-out-of-order", "chain-oversized", "cloudflare-edge", "cloudflare-origin",
+	"ct-policy", "ct-unobserved", "dane-mismatch", "expiry", "legacy-cipher",
+	"name-mismatch", "ocsp-revoked", "pin-mismatch", "split-brain", "stepca-revoked",
+	"stepca-superseded", "validity-policy", "verify-failed", "watchdog", "weak-crypto",
+}
+
+// PagerDuty opens an incident through the Events API v2 when a critical
+// alert of one of the Alerts kinds fires, and resolves it when the alert
+// does. By default only expiry and verify-failed alerts open incidents.
+type PagerDuty struct {
+	RoutingKey Secret            `json:"routingKey"`
+	Alerts     []string          `json:"alerts"`
+	Match      map[string]string `json:"match"`
+}
+
+func (p *PagerDuty) UnmarshalJSON(data []byte) error {
+	type plain PagerDuty
+	v := plain{Alerts: []string{"expiry", "verify-failed"}}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.RoutingKey == "" {
+		return errors.New("pagerDuty needs a routingKey")
+	}
+	if err := checkIncidentAlerts(v.Alerts); err != nil {
+		return err
+	}
+	if err := checkLabelNames("match label", v.Match); err != nil {
+		return err
+	}
+	*p = PagerDuty(v)
+	return nil
+}
+
+// Opsgenie opens and closes Opsgenie alerts like PagerDuty incidents, in
+// the "us" or "eu" Region.
+type Opsgenie struct {
+	APIKey Secret            `json:"apiKey"`
+	Region string            `json:"region"`
+	Alerts []string          `json:"alerts"`
+	Match  map[string]string `json:"match"`
+}
+
+func (o *Opsgenie) UnmarshalJSON(data []byte) error {
+	type plain Opsgenie
+	p := plain{Region: "us", Alerts: []string{"expiry", "verify-failed"}}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.APIKey == "" {
+		return errors.New("opsgenie needs an apiKey")
+	}
+	if p.Region != "us" && p.Region != "eu" {
+		return fmt.Errorf("opsgenie region %q must be us or eu", p.Region)
+	}
+	if err := checkIncidentAlerts(p.Alerts); err != nil {
+		return err
+	}
+	if err := checkLabelNames("match label", p.Match); err != nil {
+		return err
+	}
+	*o = Opsgenie(p)
+	return nil
+}
+
+// Alertmanager sends every alert to the Prometheus Alertmanager at URL
+// through its v2 API, with Labels added to each. Firing alerts are sent
+// again every Resend so Alertmanager doesn't resolve them on its own.
+type Alertmanager struct {
+	URL    string            `json:"url"`
+	Labels map[string]string `json:"labels"`
+	Resend Duration          `json:"resend"`
+	Match  map[string]string `json:"match"`
+}
+
+func (a *Alertmanager) UnmarshalJSON(data []byte) error {
+	type plain Alertmanager
+	p := plain{Resend: Duration(time.Minute)}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	u, err := url.Parse(p.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("alertmanager url %q must be an http or https URL", p.URL)
+	}
+	if err := checkLabelNames("alertmanager label", p.Labels); err != nil {
+		return err
+	}
+	if err := checkLabelNames("match label", p.Match); err != nil {
+		return err
+	}
+	if p.Resend <= 0 {
+		return errors.New("alertmanager resend must be positive")
+	}
+	*a = Alertmanager(p)
+	return nil
+}
+
+var labelName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+func checkIncidentAlerts(kinds []string) error {
+	if len(kinds) == 0 {
+		return errors.New("incident alerts must not be empty")
+	}
+	for _, k := range kinds {
+		if !slices.Contains(incidentAlerts, k) {
+			return fmt.Errorf("unknown alert kind %q", k)
+		}
+	}
+	return nil
+}
+
+func checkEvents(events []string) error {
+	for _, e := range events {
+		if !slices.Contains(eventKinds, e) {
+			return fmt.Errorf("unknown notification event %q", e)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package cfg
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhook_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Webhook
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			input: `{"url": "https://hooks.example.com/certs"}`,
+			want:  Webhook{URL: "https://hooks.example.com/certs", Retries: 3},
+		},
+		{
+			name:  "signed and filtered",
+			input: `{"url": "https://hooks.example.com/certs", "secret": "s3cret", "events": ["alert.firing", "certificate.rotated"], "retries": 0}`,
+			want: Webhook{
+				URL:    "https://hooks.example.com/certs",
+				Secret: "s3cret",
+				Events: []string{"alert.firing", "certificate.rotated"},
+			},
+		},
+		{name: "invalid - no url", input: `{}`, wantErr: true},
+		{name: "invalid - scheme", input: `{"url": "ftp://hooks.example.com"}`, wantErr: true},
+		{name: "invalid - event", input: `{"url": "https://hooks.example.com", "events": ["alert.fired"]}`, wantErr: true},
+		{
+			name:  "routed by label",
+			input: `{"url": "https://hooks.example.com/payments", "match": {"team": "payments"}}`,
+			want:  Webhook{URL: "https://hooks.example.com/payments", Retries: 3, Match: map[string]string{"team": "payments"}},
+		},
+		{name: "invalid - retries", input: `{"url": "https://hooks.example.com", "retries": -1}`, wantErr: true},
+		{name: "invalid - match label", input: `{"url": "https://hooks.example.com", "match": {"on-call": "payments"}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Webhook
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Webhook.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Webhook.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlack_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Slack
+		wantErr bool
+	}{
+		{name: "incoming webhook", input: `{"webhookUrl": "https://hooks.slack.com/services/T0/B0/x"}`, want: Slack{WebhookURL: "https://hooks.slack.com/services/T0/B0/x"}},
+		{name: "bot token", input: `{"token": "xoxb-1", "channel": "#certs", "template": "{{.Title}}", "events": ["alert.firing"]}`, want: Slack{Token: "xoxb-1", Channel: "#certs", Template: "{{.Title}}", Events: []string{"alert.firing"}}},
+		{name: "invalid - neither", input: `{}`, wantErr: true},
+		{name: "invalid - both", input: `{"webhookUrl": "https://hooks.slack.com/services/T0/B0/x", "token": "xoxb-1", "channel": "#certs"}`, wantErr: true},
+		{name: "invalid - token without channel", input: `{"token": "xoxb-1"}`, wantErr: true},
+		{name: "invalid - webhook scheme", input: `{"webhookUrl": "http://hooks.slack.com/services/T0/B0/x"}`, wantErr: true},
+		{name: "invalid - template", input: `{"webhookUrl": "https://hooks.slack.com/services/T0/B0/x", "template": "{{.Title"}`, wantErr: true},
+		{name: "invalid - event", input: `{"webhookUrl": "https://hooks.slack.com/services/T0/B0/x", "events": ["expiring"]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Slack
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Slack.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Slack.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmail_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Email
+		wantErr bool
+	}{
+		{
+			name:  "default port",
+			input: `{"smtpServer": "smtp.example.com", "from": "cert-tracker@example.com", "to": ["ops@example.com"]}`,
+			want:  Email{SMTPServer: "smtp.example.com:587", From: "cert-tracker@example.com", To: []string{"ops@example.com"}},
+		},
+		{
+			name:  "authenticated",
+			input: `{"smtpServer": "smtp.example.com:465", "username": "ct", "password": "s3cret", "from": "Cert Tracker <ct@example.com>", "to": ["ops@example.com", "sec@example.com"], "subject": "{{.Summary}}"}`,
+			want:  Email{SMTPServer: "smtp.example.com:465", Username: "ct", Password: "s3cret", From: "Cert Tracker <ct@example.com>", To: []string{"ops@example.com", "sec@example.com"}, Subject: "{{.Summary}}"},
+		},
+		{name: "invalid - no server", input: `{"from": "ct@example.com", "to": ["ops@example.com"]}`, wantErr: true},
+		{name: "invalid - from", input: `{"smtpServer": "smtp.example.com", "from": "ct", "to": ["ops@example.com"]}`, wantErr: true},
+		{name: "invalid - no to", input: `{"smtpServer": "smtp.example.com", "from": "ct@example.com"}`, wantErr: true},
+		{name: "invalid - to", input: `{"smtpServer": "smtp.example.com", "from": "ct@example.com", "to": ["ops"]}`, wantErr: true},
+		{name: "invalid - subject", input: `{"smtpServer": "smtp.example.com", "from": "ct@example.com", "to": ["ops@example.com"], "subject": "{{end}}"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Email
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Email.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Email.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPagerDuty_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    PagerDuty
+		wantErr bool
+	}{
+		{name: "defaults", input: `{"routingKey": "R0UT1NG"}`, want: PagerDuty{RoutingKey: "R0UT1NG", Alerts: []string{"expiry", "verify-failed"}}},
+		{name: "alerts", input: `{"routingKey": "R0UT1NG", "alerts": ["expiry", "ocsp-revoked"]}`, want: PagerDuty{RoutingKey: "R0UT1NG", Alerts: []string{"expiry", "ocsp-revoked"}}},
+		{name: "invalid - no routing key", input: `{}`, wantErr: true},
+		{name: "invalid - no alerts", input: `{"routingKey": "R0UT1NG", "alerts": []}`, wantErr: true},
+		{name: "invalid - alert kind", input: `{"routingKey": "R0UT1NG", "alerts": ["expired"]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got PagerDuty
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PagerDuty.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PagerDuty.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpsgenie_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Opsgenie
+		wantErr bool
+	}{
+		{name: "defaults", input: `{"apiKey": "k3y"}`, want: Opsgenie{APIKey: "k3y", Region: "us", Alerts: []string{"expiry", "verify-failed"}}},
+		{name: "eu", input: `{"apiKey": "k3y", "region": "eu", "alerts": ["watchdog"]}`, want: Opsgenie{APIKey: "k3y", Region: "eu", Alerts: []string{"watchdog"}}},
+		{name: "invalid - no api key", input: `{}`, wantErr: true},
+		{name: "invalid - region", input: `{"apiKey": "k3y", "region": "apac"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Opsgenie
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Opsgenie.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Opsgenie.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAlertmanager_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Alertmanager
+		wantErr bool
+	}{
+		{name: "defaults", input: `{"url": "http://alertmanager:9093"}`, want: Alertmanager{URL: "http://alertmanager:9093", Resend: Duration(time.Minute)}},
+		{name: "labels", input: `{"url": "https://alertmanager.example.com", "labels": {"env": "prod", "team_name": "sre"}, "resend": "5m"}`, want: Alertmanager{URL: "https://alertmanager.example.com", Labels: map[string]string{"env": "prod", "team_name": "sre"}, Resend: Duration(5 * time.Minute)}},
+		{name: "invalid - no url", input: `{}`, wantErr: true},
+		{name: "invalid - label name", input: `{"url": "http://alertmanager:9093", "labels": {"team-name": "sre"}}`, wantErr: true},
+		{name: "invalid - resend", input: `{"url": "http://alertmanager:9093", "resend": "0s"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Alertmanager
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Alertmanager.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Alertmanager.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotificationSecretsRedactedWhenMarshaled(t *testing.T) {
+	n := Notifications{
+		Webhooks: []Webhook{{URL: "https://hooks.example.com/certs", Secret: "webhook-s3cret"}},
+		Slack: []Slack{
+			{WebhookURL: "https://hooks.slack.com/services/T0/B0/slack-s3cret"},
+			{Token: "xoxb-s3cret", Channel: "#certs"},
+		},
+		Email:     []Email{{SMTPServer: "smtp.example.com", Username: "tracker", Password: "smtp-s3cret"}},
+		PagerDuty: []PagerDuty{{RoutingKey: "pagerduty-s3cret"}},
+		Opsgenie:  []Opsgenie{{APIKey: "opsgenie-s3cret", Region: "eu"}},
+	}
+	data, err := json.Marshal(Params{Notifications: n})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"webhook-s3cret", "slack-s3cret", "xoxb-s3cret", "smtp-s3cret", "pagerduty-s3cret", "opsgenie-s3cret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Expected %q to be redacted, got %s", secret, data)
+		}
+	}
+}
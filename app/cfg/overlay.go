@@ -0,0 +1,69 @@
+package cfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// profileEnv selects an overlay merged over the base config, e.g.
+// CERTTRACKER_ENV=prod merges config.prod.json over config.json.
+const profileEnv = "CERTTRACKER_ENV"
+
+func overlayPath(configFilePath, profile string) string {
+	ext := filepath.Ext(configFilePath)
+	return strings.TrimSuffix(configFilePath, ext) + "." + profile + ext
+}
+
+func applyOverlay(base []byte, path string) ([]byte, error) {
+	patch, err := readConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("config overlay: %w", err)
+	}
+	merged, err := mergePatch(base, patch)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return merged, nil
+}
+
+// mergePatch applies patch to base using JSON Merge Patch (RFC 7386) rules:
+// objects merge recursively, a null value removes the key, and anything else,
+// including arrays, replaces the base value.
+func mergePatch(base, patch []byte) ([]byte, error) {
+	var b, p any
+	if err := decode(base, &b); err != nil {
+		return nil, err
+	}
+	if err := decode(patch, &p); err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(b, p))
+}
+
+func decode(data []byte, v any) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+func merge(base, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	b, ok := base.(map[string]any)
+	if !ok {
+		b = make(map[string]any)
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(b, k)
+			continue
+		}
+		b[k] = merge(b[k], v)
+	}
+	return b
+}
@@ -0,0 +1,87 @@
+package cfg
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		base  string
+		patch string
+		want  string
+	}{
+		{
+			name:  "replace scalar",
+			base:  `{"timeout": "30s", "logLevel": "INFO"}`,
+			patch: `{"logLevel": "DEBUG"}`,
+			want:  `{"timeout": "30s", "logLevel": "DEBUG"}`,
+		},
+		{
+			name:  "replace array",
+			base:  `{"dnsResolvers": ["9.9.9.9", "1.1.1.1"]}`,
+			patch: `{"dnsResolvers": ["10.0.0.2"]}`,
+			want:  `{"dnsResolvers": ["10.0.0.2"]}`,
+		},
+		{
+			name:  "merge nested object",
+			base:  `{"api": {"listen": ":8080", "tls": {"certFile": "a"}}}`,
+			patch: `{"api": {"tls": {"keyFile": "b"}}}`,
+			want:  `{"api": {"listen": ":8080", "tls": {"certFile": "a", "keyFile": "b"}}}`,
+		},
+		{
+			name:  "null removes key",
+			base:  `{"storeDir": "/var/lib/cert-tracker", "timeout": "30s"}`,
+			patch: `{"storeDir": null}`,
+			want:  `{"timeout": "30s"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergePatch([]byte(tt.base), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("mergePatch() error = %v", err)
+			}
+			var gotValue, wantValue any
+			json.Unmarshal(got, &gotValue)
+			json.Unmarshal([]byte(tt.want), &wantValue)
+			if !reflect.DeepEqual(gotValue, wantValue) {
+				t.Errorf("mergePatch() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFileWithProfile(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.json")
+	os.WriteFile(base, []byte(`{
+		"hostnames": ["example.com", "test.com"],
+		"timeout": "30s",
+		"scanInterval": "30m"
+	}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "config.prod.json"), []byte(`{"scanInterval": "5m"}`), 0o644)
+
+	t.Setenv(profileEnv, "prod")
+	var p Params
+	if err := loadFile(base, &p); err != nil {
+		t.Fatalf("loadFile() error = %v", err)
+	}
+	if p.ScanInterval != Duration(5*time.Minute) {
+		t.Errorf("ScanInterval = %v, want overlay value 5m", time.Duration(p.ScanInterval))
+	}
+	if p.Timeout != Duration(30*time.Second) || len(p.Hostnames) != 2 {
+		t.Errorf("Expected base values to be kept, got %+v", p)
+	}
+
+	t.Setenv(profileEnv, "staging")
+	if err := loadFile(base, &p); err == nil {
+		t.Error("Expected error for missing overlay file")
+	}
+}
@@ -0,0 +1,135 @@
+package cfg
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// envPrefix starts the environment variables that override top-level
+// config fields, e.g. CERTTRACKER_SCAN_INTERVAL=10m sets scanInterval.
+const envPrefix = "CERTTRACKER_"
+
+// flagOverrides holds the override flags given on the command line, by
+// JSON field name.
+var flagOverrides = make(map[string]string)
+
+// RegisterConfigFlag adds --config, which chooses the file Load reads.
+func RegisterConfigFlag(fs *flag.FlagSet) {
+	fs.StringVar(&configFilePath, "config", configFilePath, "read the config from this `file`")
+}
+
+// RegisterOverrideFlags adds a flag for every top-level config field, named
+// like --scan-interval for scanInterval, that Load applies over the config
+// file and environment variables.
+func RegisterOverrideFlags(fs *flag.FlagSet) {
+	for _, f := range overridableFields() {
+		fs.Func(strings.Join(words(f.name), "-"), "override "+f.name+" in the config", func(s string) error {
+			if _, err := overrideValue(f.typ, s); err != nil {
+				return err
+			}
+			flagOverrides[f.name] = s
+			return nil
+		})
+	}
+}
+
+type overridableField struct {
+	name string
+	typ  reflect.Type
+}
+
+func overridableFields() []overridableField {
+	var fields []overridableField
+	t := reflect.TypeFor[Params]()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields = append(fields, overridableField{name, t.Field(i).Type})
+	}
+	return fields
+}
+
+// applyOverrides layers environment variables, then flags, over the config
+// file, so that the fields they set go through the same validation.
+func applyOverrides(data []byte) ([]byte, error) {
+	patch := make(map[string]any)
+	for _, f := range overridableFields() {
+		env := envPrefix + strings.ToUpper(strings.Join(words(f.name), "_"))
+		s, fromEnv := os.LookupEnv(env)
+		source := env
+		if flagged, ok := flagOverrides[f.name]; ok {
+			s, source = flagged, "--"+strings.Join(words(f.name), "-")
+		} else if !fromEnv {
+			continue
+		}
+		v, err := overrideValue(f.typ, s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		patch[f.name] = v
+	}
+	if len(patch) == 0 {
+		return data, nil
+	}
+	encoded, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	return mergePatch(data, encoded)
+}
+
+// overrideValue turns an override into the JSON value of a field of type t.
+// Lists may be comma separated, and blocks are given as JSON objects, which
+// merge into the block in the file.
+func overrideValue(t reflect.Type, s string) (any, error) {
+	switch t.Kind() {
+	case reflect.Slice:
+		if strings.HasPrefix(strings.TrimSpace(s), "[") {
+			var v []any
+			if err := json.Unmarshal([]byte(s), &v); err != nil {
+				return nil, err
+			}
+			return v, nil
+		}
+		var v []any
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				v = append(v, item)
+			}
+		}
+		return v, nil
+	case reflect.Struct, reflect.Map:
+		var v map[string]any
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return nil, fmt.Errorf("want a JSON object: %w", err)
+		}
+		return v, nil
+	case reflect.Bool:
+		return strconv.ParseBool(s)
+	}
+	return s, nil
+}
+
+// words splits a camel case field name, so stepCA becomes step and CA.
+func words(name string) []string {
+	var out []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		lowerBefore := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+		acronymEnds := unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsUpper(runes[i]) && (lowerBefore || acronymEnds) {
+			out = append(out, strings.ToLower(string(runes[start:i])))
+			start = i
+		}
+	}
+	return append(out, strings.ToLower(string(runes[start:])))
+}
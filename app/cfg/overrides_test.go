@@ -0,0 +1,77 @@
+package cfg
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadWithOverrides(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "custom.json")
+	os.WriteFile(path, []byte(`{
+		"hostnames": ["example.com"],
+		"timeout": "30s",
+		"scanInterval": "30m",
+		"api": {"listen": ":8080"}
+	}`), 0o644)
+	t.Cleanup(func() {
+		configFilePath = defaultConfigFile
+		clear(flagOverrides)
+	})
+
+	t.Setenv("CERTTRACKER_SCAN_INTERVAL", "10m")
+	t.Setenv("CERTTRACKER_TIMEOUT", "5s")
+	t.Setenv("CERTTRACKER_HOSTNAMES", "a.example.com, b.example.com")
+	t.Setenv("CERTTRACKER_API", `{"tokens": [{"name": "ops", "token": "t", "scope": "admin"}]}`)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	RegisterConfigFlag(fs)
+	RegisterOverrideFlags(fs)
+	if err := fs.Parse([]string{"--config", path, "--timeout", "15s", "--log-add-source", "true"}); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if p.ScanInterval != Duration(10*time.Minute) {
+		t.Errorf("ScanInterval = %v, want the environment's 10m", time.Duration(p.ScanInterval))
+	}
+	if p.Timeout != Duration(15*time.Second) {
+		t.Errorf("Timeout = %v, want the flag's 15s over the environment's", time.Duration(p.Timeout))
+	}
+	if !slices.Equal(p.Hostnames, []Hostname{"a.example.com", "b.example.com"}) {
+		t.Errorf("Hostnames = %v", p.Hostnames)
+	}
+	if !p.LogAddSource {
+		t.Error("LogAddSource not set by flag")
+	}
+	if p.API.Listen != ":8080" || len(p.API.Tokens) != 1 {
+		t.Errorf("API = %+v, want the override merged into the file's block", p.API)
+	}
+
+	t.Setenv("CERTTRACKER_SCAN_INTERVAL", "soon")
+	if _, err := Load(); err == nil {
+		t.Error("Load() accepted an invalid override")
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := map[string]string{
+		"scanInterval": "scan-interval",
+		"dnsResolvers": "dns-resolvers",
+		"stepCA":       "step-ca",
+		"logAddSource": "log-add-source",
+		"api":          "api",
+	}
+	for name, want := range tests {
+		if got := strings.Join(words(name), "-"); got != want {
+			t.Errorf("words(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package cfg
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Pins are the SPKI pins expected of each hostname: the base64 SHA-256 of
+// a public key, as in HPKP. An endpoint whose chain has no certificate
+// with one of its hostname's pins raises an alert.
+type Pins map[Hostname][]string
+
+func (p *Pins) UnmarshalJSON(data []byte) error {
+	var raw map[Hostname][]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	pins, err := normalizeHostnameKeys(raw, func(a, b []string) []string {
+		for _, pin := range b {
+			if !slices.Contains(a, pin) {
+				a = append(a, pin)
+			}
+		}
+		return a
+	})
+	if err != nil {
+		return fmt.Errorf("pins %w", err)
+	}
+	for target, list := range pins {
+		if len(list) == 0 {
+			return fmt.Errorf("pins %q needs at least one pin", target)
+		}
+		for _, pin := range list {
+			if hash, err := base64.StdEncoding.DecodeString(pin); err != nil || len(hash) != sha256.Size {
+				return fmt.Errorf("pin %q of %q must be a base64 SHA-256 hash", pin, target)
+			}
+		}
+	}
+	*p = pins
+	return nil
+}
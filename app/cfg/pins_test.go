@@ -0,0 +1,42 @@
+package cfg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPins_UnmarshalJSON(t *testing.T) {
+	const (
+		current = "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+		backup  = "YLh1dUR9y6Kja30RrAn7JKnbQG/uEtLMkBgFF2Fuihg="
+	)
+	tests := []struct {
+		name    string
+		input   string
+		want    Pins
+		wantErr bool
+	}{
+		{name: "none", input: `{}`, want: Pins{}},
+		{name: "current and backup", input: `{"www.example.com": ["` + current + `", "` + backup + `"]}`, want: Pins{"www.example.com": {current, backup}}},
+		{name: "normalizes hostnames", input: `{"https://www.example.com:443": ["` + current + `"]}`, want: Pins{"www.example.com": {current}}},
+		{name: "merges spellings", input: `{"www.example.com": ["` + current + `"], "https://WWW.example.com": ["` + current + `"]}`, want: Pins{"www.example.com": {current}}},
+		{name: "invalid - hostname", input: `{"exa mple.com": ["` + current + `"]}`, wantErr: true},
+		{name: "invalid - no pins", input: `{"www.example.com": []}`, wantErr: true},
+		{name: "invalid - not base64", input: `{"www.example.com": ["sha256//` + current + `"]}`, wantErr: true},
+		{name: "invalid - not SHA-256", input: `{"www.example.com": ["AAAA"]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Pins
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Pins.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Pins.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// PrivateCA marks Hostnames, which may be wildcards, as expected to serve
+// certificates from a private CA or self-signed ones. Their chains then
+// verify against the PEM roots in RootFile, or without it against the last
+// certificate they serve, instead of failing for lack of a public root.
+type PrivateCA struct {
+	Hostnames []Hostname `json:"hostnames"`
+	RootFile  string     `json:"rootFile"`
+}
+
+func (p *PrivateCA) UnmarshalJSON(data []byte) error {
+	type plain PrivateCA
+	var v plain
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if len(v.Hostnames) == 0 {
+		return errors.New("privateCA needs hostnames")
+	}
+	*p = PrivateCA(v)
+	return nil
+}
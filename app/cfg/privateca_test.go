@@ -0,0 +1,33 @@
+package cfg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPrivateCA_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    PrivateCA
+		wantErr bool
+	}{
+		{name: "hostnames", input: `{"hostnames": ["*.corp.example.com"]}`, want: PrivateCA{Hostnames: []Hostname{"*.corp.example.com"}}},
+		{name: "root file", input: `{"hostnames": ["printer.example.com"], "rootFile": "/etc/ssl/corp-root.pem"}`, want: PrivateCA{Hostnames: []Hostname{"printer.example.com"}, RootFile: "/etc/ssl/corp-root.pem"}},
+		{name: "invalid - no hostnames", input: `{"rootFile": "/etc/ssl/corp-root.pem"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got PrivateCA
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PrivateCA.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PrivateCA.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
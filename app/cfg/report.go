@@ -0,0 +1,33 @@
+package cfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+// Report is a summary of every scan cycle, rewritten at Path afterwards.
+// Format is "json" or "csv", and defaults to Path's extension.
+type Report struct {
+	Path   string `json:"path"`
+	Format string `json:"format"`
+}
+
+func (r *Report) UnmarshalJSON(data []byte) error {
+	type plain Report
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.Format == "" && p.Path != "" {
+		p.Format = "json"
+		if filepath.Ext(p.Path) == ".csv" {
+			p.Format = "csv"
+		}
+	}
+	if p.Format != "" && p.Format != "json" && p.Format != "csv" {
+		return fmt.Errorf("report format %q must be json or csv", p.Format)
+	}
+	*r = Report(p)
+	return nil
+}
@@ -0,0 +1,34 @@
+package cfg
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestReport_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Report
+		wantErr bool
+	}{
+		{name: "disabled", input: `{}`, want: Report{}},
+		{name: "json by default", input: `{"path": "/var/lib/cert-tracker/report"}`, want: Report{Path: "/var/lib/cert-tracker/report", Format: "json"}},
+		{name: "csv from extension", input: `{"path": "/tmp/report.csv"}`, want: Report{Path: "/tmp/report.csv", Format: "csv"}},
+		{name: "explicit format", input: `{"path": "/tmp/report.txt", "format": "csv"}`, want: Report{Path: "/tmp/report.txt", Format: "csv"}},
+		{name: "invalid - format", input: `{"path": "/tmp/report.xml", "format": "xml"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Report
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Report.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Report.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
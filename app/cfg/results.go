@@ -0,0 +1,113 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"text/template"
+)
+
+var (
+	resultSinks     = []string{"log", "file", "http", "stdout", "kafka", "nats"}
+	resultEncodings = []string{"json", "avro"}
+)
+
+// Results are where the result of scanning each target goes: "log" logs
+// it, "file" appends it as a JSON line to File, "http" POSTs it as JSON to
+// URL and "stdout" prints it as a JSON line. "kafka" and "nats" publish it
+// to a message queue. Only "log" is on by default.
+type Results struct {
+	Sinks []string `json:"sinks"`
+	File  string   `json:"file"`
+	URL   string   `json:"url"`
+	Kafka Kafka    `json:"kafka"`
+	NATS  NATS     `json:"nats"`
+}
+
+// Kafka publishes each result to Brokers, keyed by hostname. Topic is a
+// Go text/template over the result, e.g. "certs.{{.Hostname}}", and
+// Encoding is "json" or "avro". Username and Password authenticate with
+// SASL PLAIN, which needs TLS.
+type Kafka struct {
+	Brokers  []string `json:"brokers"`
+	Topic    string   `json:"topic"`
+	Encoding string   `json:"encoding"`
+	TLS      bool     `json:"tls"`
+	Username string   `json:"username"`
+	Password Secret   `json:"password"`
+}
+
+// NATS publishes each result to the server at URL, on Subject, a Go
+// text/template over the result like Kafka's Topic.
+type NATS struct {
+	URL      string `json:"url"`
+	Subject  string `json:"subject"`
+	Encoding string `json:"encoding"`
+}
+
+func defaultResults() Results {
+	return Results{
+		Sinks: []string{"log"},
+		Kafka: Kafka{Topic: "cert-tracker.results", Encoding: "json"},
+		NATS:  NATS{Subject: "cert-tracker.results", Encoding: "json"},
+	}
+}
+
+func (r *Results) UnmarshalJSON(data []byte) error {
+	type plain Results
+	p := plain(defaultResults())
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	for _, sink := range p.Sinks {
+		if !slices.Contains(resultSinks, sink) {
+			return fmt.Errorf("results sink %q must be one of %v", sink, resultSinks)
+		}
+	}
+	if slices.Contains(p.Sinks, "file") && p.File == "" {
+		return errors.New("results file sink needs a file")
+	}
+	if slices.Contains(p.Sinks, "http") {
+		u, err := url.Parse(p.URL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("results url %q must be an http or https URL", p.URL)
+		}
+	}
+	if slices.Contains(p.Sinks, "kafka") {
+		if len(p.Kafka.Brokers) == 0 {
+			return errors.New("results kafka sink needs at least one broker")
+		}
+		if p.Kafka.Username != "" && !p.Kafka.TLS {
+			return errors.New("results kafka username needs tls")
+		}
+		if err := checkQueue("kafka topic", p.Kafka.Topic, p.Kafka.Encoding); err != nil {
+			return err
+		}
+	}
+	if slices.Contains(p.Sinks, "nats") {
+		if p.NATS.URL == "" {
+			return errors.New("results nats sink needs a url")
+		}
+		if err := checkQueue("nats subject", p.NATS.Subject, p.NATS.Encoding); err != nil {
+			return err
+		}
+	}
+	*r = Results(p)
+	return nil
+}
+
+// checkQueue checks a message queue's topic template and encoding.
+func checkQueue(what, topic, encoding string) error {
+	if topic == "" {
+		return fmt.Errorf("results %s must not be empty", what)
+	}
+	if _, err := template.New(what).Parse(topic); err != nil {
+		return fmt.Errorf("results %s: %w", what, err)
+	}
+	if !slices.Contains(resultEncodings, encoding) {
+		return fmt.Errorf("results encoding %q must be one of %v", encoding, resultEncodings)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package cfg
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestResults_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Results
+		wantErr bool
+	}{
+		{name: "defaults", input: `{}`, want: Results{Sinks: []string{"log"}}},
+		{name: "none", input: `{"sinks": []}`, want: Results{Sinks: []string{}}},
+		{name: "every sink", input: `{"sinks": ["log", "file", "http", "stdout"], "file": "/var/lib/cert-tracker/results.jsonl", "url": "https://collector.example.com/scans"}`, want: Results{Sinks: []string{"log", "file", "http", "stdout"}, File: "/var/lib/cert-tracker/results.jsonl", URL: "https://collector.example.com/scans"}},
+		{name: "kafka", input: `{"sinks": ["kafka"], "kafka": {"brokers": ["kafka-1:9093"], "topic": "certs.{{.Hostname}}", "encoding": "avro", "tls": true, "username": "tracker", "password": "hunter2"}}`, want: Results{Sinks: []string{"kafka"}, Kafka: Kafka{Brokers: []string{"kafka-1:9093"}, Topic: "certs.{{.Hostname}}", Encoding: "avro", TLS: true, Username: "tracker", Password: "hunter2"}}},
+		{name: "nats defaults", input: `{"sinks": ["nats"], "nats": {"url": "nats://nats.internal:4222"}}`, want: Results{Sinks: []string{"nats"}, NATS: NATS{URL: "nats://nats.internal:4222", Subject: "cert-tracker.results", Encoding: "json"}}},
+		{name: "invalid - unknown sink", input: `{"sinks": ["syslog"]}`, wantErr: true},
+		{name: "invalid - file sink without file", input: `{"sinks": ["file"]}`, wantErr: true},
+		{name: "invalid - http sink url", input: `{"sinks": ["http"], "url": "collector.example.com"}`, wantErr: true},
+		{name: "invalid - kafka sink without brokers", input: `{"sinks": ["kafka"]}`, wantErr: true},
+		{name: "invalid - kafka username without tls", input: `{"sinks": ["kafka"], "kafka": {"brokers": ["kafka-1:9092"], "username": "tracker"}}`, wantErr: true},
+		{name: "invalid - kafka topic template", input: `{"sinks": ["kafka"], "kafka": {"brokers": ["kafka-1:9092"], "topic": "certs.{{.Hostname"}}`, wantErr: true},
+		{name: "invalid - nats sink without url", input: `{"sinks": ["nats"]}`, wantErr: true},
+		{name: "invalid - nats encoding", input: `{"sinks": ["nats"], "nats": {"url": "nats://nats.internal:4222", "encoding": "protobuf"}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Results
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Results.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (!slices.Equal(got.Sinks, tt.want.Sinks) || got.File != tt.want.File || got.URL != tt.want.URL ||
+				(tt.want.Kafka.Brokers != nil && (got.Kafka.Topic != tt.want.Kafka.Topic || got.Kafka.Encoding != tt.want.Kafka.Encoding || got.Kafka.Password != tt.want.Kafka.Password)) ||
+				!slices.Equal(got.Kafka.Brokers, tt.want.Kafka.Brokers) ||
+				(tt.want.NATS.URL != "" && got.NATS != tt.want.NATS)) {
+				t.Errorf("Results.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
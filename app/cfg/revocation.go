@@ -0,0 +1,80 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// OCSPResponders monitors the OCSP responders named by scanned certificates.
+// A responder is reported once AlertAfter probes in a row failed or took
+// longer than SlowAfter.
+type OCSPResponders struct {
+	Enabled    bool     `json:"enabled"`
+	SlowAfter  Duration `json:"slowAfter"`
+	AlertAfter int      `json:"alertAfter"`
+}
+
+func (o *OCSPResponders) UnmarshalJSON(data []byte) error {
+	type plain OCSPResponders
+	p := plain{SlowAfter: Duration(2 * time.Second), AlertAfter: 3}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.SlowAfter <= 0 {
+		return errors.New("ocspResponders slowAfter must be positive")
+	}
+	if p.AlertAfter < 1 {
+		return errors.New("ocspResponders alertAfter must be at least 1")
+	}
+	*o = OCSPResponders(p)
+	return nil
+}
+
+// OCSPStatus checks whether each scanned leaf certificate has been revoked,
+// using the OCSP response stapled to the handshake or else asking the
+// certificate's responder at most once per Interval.
+type OCSPStatus struct {
+	Enabled  bool     `json:"enabled"`
+	Interval Duration `json:"interval"`
+}
+
+func (o *OCSPStatus) UnmarshalJSON(data []byte) error {
+	type plain OCSPStatus
+	p := plain{Interval: Duration(time.Hour)}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.Interval <= 0 {
+		return errors.New("ocspStatus interval must be positive")
+	}
+	*o = OCSPStatus(p)
+	return nil
+}
+
+// CRLs monitors the CRL distribution points named by scanned certificates,
+// fetching each at most once per Interval. A CRL is reported once AlertAfter
+// fetches in a row failed, when its nextUpdate has passed or when it grows
+// beyond MaxSize bytes.
+type CRLs struct {
+	Enabled    bool     `json:"enabled"`
+	Interval   Duration `json:"interval"`
+	AlertAfter int      `json:"alertAfter"`
+	MaxSize    int64    `json:"maxSize"`
+}
+
+func (c *CRLs) UnmarshalJSON(data []byte) error {
+	type plain CRLs
+	p := plain{Interval: Duration(time.Hour), AlertAfter: 3, MaxSize: 10 << 20}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.AlertAfter < 1 {
+		return errors.New("crls alertAfter must be at least 1")
+	}
+	if p.MaxSize < 0 {
+		return errors.New("crls maxSize must not be negative")
+	}
+	*c = CRLs(p)
+	return nil
+}
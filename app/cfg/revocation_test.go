@@ -0,0 +1,114 @@
+package cfg
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestOCSPResponders_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    OCSPResponders
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			input: `{"enabled": true}`,
+			want:  OCSPResponders{Enabled: true, SlowAfter: Duration(2 * time.Second), AlertAfter: 3},
+		},
+		{
+			name:  "custom thresholds",
+			input: `{"enabled": true, "slowAfter": "500ms", "alertAfter": 1}`,
+			want:  OCSPResponders{Enabled: true, SlowAfter: Duration(500 * time.Millisecond), AlertAfter: 1},
+		},
+		{
+			name:    "invalid - zero alertAfter",
+			input:   `{"enabled": true, "alertAfter": 0}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid - zero slowAfter",
+			input:   `{"enabled": true, "slowAfter": "0s"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got OCSPResponders
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OCSPResponders.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("OCSPResponders.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOCSPStatus_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    OCSPStatus
+		wantErr bool
+	}{
+		{name: "defaults", input: `{"enabled": true}`, want: OCSPStatus{Enabled: true, Interval: Duration(time.Hour)}},
+		{name: "custom interval", input: `{"enabled": true, "interval": "6h"}`, want: OCSPStatus{Enabled: true, Interval: Duration(6 * time.Hour)}},
+		{name: "invalid - zero interval", input: `{"enabled": true, "interval": "0s"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got OCSPStatus
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OCSPStatus.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("OCSPStatus.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCRLs_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    CRLs
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			input: `{"enabled": true}`,
+			want:  CRLs{Enabled: true, Interval: Duration(time.Hour), AlertAfter: 3, MaxSize: 10 << 20},
+		},
+		{
+			name:  "no size limit",
+			input: `{"enabled": true, "interval": "1d", "maxSize": 0}`,
+			want:  CRLs{Enabled: true, Interval: Duration(24 * time.Hour), AlertAfter: 3},
+		},
+		{
+			name:    "invalid - negative maxSize",
+			input:   `{"enabled": true, "maxSize": -1}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got CRLs
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CRLs.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("CRLs.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
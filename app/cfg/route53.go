@@ -0,0 +1,37 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Route53 discovers hostnames from the records of Route 53 hosted zones,
+// listing them again every Refresh. Include and Exclude take patterns such
+// as *.example.com. Discovered hostnames are only proposed, by the discover
+// command, unless AddTargets is set.
+type Route53 struct {
+	Enabled    bool     `json:"enabled"`
+	Zones      []string `json:"zones"`
+	Types      []string `json:"types"`
+	Include    []string `json:"include"`
+	Exclude    []string `json:"exclude"`
+	AddTargets bool     `json:"addTargets"`
+	Refresh    Duration `json:"refresh"`
+}
+
+func (r *Route53) UnmarshalJSON(data []byte) error {
+	type plain Route53
+	p := plain{Types: []string{"A", "AAAA", "CNAME"}, Refresh: Duration(time.Hour)}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.Enabled && len(p.Zones) == 0 {
+		return errors.New("route53 zones must not be empty")
+	}
+	if p.Refresh <= 0 {
+		return errors.New("route53 refresh must be positive")
+	}
+	*r = Route53(p)
+	return nil
+}
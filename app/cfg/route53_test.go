@@ -0,0 +1,46 @@
+package cfg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRoute53_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Route53
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			input: `{"enabled": true, "zones": ["Z123"]}`,
+			want:  Route53{Enabled: true, Zones: []string{"Z123"}, Types: []string{"A", "AAAA", "CNAME"}, Refresh: Duration(time.Hour)},
+		},
+		{
+			name:  "filtered",
+			input: `{"enabled": true, "zones": ["Z123"], "types": ["A"], "include": ["*.example.com"], "addTargets": true, "refresh": "15m"}`,
+			want: Route53{
+				Enabled: true, Zones: []string{"Z123"}, Types: []string{"A"},
+				Include: []string{"*.example.com"}, AddTargets: true, Refresh: Duration(15 * time.Minute),
+			},
+		},
+		{name: "invalid - no zones", input: `{"enabled": true}`, wantErr: true},
+		{name: "invalid - zero refresh", input: `{"enabled": true, "zones": ["Z123"], "refresh": "0"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Route53
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Route53.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Route53.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
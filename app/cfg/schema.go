@@ -0,0 +1,99 @@
+package cfg
+
+import (
+	"log/slog"
+	"maps"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// schemaTypes describes the types whose JSON form differs from their Go
+// kind.
+var schemaTypes = map[reflect.Type]map[string]any{
+	reflect.TypeFor[Duration](): {
+		"type":        "string",
+		"pattern":     `^(0|([0-9]+d)?([0-9.]+(ns|us|µs|ms|s|m|h))*)$`,
+		"description": "a Go duration with an optional leading day count, e.g. 90s, 30d or 1d12h",
+	},
+	reflect.TypeFor[time.Time](): {
+		"type":        "string",
+		"description": "an RFC 3339 time or a date, e.g. 2025-08-01T22:00:00Z or 2025-08-01",
+	},
+	reflect.TypeFor[Hostname](): {
+		"type":        "string",
+		"description": "a hostname with an optional port, e.g. example.com:8443, or a URL with a TLS scheme, e.g. https://example.com/login",
+	},
+	reflect.TypeFor[net.IP](): {
+		"type":  "string",
+		"anyOf": []any{map[string]any{"format": "ipv4"}, map[string]any{"format": "ipv6"}},
+	},
+	reflect.TypeFor[slog.Level](): {
+		"type": "string",
+		"enum": []any{"debug", "info", "warn", "error", "DEBUG", "INFO", "WARN", "ERROR"},
+	},
+	reflect.TypeFor[AddressFamily](): {
+		"type": "string",
+		"enum": []any{"ipv4", "ipv6", "both"},
+	},
+	reflect.TypeFor[LogFormat](): {
+		"type": "string",
+		"enum": []any{"json", "text"},
+	},
+	reflect.TypeFor[Scope](): {
+		"type": "string",
+		"enum": []any{string(ScopeRead), string(ScopeAdmin)},
+	},
+}
+
+// Schema describes Params as a JSON Schema, for editors and CI to validate
+// config files against.
+func Schema() map[string]any {
+	s := schemaOf(reflect.TypeFor[Params]())
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["title"] = "cert-tracker config"
+	// lets config files point editors at the schema
+	s["properties"].(map[string]any)["$schema"] = map[string]any{"type": "string"}
+	return s
+}
+
+func schemaOf(t reflect.Type) map[string]any {
+	if s, ok := schemaTypes[t]; ok {
+		return maps.Clone(s)
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := make(map[string]any)
+		for i := range t.NumField() {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = schemaOf(field.Type)
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	return map[string]any{}
+}
@@ -0,0 +1,63 @@
+package cfg
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	schema := Schema()
+	if _, err := json.Marshal(schema); err != nil {
+		t.Fatalf("json.Marshal(Schema()) error = %v", err)
+	}
+	properties := schema["properties"].(map[string]any)
+
+	tests := []struct {
+		name string
+		path []string
+		key  string
+		want any
+	}{
+		{name: "durations are strings", path: []string{"scanInterval"}, key: "type", want: "string"},
+		{name: "hostnames are strings", path: []string{"hostnames", "items"}, key: "type", want: "string"},
+		{name: "nested blocks", path: []string{"retention", "properties", "observations"}, key: "type", want: "string"},
+		{name: "maps", path: []string{"caa", "properties", "identities", "additionalProperties"}, key: "type", want: "array"},
+		{name: "integers", path: []string{"crls", "properties", "maxSize"}, key: "type", want: "integer"},
+		{name: "unknown keys rejected", path: []string{"api"}, key: "additionalProperties", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := properties
+			for _, p := range tt.path {
+				next, ok := node[p].(map[string]any)
+				if !ok {
+					t.Fatalf("Schema() has no %v", tt.path)
+				}
+				node = next
+			}
+			if node[tt.key] != tt.want {
+				t.Errorf("Schema() %v %s = %v, want %v", tt.path, tt.key, node[tt.key], tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaDurationPattern(t *testing.T) {
+	pattern := regexp.MustCompile(schemaTypes[reflect.TypeFor[Duration]()]["pattern"].(string))
+	for _, s := range []string{"0", "90s", "1h30m", "30d", "1d12h", "1.5h"} {
+		if _, err := ParseDuration(s); err != nil {
+			t.Fatalf("ParseDuration(%q) error = %v", s, err)
+		}
+		if !pattern.MatchString(s) {
+			t.Errorf("pattern doesn't match valid duration %q", s)
+		}
+	}
+	for _, s := range []string{"soon", "30", "d"} {
+		if pattern.MatchString(s) {
+			t.Errorf("pattern matches invalid duration %q", s)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package cfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SNI controls the server name sent in handshakes. ServerNames sends a
+// different name for some targets, e.g. the vhost behind a load balancer's
+// own hostname. ProbeDefault also connects to every endpoint without SNI, to
+// capture the certificate the server falls back to.
+type SNI struct {
+	ServerNames  map[Hostname]string `json:"serverNames"`
+	ProbeDefault bool                `json:"probeDefault"`
+}
+
+func (s *SNI) UnmarshalJSON(data []byte) error {
+	type plain SNI
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	serverNames, err := normalizeHostnameKeys(p.ServerNames, nil)
+	if err != nil {
+		return fmt.Errorf("sni serverNames target %w", err)
+	}
+	for target, name := range serverNames {
+		if !isDomain(name) {
+			return fmt.Errorf("sni server name %q for %q must be a hostname without a port", name, target)
+		}
+	}
+	if len(serverNames) > 0 {
+		p.ServerNames = serverNames
+	}
+	*s = SNI(p)
+	return nil
+}
+
+// isDomain reports whether s is a bare hostname: no scheme, no port, no
+// wildcard.
+func isDomain(s string) bool {
+	_, err := ParseHostname(s)
+	return err == nil && !strings.ContainsAny(s, ":/*")
+}
+
+// ServerName returns the name to send when connecting to h.
+func (s SNI) ServerName(h Hostname) string {
+	if name, ok := s.ServerNames[h]; ok {
+		return name
+	}
+	return h.Host()
+}
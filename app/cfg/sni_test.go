@@ -0,0 +1,54 @@
+package cfg
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSNI_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    SNI
+		wantErr bool
+	}{
+		{name: "disabled", input: `{}`, want: SNI{}},
+		{
+			name:  "overrides",
+			input: `{"serverNames": {"https://lb.example.com:443": "www.example.com", "lb.example.com:8443": "api.example.com"}, "probeDefault": true}`,
+			want: SNI{
+				ServerNames:  map[Hostname]string{"lb.example.com": "www.example.com", "lb.example.com:8443": "api.example.com"},
+				ProbeDefault: true,
+			},
+		},
+		{name: "invalid - target", input: `{"serverNames": {"http://lb.example.com": "www.example.com"}}`, wantErr: true},
+		{name: "invalid - target listed twice", input: `{"serverNames": {"LB.example.com": "www.example.com", "lb.example.com:443": "api.example.com"}}`, wantErr: true},
+		{name: "invalid - server name with port", input: `{"serverNames": {"lb.example.com": "www.example.com:443"}}`, wantErr: true},
+		{name: "invalid - server name with other port", input: `{"serverNames": {"lb.example.com": "www.example.com:8443"}}`, wantErr: true},
+		{name: "invalid - server name IP", input: `{"serverNames": {"lb.example.com": "192.0.2.1"}}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got SNI
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SNI.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SNI.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSNI_ServerName(t *testing.T) {
+	s := SNI{ServerNames: map[Hostname]string{"lb.example.com": "www.example.com"}}
+	if got := s.ServerName("lb.example.com"); got != "www.example.com" {
+		t.Errorf("ServerName() = %q, want the override", got)
+	}
+	if got := s.ServerName("smtp://mail.example.com:587"); got != "mail.example.com" {
+		t.Errorf("ServerName() = %q, want the host", got)
+	}
+}
@@ -0,0 +1,9 @@
+package cfg
+
+// SplitHorizon resolves every target through each of the DNS resolvers
+// instead of the healthiest one, scanning the addresses any of them return
+// and noting which resolvers returned each, so internal and public views of
+// split-horizon or GeoDNS names can be compared.
+type SplitHorizon struct {
+	Enabled bool `json:"enabled"`
+}
@@ -0,0 +1,32 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Spread paces each cycle's handshakes evenly across Window, half the scan
+// interval when unset, instead of running them back to back, delaying each
+// target by up to Jitter more.
+type Spread struct {
+	Enabled bool     `json:"enabled"`
+	Window  Duration `json:"window"`
+	Jitter  Duration `json:"jitter"`
+}
+
+func (s *Spread) UnmarshalJSON(data []byte) error {
+	type plain Spread
+	p := plain{Jitter: Duration(5 * time.Second)}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.Window < 0 {
+		return errors.New("spread window must not be negative")
+	}
+	if p.Jitter < 0 {
+		return errors.New("spread jitter must not be negative")
+	}
+	*s = Spread(p)
+	return nil
+}
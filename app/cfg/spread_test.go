@@ -0,0 +1,34 @@
+package cfg
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSpread_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Spread
+		wantErr bool
+	}{
+		{name: "defaults", input: `{"enabled": true}`, want: Spread{Enabled: true, Jitter: Duration(5 * time.Second)}},
+		{name: "window", input: `{"enabled": true, "window": "10m", "jitter": "0s"}`, want: Spread{Enabled: true, Window: Duration(10 * time.Minute)}},
+		{name: "invalid - negative window", input: `{"window": "-1m"}`, wantErr: true},
+		{name: "invalid - negative jitter", input: `{"jitter": "-1s"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Spread
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Spread.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Spread.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package cfg
+
+// StateCache saves the last cycle's results, the certificates seen and the
+// firing alerts to Path after every cycle, so a restarted tracker notices
+// rotations in its first cycle and doesn't notify about alerts again. It
+// matters most without a storeDir, which keeps results across restarts too.
+type StateCache struct {
+	Path string `json:"path"`
+}
@@ -0,0 +1,30 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// StepCA correlates scanned endpoints with a step-ca instance at URL, whose
+// root certificates are in the PEM file RootFile.
+type StepCA struct {
+	Enabled  bool   `json:"enabled"`
+	URL      string `json:"url"`
+	RootFile string `json:"rootFile"`
+}
+
+func (s *StepCA) UnmarshalJSON(data []byte) error {
+	type plain StepCA
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.Enabled && p.URL == "" {
+		return errors.New("stepCA url is required")
+	}
+	if p.Enabled && p.RootFile == "" {
+		return errors.New("stepCA rootFile is required")
+	}
+	*s = StepCA(p)
+	return nil
+}
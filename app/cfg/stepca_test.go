@@ -0,0 +1,37 @@
+package cfg
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStepCA_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    StepCA
+		wantErr bool
+	}{
+		{name: "disabled", input: `{}`, want: StepCA{}},
+		{
+			name:  "enabled",
+			input: `{"enabled": true, "url": "https://ca.internal:9000", "rootFile": "/etc/step/root_ca.crt"}`,
+			want:  StepCA{Enabled: true, URL: "https://ca.internal:9000", RootFile: "/etc/step/root_ca.crt"},
+		},
+		{name: "invalid - no url", input: `{"enabled": true, "rootFile": "/etc/step/root_ca.crt"}`, wantErr: true},
+		{name: "invalid - no root", input: `{"enabled": true, "url": "https://ca.internal:9000"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got StepCA
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StepCA.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("StepCA.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Summary is the line logged after every scan cycle, listing the Soonest
+// leaf certificates to expire.
+type Summary struct {
+	Soonest int `json:"soonest"`
+}
+
+func defaultSummary() Summary {
+	return Summary{Soonest: 5}
+}
+
+func (s *Summary) UnmarshalJSON(data []byte) error {
+	type plain Summary
+	p := plain(defaultSummary())
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.Soonest <= 0 {
+		return errors.New("summary soonest must be positive")
+	}
+	*s = Summary(p)
+	return nil
+}
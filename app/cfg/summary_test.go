@@ -0,0 +1,32 @@
+package cfg
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSummary_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Summary
+		wantErr bool
+	}{
+		{name: "defaults", input: `{}`, want: Summary{Soonest: 5}},
+		{name: "soonest", input: `{"soonest": 20}`, want: Summary{Soonest: 20}},
+		{name: "invalid - zero", input: `{"soonest": 0}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Summary
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Summary.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Summary.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
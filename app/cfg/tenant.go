@@ -0,0 +1,34 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Tenant is an isolated set of targets sharing one deployment. Its
+// Notifications only hear about the tenant's alerts and rotations, while
+// the global ones hear about every tenant's. Escalation, when set, replaces
+// the expiry escalation for the tenant's certificates.
+type Tenant struct {
+	Name          string           `json:"name"`
+	Hostnames     []Hostname       `json:"hostnames"`
+	Notifications Notifications    `json:"notifications"`
+	Escalation    []EscalationStep `json:"escalation"`
+}
+
+func (t *Tenant) UnmarshalJSON(data []byte) error {
+	type plain Tenant
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.Name == "" {
+		return errors.New("tenant needs a name")
+	}
+	if err := sortEscalation(p.Escalation); err != nil {
+		return fmt.Errorf("tenant %q: %w", p.Name, err)
+	}
+	*t = Tenant(p)
+	return nil
+}
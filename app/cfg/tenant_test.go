@@ -0,0 +1,90 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFileValidatesTenants(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{
+			name: "tenant scoped token",
+			input: `{
+				"tenants": [{"name": "payments", "hostnames": ["pay.example.com"]}],
+				"api": {"tokens": [{"name": "ci", "token": "t", "tenant": "payments"}]}
+			}`,
+		},
+		{
+			name: "tenant notifications and escalation",
+			input: `{"tenants": [{
+				"name": "payments",
+				"notifications": {"pagerDuty": [{"routingKey": "R0UT1NGKEY"}]},
+				"escalation": [{"within": "7d", "severity": "critical", "route": "page"}]
+			}]}`,
+		},
+		{
+			name: "OIDC users mapped to tenants",
+			input: `{
+				"tenants": [{"name": "payments"}],
+				"api": {"oidc": {"issuer": "https://idp.example.com", "clientID": "tracker", "redirectURL": "https://tracker.example.com/auth/callback",
+					"tenantGroups": {"payments-team": "payments", "sre": "*"}}}
+			}`,
+		},
+		{
+			name:    "invalid - tenant without name",
+			input:   `{"tenants": [{"hostnames": ["pay.example.com"]}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid - duplicate tenant",
+			input:   `{"tenants": [{"name": "payments"}, {"name": "payments"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid - token for unknown tenant",
+			input:   `{"api": {"tokens": [{"name": "ci", "token": "t", "tenant": "payments"}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid - tenant escalation severity",
+			input:   `{"tenants": [{"name": "payments", "escalation": [{"within": "7d", "severity": "page"}]}]}`,
+			wantErr: true,
+		},
+		{
+			name: "invalid - OIDC without tenant groups",
+			input: `{
+				"tenants": [{"name": "payments"}],
+				"api": {"oidc": {"issuer": "https://idp.example.com", "clientID": "tracker", "redirectURL": "https://tracker.example.com/auth/callback"}}
+			}`,
+			wantErr: true,
+		},
+		{
+			name: "invalid - OIDC group for unknown tenant",
+			input: `{
+				"tenants": [{"name": "payments"}],
+				"api": {"oidc": {"issuer": "https://idp.example.com", "clientID": "tracker", "redirectURL": "https://tracker.example.com/auth/callback",
+					"tenantGroups": {"web-team": "web"}}}
+			}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.input), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			var p Params
+			err := loadFile(path, &p)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
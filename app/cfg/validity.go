@@ -0,0 +1,46 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ValidityPolicy sets rules for every matching leaf certificate: keep at
+// least MinRemaining of validity, come from an issuer whose name contains
+// one of AllowedIssuers, name every one of RequiredSANs, have a key of at
+// least MinKeySize bits, and be valid for no longer than MaxValidity. Rules
+// left unset don't apply, but a policy needs at least one.
+//
+// Tenant, Tags and Hostnames narrow which targets it covers; Tags match
+// imported inventory columns. With none set it covers every target.
+type ValidityPolicy struct {
+	Name           string            `json:"name"`
+	MinRemaining   Duration          `json:"minRemaining"`
+	AllowedIssuers []string          `json:"allowedIssuers"`
+	RequiredSANs   []string          `json:"requiredSANs"`
+	MinKeySize     int               `json:"minKeySize"`
+	MaxValidity    Duration          `json:"maxValidity"`
+	Tenant         string            `json:"tenant"`
+	Tags           map[string]string `json:"tags"`
+	Hostnames      []Hostname        `json:"hostnames"`
+}
+
+func (v *ValidityPolicy) UnmarshalJSON(data []byte) error {
+	type plain ValidityPolicy
+	var p plain
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if p.Name == "" {
+		return errors.New("validity policy needs a name")
+	}
+	if p.MinRemaining < 0 || p.MinKeySize < 0 || p.MaxValidity < 0 {
+		return fmt.Errorf("validity policy %q rules must not be negative", p.Name)
+	}
+	if p.MinRemaining == 0 && len(p.AllowedIssuers) == 0 && len(p.RequiredSANs) == 0 && p.MinKeySize == 0 && p.MaxValidity == 0 {
+		return fmt.Errorf("validity policy %q sets no rules", p.Name)
+	}
+	*v = ValidityPolicy(p)
+	return nil
+}
@@ -0,0 +1,38 @@
+package cfg
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestValidityPolicy_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Duration
+		wantErr bool
+	}{
+		{name: "tagged policy", input: `{"name": "prod", "minRemaining": "21d", "tags": {"env": "prod"}}`, want: Duration(21 * 24 * time.Hour)},
+		{name: "invalid - no name", input: `{"minRemaining": "21d"}`, wantErr: true},
+		{
+			name:  "issuer and key rules",
+			input: `{"name": "public", "allowedIssuers": ["Let's Encrypt"], "requiredSANs": ["example.com"], "minKeySize": 2048, "maxValidity": "90d"}`,
+		},
+		{name: "invalid - no minimum", input: `{"name": "prod"}`, wantErr: true},
+		{name: "invalid - negative key size", input: `{"name": "prod", "minKeySize": -1}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ValidityPolicy
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidityPolicy.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.MinRemaining != tt.want {
+				t.Errorf("MinRemaining = %v, want %v", got.MinRemaining, tt.want)
+			}
+		})
+	}
+}
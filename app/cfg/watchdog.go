@@ -0,0 +1,9 @@
+package cfg
+
+// Watchdog alerts when no scan cycle completes within twice the scan
+// interval. With Exit set the process also exits, so its orchestrator
+// restarts it.
+type Watchdog struct {
+	Enabled bool `json:"enabled"`
+	Exit    bool `json:"exit"`
+}
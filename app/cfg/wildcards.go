@@ -0,0 +1,61 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"time"
+)
+
+// Wildcards finds the subdomains of wildcard targets such as *.example.com
+// and scans them, looking again every Refresh. Sources are "ct", searching
+// CT logs through CTEndpoint, "route53" and "cloudflare", listing the zones
+// configured for them, and "axfr", transferring each wildcard's zone from
+// ZoneTransferServer.
+type Wildcards struct {
+	Sources            []string `json:"sources"`
+	Refresh            Duration `json:"refresh"`
+	CTEndpoint         string   `json:"ctEndpoint"`
+	ZoneTransferServer string   `json:"zoneTransferServer"`
+}
+
+var wildcardSources = []string{"ct", "route53", "cloudflare", "axfr"}
+
+func defaultWildcards() Wildcards {
+	return Wildcards{
+		Sources:    []string{"ct"},
+		Refresh:    Duration(24 * time.Hour),
+		CTEndpoint: "https://crt.sh",
+	}
+}
+
+func (w *Wildcards) UnmarshalJSON(data []byte) error {
+	type plain Wildcards
+	p := plain(defaultWildcards())
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	if len(p.Sources) == 0 {
+		return errors.New("wildcards sources must not be empty")
+	}
+	for _, source := range p.Sources {
+		if !slices.Contains(wildcardSources, source) {
+			return fmt.Errorf("wildcards source %q must be one of %v", source, wildcardSources)
+		}
+	}
+	if p.Refresh <= 0 {
+		return errors.New("wildcards refresh must be positive")
+	}
+	if slices.Contains(p.Sources, "axfr") {
+		if p.ZoneTransferServer == "" {
+			return errors.New("wildcards axfr source needs a zoneTransferServer")
+		}
+		if _, _, err := net.SplitHostPort(p.ZoneTransferServer); err != nil {
+			p.ZoneTransferServer = net.JoinHostPort(p.ZoneTransferServer, "53")
+		}
+	}
+	*w = Wildcards(p)
+	return nil
+}
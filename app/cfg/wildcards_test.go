@@ -0,0 +1,42 @@
+package cfg
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWildcards_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Wildcards
+		wantErr bool
+	}{
+		{name: "defaults", input: `{}`, want: defaultWildcards()},
+		{name: "zone transfer", input: `{"sources": ["ct", "axfr"], "refresh": "6h", "zoneTransferServer": "ns1.example.com"}`, want: Wildcards{Sources: []string{"ct", "axfr"}, Refresh: Duration(6 * time.Hour), CTEndpoint: "https://crt.sh", ZoneTransferServer: "ns1.example.com:53"}},
+		{name: "zone transfer port kept", input: `{"sources": ["axfr"], "zoneTransferServer": "192.0.2.53:5353"}`, want: Wildcards{Sources: []string{"axfr"}, Refresh: Duration(24 * time.Hour), CTEndpoint: "https://crt.sh", ZoneTransferServer: "192.0.2.53:5353"}},
+		{name: "invalid - no sources", input: `{"sources": []}`, wantErr: true},
+		{name: "invalid - unknown source", input: `{"sources": ["dnsdumpster"]}`, wantErr: true},
+		{name: "invalid - refresh", input: `{"refresh": "0s"}`, wantErr: true},
+		{name: "invalid - axfr without server", input: `{"sources": ["axfr"]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Wildcards
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Wildcards.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !slices.Equal(got.Sources, tt.want.Sources) || got.Refresh != tt.want.Refresh ||
+				got.CTEndpoint != tt.want.CTEndpoint || got.ZoneTransferServer != tt.want.ZoneTransferServer {
+				t.Errorf("Wildcards.UnmarshalJSON() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"cert-tracker/alert"
+	"cert-tracker/store"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// chain finding kinds, each raised as a chain-<kind> alert
+const (
+	chainIncomplete          = "incomplete"
+	chainOutOfOrder          = "out-of-order"
+	chainExpiredIntermediate = "expired-intermediate"
+	chainDuplicate           = "duplicate"
+)
+
+var chainFindingKinds = []string{chainIncomplete, chainOutOfOrder, chainExpiredIntermediate, chainDuplicate}
+
+// an incomplete chain fails outright on clients that don't fetch issuers
+var chainSeverity = map[string]alert.Severity{
+	chainIncomplete:          alert.Critical,
+	chainOutOfOrder:          alert.Warning,
+	chainExpiredIntermediate: alert.Warning,
+	chainDuplicate:           alert.Warning,
+}
+
+type chainProblem struct {
+	kind, detail string
+}
+
+// checkChains analyzes every served chain, raising a chain-<kind> alert
+// for each problem found at an endpoint.
+func checkChains(served []servedChain, roots *x509.CertPool, issuers *issuerFetcher, alerts *alert.Manager, now time.Time) []store.ChainFinding {
+	var findings []store.ChainFinding
+	for _, s := range served {
+		problems := analyzeChain(s.chain, roots, issuers.fetch, now)
+		endpoint := string(s.target.Hostname) + "@" + s.target.IPAddress.String()
+		for _, tenant := range s.target.Tenants {
+			for _, kind := range chainFindingKinds {
+				var found []string
+				for _, p := range problems {
+					if p.kind == kind {
+						found = append(found, p.detail)
+						findings = append(findings, store.ChainFinding{
+							Tenant:    tenant,
+							Hostname:  s.target.Hostname,
+							IPAddress: s.target.IPAddress,
+							Kind:      kind,
+							Detail:    p.detail,
+						})
+					}
+				}
+				alerts.Set(len(found) > 0, alert.Alert{
+					Key:      alert.Key("chain-"+kind, tenant, endpoint),
+					Severity: chainSeverity[kind],
+					Summary:  fmt.Sprintf("%s serves a chain that is %s: %s", endpoint, kind, strings.Join(found, "; ")),
+					Tenant:   tenant,
+					Labels:   map[string]string{"hostname": string(s.target.Hostname), "ipAddress": s.target.IPAddress.String()},
+					Since:    now,
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// analyzeChain finds what's wrong with chain, as served with the leaf
+// first. An issuer that's neither served nor trusted by roots only counts
+// as missing once fetch finds it and it turns out to be an intermediate,
+// since a private root is expected to be missing.
+func analyzeChain(chain []*x509.Certificate, roots *x509.CertPool, fetch func(*x509.Certificate) (*x509.Certificate, string, error), now time.Time) []chainProblem {
+	var problems []chainProblem
+
+	// follow issuers from the leaf through whatever was served
+	path := []int{0}
+	used := map[int]bool{0: true}
+	for top := chain[0]; !selfSigned(top); {
+		next := -1
+		for i, c := range chain {
+			if !used[i] && issuedBy(top, c) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			break
+		}
+		path, used[next], top = append(path, next), true, chain[next]
+	}
+	for i, served := range path {
+		if i != served {
+			problems = append(problems, chainProblem{chainOutOfOrder, fmt.Sprintf("served in the order %s instead of leaf to root", servedOrder(chain, path))})
+			break
+		}
+	}
+
+	top := chain[path[len(path)-1]]
+	if !selfSigned(top) && !trusted(top, roots) {
+		issuer, url, err := fetch(top)
+		switch {
+		case err != nil:
+			log.Debug("cannot fetch issuer", "subject", top.Subject.String(), "error", err)
+		case !selfSigned(issuer):
+			problems = append(problems, chainProblem{chainIncomplete, fmt.Sprintf("intermediate %q isn't served; fetched it from %s", issuer.Subject.String(), url)})
+		}
+	}
+
+	for i, c := range chain[1:] {
+		if now.After(c.NotAfter) {
+			problems = append(problems, chainProblem{chainExpiredIntermediate, fmt.Sprintf("%q expired on %s", c.Subject.String(), c.NotAfter.Format(time.DateOnly))})
+		}
+		for _, d := range chain[i+2:] {
+			switch {
+			case bytes.Equal(c.Raw, d.Raw):
+				problems = append(problems, chainProblem{chainDuplicate, fmt.Sprintf("%q is served twice", c.Subject.String())})
+			case bytes.Equal(c.RawSubject, d.RawSubject) && bytes.Equal(c.RawSubjectPublicKeyInfo, d.RawSubjectPublicKeyInfo):
+				problems = append(problems, chainProblem{chainDuplicate, fmt.Sprintf("%q is served cross-signed by both %q and %q", c.Subject.String(), c.Issuer.String(), d.Issuer.String())})
+			}
+		}
+	}
+	return problems
+}
+
+// servedOrder describes how the certificates in path were served, by their
+// position in the path: "leaf, 2, 1" has the path's second and third
+// certificates swapped.
+func servedOrder(chain []*x509.Certificate, path []int) string {
+	position := make(map[int]int)
+	for i, served := range path {
+		position[served] = i
+	}
+	var order []string
+	for i := range chain {
+		switch p, ok := position[i]; {
+		case !ok:
+			order = append(order, "unused")
+		case p == 0:
+			order = append(order, "leaf")
+		default:
+			order = append(order, fmt.Sprint(p))
+		}
+	}
+	return strings.Join(order, ", ")
+}
+
+func issuedBy(child, parent *x509.Certificate) bool {
+	return bytes.Equal(child.RawIssuer, parent.RawSubject) && child.CheckSignatureFrom(parent) == nil
+}
+
+func selfSigned(c *x509.Certificate) bool {
+	return bytes.Equal(c.RawIssuer, c.RawSubject) && c.CheckSignature(c.SignatureAlgorithm, c.RawTBSCertificate, c.Signature) == nil
+}
+
+// trusted reports whether c chains directly to one of roots, judged while
+// c is valid so that expiry doesn't hide a complete chain.
+func trusted(c *x509.Certificate, roots *x509.CertPool) bool {
+	if roots == nil {
+		return false
+	}
+	_, err := c.Verify(x509.VerifyOptions{
+		Roots:       roots,
+		CurrentTime: c.NotBefore.Add(c.NotAfter.Sub(c.NotBefore) / 2),
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err == nil
+}
+
+// issuerRetry is how long a failed issuer fetch is remembered.
+const issuerRetry = time.Hour
+
+// maxIssuerSize bounds an AIA download; certificates are a few KB.
+const maxIssuerSize = 1 << 20
+
+// issuerFetcher downloads issuers through AIA URLs, fetching each URL once
+// and trying failed ones again after issuerRetry.
+type issuerFetcher struct {
+	client  *http.Client
+	fetched map[string]fetchedIssuer
+}
+
+type fetchedIssuer struct {
+	cert *x509.Certificate
+	err  error
+	at   time.Time
+}
+
+func newIssuerFetcher(client *http.Client) *issuerFetcher {
+	return &issuerFetcher{client: client, fetched: make(map[string]fetchedIssuer)}
+}
+
+// fetch returns the issuer of c from the first of its AIA URLs that has it.
+func (f *issuerFetcher) fetch(c *x509.Certificate) (*x509.Certificate, string, error) {
+	if len(c.IssuingCertificateURL) == 0 {
+		return nil, "", errors.New("no issuer URL")
+	}
+	var errs []error
+	for _, url := range c.IssuingCertificateURL {
+		got, ok := f.fetched[url]
+		if !ok || got.err != nil && time.Since(got.at) >= issuerRetry {
+			got.cert, got.err = f.download(url)
+			got.at = time.Now()
+			f.fetched[url] = got
+		}
+		switch {
+		case got.err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", url, got.err))
+		case !issuedBy(c, got.cert):
+			errs = append(errs, fmt.Errorf("%s: %q didn't issue the certificate", url, got.cert.Subject.String()))
+		default:
+			return got.cert, url, nil
+		}
+	}
+	return nil, "", errors.Join(errs...)
+}
+
+func (f *issuerFetcher) download(url string) (*x509.Certificate, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, errors.New("not an http URL")
+	}
+	resp, err := f.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("returned %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxIssuerSize))
+	if err != nil {
+		return nil, err
+	}
+	// AIA URLs should serve DER, but some serve PEM
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	return x509.ParseCertificate(data)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"crypto/x509"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeChain(t *testing.T) {
+	now := time.Now()
+	year := now.Add(365 * 24 * time.Hour)
+	rootKey, otherRootKey, interKey := newKey(t), newKey(t), newKey(t)
+	root := &testCA{issueCert(t, certTemplate("Root A", true, year), rootKey, nil), rootKey}
+	otherRoot := &testCA{issueCert(t, certTemplate("Root B", true, year), otherRootKey, nil), otherRootKey}
+	inter := &testCA{issueCert(t, certTemplate("Intermediate", true, year), interKey, root), interKey}
+	crossSigned := issueCert(t, certTemplate("Intermediate", true, year), interKey, otherRoot)
+	expired := issueCert(t, certTemplate("Old Intermediate", true, now.Add(-time.Hour)), newKey(t), root)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/intermediate.der" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(inter.cert.Raw)
+	}))
+	defer srv.Close()
+	leafTemplate := certTemplate("www.example.com", false, year)
+	leafTemplate.IssuingCertificateURL = []string{srv.URL + "/intermediate.der"}
+	leaf := issueCert(t, leafTemplate, newKey(t), inter)
+	unfetchableTemplate := certTemplate("api.example.com", false, year)
+	unfetchableTemplate.IssuingCertificateURL = []string{srv.URL + "/gone.der"}
+	unfetchable := issueCert(t, unfetchableTemplate, newKey(t), inter)
+	private := issueCert(t, certTemplate("internal.example.com", false, year), newKey(t), otherRoot)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root.cert)
+	fetch := newIssuerFetcher(srv.Client()).fetch
+
+	tests := []struct {
+		name  string
+		chain []*x509.Certificate
+		want  []string
+	}{
+		{name: "complete", chain: []*x509.Certificate{leaf, inter.cert}},
+		{name: "root served too", chain: []*x509.Certificate{leaf, inter.cert, root.cert}},
+		{name: "missing intermediate", chain: []*x509.Certificate{leaf}, want: []string{chainIncomplete}},
+		{name: "missing intermediate not fetched", chain: []*x509.Certificate{unfetchable}},
+		{name: "private root", chain: []*x509.Certificate{private}},
+		{name: "out of order", chain: []*x509.Certificate{leaf, root.cert, inter.cert}, want: []string{chainOutOfOrder}},
+		{name: "expired intermediate", chain: []*x509.Certificate{leaf, inter.cert, expired}, want: []string{chainExpiredIntermediate}},
+		{name: "cross-signed duplicate", chain: []*x509.Certificate{leaf, inter.cert, crossSigned}, want: []string{chainDuplicate}},
+		{name: "served twice", chain: []*x509.Certificate{leaf, inter.cert, inter.cert}, want: []string{chainDuplicate}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range analyzeChain(tt.chain, roots, fetch, now) {
+				got = append(got, p.kind)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("analyzeChain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	alerts := alert.NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	target := scanTarget{Hostname: cfg.Hostname("www.example.com"), IPAddress: net.ParseIP("192.0.2.1"), Tenants: []string{""}}
+	findings := checkChains([]servedChain{{target: target, chain: []*x509.Certificate{leaf}}}, roots, newIssuerFetcher(srv.Client()), alerts, now)
+	if len(findings) != 1 || findings[0].Kind != chainIncomplete {
+		t.Errorf("checkChains() = %+v, want one incomplete chain", findings)
+	}
+	if a, ok := alerts.Get("chain-incomplete:www.example.com@192.0.2.1"); !ok || a.Severity != alert.Critical {
+		t.Errorf("chain-incomplete alert = %+v, firing %v", a, ok)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/store"
+	"fmt"
+	"time"
+)
+
+// checkChainSize raises an alert for every scanned endpoint sending a
+// certificate chain larger than maxBytes.
+func checkChainSize(snapshot store.Snapshot, maxBytes int, alerts *alert.Manager, now time.Time) {
+	for _, c := range snapshot.Certificates {
+		if c.Index != 0 {
+			continue
+		}
+		endpoint := string(c.Hostname) + "@" + c.IPAddress.String()
+		size := c.Connection.ChainBytes
+		alerts.Set(size > maxBytes, alert.Alert{
+			Key:      alert.Key("chain-oversized", c.Tenant, endpoint),
+			Severity: alert.Warning,
+			Summary:  fmt.Sprintf("%s sends a %d byte certificate chain, over the %d byte limit", endpoint, size, maxBytes),
+			Tenant:   c.Tenant,
+			Labels:   map[string]string{"hostname": string(c.Hostname), "ipAddress": c.IPAddress.String()},
+			Since:    now,
+		})
+	}
+}
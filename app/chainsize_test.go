@@ -0,0 +1,39 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/store"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestCheckChainSize(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		chainBytes int
+		wantFiring bool
+	}{
+		{name: "small chain", chainBytes: 3000},
+		{name: "at the limit", chainBytes: 8192},
+		{name: "oversized chain", chainBytes: 9000, wantFiring: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := alert.NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+			snapshot := store.Snapshot{Certificates: []store.Certificate{{
+				Hostname:   "example.com",
+				IPAddress:  net.ParseIP("192.0.2.1"),
+				Connection: store.Connection{ChainBytes: tt.chainBytes},
+			}}}
+			checkChainSize(snapshot, 8192, alerts, now)
+			if _, firing := alerts.Get("chain-oversized:example.com@192.0.2.1"); firing != tt.wantFiring {
+				t.Errorf("firing = %v, want %v", firing, tt.wantFiring)
+			}
+		})
+	}
+}
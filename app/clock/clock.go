@@ -0,0 +1,30 @@
+// Package clock lets staging deployments fast-forward time so expiry
+// handling can be exercised without waiting for certificates to age.
+package clock
+
+import "time"
+
+// Clock is the real clock shifted by a fixed offset. The zero value is the
+// real clock.
+type Clock struct {
+	offset time.Duration
+}
+
+// Shifted returns a clock running offset ahead of real time, or behind for a
+// negative offset.
+func Shifted(offset time.Duration) Clock {
+	return Clock{offset: offset}
+}
+
+// StartingAt returns a clock that reads t now and keeps ticking from there.
+func StartingAt(t time.Time) Clock {
+	return Clock{offset: time.Until(t)}
+}
+
+func (c Clock) Now() time.Time {
+	return time.Now().Add(c.offset)
+}
+
+func (c Clock) Offset() time.Duration {
+	return c.offset
+}
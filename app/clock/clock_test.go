@@ -0,0 +1,30 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	const slack = time.Minute
+
+	tests := []struct {
+		name  string
+		clock Clock
+		want  time.Time
+	}{
+		{name: "zero value is real time", clock: Clock{}, want: time.Now()},
+		{name: "shifted forward", clock: Shifted(30 * 24 * time.Hour), want: time.Now().Add(30 * 24 * time.Hour)},
+		{name: "shifted back", clock: Shifted(-time.Hour), want: time.Now().Add(-time.Hour)},
+		{name: "starting at", clock: StartingAt(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)), want: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.clock.Now()
+			if got.Before(tt.want.Add(-slack)) || got.After(tt.want.Add(slack)) {
+				t.Errorf("Now() = %v, want about %v", got, tt.want)
+			}
+		})
+	}
+}
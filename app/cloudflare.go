@@ -0,0 +1,140 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/discovery"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// checkCloudflare compares, for every proxied record, what the edge serves
+// with the certificates Cloudflare says it deployed, and checks that the
+// origin behind it presents a certificate the edge can accept in Full
+// (strict) mode.
+func checkCloudflare(config cfg.Params, handshakes map[cfg.Hostname]*tls.ConnectionState, alerts *alert.Manager, now time.Time) {
+	cf, err := cloudflareClient(config)
+	if err != nil {
+		log.Warn("cannot check Cloudflare origins", "error", err)
+		return
+	}
+	filter := cloudflareFilter(config.Cloudflare)
+	for _, zone := range config.Cloudflare.Zones {
+		records, err := cf.Records(zone)
+		if err != nil {
+			log.Warn("cannot check Cloudflare origins", "error", err)
+			continue
+		}
+		edge, err := cf.EdgeCertificates(zone)
+		if err != nil {
+			log.Warn("cannot list Cloudflare edge certificates", "error", err)
+			continue
+		}
+		for _, r := range records {
+			if !r.Proxied || !filter.Match(r) {
+				continue
+			}
+			hostname := cfg.Hostname(r.Name)
+			tenants := tenantsOf(config, []cfg.Hostname{hostname})[hostname]
+			labels := map[string]string{"hostname": r.Name, "origin": r.Content}
+			origin, err := originLeaf(r, time.Duration(config.Timeout))
+			drift := originDrift(r.Name, origin, err, now)
+			var edgeDrift string
+			if state, ok := handshakes[hostname]; ok {
+				edgeDrift = edgeCertificateDrift(edge, r.Name, state.PeerCertificates[0])
+			}
+			for _, tenant := range tenants {
+				alerts.Set(drift != "", alert.Alert{
+					Key:      alert.Key("cloudflare-origin", tenant, r.Name),
+					Severity: alert.Critical,
+					Summary:  fmt.Sprintf("origin %s of %s %s", r.Content, r.Name, drift),
+					Tenant:   tenant,
+					Labels:   labels,
+					Since:    now,
+				})
+				alerts.Set(edgeDrift != "", alert.Alert{
+					Key:      alert.Key("cloudflare-edge", tenant, r.Name),
+					Severity: alert.Warning,
+					Summary:  fmt.Sprintf("Cloudflare edge for %s %s", r.Name, edgeDrift),
+					Tenant:   tenant,
+					Labels:   labels,
+					Since:    now,
+				})
+			}
+		}
+	}
+}
+
+// originLeaf connects to the origin a proxied record points to, sending the
+// record's name the way the edge does.
+func originLeaf(r discovery.Record, timeout time.Duration) (*x509.Certificate, error) {
+	conn, err := tls.DialWithDialer(
+		&net.Dialer{Timeout: timeout},
+		"tcp",
+		net.JoinHostPort(r.Content, defaultPort),
+		&tls.Config{
+			InsecureSkipVerify: true,
+			ServerName:         r.Name,
+		})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+// originDrift describes why the edge would refuse the origin's certificate
+// for name, or returns "" if it wouldn't.
+func originDrift(name string, leaf *x509.Certificate, err error, now time.Time) string {
+	switch {
+	case err != nil:
+		return "failed the TLS handshake: " + err.Error()
+	case leaf.VerifyHostname(name) != nil:
+		return "serves a certificate for " + strings.Join(leaf.DNSNames, ", ") + ", which doesn't cover it"
+	case now.After(leaf.NotAfter):
+		return "serves a certificate that expired " + leaf.NotAfter.Format(time.RFC3339)
+	}
+	return ""
+}
+
+// edgeCertificateDrift describes how the certificate the edge served for name
+// differs from the active ones Cloudflare lists for it, or returns "" if it
+// is one of them.
+func edgeCertificateDrift(edge []discovery.EdgeCertificate, name string, served *x509.Certificate) string {
+	var listed []string
+	for _, c := range edge {
+		if c.Status != "active" || !coversHost(c.Hosts, name) {
+			continue
+		}
+		// Cloudflare reports expiry to the second
+		if c.ExpiresOn.Equal(served.NotAfter.Truncate(time.Second)) {
+			return ""
+		}
+		listed = append(listed, c.ExpiresOn.Format(time.RFC3339))
+	}
+	if len(listed) == 0 {
+		return "serves a certificate no active Cloudflare certificate pack covers"
+	}
+	return fmt.Sprintf("serves a certificate expiring %s; Cloudflare lists %s",
+		served.NotAfter.Format(time.RFC3339), strings.Join(listed, ", "))
+}
+
+// coversHost reports whether hosts, which may hold wildcards, include name.
+func coversHost(hosts []string, name string) bool {
+	for _, h := range hosts {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(h, "*."); ok {
+			label, rest, found := strings.Cut(name, ".")
+			if found && label != "" && strings.EqualFold(rest, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
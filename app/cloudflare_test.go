@@ -0,0 +1,76 @@
+package main
+
+import (
+	"cert-tracker/discovery"
+	"crypto/x509"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCoversHost(t *testing.T) {
+	hosts := []string{"example.com", "*.example.com"}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "example.com", want: true},
+		{name: "WWW.example.com", want: true},
+		{name: "a.b.example.com"},
+		{name: "example.org"},
+	}
+	for _, tt := range tests {
+		if got := coversHost(hosts, tt.name); got != tt.want {
+			t.Errorf("coversHost(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOriginDrift(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	valid := &x509.Certificate{DNSNames: []string{"*.example.com"}, NotAfter: now.Add(time.Hour)}
+	tests := []struct {
+		name      string
+		leaf      *x509.Certificate
+		err       error
+		wantDrift bool
+	}{
+		{name: "valid", leaf: valid},
+		{name: "unreachable", err: errors.New("connection refused"), wantDrift: true},
+		{name: "wrong name", leaf: &x509.Certificate{DNSNames: []string{"other.example.org"}, NotAfter: now.Add(time.Hour)}, wantDrift: true},
+		{name: "expired", leaf: &x509.Certificate{DNSNames: []string{"www.example.com"}, NotAfter: now.Add(-time.Hour)}, wantDrift: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := originDrift("www.example.com", tt.leaf, tt.err, now); (got != "") != tt.wantDrift {
+				t.Errorf("originDrift() = %q, wantDrift %v", got, tt.wantDrift)
+			}
+		})
+	}
+}
+
+func TestEdgeCertificateDrift(t *testing.T) {
+	expiry := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	edge := []discovery.EdgeCertificate{
+		{Hosts: []string{"example.com", "*.example.com"}, Status: "active", ExpiresOn: expiry},
+		{Hosts: []string{"example.com"}, Status: "expired", ExpiresOn: expiry.AddDate(0, -3, 0)},
+	}
+	tests := []struct {
+		name      string
+		host      string
+		notAfter  time.Time
+		wantDrift bool
+	}{
+		{name: "listed", host: "www.example.com", notAfter: expiry.Add(500 * time.Millisecond)},
+		{name: "other expiry", host: "www.example.com", notAfter: expiry.AddDate(0, -3, 0), wantDrift: true},
+		{name: "no pack", host: "example.org", notAfter: expiry, wantDrift: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := edgeCertificateDrift(edge, tt.host, &x509.Certificate{NotAfter: tt.notAfter})
+			if (got != "") != tt.wantDrift {
+				t.Errorf("edgeCertificateDrift() = %q, wantDrift %v", got, tt.wantDrift)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/store"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type tenantHostname struct {
+	tenant   string
+	hostname string
+}
+
+// splitBrains groups the leaf certificates of every hostname whose addresses
+// don't all serve the same certificate, typically a renewal rolled out to
+// only some backends.
+func splitBrains(certs []store.Certificate) map[tenantHostname][]store.Certificate {
+	leaves := make(map[tenantHostname][]store.Certificate)
+	for _, c := range certs {
+		if c.Index == 0 {
+			key := tenantHostname{c.Tenant, string(c.Hostname)}
+			leaves[key] = append(leaves[key], c)
+		}
+	}
+	for key, group := range leaves {
+		consistent := true
+		for _, c := range group[1:] {
+			if c.SHA256Fingerprint != group[0].SHA256Fingerprint {
+				consistent = false
+			}
+		}
+		if consistent {
+			delete(leaves, key)
+		}
+	}
+	return leaves
+}
+
+// checkConsistency raises a split brain alert for every hostname serving
+// different certificates on different addresses, and clears it for every
+// scanned hostname that agrees again.
+func checkConsistency(snapshot store.Snapshot, alerts *alert.Manager, now time.Time) {
+	split := splitBrains(snapshot.Certificates)
+	scanned := make(map[tenantHostname]bool)
+	for _, c := range snapshot.Certificates {
+		scanned[tenantHostname{c.Tenant, string(c.Hostname)}] = true
+	}
+	for key := range scanned {
+		group, ok := split[key]
+		a := alert.Alert{
+			Key:      alert.Key("split-brain", key.tenant, key.hostname),
+			Severity: alert.Warning,
+			Tenant:   key.tenant,
+			Labels:   map[string]string{"hostname": key.hostname},
+			Since:    now,
+		}
+		if ok {
+			a.Summary = key.hostname + " serves different certificates: " + describeLeaves(group)
+		}
+		alerts.Set(ok, a)
+	}
+}
+
+// describeLeaves lists which addresses serve which certificate.
+func describeLeaves(group []store.Certificate) string {
+	byFingerprint := make(map[string][]string)
+	expiry := make(map[string]time.Time)
+	for _, c := range group {
+		byFingerprint[c.SHA256Fingerprint] = append(byFingerprint[c.SHA256Fingerprint], c.IPAddress.String())
+		expiry[c.SHA256Fingerprint] = c.NotAfter
+	}
+	var parts []string
+	for fingerprint, addresses := range byFingerprint {
+		sort.Strings(addresses)
+		parts = append(parts, fmt.Sprintf("%s expiring %s on %s",
+			fingerprint[:min(16, len(fingerprint))],
+			expiry[fingerprint].Format(time.DateOnly),
+			strings.Join(addresses, ", "),
+		))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckConsistency(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	leaf := func(tenant, hostname, ip, fingerprint string, notAfter time.Time) store.Certificate {
+		return store.Certificate{Tenant: tenant, Hostname: cfg.Hostname(hostname), IPAddress: net.ParseIP(ip), SHA256Fingerprint: fingerprint, NotAfter: notAfter}
+	}
+	old, renewed := now.Add(5*24*time.Hour), now.Add(90*24*time.Hour)
+
+	snapshot := store.Snapshot{Certificates: []store.Certificate{
+		// partially rolled out renewal
+		leaf("", "example.com", "192.0.2.1", "aaaa", old),
+		leaf("", "example.com", "192.0.2.2", "bbbb", renewed),
+		{Hostname: "example.com", IPAddress: net.ParseIP("192.0.2.1"), Index: 1, SHA256Fingerprint: "ca"},
+		// consistent under another tenant
+		leaf("web", "www.example.com", "192.0.2.1", "aaaa", old),
+		leaf("web", "www.example.com", "192.0.2.3", "aaaa", old),
+	}}
+
+	alerts := alert.NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	checkConsistency(snapshot, alerts, now)
+
+	active := alerts.Active()
+	if len(active) != 1 || active[0].Key != "split-brain:example.com" {
+		t.Fatalf("Active() = %+v, want one split brain alert for example.com", active)
+	}
+	for _, want := range []string{"aaaa", "bbbb", "192.0.2.1", "192.0.2.2", old.Format(time.DateOnly), renewed.Format(time.DateOnly)} {
+		if !strings.Contains(active[0].Summary, want) {
+			t.Errorf("Expected %q in summary %q", want, active[0].Summary)
+		}
+	}
+
+	// renewal finished
+	snapshot.Certificates[0].SHA256Fingerprint = "bbbb"
+	checkConsistency(snapshot, alerts, now)
+	if active := alerts.Active(); len(active) != 0 {
+		t.Errorf("Active() after renewal completed = %+v", active)
+	}
+}
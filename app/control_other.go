@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+import "errors"
+
+func controlPipe(path string) (<-chan struct{}, error) {
+	return nil, errors.New("control pipes are not supported on this platform")
+}
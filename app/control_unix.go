@@ -0,0 +1,61 @@
+//go:build unix
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// controlPipe creates the named pipe at path, or reuses one already there,
+// and delivers a value for every "scan" line written to it. Requests that
+// arrive while one is pending are folded into it.
+func controlPipe(path string) (<-chan struct{}, error) {
+	info, err := os.Lstat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if err := syscall.Mkfifo(path, 0o620); err != nil {
+			return nil, &fs.PathError{Op: "mkfifo", Path: path, Err: err}
+		}
+		// the umask usually takes the group's write permission away
+		if err := os.Chmod(path, 0o620); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case info.Mode()&fs.ModeNamedPipe == 0:
+		return nil, fmt.Errorf("%s exists and isn't a named pipe", path)
+	}
+	// opened for writing too, so it neither blocks until a writer comes
+	// along nor reads EOF each time one goes away
+	pipe, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	requests := make(chan struct{}, 1)
+	go func() {
+		defer pipe.Close()
+		lines := bufio.NewScanner(pipe)
+		for lines.Scan() {
+			switch command := strings.TrimSpace(lines.Text()); command {
+			case "":
+			case "scan":
+				select {
+				case requests <- struct{}{}:
+				default:
+				}
+			default:
+				log.Warn("unknown control command", "command", command, "pipe", path)
+			}
+		}
+		if err := lines.Err(); err != nil {
+			log.Error("cannot read control pipe", "pipe", path, "error", err)
+		}
+	}()
+	return requests, nil
+}
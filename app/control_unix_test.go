@@ -0,0 +1,57 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestControlPipe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "control")
+	requests, err := controlPipe(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode()&os.ModeNamedPipe == 0 || info.Mode().Perm() != 0o620 {
+		t.Fatalf("Stat() = %v, %v, want a named pipe its group can write to", info, err)
+	}
+
+	write := func(s string) {
+		t.Helper()
+		w, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer w.Close()
+		if _, err := w.WriteString(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// unknown commands and blank lines are skipped
+	write("reload\n\nscan\n")
+	select {
+	case <-requests:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no scan requested")
+	}
+
+	// the pipe keeps working once a writer goes away
+	write("scan\n")
+	select {
+	case <-requests:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no scan requested after the writer went away")
+	}
+
+	regular := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(regular, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := controlPipe(regular); err == nil {
+		t.Error("Expected an error for a path that isn't a named pipe")
+	}
+}
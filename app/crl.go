@@ -0,0 +1,75 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/revocation"
+	"cert-tracker/store"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// crlURLs returns every CRL distribution point named in chains.
+func crlURLs(chains [][]*x509.Certificate) []string {
+	seen := make(map[string]bool)
+	var urls []string
+	for _, chain := range chains {
+		for _, cert := range chain {
+			for _, url := range cert.CRLDistributionPoints {
+				if !seen[url] {
+					seen[url] = true
+					urls = append(urls, url)
+				}
+			}
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// checkCRLs fetches the CRLs that are due and raises or clears their alerts.
+func checkCRLs(client *http.Client, chains [][]*x509.Certificate, monitor *revocation.CRLMonitor, alerts *alert.Manager, now time.Time) []store.CRLFetch {
+	var fetches []store.CRLFetch
+	for _, url := range crlURLs(chains) {
+		if !monitor.Due(url, now) {
+			continue
+		}
+		fetch := revocation.FetchCRL(client, url)
+		fetches = append(fetches, fetch)
+		log.Debug("CRL fetched", "fetch", fetch)
+
+		v := monitor.Observe(fetch, now)
+		if v.Growth != 0 {
+			log.Info("CRL size changed",
+				"url", url,
+				"size", fetch.Size,
+				"growth", v.Growth,
+			)
+		}
+		labels := map[string]string{"crl": url}
+		alerts.Set(v.Unreachable, alert.Alert{
+			Key:      "crl-unreachable:" + url,
+			Severity: alert.Critical,
+			Summary:  "CRL " + url + " is unreachable: " + fetch.Error,
+			Labels:   labels,
+			Since:    now,
+		})
+		alerts.Set(v.Stale, alert.Alert{
+			Key:      "crl-stale:" + url,
+			Severity: alert.Critical,
+			Summary:  "CRL " + url + " is stale; nextUpdate was " + fetch.NextUpdate.Format(time.RFC3339),
+			Labels:   labels,
+			Since:    now,
+		})
+		alerts.Set(v.Oversized, alert.Alert{
+			Key:      "crl-oversized:" + url,
+			Severity: alert.Warning,
+			Summary:  fmt.Sprintf("CRL %s is %d bytes", url, fetch.Size),
+			Labels:   labels,
+			Since:    now,
+		})
+	}
+	return fetches
+}
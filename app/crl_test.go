@@ -0,0 +1,19 @@
+package main
+
+import (
+	"crypto/x509"
+	"slices"
+	"testing"
+)
+
+func TestCRLURLs(t *testing.T) {
+	leaf := &x509.Certificate{CRLDistributionPoints: []string{"http://b.example/r3.crl"}}
+	intermediate := &x509.Certificate{CRLDistributionPoints: []string{"http://a.example/root.crl"}}
+	other := &x509.Certificate{CRLDistributionPoints: []string{"http://b.example/r3.crl"}}
+
+	got := crlURLs([][]*x509.Certificate{{leaf, intermediate}, {other}})
+	want := []string{"http://a.example/root.crl", "http://b.example/r3.crl"}
+	if !slices.Equal(got, want) {
+		t.Errorf("crlURLs() = %v, want %v", got, want)
+	}
+}
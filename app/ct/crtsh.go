@@ -0,0 +1,101 @@
+package ct
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Issuance is a certificate found in CT logs for a domain.
+type Issuance struct {
+	ID           int64     `json:"id"`
+	SerialNumber string    `json:"serialNumber"`
+	Issuer       string    `json:"issuer"`
+	Names        []string  `json:"names"`
+	NotBefore    time.Time `json:"notBefore"`
+	NotAfter     time.Time `json:"notAfter"`
+	LoggedAt     time.Time `json:"loggedAt"`
+}
+
+// CrtSh searches CT logs through crt.sh, or another service with its JSON
+// API at Endpoint.
+type CrtSh struct {
+	Endpoint string
+	Client   *http.Client
+}
+
+type crtshEntry struct {
+	ID             int64  `json:"id"`
+	IssuerName     string `json:"issuer_name"`
+	NameValue      string `json:"name_value"`
+	SerialNumber   string `json:"serial_number"`
+	NotBefore      string `json:"not_before"`
+	NotAfter       string `json:"not_after"`
+	EntryTimestamp string `json:"entry_timestamp"`
+}
+
+// crt.sh leaves the zone off its timestamps; they are UTC
+const crtshTime = "2006-01-02T15:04:05"
+
+// Issued returns the unexpired certificates logged for domain, and for its
+// subdomains too when subdomains is set. A precertificate and its final
+// certificate count once.
+func (c *CrtSh) Issued(domain string, subdomains bool) ([]Issuance, error) {
+	q := domain
+	if subdomains {
+		q = "%." + domain
+	}
+	query := url.Values{"q": {q}, "output": {"json"}, "exclude": {"expired"}, "deduplicate": {"Y"}}
+	resp, err := c.Client.Get(strings.TrimSuffix(c.Endpoint, "/") + "/?" + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("crt.sh: %s returned %s", c.Endpoint, resp.Status)
+	}
+	var entries []crtshEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("crt.sh: %w", err)
+	}
+
+	var issued []Issuance
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		serial := NormalizeSerial(e.SerialNumber)
+		if seen[serial] {
+			continue
+		}
+		seen[serial] = true
+		issued = append(issued, Issuance{
+			ID:           e.ID,
+			SerialNumber: serial,
+			Issuer:       e.IssuerName,
+			Names:        strings.Fields(e.NameValue),
+			NotBefore:    parseCrtShTime(e.NotBefore),
+			NotAfter:     parseCrtShTime(e.NotAfter),
+			LoggedAt:     parseCrtShTime(e.EntryTimestamp),
+		})
+	}
+	return issued, nil
+}
+
+func parseCrtShTime(s string) time.Time {
+	// entry timestamps carry fractional seconds
+	s, _, _ = strings.Cut(s, ".")
+	t, _ := time.Parse(crtshTime, s)
+	return t
+}
+
+// NormalizeSerial spells a hex serial number the way scans record it:
+// lowercase, without leading zeros.
+func NormalizeSerial(s string) string {
+	s = strings.TrimLeft(strings.ToLower(strings.ReplaceAll(s, ":", "")), "0")
+	if s == "" {
+		return "0"
+	}
+	return s
+}
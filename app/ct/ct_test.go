@@ -0,0 +1,186 @@
+package ct
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func logID(b byte) [32]byte {
+	var id [32]byte
+	id[0] = b
+	return id
+}
+
+func rawSCT(id [32]byte, ts time.Time) []byte {
+	b := append([]byte{0}, id[:]...)
+	b = binary.BigEndian.AppendUint64(b, uint64(ts.UnixMilli()))
+	// no extensions, dummy signature
+	return append(b, 0, 0, 4, 3, 0, 1, 0)
+}
+
+// testLeaf returns a certificate valid for lifetime with the given SCTs embedded.
+func testLeaf(t *testing.T, lifetime time.Duration, scts ...[]byte) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Add(lifetime),
+	}
+	if len(scts) > 0 {
+		var list []byte
+		for _, s := range scts {
+			list = binary.BigEndian.AppendUint16(list, uint16(len(s)))
+			list = append(list, s...)
+		}
+		value, err := asn1.Marshal(append(binary.BigEndian.AppendUint16(nil, uint16(len(list))), list...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		template.ExtraExtensions = []pkix.Extension{{Id: oidSCTList, Value: value}}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestEmbeddedSCTs(t *testing.T) {
+	issued := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	leaf := testLeaf(t, 90*24*time.Hour, rawSCT(logID(1), issued), rawSCT(logID(2), issued))
+
+	scts, err := EmbeddedSCTs(leaf)
+	if err != nil {
+		t.Fatalf("EmbeddedSCTs() error = %v", err)
+	}
+	if len(scts) != 2 || scts[0].LogID != logID(1) || !scts[1].Timestamp.Equal(issued) {
+		t.Errorf("EmbeddedSCTs() = %+v", scts)
+	}
+	if scts, err := EmbeddedSCTs(testLeaf(t, time.Hour)); err != nil || scts != nil {
+		t.Errorf("EmbeddedSCTs() without extension = %v, %v", scts, err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	issued := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	logs := LogList{
+		logID(1): {Operator: "Google", State: "usable"},
+		logID(2): {Operator: "Google", State: "usable"},
+		logID(3): {Operator: "Cloudflare", State: "usable"},
+		logID(4): {Operator: "DigiCert", State: "retired", Since: issued.Add(time.Hour)},
+		logID(5): {Operator: "Sectigo", State: "retired", Since: issued.Add(-time.Hour)},
+		logID(6): {Operator: "Let's Encrypt", State: "pending"},
+	}
+	short, long := 90*24*time.Hour, 365*24*time.Hour
+
+	tests := []struct {
+		name      string
+		leaf      *x509.Certificate
+		delivered [][]byte
+		want      bool
+	}{
+		{name: "two operators", leaf: testLeaf(t, short, rawSCT(logID(1), issued), rawSCT(logID(3), issued)), want: true},
+		{name: "one operator", leaf: testLeaf(t, short, rawSCT(logID(1), issued), rawSCT(logID(2), issued)), want: false},
+		{name: "no SCTs", leaf: testLeaf(t, short), want: false},
+		{name: "long lifetime needs three", leaf: testLeaf(t, long, rawSCT(logID(1), issued), rawSCT(logID(3), issued)), want: false},
+		{name: "long lifetime with three", leaf: testLeaf(t, long, rawSCT(logID(1), issued), rawSCT(logID(2), issued), rawSCT(logID(3), issued)), want: true},
+		{name: "retired log before retirement", leaf: testLeaf(t, short, rawSCT(logID(1), issued), rawSCT(logID(4), issued)), want: true},
+		{name: "retired log after retirement", leaf: testLeaf(t, short, rawSCT(logID(1), issued), rawSCT(logID(5), issued)), want: false},
+		{name: "pending log", leaf: testLeaf(t, short, rawSCT(logID(1), issued), rawSCT(logID(6), issued)), want: false},
+		{name: "only retired logs", leaf: testLeaf(t, short, rawSCT(logID(4), issued), rawSCT(logID(4), issued)), want: false},
+		{name: "delivered in handshake", leaf: testLeaf(t, long), delivered: [][]byte{rawSCT(logID(2), issued), rawSCT(logID(3), issued)}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Check(tt.leaf, tt.delivered, logs)
+			if got.Compliant != tt.want {
+				t.Errorf("Check() = %+v, want compliant %v", got, tt.want)
+			}
+			if !got.Compliant && got.Reason == "" {
+				t.Error("Expected a reason for non-compliance")
+			}
+		})
+	}
+}
+
+func TestParseLogList(t *testing.T) {
+	data := []byte(`{
+		"operators": [{
+			"name": "Google",
+			"logs": [{"log_id": "AQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", "state": {"usable": {"timestamp": "2024-01-01T00:00:00Z"}}}],
+			"tiled_logs": [{"log_id": "AgAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", "state": {"retired": {"timestamp": "2025-01-01T00:00:00Z"}}}]
+		}]
+	}`)
+	list, err := ParseLogList(data)
+	if err != nil {
+		t.Fatalf("ParseLogList() error = %v", err)
+	}
+	if got := list[logID(1)]; got.Operator != "Google" || got.State != "usable" {
+		t.Errorf("log 1 = %+v", got)
+	}
+	if got := list[logID(2)]; got.State != "retired" || !got.Since.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("tiled log 2 = %+v", got)
+	}
+	if _, err := ParseLogList([]byte(`{"operators": [{"logs": [{"log_id": "short"}]}]}`)); err == nil {
+		t.Error("Expected an invalid log ID to be rejected")
+	}
+}
+
+func TestCrtSh_Issued(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		io.WriteString(w, `[
+			{"id": 2, "issuer_name": "C=US, O=Let's Encrypt, CN=R10", "name_value": "example.com\nwww.example.com", "serial_number": "03a1B2", "not_before": "2026-09-01T00:00:00", "not_after": "2026-11-30T00:00:00", "entry_timestamp": "2026-09-01T01:02:03.456"},
+			{"id": 1, "issuer_name": "C=US, O=Let's Encrypt, CN=R10", "name_value": "example.com", "serial_number": "3a1b2", "not_before": "2026-09-01T00:00:00", "not_after": "2026-11-30T00:00:00", "entry_timestamp": "2026-09-01T01:02:00.000"}
+		]`)
+	}))
+	defer server.Close()
+
+	c := &CrtSh{Endpoint: server.URL, Client: server.Client()}
+	issued, err := c.Issued("example.com", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := query.Get("q"); got != "%.example.com" {
+		t.Errorf("query q = %q, want subdomains", got)
+	}
+	if len(issued) != 1 {
+		t.Fatalf("Issued() = %+v, want the precertificate and certificate once", issued)
+	}
+	want := Issuance{
+		ID:           2,
+		SerialNumber: "3a1b2",
+		Issuer:       "C=US, O=Let's Encrypt, CN=R10",
+		Names:        []string{"example.com", "www.example.com"},
+		NotBefore:    time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC),
+		LoggedAt:     time.Date(2026, 9, 1, 1, 2, 3, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(issued[0], want) {
+		t.Errorf("Issued() = %+v, want %+v", issued[0], want)
+	}
+}
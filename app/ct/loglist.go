@@ -0,0 +1,107 @@
+package ct
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Log is a CT log and the operator running it.
+type Log struct {
+	Operator string
+	// State is the log's state in the log list: pending, qualified,
+	// usable, readonly, retired or rejected.
+	State string
+	// Since is when the log entered State.
+	Since time.Time
+}
+
+// LogList maps log IDs to logs.
+type LogList map[[32]byte]Log
+
+type logListJSON struct {
+	Operators []struct {
+		Name      string    `json:"name"`
+		Logs      []logJSON `json:"logs"`
+		TiledLogs []logJSON `json:"tiled_logs"`
+	} `json:"operators"`
+}
+
+type logJSON struct {
+	LogID string               `json:"log_id"`
+	State map[string]stateJSON `json:"state"`
+}
+
+type stateJSON struct {
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ParseLogList decodes a log list in Chrome's v3 JSON format.
+func ParseLogList(data []byte) (LogList, error) {
+	var raw logListJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	list := make(LogList)
+	for _, op := range raw.Operators {
+		for _, l := range append(op.Logs, op.TiledLogs...) {
+			id, err := base64.StdEncoding.DecodeString(l.LogID)
+			if err != nil || len(id) != 32 {
+				return nil, fmt.Errorf("log list: invalid log_id %q", l.LogID)
+			}
+			log := Log{Operator: op.Name}
+			for state, v := range l.State {
+				log.State, log.Since = state, v.Timestamp
+			}
+			list[[32]byte(id)] = log
+		}
+	}
+	return list, nil
+}
+
+// LogListSource downloads the log list from URL and keeps it for MaxAge.
+type LogListSource struct {
+	URL    string
+	MaxAge time.Duration
+	Client *http.Client
+
+	mu      sync.Mutex
+	list    LogList
+	fetched time.Time
+}
+
+// Get returns the cached log list, refreshing it when it is older than
+// MaxAge. When a refresh fails, Get returns the error along with the
+// previous list, if any.
+func (s *LogListSource) Get() (LogList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.list != nil && time.Since(s.fetched) < s.MaxAge {
+		return s.list, nil
+	}
+	list, err := s.fetch()
+	if err != nil {
+		return s.list, err
+	}
+	s.list, s.fetched = list, time.Now()
+	return list, nil
+}
+
+func (s *LogListSource) fetch() (LogList, error) {
+	resp, err := s.Client.Get(s.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("log list: %s returned %s", s.URL, resp.Status)
+	}
+	var body json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return ParseLogList(body)
+}
@@ -0,0 +1,78 @@
+package ct
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+)
+
+// Compliance is the outcome of checking a certificate against Chrome's CT
+// policy.
+type Compliance struct {
+	Compliant bool
+	Reason    string
+}
+
+// lifetimeThreshold is where Chrome starts requiring a third embedded SCT.
+const lifetimeThreshold = 180 * 24 * time.Hour
+
+// Check evaluates leaf against Chrome's CT policy using the SCTs embedded in
+// it and those the server delivered in the TLS handshake. Either source on
+// its own can satisfy the policy.
+func Check(leaf *x509.Certificate, delivered [][]byte, logs LogList) Compliance {
+	var tlsSCTs []SCT
+	for _, raw := range delivered {
+		if s, err := ParseSCT(raw); err == nil {
+			tlsSCTs = append(tlsSCTs, s)
+		}
+	}
+	if n, operators, _ := count(tlsSCTs, logs); n >= 2 && operators >= 2 {
+		return Compliance{Compliant: true}
+	}
+
+	embedded, err := EmbeddedSCTs(leaf)
+	if err != nil {
+		return Compliance{Reason: "embedded SCT list is malformed: " + err.Error()}
+	}
+	required := 2
+	if leaf.NotAfter.Sub(leaf.NotBefore) > lifetimeThreshold {
+		required = 3
+	}
+	n, operators, current := count(embedded, logs)
+	switch {
+	case n < required:
+		return Compliance{Reason: fmt.Sprintf("%d SCTs from qualifying logs, %d required", n, required)}
+	case operators < 2:
+		return Compliance{Reason: "SCTs must come from at least 2 log operators"}
+	case !current:
+		return Compliance{Reason: "no SCT from a log that is currently qualified, usable or read-only"}
+	}
+	return Compliance{Compliant: true}
+}
+
+// count returns how many SCTs come from logs that qualify, how many
+// distinct operators run those logs, and whether any of them is in a
+// currently accepted state.
+func count(scts []SCT, logs LogList) (n, operators int, current bool) {
+	seen := make(map[string]bool)
+	for _, s := range scts {
+		log, ok := logs[s.LogID]
+		if !ok {
+			continue
+		}
+		switch log.State {
+		case "qualified", "usable", "readonly":
+			current = true
+		case "retired":
+			// SCTs issued before the log retired still count
+			if !s.Timestamp.Before(log.Since) {
+				continue
+			}
+		default:
+			continue
+		}
+		n++
+		seen[log.Operator] = true
+	}
+	return n, len(seen), current
+}
@@ -0,0 +1,73 @@
+// Package ct reads the certificate transparency evidence attached to
+// certificates and checks it against browser CT policy.
+package ct
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+var oidSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+var errMalformed = errors.New("malformed SCT")
+
+// SCT is the part of a signed certificate timestamp policy checks need. The
+// signature is not verified.
+type SCT struct {
+	LogID     [32]byte
+	Timestamp time.Time
+}
+
+// ParseSCT decodes a single serialized SCT (RFC 6962 section 3.2).
+func ParseSCT(b []byte) (SCT, error) {
+	// version, log id and timestamp
+	if len(b) < 1+32+8 || b[0] != 0 {
+		return SCT{}, errMalformed
+	}
+	var s SCT
+	copy(s.LogID[:], b[1:33])
+	s.Timestamp = time.UnixMilli(int64(binary.BigEndian.Uint64(b[33:41]))).UTC()
+	return s, nil
+}
+
+// EmbeddedSCTs returns the SCTs in the certificate's SCT list extension.
+func EmbeddedSCTs(cert *x509.Certificate) ([]SCT, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidSCTList) {
+			continue
+		}
+		var list []byte
+		if _, err := asn1.Unmarshal(ext.Value, &list); err != nil {
+			return nil, err
+		}
+		return parseSCTList(list)
+	}
+	return nil, nil
+}
+
+func parseSCTList(b []byte) ([]SCT, error) {
+	if len(b) < 2 || int(binary.BigEndian.Uint16(b)) != len(b)-2 {
+		return nil, errMalformed
+	}
+	b = b[2:]
+	var scts []SCT
+	for len(b) > 0 {
+		if len(b) < 2 {
+			return nil, errMalformed
+		}
+		n := int(binary.BigEndian.Uint16(b))
+		if len(b) < 2+n {
+			return nil, errMalformed
+		}
+		s, err := ParseSCT(b[2 : 2+n])
+		if err != nil {
+			return nil, err
+		}
+		scts = append(scts, s)
+		b = b[2+n:]
+	}
+	return scts, nil
+}
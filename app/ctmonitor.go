@@ -0,0 +1,102 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/ct"
+	"cert-tracker/store"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// issuanceWatch compares the certificates CT logs show for the monitored
+// domains with those the scanner has seen served.
+type issuanceWatch struct {
+	crtsh *ct.CrtSh
+	// observed holds the serial numbers of every leaf seen served
+	observed map[string]bool
+	// firing maps the keys of raised alerts to their domain, to clear them
+	// once the certificate is seen or expires
+	firing map[string]string
+}
+
+func newIssuanceWatch(crtsh *ct.CrtSh, st *store.Store, config cfg.Params) *issuanceWatch {
+	w := &issuanceWatch{crtsh: crtsh, observed: make(map[string]bool), firing: make(map[string]string)}
+	if config.StoreDir != "" {
+		serials, err := st.SerialNumbers()
+		if err != nil {
+			log.Warn("cannot load served serial numbers; CT issuances may be reported until they are scanned", "error", err)
+		}
+		for serial := range serials {
+			w.observe(serial)
+		}
+	}
+	return w
+}
+
+func (w *issuanceWatch) observe(serial string) {
+	w.observed[ct.NormalizeSerial(serial)] = true
+}
+
+func (w *issuanceWatch) observeSnapshot(snapshot store.Snapshot) {
+	for _, c := range snapshot.Certificates {
+		if c.Index == 0 {
+			w.observe(c.SerialNumber)
+		}
+	}
+}
+
+// ctDomains are the domains to search: those configured, or else the host
+// of every monitored hostname.
+func ctDomains(config cfg.Params, hostnames []cfg.Hostname) []string {
+	if len(config.CTMonitor.Domains) > 0 {
+		return config.CTMonitor.Domains
+	}
+	var domains []string
+	for _, h := range hostnames {
+		domains = append(domains, h.Host())
+	}
+	slices.Sort(domains)
+	return slices.Compact(domains)
+}
+
+// check raises an alert for every certificate logged for domains more than
+// grace ago that the scanner has never seen served. A domain whose search
+// fails keeps its alerts as they were.
+func (w *issuanceWatch) check(domains []string, subdomains bool, grace time.Duration, alerts *alert.Manager, now time.Time) {
+	unobserved := make(map[string]bool)
+	searched := make(map[string]bool)
+	for _, domain := range domains {
+		issued, err := w.crtsh.Issued(domain, subdomains)
+		if err != nil {
+			log.Warn("cannot search CT logs", "domain", domain, "error", err)
+			continue
+		}
+		searched[domain] = true
+		for _, i := range issued {
+			if w.observed[i.SerialNumber] || now.Sub(i.LoggedAt) < grace {
+				continue
+			}
+			key := alert.Key("ct-unobserved", "", domain+"#"+i.SerialNumber)
+			unobserved[key] = true
+			w.firing[key] = domain
+			alerts.Fire(alert.Alert{
+				Key:      key,
+				Severity: alert.Warning,
+				Summary: fmt.Sprintf("certificate %s for %s was logged on %s by %s but never seen served",
+					i.SerialNumber, strings.Join(i.Names, ", "), i.LoggedAt.Format(time.DateOnly), i.Issuer),
+				Labels: map[string]string{"domain": domain, "serialNumber": i.SerialNumber, "issuer": i.Issuer},
+				Since:  now,
+			})
+		}
+	}
+	for key, domain := range w.firing {
+		if unobserved[key] || !searched[domain] {
+			continue
+		}
+		alerts.Resolve(key)
+		delete(w.firing, key)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/ct"
+	"cert-tracker/store"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIssuanceWatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `[
+			{"id": 1, "issuer_name": "CN=R10", "name_value": "example.com", "serial_number": "0aa", "entry_timestamp": "2026-09-01T00:00:00.000"},
+			{"id": 2, "issuer_name": "CN=Rogue CA", "name_value": "example.com", "serial_number": "bb", "entry_timestamp": "2026-09-02T00:00:00.000"},
+			{"id": 3, "issuer_name": "CN=R10", "name_value": "example.com", "serial_number": "cc", "entry_timestamp": "2026-10-14T12:00:00.000"}
+		]`)
+	}))
+	defer server.Close()
+	now := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	alerts := alert.NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	w := &issuanceWatch{
+		crtsh:    &ct.CrtSh{Endpoint: server.URL, Client: server.Client()},
+		observed: make(map[string]bool),
+		firing:   make(map[string]string),
+	}
+	w.observeSnapshot(store.Snapshot{Certificates: []store.Certificate{{Hostname: "example.com", SerialNumber: "aa"}}})
+
+	w.check([]string{"example.com"}, false, 48*time.Hour, alerts, now)
+	for serial, want := range map[string]bool{"aa": false, "bb": true, "cc": false} {
+		if _, firing := alerts.Get("ct-unobserved:example.com#" + serial); firing != want {
+			t.Errorf("serial %s firing = %v, want %v", serial, firing, want)
+		}
+	}
+
+	// the certificate turns up on an endpoint
+	w.observe("bb")
+	w.check([]string{"example.com"}, false, 48*time.Hour, alerts, now)
+	if _, firing := alerts.Get("ct-unobserved:example.com#bb"); firing {
+		t.Error("alert still firing after the certificate was seen served")
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/ct"
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+)
+
+// publiclyTrusted reports whether chain leads to a root in the system pool.
+// Private CAs aren't subject to CT policy.
+func publiclyTrusted(chain []*x509.Certificate) bool {
+	intermediates := x509.NewCertPool()
+	for _, c := range chain[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{Intermediates: intermediates})
+	return err == nil
+}
+
+// checkCTPolicy raises an alert for every hostname serving a publicly trusted
+// certificate that Chrome would reject for insufficient CT evidence.
+func checkCTPolicy(logLists *ct.LogListSource, targets []scanTarget, handshakes map[cfg.Hostname]*tls.ConnectionState, alerts *alert.Manager, now time.Time) {
+	logs, err := logLists.Get()
+	if err != nil {
+		log.Warn("cannot refresh CT log list", "error", err)
+	}
+	if logs == nil {
+		return
+	}
+	tenants := tenantsByHostname(targets)
+
+	for _, hostname := range sortedHostnames(handshakes) {
+		state := handshakes[hostname]
+		if !publiclyTrusted(state.PeerCertificates) {
+			continue
+		}
+		result := ct.Check(state.PeerCertificates[0], state.SignedCertificateTimestamps, logs)
+		for _, tenant := range tenants[hostname] {
+			alerts.Set(!result.Compliant, alert.Alert{
+				Key:      alert.Key("ct-policy", tenant, string(hostname)),
+				Severity: alert.Critical,
+				Summary:  string(hostname) + " serves a certificate Chrome will reject: " + result.Reason,
+				Tenant:   tenant,
+				Labels:   map[string]string{"hostname": string(hostname)},
+				Since:    now,
+			})
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/dns"
+	"cert-tracker/store"
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// daneMatches reports whether r names a certificate of chain. Usages 1 and 3
+// name the leaf, 0 and 2 a CA; 0 and 1 also need the chain to pass PKIX
+// verification. A trust anchor the server doesn't send can't be matched.
+func daneMatches(r dns.TLSA, chain []*x509.Certificate, verified bool) bool {
+	switch r.Usage {
+	case 0, 1:
+		if !verified {
+			return false
+		}
+	case 2, 3:
+	default:
+		return false
+	}
+	if r.Usage == 1 || r.Usage == 3 {
+		return r.Matches(chain[0])
+	}
+	return slices.ContainsFunc(chain[1:], r.Matches)
+}
+
+// daneStatus compares chain with the TLSA records of the endpoint serving
+// it: "invalid" when no record matches, "insecure" when one does but the
+// resolver didn't authenticate the records, else "valid".
+func daneStatus(records []dns.TLSA, authenticated bool, chain []*x509.Certificate, verified bool) string {
+	if !slices.ContainsFunc(records, func(r dns.TLSA) bool { return daneMatches(r, chain, verified) }) {
+		return "invalid"
+	}
+	if !authenticated {
+		return "insecure"
+	}
+	return "valid"
+}
+
+// checkDANE records on each leaf in snapshot how it compares with its
+// endpoint's TLSA records, and raises an alert for every endpoint serving a
+// chain that matches none of them.
+func checkDANE(client dns.Client, served []servedChain, snapshot *store.Snapshot, alerts *alert.Manager, now time.Time) {
+	leaves := make(map[string][]int)
+	for i, c := range snapshot.Certificates {
+		if c.Index == 0 && !c.Deferred {
+			endpoint := string(c.Hostname) + "@" + c.IPAddress.String()
+			leaves[endpoint] = append(leaves[endpoint], i)
+		}
+	}
+	type service struct {
+		name, host, port, network string
+	}
+	type lookup struct {
+		records       []dns.TLSA
+		authenticated bool
+		err           error
+	}
+	lookups := make(map[service]lookup)
+	for _, s := range served {
+		hostname := s.target.Hostname
+		endpoint := string(hostname) + "@" + s.target.IPAddress.String()
+		if len(leaves[endpoint]) == 0 {
+			continue
+		}
+		svc := service{host: hostname.Host(), port: hostname.Port(), network: "tcp"}
+		if hostname.Protocol() == "quic" {
+			svc.network = "udp"
+		}
+		svc.name = "_" + svc.port + "._" + svc.network + "." + svc.host
+		l, ok := lookups[svc]
+		if !ok {
+			l.records, l.authenticated, l.err = client.LookupTLSA(svc.host, svc.port, svc.network)
+			lookups[svc] = l
+			if l.err != nil {
+				log.Warn("cannot look up TLSA records", "hostname", hostname, "error", l.err)
+			}
+		}
+		// keep the alert state until a lookup succeeds
+		if l.err != nil {
+			continue
+		}
+		leaf := snapshot.Certificates[leaves[endpoint][0]]
+		status := ""
+		if len(l.records) > 0 {
+			status = daneStatus(l.records, l.authenticated, s.chain, leaf.VerifyError == "")
+		}
+		for _, i := range leaves[endpoint] {
+			snapshot.Certificates[i].DANE = status
+		}
+		for _, tenant := range s.target.Tenants {
+			alerts.Set(status == "invalid", alert.Alert{
+				Key:      alert.Key("dane-mismatch", tenant, endpoint),
+				Severity: alert.Critical,
+				Summary:  fmt.Sprintf("%s serves a chain matching none of the TLSA records at %s", endpoint, svc.name),
+				Tenant:   tenant,
+				Labels: map[string]string{
+					"hostname":    string(hostname),
+					"ipAddress":   s.target.IPAddress.String(),
+					"fingerprint": leaf.SHA256Fingerprint,
+				},
+				Since: now,
+			})
+		}
+	}
+}
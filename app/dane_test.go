@@ -0,0 +1,45 @@
+package main
+
+import (
+	"cert-tracker/dns"
+	"crypto/sha256"
+	"crypto/x509"
+	"testing"
+)
+
+func TestDANEStatus(t *testing.T) {
+	leaf := &x509.Certificate{Raw: []byte("leaf"), RawSubjectPublicKeyInfo: []byte("leaf key")}
+	issuer := &x509.Certificate{Raw: []byte("issuer"), RawSubjectPublicKeyInfo: []byte("issuer key")}
+	chain := []*x509.Certificate{leaf, issuer}
+	record := func(usage uint8, cert *x509.Certificate) dns.TLSA {
+		hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		return dns.TLSA{Usage: usage, Selector: 1, MatchingType: 1, Data: hash[:]}
+	}
+
+	tests := []struct {
+		name          string
+		records       []dns.TLSA
+		authenticated bool
+		verified      bool
+		want          string
+	}{
+		{name: "DANE-EE", records: []dns.TLSA{record(3, leaf)}, authenticated: true, want: "valid"},
+		{name: "DANE-TA", records: []dns.TLSA{record(2, issuer)}, authenticated: true, want: "valid"},
+		{name: "DANE-TA names the leaf", records: []dns.TLSA{record(2, leaf)}, authenticated: true, want: "invalid"},
+		{name: "DANE-EE names the issuer", records: []dns.TLSA{record(3, issuer)}, authenticated: true, want: "invalid"},
+		{name: "PKIX-EE verified", records: []dns.TLSA{record(1, leaf)}, authenticated: true, verified: true, want: "valid"},
+		{name: "PKIX-TA unverified", records: []dns.TLSA{record(0, issuer)}, authenticated: true, want: "invalid"},
+		{name: "any record matching", records: []dns.TLSA{record(3, issuer), record(3, leaf)}, authenticated: true, want: "valid"},
+		{name: "unknown usage", records: []dns.TLSA{record(4, leaf)}, authenticated: true, want: "invalid"},
+		{name: "not authenticated", records: []dns.TLSA{record(3, leaf)}, want: "insecure"},
+		{name: "mismatch not authenticated", records: []dns.TLSA{record(3, issuer)}, want: "invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := daneStatus(tt.records, tt.authenticated, chain, tt.verified); got != tt.want {
+				t.Errorf("daneStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
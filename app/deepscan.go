@@ -0,0 +1,59 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/tlsprobe"
+	"net"
+	"strings"
+	"time"
+)
+
+// deepScan probes every endpoint for legacy cipher suites and, when
+// protocols isn't nil, for the TLS versions it accepts.
+func deepScan(targets []scanTarget, timeout cfg.Duration, protocols protocolVersions, alerts *alert.Manager, now time.Time) {
+	for _, t := range targets {
+		// the probe speaks TLS from the first byte
+		if t.Protocol != "tls" {
+			continue
+		}
+		if protocols != nil {
+			if err := protocols.probe(t, time.Duration(timeout)); err != nil {
+				log.Warn("cannot probe TLS versions",
+					"hostname", t.Hostname,
+					"ipAddress", t.IPAddress,
+					"error", err,
+				)
+			}
+		}
+		findings, err := tlsprobe.LegacySuites(
+			net.JoinHostPort(t.IPAddress.String(), t.Port),
+			t.Hostname.Host(),
+			time.Duration(timeout),
+		)
+		// keep the alert state until a probe succeeds
+		if err != nil {
+			log.Warn("cannot probe legacy cipher suites",
+				"hostname", t.Hostname,
+				"ipAddress", t.IPAddress,
+				"error", err,
+			)
+			continue
+		}
+		var suites []string
+		for _, f := range findings {
+			suites = append(suites, f.CipherSuite)
+		}
+		endpoint := string(t.Hostname) + "@" + t.IPAddress.String()
+		for _, tenant := range t.Tenants {
+			alerts.Set(len(findings) > 0, alert.Alert{
+				Key:      alert.Key("legacy-cipher", tenant, endpoint),
+				Severity: alert.Critical,
+				Summary:  endpoint + " accepts legacy cipher suites: " + strings.Join(suites, ", "),
+				Tenant:   tenant,
+				Labels:   map[string]string{"hostname": string(t.Hostname), "ipAddress": t.IPAddress.String()},
+				Since:    now,
+			})
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"fmt"
+	"net"
+)
+
+// dialerFor returns the dialer overrides config has for hostname, if any.
+func dialerFor(config cfg.Params, hostname cfg.Hostname) *cfg.Dialer {
+	for i, d := range config.Dialers {
+		if covered(d.Hostnames, hostname) {
+			return &config.Dialers[i]
+		}
+	}
+	return nil
+}
+
+// applyDialer copies the dialer overrides for t's hostname onto t. An
+// interface stands for its first address in the family of t's address;
+// without one, t is dialed from the default address.
+func applyDialer(config cfg.Params, t *scanTarget) {
+	d := dialerFor(config, t.Hostname)
+	if d == nil {
+		return
+	}
+	t.Timeout, t.Source, t.KeepAlive = d.Timeout, d.Source, d.KeepAlive
+	if d.Interface == "" {
+		return
+	}
+	source, err := interfaceAddress(d.Interface, t.IPAddress)
+	if err != nil {
+		log.Warn("cannot dial from the configured interface; using the default address",
+			"hostname", t.Hostname,
+			"ipAddress", t.IPAddress,
+			"error", err,
+		)
+		return
+	}
+	t.Source = source
+}
+
+func interfaceAddress(name string, ip net.IP) (net.IP, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	v4 := ip.To4() != nil
+	for _, addr := range addrs {
+		if n, ok := addr.(*net.IPNet); ok && (n.IP.To4() != nil) == v4 && !n.IP.IsLinkLocalUnicast() {
+			return n.IP, nil
+		}
+	}
+	family := "IPv6"
+	if v4 {
+		family = "IPv4"
+	}
+	return nil, fmt.Errorf("interface %s has no %s address", name, family)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestApplyDialer(t *testing.T) {
+	config := cfg.Params{Dialers: []cfg.Dialer{
+		{Hostnames: []cfg.Hostname{"*.corp.example.com"}, Timeout: cfg.Duration(time.Minute), KeepAlive: cfg.Duration(-1)},
+		{Hostnames: []cfg.Hostname{"lo.example.com"}, Interface: "lo"},
+		{Hostnames: []cfg.Hostname{"missing.example.com"}, Interface: "no-such-interface0"},
+	}}
+	ipv4 := net.ParseIP("192.0.2.1")
+	tests := []struct {
+		hostname  cfg.Hostname
+		timeout   cfg.Duration
+		keepAlive cfg.Duration
+		source    net.IP
+	}{
+		{hostname: "vpn.corp.example.com", timeout: cfg.Duration(time.Minute), keepAlive: cfg.Duration(-1)},
+		{hostname: "lo.example.com", source: net.ParseIP("127.0.0.1")},
+		{hostname: "missing.example.com"},
+		{hostname: "www.example.com"},
+	}
+	for _, tt := range tests {
+		target := scanTarget{Hostname: tt.hostname, IPAddress: ipv4}
+		applyDialer(config, &target)
+		if target.Timeout != tt.timeout || target.KeepAlive != tt.keepAlive || !target.Source.Equal(tt.source) {
+			t.Errorf("%s: timeout %v, keep-alive %v, source %v, want %v, %v, %v", tt.hostname, target.Timeout, target.KeepAlive, target.Source, tt.timeout, tt.keepAlive, tt.source)
+		}
+	}
+}
+
+func TestDialTLS_TargetTimeout(t *testing.T) {
+	// accepts connections but never answers the handshake
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Addr, 1)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn.RemoteAddr()
+			defer conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	target := scanTarget{
+		Hostname:   cfg.Hostname("example.com:" + port),
+		ServerName: "example.com",
+		IPAddress:  net.ParseIP("127.0.0.1"),
+		Timeout:    cfg.Duration(50 * time.Millisecond),
+		Source:     net.ParseIP("127.0.0.1"),
+	}
+	start := time.Now()
+	_, _, err = dialTLS(context.Background(), target, cfg.Duration(time.Minute), true)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("dialTLS() error = %v, want the target's deadline exceeded", err)
+	}
+	if took := time.Since(start); took > 5*time.Second {
+		t.Errorf("dialTLS() took %v, want the target's 50ms timeout", took)
+	}
+	if from := (<-accepted).(*net.TCPAddr); !from.IP.Equal(target.Source) {
+		t.Errorf("connected from %v, want %v", from.IP, target.Source)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// diffCommand exits 0 when the snapshots match, 1 when they differ and 2 on error, like diff(1).
+func diffCommand(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: cert-tracker diff [-json] <snapshotA> <snapshotB>")
+		fmt.Fprintln(flags.Output(), "snapshots are file paths or RFC 3339 timestamps looked up in storeDir")
+		flags.PrintDefaults()
+	}
+	asJSON := flags.Bool("json", false, "print changes as JSON")
+	cfg.RegisterConfigFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	config, err := cfg.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	st, err := openStore(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	a, err := findSnapshot(st, flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	b, err := findSnapshot(st, flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	changes := store.Diff(a, b)
+	if *asJSON {
+		err = json.NewEncoder(os.Stdout).Encode(changes)
+	} else {
+		err = printChanges(os.Stdout, changes)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if len(changes) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func findSnapshot(st *store.Store, arg string) (store.Snapshot, error) {
+	t, err := time.Parse(time.RFC3339, arg)
+	if err != nil {
+		return st.Load(arg)
+	}
+	if st.Dir() == "" {
+		return store.Snapshot{}, errors.New("storeDir is not configured; pass snapshot file paths instead")
+	}
+	s, err := st.At(t)
+	if err != nil {
+		return s, fmt.Errorf("%s: %w", arg, err)
+	}
+	return s, nil
+}
+
+func printChanges(w io.Writer, changes []store.Change) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tHOSTNAME\tIP ADDRESS\tOLD\tNEW")
+	for _, c := range changes {
+		ipAddress := ""
+		if c.IPAddress != nil {
+			ipAddress = c.IPAddress.String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.Kind, c.Hostname, ipAddress, c.Old, c.New)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"cert-tracker/store"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrintChanges(t *testing.T) {
+	changes := []store.Change{
+		{Kind: store.TargetAdded, Hostname: "new.example.com"},
+		{Kind: store.Rotated, Hostname: "example.com", IPAddress: net.ParseIP("192.0.2.1"), Old: "aa", New: "bb"},
+	}
+
+	var out strings.Builder
+	if err := printChanges(&out, changes); err != nil {
+		t.Fatalf("printChanges() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %d lines:\n%s", len(lines), out.String())
+	}
+	if !strings.HasPrefix(lines[0], "KIND") {
+		t.Errorf("Expected header row, got %q", lines[0])
+	}
+	if strings.Contains(lines[1], "<nil>") {
+		t.Errorf("Expected empty IP address column, got %q", lines[1])
+	}
+	for _, want := range []string{"rotated", "192.0.2.1", "aa", "bb"} {
+		if !strings.Contains(lines[2], want) {
+			t.Errorf("Expected %q in row %q", want, lines[2])
+		}
+	}
+}
+
+func TestFindSnapshotByPath(t *testing.T) {
+	taken := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	st, err := store.Open(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := st.Save(store.Snapshot{Time: taken})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := findSnapshot(st, path)
+	if err != nil {
+		t.Fatalf("findSnapshot() error = %v", err)
+	}
+	if !s.Time.Equal(taken) {
+		t.Errorf("findSnapshot() time = %v, want %v", s.Time, taken)
+	}
+
+	if _, err := findSnapshot(st, "does-not-exist.json"); err == nil {
+		t.Error("Expected error for missing snapshot file")
+	}
+
+	noStore, err := store.Open("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := findSnapshot(noStore, "2025-06-01T00:00:00Z"); err == nil {
+		t.Error("Expected error for timestamp without storeDir")
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"cert-tracker/aws"
+	"cert-tracker/cfg"
+	"cert-tracker/discovery"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+)
+
+// the hostnames found in each DNS provider, kept between listings
+var route53Discovered, cloudflareDiscovered discoveredHostnames
+
+type discoveredHostnames struct {
+	hostnames []cfg.Hostname
+	fetched   time.Time
+}
+
+// refresh lists the hostnames again once every has passed since the last
+// listing. A failed listing keeps the last one, so a provider outage doesn't
+// drop targets.
+func (d *discoveredHostnames) refresh(every time.Duration, now time.Time, list func() ([]cfg.Hostname, error)) []cfg.Hostname {
+	if !d.fetched.IsZero() && now.Sub(d.fetched) < every {
+		return d.hostnames
+	}
+	hostnames, err := list()
+	if err != nil {
+		log.Warn("cannot discover hostnames", "error", err)
+		return d.hostnames
+	}
+	if added := len(hostnames) - len(d.hostnames); added != 0 {
+		log.Info("discovered hostnames changed", "hostnames", len(hostnames), "change", added)
+	}
+	d.hostnames, d.fetched = hostnames, now
+	return hostnames
+}
+
+// discoveredTargets returns the hostnames of the DNS providers set to add
+// their hostnames as scan targets.
+func discoveredTargets(config cfg.Params, now time.Time) []cfg.Hostname {
+	var hostnames []cfg.Hostname
+	if config.Route53.Enabled && config.Route53.AddTargets {
+		list := func() ([]cfg.Hostname, error) { return route53Hostnames(config) }
+		hostnames = append(hostnames, route53Discovered.refresh(time.Duration(config.Route53.Refresh), now, list)...)
+	}
+	if config.Cloudflare.Enabled && config.Cloudflare.AddTargets {
+		list := func() ([]cfg.Hostname, error) { return cloudflareHostnames(config) }
+		hostnames = append(hostnames, cloudflareDiscovered.refresh(time.Duration(config.Cloudflare.Refresh), now, list)...)
+	}
+	return hostnames
+}
+
+// discoverHostnames lists the hostnames in every enabled DNS provider.
+func discoverHostnames(config cfg.Params) ([]cfg.Hostname, error) {
+	var hostnames []cfg.Hostname
+	if config.Route53.Enabled {
+		found, err := route53Hostnames(config)
+		if err != nil {
+			return nil, err
+		}
+		hostnames = append(hostnames, found...)
+	}
+	if config.Cloudflare.Enabled {
+		found, err := cloudflareHostnames(config)
+		if err != nil {
+			return nil, err
+		}
+		hostnames = append(hostnames, found...)
+	}
+	return hostnames, nil
+}
+
+// parseDiscovered keeps the names that are valid hostnames, once each.
+func parseDiscovered(hostnames []cfg.Hostname, names []string) []cfg.Hostname {
+	for _, name := range names {
+		hostname, err := cfg.ParseHostname(name)
+		if err != nil {
+			log.Debug("skipping discovered name", "name", name, "error", err)
+			continue
+		}
+		if !slices.Contains(hostnames, hostname) {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	return hostnames
+}
+
+func route53Hostnames(config cfg.Params) ([]cfg.Hostname, error) {
+	creds, err := aws.CredentialsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	r53 := &discovery.Route53{
+		Client:      &http.Client{Timeout: time.Duration(config.Timeout)},
+		Credentials: creds,
+	}
+	filter := discovery.Filter{
+		Types:   config.Route53.Types,
+		Include: config.Route53.Include,
+		Exclude: config.Route53.Exclude,
+	}
+	var hostnames []cfg.Hostname
+	for _, zone := range config.Route53.Zones {
+		records, err := r53.Records(zone)
+		if err != nil {
+			return nil, err
+		}
+		hostnames = parseDiscovered(hostnames, filter.Hostnames(records))
+	}
+	return hostnames, nil
+}
+
+func cloudflareClient(config cfg.Params) (*discovery.Cloudflare, error) {
+	token, err := discovery.CloudflareTokenFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return &discovery.Cloudflare{
+		Client: &http.Client{Timeout: time.Duration(config.Timeout)},
+		Token:  token,
+	}, nil
+}
+
+func cloudflareFilter(config cfg.Cloudflare) discovery.Filter {
+	return discovery.Filter{Types: config.Types, Include: config.Include, Exclude: config.Exclude}
+}
+
+func cloudflareHostnames(config cfg.Params) ([]cfg.Hostname, error) {
+	cf, err := cloudflareClient(config)
+	if err != nil {
+		return nil, err
+	}
+	filter := cloudflareFilter(config.Cloudflare)
+	var hostnames []cfg.Hostname
+	for _, zone := range config.Cloudflare.Zones {
+		records, err := cf.Records(zone)
+		if err != nil {
+			return nil, err
+		}
+		hostnames = parseDiscovered(hostnames, filter.Hostnames(records))
+	}
+	return hostnames, nil
+}
+
+func discoverCommand(args []string) int {
+	flags := flag.NewFlagSet("discover", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: cert-tracker discover [-config file]")
+		fmt.Fprintln(flags.Output(), "prints the discovered hostnames that aren't scan targets yet")
+	}
+	cfg.RegisterConfigFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	config, err := cfg.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	// keeps stdout to the list of hostnames
+	log = slog.New(slog.NewTextHandler(os.Stderr, nil))
+	if !config.Route53.Enabled && !config.Cloudflare.Enabled {
+		fmt.Fprintln(os.Stderr, "no DNS provider is enabled")
+		return 1
+	}
+	found, err := discoverHostnames(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	st, err := openStore(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	known := targets(config, st)
+	for _, hostname := range found {
+		if !slices.Contains(known, hostname) {
+			fmt.Println(hostname)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDiscoveredHostnamesRefresh(t *testing.T) {
+	now := time.Now()
+	var d discoveredHostnames
+	calls := 0
+	listing := []cfg.Hostname{"a.example.com"}
+	var listErr error
+	list := func() ([]cfg.Hostname, error) {
+		calls++
+		return listing, listErr
+	}
+
+	steps := []struct {
+		name      string
+		at        time.Time
+		listing   []cfg.Hostname
+		err       error
+		want      []cfg.Hostname
+		wantCalls int
+	}{
+		{name: "first listing", at: now, listing: listing, want: []cfg.Hostname{"a.example.com"}, wantCalls: 1},
+		{name: "cached", at: now.Add(30 * time.Minute), listing: []cfg.Hostname{"b.example.com"}, want: []cfg.Hostname{"a.example.com"}, wantCalls: 1},
+		{name: "refreshed", at: now.Add(time.Hour), listing: []cfg.Hostname{"b.example.com"}, want: []cfg.Hostname{"b.example.com"}, wantCalls: 2},
+		{name: "failure keeps the last listing", at: now.Add(3 * time.Hour), err: errors.New("throttled"), want: []cfg.Hostname{"b.example.com"}, wantCalls: 3},
+	}
+	for _, step := range steps {
+		listing, listErr = step.listing, step.err
+		got := d.refresh(time.Hour, step.at, list)
+		if !slices.Equal(got, step.want) || calls != step.wantCalls {
+			t.Errorf("%s: refresh() = %v after %d listings, want %v after %d", step.name, got, calls, step.want, step.wantCalls)
+		}
+	}
+}
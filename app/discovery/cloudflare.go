@@ -0,0 +1,140 @@
+package discovery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const cloudflareEndpoint = "https://api.cloudflare.com/client/v4"
+
+// Cloudflare lists the DNS records and edge certificates of Cloudflare
+// zones.
+type Cloudflare struct {
+	Client *http.Client
+	Token  string
+	// Endpoint overrides the Cloudflare API endpoint, for tests.
+	Endpoint string
+}
+
+// CloudflareTokenFromEnv reads the API token from CLOUDFLARE_API_TOKEN.
+func CloudflareTokenFromEnv() (string, error) {
+	token := os.Getenv("CLOUDFLARE_API_TOKEN")
+	if token == "" {
+		return "", errors.New("CLOUDFLARE_API_TOKEN must be set")
+	}
+	return token, nil
+}
+
+// EdgeCertificate is a certificate Cloudflare deploys to its edge.
+type EdgeCertificate struct {
+	Hosts     []string  `json:"hosts"`
+	Issuer    string    `json:"issuer"`
+	Status    string    `json:"status"`
+	ExpiresOn time.Time `json:"expires_on"`
+}
+
+type cloudflareResponse struct {
+	Success bool `json:"success"`
+	Errors  []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+	Result     json.RawMessage `json:"result"`
+	ResultInfo struct {
+		TotalPages int `json:"total_pages"`
+	} `json:"result_info"`
+}
+
+// Records lists the DNS records of the zone. Content is the address or
+// target a record points to, which is the origin for proxied records.
+func (c *Cloudflare) Records(zoneID string) ([]Record, error) {
+	var records []Record
+	err := c.pages("/zones/"+url.PathEscape(zoneID)+"/dns_records", func(result json.RawMessage) error {
+		var page []struct {
+			Name    string `json:"name"`
+			Type    string `json:"type"`
+			Content string `json:"content"`
+			Proxied bool   `json:"proxied"`
+		}
+		if err := json.Unmarshal(result, &page); err != nil {
+			return err
+		}
+		for _, r := range page {
+			records = append(records, Record{Name: strings.ToLower(r.Name), Type: r.Type, Content: r.Content, Proxied: r.Proxied})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cloudflare zone %s: %w", zoneID, err)
+	}
+	return records, nil
+}
+
+// EdgeCertificates lists the certificates in every certificate pack of the
+// zone, universal and advanced alike.
+func (c *Cloudflare) EdgeCertificates(zoneID string) ([]EdgeCertificate, error) {
+	var certs []EdgeCertificate
+	err := c.pages("/zones/"+url.PathEscape(zoneID)+"/ssl/certificate_packs?status=all", func(result json.RawMessage) error {
+		var packs []struct {
+			Certificates []EdgeCertificate `json:"certificates"`
+		}
+		if err := json.Unmarshal(result, &packs); err != nil {
+			return err
+		}
+		for _, p := range packs {
+			certs = append(certs, p.Certificates...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cloudflare zone %s: %w", zoneID, err)
+	}
+	return certs, nil
+}
+
+// pages fetches every page of a list endpoint.
+func (c *Cloudflare) pages(path string, each func(json.RawMessage) error) error {
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = cloudflareEndpoint
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	for page := 1; ; page++ {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s%spage=%d&per_page=100", endpoint, path, sep, page), nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		resp, err := c.Client.Do(req)
+		if err != nil {
+			return err
+		}
+		var body cloudflareResponse
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", resp.Status, err)
+		}
+		if !body.Success {
+			var messages []string
+			for _, e := range body.Errors {
+				messages = append(messages, e.Message)
+			}
+			return fmt.Errorf("%s: %s", resp.Status, strings.Join(messages, "; "))
+		}
+		if err := each(body.Result); err != nil {
+			return err
+		}
+		if page >= body.ResultInfo.TotalPages {
+			return nil
+		}
+	}
+}
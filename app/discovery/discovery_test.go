@@ -0,0 +1,136 @@
+package discovery
+
+import (
+	"cert-tracker/aws"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoute53_Records(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2013-04-01/hostedzone/Z123/rrset" || !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("name") == "" {
+			fmt.Fprint(w, `<ListResourceRecordSetsResponse><ResourceRecordSets>
+<ResourceRecordSet><Name>example.com.</Name><Type>A</Type></ResourceRecordSet>
+<ResourceRecordSet><Name>\052.example.com.</Name><Type>CNAME</Type></ResourceRecordSet>
+</ResourceRecordSets><IsTruncated>true</IsTruncated><NextRecordName>www.example.com.</NextRecordName><NextRecordType>A</NextRecordType></ListResourceRecordSetsResponse>`)
+			return
+		}
+		fmt.Fprint(w, `<ListResourceRecordSetsResponse><ResourceRecordSets>
+<ResourceRecordSet><Name>www.example.com.</Name><Type>A</Type></ResourceRecordSet>
+</ResourceRecordSets><IsTruncated>false</IsTruncated></ListResourceRecordSetsResponse>`)
+	}))
+	defer server.Close()
+
+	r53 := &Route53{Client: server.Client(), Credentials: aws.Credentials{AccessKeyID: "id", SecretAccessKey: "secret"}, Endpoint: server.URL}
+	records, err := r53.Records("/hostedzone/Z123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Record{{Name: "example.com", Type: "A"}, {Name: "*.example.com", Type: "CNAME"}, {Name: "www.example.com", Type: "A"}}
+	if !slices.Equal(records, want) {
+		t.Errorf("Records() = %v, want %v", records, want)
+	}
+
+	if _, err := r53.Records("Zmissing"); err == nil {
+		t.Error("Records() of an unknown zone succeeded")
+	}
+}
+
+func TestFilter_Hostnames(t *testing.T) {
+	records := []Record{
+		{Name: "example.com", Type: "A"},
+		{Name: "example.com", Type: "AAAA"},
+		{Name: "*.example.com", Type: "CNAME"},
+		{Name: "api.example.com", Type: "CNAME"},
+		{Name: "internal.example.com", Type: "A"},
+		{Name: "example.com", Type: "MX"},
+		{Name: "mail.example.com", Type: "MX"},
+	}
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{
+			name:   "types",
+			filter: Filter{Types: []string{"A", "AAAA", "CNAME"}},
+			want:   []string{"example.com", "api.example.com", "internal.example.com"},
+		},
+		{
+			name:   "include",
+			filter: Filter{Types: []string{"A", "AAAA", "CNAME"}, Include: []string{"*.example.com"}},
+			want:   []string{"api.example.com", "internal.example.com"},
+		},
+		{
+			name:   "exclude",
+			filter: Filter{Types: []string{"A", "AAAA", "CNAME"}, Exclude: []string{"internal.*"}},
+			want:   []string{"example.com", "api.example.com"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Hostnames(records); !slices.Equal(got, tt.want) {
+				t.Errorf("Hostnames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloudflare(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"success": false, "errors": [{"message": "Authentication error"}]}`)
+			return
+		}
+		switch r.URL.Path + "?" + r.URL.Query().Get("page") {
+		case "/zones/z1/dns_records?1":
+			fmt.Fprint(w, `{"success": true, "result": [{"name": "Example.com", "type": "A", "content": "192.0.2.1", "proxied": true}], "result_info": {"total_pages": 2}}`)
+		case "/zones/z1/dns_records?2":
+			fmt.Fprint(w, `{"success": true, "result": [{"name": "www.example.com", "type": "CNAME", "content": "example.com"}], "result_info": {"total_pages": 2}}`)
+		case "/zones/z1/ssl/certificate_packs?1":
+			fmt.Fprint(w, `{"success": true, "result": [{"type": "universal", "certificates": [
+				{"hosts": ["example.com", "*.example.com"], "issuer": "GoogleTrustServices", "status": "active", "expires_on": "2026-12-01T00:00:00Z"}
+			]}], "result_info": {"total_pages": 1}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"success": false, "errors": [{"message": "Could not route"}]}`)
+		}
+	}))
+	defer server.Close()
+
+	cf := &Cloudflare{Client: server.Client(), Token: "token", Endpoint: server.URL}
+	records, err := cf.Records("z1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Record{
+		{Name: "example.com", Type: "A", Content: "192.0.2.1", Proxied: true},
+		{Name: "www.example.com", Type: "CNAME", Content: "example.com"},
+	}
+	if !slices.Equal(records, want) {
+		t.Errorf("Records() = %v, want %v", records, want)
+	}
+
+	certs, err := cf.EdgeCertificates("z1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(certs) != 1 || certs[0].Issuer != "GoogleTrustServices" || !certs[0].ExpiresOn.Equal(time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("EdgeCertificates() = %+v", certs)
+	}
+
+	cf.Token = "wrong"
+	if _, err := cf.Records("z1"); err == nil || !strings.Contains(err.Error(), "Authentication error") {
+		t.Errorf("Records() with a bad token error = %v", err)
+	}
+}
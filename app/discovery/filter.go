@@ -0,0 +1,50 @@
+package discovery
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// Filter selects the records worth scanning.
+type Filter struct {
+	// Types are the record types to keep.
+	Types []string
+	// Include keeps only names matching one of these patterns, when set.
+	// Patterns use path.Match syntax, e.g. *.example.com.
+	Include []string
+	// Exclude drops names matching any of these patterns.
+	Exclude []string
+}
+
+// Hostnames returns the distinct names of the records the filter keeps, in
+// the order first seen. Wildcard records can't be scanned and are skipped.
+func (f Filter) Hostnames(records []Record) []string {
+	var hostnames []string
+	for _, r := range records {
+		if f.Match(r) && !slices.Contains(hostnames, r.Name) {
+			hostnames = append(hostnames, r.Name)
+		}
+	}
+	return hostnames
+}
+
+// Match reports whether the filter keeps r.
+func (f Filter) Match(r Record) bool {
+	if !slices.Contains(f.Types, r.Type) || strings.Contains(r.Name, "*") {
+		return false
+	}
+	if len(f.Include) > 0 && !matchAny(f.Include, r.Name) {
+		return false
+	}
+	return !matchAny(f.Exclude, r.Name)
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+// Package discovery finds hostnames to scan in the systems that are the
+// source of truth for DNS.
+package discovery
+
+import (
+	"cert-tracker/aws"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const route53Endpoint = "https://route53.amazonaws.com"
+
+// Record is a DNS record found in a zone.
+type Record struct {
+	Name string
+	Type string
+	// Content is the address or name the record points to, where the
+	// provider reports it.
+	Content string
+	// Proxied marks a record served through the provider's edge.
+	Proxied bool
+}
+
+// Route53 lists the records of Route 53 hosted zones.
+type Route53 struct {
+	Client      *http.Client
+	Credentials aws.Credentials
+	// Endpoint overrides the Route 53 API endpoint, for tests.
+	Endpoint string
+}
+
+type listResourceRecordSetsResponse struct {
+	ResourceRecordSets []struct {
+		Name string `xml:"Name"`
+		Type string `xml:"Type"`
+	} `xml:"ResourceRecordSets>ResourceRecordSet"`
+	IsTruncated          bool   `xml:"IsTruncated"`
+	NextRecordName       string `xml:"NextRecordName"`
+	NextRecordType       string `xml:"NextRecordType"`
+	NextRecordIdentifier string `xml:"NextRecordIdentifier"`
+}
+
+// Records lists every record in the hosted zone, following pagination.
+// zoneID may carry the /hostedzone/ prefix the console shows.
+func (r *Route53) Records(zoneID string) ([]Record, error) {
+	endpoint := r.Endpoint
+	if endpoint == "" {
+		endpoint = route53Endpoint
+	}
+	zoneID = strings.TrimPrefix(zoneID, "/hostedzone/")
+	var records []Record
+	query := url.Values{}
+	for {
+		u := endpoint + "/2013-04-01/hostedzone/" + url.PathEscape(zoneID) + "/rrset"
+		if len(query) > 0 {
+			u += "?" + aws.CanonicalQuery(query)
+		}
+		page, err := r.get(u)
+		if err != nil {
+			return nil, fmt.Errorf("route53 zone %s: %w", zoneID, err)
+		}
+		for _, set := range page.ResourceRecordSets {
+			records = append(records, Record{Name: unescapeName(set.Name), Type: set.Type})
+		}
+		if !page.IsTruncated {
+			return records, nil
+		}
+		query = url.Values{"name": {page.NextRecordName}, "type": {page.NextRecordType}}
+		if page.NextRecordIdentifier != "" {
+			query.Set("identifier", page.NextRecordIdentifier)
+		}
+	}
+}
+
+func (r *Route53) get(u string) (*listResourceRecordSetsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Route 53 is a global service signed for us-east-1
+	r.Credentials.Sign(req, nil, "us-east-1", "route53", time.Now())
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var page listResourceRecordSetsResponse
+	if err := xml.Unmarshal(body, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// unescapeName drops the trailing dot and undoes the octal escapes Route 53
+// uses for characters such as the * of wildcard records.
+func unescapeName(name string) string {
+	name = strings.TrimSuffix(name, ".")
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '\\' && i+3 < len(name) && isOctal(name[i+1:i+4]) {
+			b.WriteByte((name[i+1]-'0')<<6 | (name[i+2]-'0')<<3 | (name[i+3] - '0'))
+			i += 3
+			continue
+		}
+		b.WriteByte(name[i])
+	}
+	return strings.ToLower(b.String())
+}
+
+func isOctal(s string) bool {
+	for _, c := range []byte(s) {
+		if c < '0' || c > '7' {
+			return false
+		}
+	}
+	return true
+}
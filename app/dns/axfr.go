@@ -0,0 +1,81 @@
+package dns
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Transfer requests the zone with AXFR over TCP and returns the names of
+// its A, AAAA and CNAME records, without the trailing dot. The server must
+// allow transfers to this host.
+func (c Client) Transfer(zone string) ([]string, error) {
+	if !strings.HasSuffix(zone, ".") {
+		zone += "."
+	}
+	qname, err := dnsmessage.NewName(zone)
+	if err != nil {
+		return nil, err
+	}
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: uint16(rand.Uint32())},
+		Questions: []dnsmessage.Question{{Name: qname, Type: dnsmessage.TypeAXFR, Class: dnsmessage.ClassINET}},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("tcp", c.Address, c.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(c.Timeout))
+	if _, err := conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(packed))), packed...)); err != nil {
+		return nil, err
+	}
+
+	// the transfer starts and ends with the zone's SOA record, and may take
+	// any number of messages in between
+	var names []string
+	soas := 0
+	for soas < 2 {
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, fmt.Errorf("zone transfer of %s: %w", zone, err)
+		}
+		buf := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return nil, fmt.Errorf("zone transfer of %s: %w", zone, err)
+		}
+		var msg dnsmessage.Message
+		if err := msg.Unpack(buf); err != nil {
+			return nil, err
+		}
+		if msg.ID != query.ID {
+			return nil, errors.New("DNS answer does not match the query")
+		}
+		if msg.RCode != dnsmessage.RCodeSuccess {
+			return nil, fmt.Errorf("zone transfer of %s refused: %s", zone, msg.RCode)
+		}
+		if len(msg.Answers) == 0 {
+			return nil, fmt.Errorf("zone transfer of %s: empty answer", zone)
+		}
+		for _, answer := range msg.Answers {
+			switch answer.Header.Type {
+			case dnsmessage.TypeSOA:
+				soas++
+			case dnsmessage.TypeA, dnsmessage.TypeAAAA, dnsmessage.TypeCNAME:
+				names = append(names, strings.TrimSuffix(answer.Header.Name.String(), "."))
+			}
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,75 @@
+package dns
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestTransfer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	header := func(name string, typ dnsmessage.Type) dnsmessage.ResourceHeader {
+		return dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Type: typ, Class: dnsmessage.ClassINET, TTL: 300}
+	}
+	soa := dnsmessage.Resource{Header: header("example.com.", dnsmessage.TypeSOA), Body: &dnsmessage.SOAResource{
+		NS: dnsmessage.MustNewName("ns1.example.com."), MBox: dnsmessage.MustNewName("hostmaster.example.com."), Serial: 1,
+	}}
+	// the transfer is split across messages, as large zones are
+	batches := [][]dnsmessage.Resource{
+		{soa, {Header: header("www.example.com.", dnsmessage.TypeA), Body: &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}}}},
+		{
+			{Header: header("api.example.com.", dnsmessage.TypeAAAA), Body: &dnsmessage.AAAAResource{AAAA: [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}}},
+			{Header: header("shop.example.com.", dnsmessage.TypeCNAME), Body: &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("www.example.com.")}},
+			{Header: header("example.com.", dnsmessage.TypeTXT), Body: &dnsmessage.TXTResource{TXT: []string{"v=spf1 -all"}}},
+		},
+		{soa},
+	}
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var length [2]byte
+		io.ReadFull(conn, length[:])
+		packed := make([]byte, binary.BigEndian.Uint16(length[:]))
+		io.ReadFull(conn, packed)
+		var query dnsmessage.Message
+		if err := query.Unpack(packed); err != nil {
+			t.Error(err)
+			return
+		}
+		for _, answers := range batches {
+			reply := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, Authoritative: true},
+				Questions: query.Questions,
+				Answers:   answers,
+			}
+			out, err := reply.Pack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(out))), out...))
+		}
+	}()
+
+	c := Client{Address: ln.Addr().String(), Timeout: 2 * time.Second}
+	names, err := c.Transfer("example.com")
+	if err != nil {
+		t.Fatalf("Transfer() error = %v", err)
+	}
+	want := []string{"www.example.com", "api.example.com", "shop.example.com"}
+	if !slices.Equal(names, want) {
+		t.Errorf("Transfer() = %v, want %v", names, want)
+	}
+}
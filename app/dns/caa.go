@@ -0,0 +1,140 @@
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const TypeCAA dnsmessage.Type = 257
+
+// CAA is a certification authority authorization record (RFC 8659).
+type CAA struct {
+	Critical bool   `json:"critical,omitempty"`
+	Tag      string `json:"tag"`
+	Value    string `json:"value"`
+}
+
+func parseCAA(data []byte) (CAA, error) {
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return CAA{}, errors.New("malformed CAA record")
+	}
+	tagEnd := 2 + int(data[1])
+	return CAA{
+		Critical: data[0]&0x80 != 0,
+		Tag:      strings.ToLower(string(data[2:tagEnd])),
+		Value:    string(data[tagEnd:]),
+	}, nil
+}
+
+// Issuer returns the CA domain named by an issue or issuewild record,
+// lowercased, or "" when the record forbids issuance.
+func (r CAA) Issuer() string {
+	// parameters after ";" don't change which CA is named
+	issuer, _, _ := strings.Cut(r.Value, ";")
+	return strings.ToLower(strings.TrimSpace(issuer))
+}
+
+// LookupCAA finds the CAA record set relevant to name by climbing towards the
+// root until a domain has records. It returns no records, and no error, when
+// no domain up the tree has any.
+func (c Client) LookupCAA(name string) (records []CAA, domain string, err error) {
+	labels := strings.Split(strings.TrimSuffix(name, "."), ".")
+	for i := range labels {
+		domain = strings.Join(labels[i:], ".")
+		answers, err := c.Query(domain, TypeCAA)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, domain, err
+		}
+		for _, a := range answers {
+			// a CNAME'd name answers with the target's records
+			unknown, ok := a.Body.(*dnsmessage.UnknownResource)
+			if !ok || unknown.Type != TypeCAA {
+				continue
+			}
+			r, err := parseCAA(unknown.Data)
+			if err != nil {
+				return nil, domain, err
+			}
+			records = append(records, r)
+		}
+		if len(records) > 0 {
+			return records, domain, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// Authorizes reports whether the record set lets the CA identified by one of
+// identities issue a certificate; wildcard selects the issuewild property.
+// An empty set authorizes every CA.
+func Authorizes(records []CAA, identities []string, wildcard bool) bool {
+	tag := "issue"
+	if wildcard {
+		for _, r := range records {
+			if r.Tag == "issuewild" {
+				tag = "issuewild"
+				break
+			}
+		}
+	}
+	relevant := false
+	for _, r := range records {
+		if r.Tag != tag {
+			continue
+		}
+		relevant = true
+		issuer := r.Issuer()
+		for _, id := range identities {
+			if issuer != "" && issuer == strings.ToLower(id) {
+				return true
+			}
+		}
+	}
+	return !relevant
+}
+
+// knownTags are the CAA properties CAs understand (RFC 8659, RFC 8657,
+// RFC 9495 and the CA/Browser Forum's contact properties).
+var knownTags = []string{"issue", "issuewild", "iodef", "issuemail", "issuevmc", "contactemail", "contactphone"}
+
+// Misconfigurations describes what is wrong with the record set: records no
+// CA can make sense of, or critical ones it doesn't know, which forbid all
+// issuance.
+func Misconfigurations(records []CAA) []string {
+	var problems []string
+	for _, r := range records {
+		switch {
+		case r.Critical && !slices.Contains(knownTags, r.Tag):
+			problems = append(problems, fmt.Sprintf("unknown critical property %q forbids all issuance", r.Tag))
+		case r.Tag == "issue" || r.Tag == "issuewild":
+			if issuer := r.Issuer(); issuer != "" && !validDomain(issuer) {
+				problems = append(problems, fmt.Sprintf("%s names %q, which isn't a domain", r.Tag, issuer))
+			}
+		case r.Tag == "iodef":
+			u, err := url.Parse(r.Value)
+			if err != nil || u.Scheme != "mailto" && u.Scheme != "http" && u.Scheme != "https" {
+				problems = append(problems, fmt.Sprintf("iodef %q isn't a mailto, http or https URL", r.Value))
+			}
+		}
+	}
+	return problems
+}
+
+func validDomain(name string) bool {
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, c := range label {
+			if !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+// Package dns sends queries straight to a DNS server, for record types the
+// standard library resolver can't look up or the details, like TTLs, it
+// hides.
+package dns
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+var ErrNotFound = errors.New("no such domain")
+
+// Client queries the recursive resolver listening on Address, a host:port.
+type Client struct {
+	Address string
+	Timeout time.Duration
+}
+
+// Query returns the answer section for name and type. It retries over TCP
+// when the UDP answer was truncated.
+func (c Client) Query(name string, qtype dnsmessage.Type) ([]dnsmessage.Resource, error) {
+	answer, err := c.query(name, qtype)
+	if err != nil {
+		return nil, err
+	}
+	return answer.Answers, nil
+}
+
+func (c Client) query(name string, qtype dnsmessage.Type) (*dnsmessage.Message, error) {
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	qname, err := dnsmessage.NewName(name)
+	if err != nil {
+		return nil, err
+	}
+	query := dnsmessage.Message{
+		// the AD bit asks a validating resolver to say whether it
+		// authenticated the answer with DNSSEC
+		Header:    dnsmessage.Header{ID: uint16(rand.Uint32()), RecursionDesired: true, AuthenticData: true},
+		Questions: []dnsmessage.Question{{Name: qname, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	// advertise a larger UDP buffer so big answers rarely need TCP
+	var opt dnsmessage.ResourceHeader
+	if err := opt.SetEDNS0(4096, dnsmessage.RCodeSuccess, false); err != nil {
+		return nil, err
+	}
+	query.Additionals = []dnsmessage.Resource{{Header: opt, Body: &dnsmessage.OPTResource{}}}
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := c.exchange("udp", packed)
+	if err == nil && answer.Truncated {
+		answer, err = c.exchange("tcp", packed)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if answer.ID != query.ID {
+		return nil, errors.New("DNS answer does not match the query")
+	}
+	switch answer.RCode {
+	case dnsmessage.RCodeSuccess:
+		return answer, nil
+	case dnsmessage.RCodeNameError:
+		return nil, ErrNotFound
+	}
+	return nil, fmt.Errorf("DNS query for %s %s failed: %s", name, qtype, answer.RCode)
+}
+
+func (c Client) exchange(network string, packed []byte) (*dnsmessage.Message, error) {
+	conn, err := net.DialTimeout(network, c.Address, c.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(c.Timeout))
+
+	var buf []byte
+	if network == "tcp" {
+		// TCP messages carry a two byte length prefix
+		if _, err := conn.Write(binary.BigEndian.AppendUint16(nil, uint16(len(packed)))); err != nil {
+			return nil, err
+		}
+		if _, err := conn.Write(packed); err != nil {
+			return nil, err
+		}
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, err
+		}
+		buf = make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := conn.Write(packed); err != nil {
+			return nil, err
+		}
+		buf = make([]byte, 4096)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		buf = buf[:n]
+	}
+
+	var answer dnsmessage.Message
+	if err := answer.Unpack(buf); err != nil {
+		return nil, err
+	}
+	return &answer, nil
+}
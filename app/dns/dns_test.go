@@ -0,0 +1,300 @@
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// listenUDPAndTCP listens on the same ephemeral port over UDP and TCP. The
+// port TCP is given may be taken for UDP, so it tries a few ports.
+func listenUDPAndTCP(t *testing.T) (net.PacketConn, net.Listener) {
+	t.Helper()
+	var err error
+	for range 20 {
+		var ln net.Listener
+		ln, err = net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pc net.PacketConn
+		if pc, err = net.ListenPacket("udp", ln.Addr().String()); err == nil {
+			return pc, ln
+		}
+		ln.Close()
+	}
+	t.Fatal(err)
+	return nil, nil
+}
+
+// fakeServer answers queries over UDP and TCP from zone, keyed by name and
+// type. Names missing from the zone get NXDOMAIN. Answers over UDP are
+// truncated when truncate is set, forcing a retry over TCP.
+func fakeServer(t *testing.T, zone map[string][]dnsmessage.Resource, truncate bool) Client {
+	t.Helper()
+	pc, ln := listenUDPAndTCP(t)
+	t.Cleanup(func() {
+		pc.Close()
+		ln.Close()
+	})
+
+	answer := func(packed []byte, udp bool) []byte {
+		var query dnsmessage.Message
+		if err := query.Unpack(packed); err != nil {
+			t.Error(err)
+			return nil
+		}
+		q := query.Questions[0]
+		reply := dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: query.ID, Response: true, RecursionAvailable: true},
+			Questions: query.Questions,
+		}
+		records, ok := zone[strings.ToLower(q.Name.String())]
+		if !ok {
+			reply.RCode = dnsmessage.RCodeNameError
+		}
+		for _, r := range records {
+			if r.Header.Type == q.Type || r.Header.Type == dnsmessage.TypeCNAME {
+				reply.Answers = append(reply.Answers, r)
+			}
+		}
+		if udp && truncate {
+			reply.Truncated = true
+			reply.Answers = nil
+		}
+		out, err := reply.Pack()
+		if err != nil {
+			t.Error(err)
+		}
+		return out
+	}
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			pc.WriteTo(answer(buf[:n], true), addr)
+		}
+	}()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			var length [2]byte
+			if _, err := io.ReadFull(conn, length[:]); err == nil {
+				packed := make([]byte, binary.BigEndian.Uint16(length[:]))
+				io.ReadFull(conn, packed)
+				out := answer(packed, false)
+				conn.Write(binary.BigEndian.AppendUint16(nil, uint16(len(out))))
+				conn.Write(out)
+			}
+			conn.Close()
+		}
+	}()
+	return Client{Address: pc.LocalAddr().String(), Timeout: 2 * time.Second}
+}
+
+func caaRecord(name, tag, value string) dnsmessage.Resource {
+	data := append([]byte{0, byte(len(tag))}, tag...)
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Type: TypeCAA, Class: dnsmessage.ClassINET, TTL: 300},
+		Body:   &dnsmessage.UnknownResource{Type: TypeCAA, Data: append(data, value...)},
+	}
+}
+
+func TestLookupCAA(t *testing.T) {
+	zone := map[string][]dnsmessage.Resource{
+		"example.com.":          {caaRecord("example.com.", "issue", "letsencrypt.org")},
+		"www.example.com.":      {},
+		"shop.example.com.":     {caaRecord("shop.example.com.", "issue", "digicert.com; cansignhttpexchanges=yes")},
+		"unrestricted.example.": {},
+	}
+
+	tests := []struct {
+		name       string
+		lookup     string
+		truncate   bool
+		wantDomain string
+		wantValues []string
+	}{
+		{name: "own records", lookup: "shop.example.com", wantDomain: "shop.example.com", wantValues: []string{"digicert.com; cansignhttpexchanges=yes"}},
+		{name: "inherited from parent", lookup: "www.example.com", wantDomain: "example.com", wantValues: []string{"letsencrypt.org"}},
+		{name: "climbs past missing names", lookup: "a.b.example.com", wantDomain: "example.com", wantValues: []string{"letsencrypt.org"}},
+		{name: "no records anywhere", lookup: "unrestricted.example"},
+		{name: "retries truncated answers over TCP", lookup: "shop.example.com", truncate: true, wantDomain: "shop.example.com", wantValues: []string{"digicert.com; cansignhttpexchanges=yes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fakeServer(t, zone, tt.truncate)
+			records, domain, err := c.LookupCAA(tt.lookup)
+			if err != nil {
+				t.Fatalf("LookupCAA() error = %v", err)
+			}
+			if domain != tt.wantDomain {
+				t.Errorf("LookupCAA() domain = %q, want %q", domain, tt.wantDomain)
+			}
+			if len(records) != len(tt.wantValues) {
+				t.Fatalf("LookupCAA() = %+v, want values %v", records, tt.wantValues)
+			}
+			for i, r := range records {
+				if r.Tag != "issue" || r.Value != tt.wantValues[i] {
+					t.Errorf("LookupCAA()[%d] = %+v, want issue %q", i, r, tt.wantValues[i])
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorizes(t *testing.T) {
+	letsencrypt := CAA{Tag: "issue", Value: "letsencrypt.org"}
+	digicertWild := CAA{Tag: "issuewild", Value: "digicert.com"}
+	noWildcards := CAA{Tag: "issuewild", Value: ";"}
+	iodef := CAA{Tag: "iodef", Value: "mailto:security@example.com"}
+
+	tests := []struct {
+		name       string
+		records    []CAA
+		identities []string
+		wildcard   bool
+		want       bool
+	}{
+		{name: "no records", identities: []string{"digicert.com"}, want: true},
+		{name: "authorized", records: []CAA{letsencrypt}, identities: []string{"letsencrypt.org"}, want: true},
+		{name: "case insensitive", records: []CAA{letsencrypt}, identities: []string{"LetsEncrypt.org"}, want: true},
+		{name: "not authorized", records: []CAA{letsencrypt}, identities: []string{"digicert.com"}, want: false},
+		{name: "unknown CA", records: []CAA{letsencrypt}, want: false},
+		{name: "only iodef", records: []CAA{iodef}, identities: []string{"digicert.com"}, want: true},
+		{name: "wildcard uses issuewild", records: []CAA{letsencrypt, digicertWild}, identities: []string{"digicert.com"}, wildcard: true, want: true},
+		{name: "issuewild ignored for names", records: []CAA{letsencrypt, digicertWild}, identities: []string{"digicert.com"}, want: false},
+		{name: "wildcard falls back to issue", records: []CAA{letsencrypt}, identities: []string{"letsencrypt.org"}, wildcard: true, want: true},
+		{name: "wildcards forbidden", records: []CAA{letsencrypt, noWildcards}, identities: []string{"letsencrypt.org"}, wildcard: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Authorizes(tt.records, tt.identities, tt.wildcard); got != tt.want {
+				t.Errorf("Authorizes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func addressRecord(name string, ttl uint32, ip string) dnsmessage.Resource {
+	header := dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Class: dnsmessage.ClassINET, TTL: ttl}
+	if v4 := net.ParseIP(ip).To4(); v4 != nil {
+		header.Type = dnsmessage.TypeA
+		return dnsmessage.Resource{Header: header, Body: &dnsmessage.AResource{A: [4]byte(v4)}}
+	}
+	header.Type = dnsmessage.TypeAAAA
+	return dnsmessage.Resource{Header: header, Body: &dnsmessage.AAAAResource{AAAA: [16]byte(net.ParseIP(ip))}}
+}
+
+func cnameRecord(name string, ttl uint32, target string) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Type: dnsmessage.TypeCNAME, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName(target)},
+	}
+}
+
+func TestTTLRecorder(t *testing.T) {
+	zone := map[string][]dnsmessage.Resource{
+		"cdn.example.com.": {
+			addressRecord("cdn.example.com.", 60, "192.0.2.1"),
+			addressRecord("cdn.example.com.", 20, "2001:db8::1"),
+		},
+		"static.example.com.": {addressRecord("static.example.com.", 3600, "192.0.2.2")},
+		"www.example.com.": {
+			cnameRecord("www.example.com.", 300, "cdn.example.net."),
+			cnameRecord("cdn.example.net.", 120, "Edge.Provider.net."),
+			addressRecord("edge.provider.net.", 600, "192.0.2.3"),
+		},
+	}
+	c := fakeServer(t, zone, false)
+	recorder := NewTTLRecorder()
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, network, c.Address)
+			if err != nil {
+				return nil, err
+			}
+			return recorder.Wrap(conn), nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		want       time.Duration
+		wantCNAMEs []string
+		wantErr    bool
+	}{
+		{name: "cdn.example.com", want: 20 * time.Second},
+		{name: "static.example.com", want: time.Hour},
+		{name: "www.example.com", want: 2 * time.Minute, wantCNAMEs: []string{"cdn.example.net", "edge.provider.net"}},
+		{name: "missing.example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolver.LookupIPAddr(context.Background(), tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LookupIPAddr() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			ttl, ok := recorder.TTL(tt.name)
+			if ok != !tt.wantErr || ttl != tt.want {
+				t.Errorf("TTL() = %v, %v, want %v", ttl, ok, tt.want)
+			}
+			if cnames := recorder.CNAMEs(tt.name); !slices.Equal(cnames, tt.wantCNAMEs) {
+				t.Errorf("CNAMEs() = %v, want %v", cnames, tt.wantCNAMEs)
+			}
+			recorder.Forget(tt.name)
+			if _, ok := recorder.TTL(tt.name); ok {
+				t.Error("TTL() still recorded after Forget()")
+			}
+			if cnames := recorder.CNAMEs(tt.name); len(cnames) > 0 {
+				t.Errorf("CNAMEs() = %v after Forget(), want none", cnames)
+			}
+		})
+	}
+}
+
+func TestMisconfigurations(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []CAA
+		want    []string
+	}{
+		{name: "fine", records: []CAA{
+			{Tag: "issue", Value: "letsencrypt.org; validationmethods=dns-01"},
+			{Tag: "issuewild", Value: ";"},
+			{Tag: "iodef", Value: "mailto:security@example.com"},
+			{Critical: true, Tag: "issue", Value: "digicert.com"},
+		}},
+		{name: "unknown non-critical property", records: []CAA{{Tag: "future", Value: "x"}}},
+		{name: "unknown critical property", records: []CAA{{Critical: true, Tag: "future", Value: "x"}}, want: []string{`unknown critical property "future" forbids all issuance`}},
+		{name: "not a domain", records: []CAA{{Tag: "issue", Value: "Let's Encrypt"}}, want: []string{`issue names "let's encrypt", which isn't a domain`}},
+		{name: "bad iodef", records: []CAA{{Tag: "iodef", Value: "security@example.com"}}, want: []string{`iodef "security@example.com" isn't a mailto, http or https URL`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Misconfigurations(tt.records); !slices.Equal(got, tt.want) {
+				t.Errorf("Misconfigurations() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package dns
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// switchMargin keeps the active resolver until another is clearly faster,
+// so noise in the measurements doesn't make the choice flap.
+const switchMargin = 0.8
+
+// Health summarizes a resolver's recent probes.
+type Health struct {
+	FailureRate float64
+	// Latency is the mean of the successful probes.
+	Latency time.Duration
+}
+
+// better reports whether h is healthier than other by a clear margin.
+func (h Health) better(other Health) bool {
+	if h.FailureRate != other.FailureRate {
+		return h.FailureRate < other.FailureRate
+	}
+	return float64(h.Latency) < switchMargin*float64(other.Latency)
+}
+
+type probe struct {
+	latency time.Duration
+	failed  bool
+}
+
+// Selector benchmarks several resolvers and picks the healthiest. The first
+// resolver is active until another does better.
+type Selector struct {
+	clients []Client
+	window  int
+	probes  [][]probe
+	active  int
+}
+
+// NewSelector judges each resolver by its last window probes.
+func NewSelector(clients []Client, window int) *Selector {
+	return &Selector{
+		clients: clients,
+		window:  window,
+		probes:  make([][]probe, len(clients)),
+	}
+}
+
+// Benchmark asks every resolver for the root name servers and reports
+// whether the active resolver changed.
+func (s *Selector) Benchmark() bool {
+	var wg sync.WaitGroup
+	results := make([]probe, len(s.clients))
+	for i, c := range s.clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			_, err := c.Query(".", dnsmessage.TypeNS)
+			// any answer at all shows the resolver is up
+			results[i] = probe{latency: time.Since(start), failed: err != nil && !errors.Is(err, ErrNotFound)}
+		}()
+	}
+	wg.Wait()
+	for i, p := range results {
+		s.observe(i, p)
+	}
+	return s.choose()
+}
+
+func (s *Selector) observe(i int, p probe) {
+	s.probes[i] = append(s.probes[i], p)
+	if len(s.probes[i]) > s.window {
+		s.probes[i] = s.probes[i][1:]
+	}
+}
+
+func (s *Selector) choose() bool {
+	best := s.active
+	for i := range s.clients {
+		if s.Health(i).better(s.Health(best)) {
+			best = i
+		}
+	}
+	changed := best != s.active
+	s.active = best
+	return changed
+}
+
+// Active returns the index of the resolver to use.
+func (s *Selector) Active() int {
+	return s.active
+}
+
+// Health returns the recent record of resolver i.
+func (s *Selector) Health(i int) Health {
+	var h Health
+	var failed, succeeded int
+	var total time.Duration
+	for _, p := range s.probes[i] {
+		if p.failed {
+			failed++
+			continue
+		}
+		succeeded++
+		total += p.latency
+	}
+	if len(s.probes[i]) > 0 {
+		h.FailureRate = float64(failed) / float64(len(s.probes[i]))
+	}
+	if succeeded > 0 {
+		h.Latency = total / time.Duration(succeeded)
+	}
+	return h
+}
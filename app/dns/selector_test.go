@@ -0,0 +1,65 @@
+package dns
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSelector_choose(t *testing.T) {
+	ok := func(ms int) probe { return probe{latency: time.Duration(ms) * time.Millisecond} }
+	failed := probe{failed: true}
+
+	tests := []struct {
+		name   string
+		probes [][]probe
+		want   int
+	}{
+		{name: "first resolver by default", probes: [][]probe{{ok(20)}, {ok(20)}}, want: 0},
+		{name: "fewer failures win", probes: [][]probe{{ok(5), failed}, {ok(50), ok(50)}}, want: 1},
+		{name: "clearly faster wins", probes: [][]probe{{ok(50)}, {ok(10)}}, want: 1},
+		{name: "slightly faster doesn't", probes: [][]probe{{ok(50)}, {ok(45)}}, want: 0},
+		{name: "only the window counts", probes: [][]probe{{failed, failed, ok(10), ok(10)}, {ok(50), ok(50)}}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSelector(make([]Client, len(tt.probes)), 2)
+			for i, probes := range tt.probes {
+				for _, p := range probes {
+					s.observe(i, p)
+				}
+			}
+			changed := s.choose()
+			if s.Active() != tt.want {
+				t.Errorf("Active() = %d, want %d", s.Active(), tt.want)
+			}
+			if changed != (tt.want != 0) {
+				t.Errorf("choose() = %v, want %v", changed, tt.want != 0)
+			}
+		})
+	}
+}
+
+func TestSelector_Benchmark(t *testing.T) {
+	// nothing listens on a port that was just closed
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead := Client{Address: pc.LocalAddr().String(), Timeout: 100 * time.Millisecond}
+	pc.Close()
+	// the fake server's NXDOMAIN answers still count as healthy
+	live := fakeServer(t, nil, false)
+
+	s := NewSelector([]Client{dead, live}, 5)
+	if !s.Benchmark() {
+		t.Error("Benchmark() = false, want a switch away from the dead resolver")
+	}
+	if s.Active() != 1 {
+		t.Errorf("Active() = %d, want 1", s.Active())
+	}
+	if h := s.Health(0); h.FailureRate != 1 {
+		t.Errorf("Health(0).FailureRate = %v, want 1", h.FailureRate)
+	}
+}
@@ -0,0 +1,83 @@
+package dns
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"errors"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const TypeTLSA dnsmessage.Type = 52
+
+// TLSA is a TLS certificate association record (RFC 6698): Usage says which
+// certificate of the chain it names, Selector whether it covers the whole
+// certificate or only its public key, and MatchingType how Data is hashed.
+type TLSA struct {
+	Usage        uint8  `json:"usage"`
+	Selector     uint8  `json:"selector"`
+	MatchingType uint8  `json:"matchingType"`
+	Data         []byte `json:"data"`
+}
+
+func parseTLSA(data []byte) (TLSA, error) {
+	if len(data) < 4 {
+		return TLSA{}, errors.New("malformed TLSA record")
+	}
+	return TLSA{Usage: data[0], Selector: data[1], MatchingType: data[2], Data: data[3:]}, nil
+}
+
+// LookupTLSA returns the TLSA records of the service on port at host, over
+// TCP or, for network "udp", over UDP, and whether the resolver
+// authenticated them with DNSSEC. It returns no records, and no error, when
+// there are none.
+func (c Client) LookupTLSA(host, port, network string) (records []TLSA, authenticated bool, err error) {
+	answer, err := c.query("_"+port+"._"+network+"."+host, TypeTLSA)
+	if errors.Is(err, ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	for _, a := range answer.Answers {
+		// a CNAME'd name answers with the target's records
+		unknown, ok := a.Body.(*dnsmessage.UnknownResource)
+		if !ok || unknown.Type != TypeTLSA {
+			continue
+		}
+		r, err := parseTLSA(unknown.Data)
+		if err != nil {
+			return nil, false, err
+		}
+		records = append(records, r)
+	}
+	return records, answer.AuthenticData, nil
+}
+
+// Matches reports whether cert is the certificate r associates, leaving
+// aside which certificate of the chain its usage calls for. Records with a
+// selector or matching type from outside RFC 6698 match nothing.
+func (r TLSA) Matches(cert *x509.Certificate) bool {
+	var selected []byte
+	switch r.Selector {
+	case 0:
+		selected = cert.Raw
+	case 1:
+		selected = cert.RawSubjectPublicKeyInfo
+	default:
+		return false
+	}
+	switch r.MatchingType {
+	case 0:
+		return bytes.Equal(selected, r.Data)
+	case 1:
+		hash := sha256.Sum256(selected)
+		return bytes.Equal(hash[:], r.Data)
+	case 2:
+		hash := sha512.Sum512(selected)
+		return bytes.Equal(hash[:], r.Data)
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package dns
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func tlsaRecord(name string, usage, selector, matchingType uint8, data []byte) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Type: TypeTLSA, Class: dnsmessage.ClassINET, TTL: 300},
+		Body:   &dnsmessage.UnknownResource{Type: TypeTLSA, Data: append([]byte{usage, selector, matchingType}, data...)},
+	}
+}
+
+func TestLookupTLSA(t *testing.T) {
+	zone := map[string][]dnsmessage.Resource{
+		"_25._tcp.mail.example.com.": {
+			tlsaRecord("_25._tcp.mail.example.com.", 3, 1, 1, []byte{0xab, 0xcd}),
+			tlsaRecord("_25._tcp.mail.example.com.", 2, 0, 1, []byte{0x01}),
+		},
+		"_443._tcp.www.example.com.": {
+			cnameRecord("_443._tcp.www.example.com.", 300, "_dane.example.net."),
+			tlsaRecord("_dane.example.net.", 3, 1, 1, []byte{0xef}),
+		},
+		"_443._udp.quic.example.com.": {tlsaRecord("_443._udp.quic.example.com.", 3, 0, 0, []byte{0x02})},
+	}
+	c := fakeServer(t, zone, false)
+
+	tests := []struct {
+		name    string
+		host    string
+		port    string
+		network string
+		want    []TLSA
+	}{
+		{name: "records", host: "mail.example.com", port: "25", network: "tcp", want: []TLSA{
+			{Usage: 3, Selector: 1, MatchingType: 1, Data: []byte{0xab, 0xcd}},
+			{Usage: 2, Selector: 0, MatchingType: 1, Data: []byte{0x01}},
+		}},
+		{name: "through a CNAME", host: "www.example.com", port: "443", network: "tcp", want: []TLSA{{Usage: 3, Selector: 1, MatchingType: 1, Data: []byte{0xef}}}},
+		{name: "udp", host: "quic.example.com", port: "443", network: "udp", want: []TLSA{{Usage: 3, Data: []byte{0x02}}}},
+		{name: "other port", host: "mail.example.com", port: "587", network: "tcp"},
+		{name: "none", host: "www.example.org", port: "443", network: "tcp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, authenticated, err := c.LookupTLSA(tt.host, tt.port, tt.network)
+			if err != nil {
+				t.Fatalf("LookupTLSA() error = %v", err)
+			}
+			if authenticated {
+				t.Error("LookupTLSA() authenticated, but the server doesn't validate")
+			}
+			if len(records) != len(tt.want) {
+				t.Fatalf("LookupTLSA() = %+v, want %+v", records, tt.want)
+			}
+			for i, r := range records {
+				w := tt.want[i]
+				if r.Usage != w.Usage || r.Selector != w.Selector || r.MatchingType != w.MatchingType || string(r.Data) != string(w.Data) {
+					t.Errorf("LookupTLSA()[%d] = %+v, want %+v", i, r, w)
+				}
+			}
+		})
+	}
+}
+
+func TestTLSAMatches(t *testing.T) {
+	cert := &x509.Certificate{Raw: []byte("certificate"), RawSubjectPublicKeyInfo: []byte("public key")}
+	certHash := sha256.Sum256(cert.Raw)
+	keyHash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	keyHash512 := sha512.Sum512(cert.RawSubjectPublicKeyInfo)
+
+	tests := []struct {
+		name   string
+		record TLSA
+		want   bool
+	}{
+		{name: "full certificate", record: TLSA{Selector: 0, MatchingType: 0, Data: cert.Raw}, want: true},
+		{name: "certificate sha256", record: TLSA{Selector: 0, MatchingType: 1, Data: certHash[:]}, want: true},
+		{name: "key sha256", record: TLSA{Selector: 1, MatchingType: 1, Data: keyHash[:]}, want: true},
+		{name: "key sha512", record: TLSA{Selector: 1, MatchingType: 2, Data: keyHash512[:]}, want: true},
+		{name: "wrong selector", record: TLSA{Selector: 0, MatchingType: 1, Data: keyHash[:]}, want: false},
+		{name: "wrong hash", record: TLSA{Selector: 1, MatchingType: 2, Data: keyHash[:]}, want: false},
+		{name: "unknown selector", record: TLSA{Selector: 2, MatchingType: 0, Data: cert.Raw}, want: false},
+		{name: "unknown matching type", record: TLSA{Selector: 0, MatchingType: 3, Data: cert.Raw}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.record.Matches(cert); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
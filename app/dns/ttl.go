@@ -0,0 +1,117 @@
+package dns
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// TTLRecorder remembers the TTLs of the address records in DNS answers read
+// through the connections it wraps, and the CNAMEs they were reached
+// through. net.Resolver reports neither, so wrap the connections its Dial
+// function returns.
+type TTLRecorder struct {
+	mu   sync.Mutex
+	ttls map[string]time.Duration
+	// the CNAME records answered for each name, alias to target
+	cnames map[string]map[string]string
+}
+
+func NewTTLRecorder() *TTLRecorder {
+	return &TTLRecorder{
+		ttls:   make(map[string]time.Duration),
+		cnames: make(map[string]map[string]string),
+	}
+}
+
+// Wrap records the answers read from conn. UDP connections stay
+// net.PacketConns, which net.Resolver relies on to frame messages. Answers
+// over TCP aren't recorded.
+func (r *TTLRecorder) Wrap(conn net.Conn) net.Conn {
+	if udp, ok := conn.(*net.UDPConn); ok {
+		return &recordingConn{UDPConn: udp, recorder: r}
+	}
+	return conn
+}
+
+// TTL returns the lowest TTL among the A, AAAA and CNAME records answered
+// for name since it was last forgotten.
+func (r *TTLRecorder) TTL(name string) (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ttl, ok := r.ttls[canonical(name)]
+	return ttl, ok
+}
+
+// CNAMEs returns the chain of canonical names answered for name since it
+// was last forgotten, in the order they were followed, e.g.
+// cdn.example.com then edge.provider.net for www.example.com. It's empty
+// when name has address records of its own.
+func (r *TTLRecorder) CNAMEs(name string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	name = canonical(name)
+	aliases := r.cnames[name]
+	var chain []string
+	for target, ok := aliases[name]; ok && len(chain) < len(aliases); target, ok = aliases[target] {
+		chain = append(chain, target)
+	}
+	return chain
+}
+
+// Forget drops the TTL and CNAMEs recorded for name, before resolving it
+// again.
+func (r *TTLRecorder) Forget(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.ttls, canonical(name))
+	delete(r.cnames, canonical(name))
+}
+
+func (r *TTLRecorder) record(packed []byte) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(packed); err != nil || !msg.Response || msg.RCode != dnsmessage.RCodeSuccess || len(msg.Questions) == 0 {
+		return
+	}
+	name := canonical(msg.Questions[0].Name.String())
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, answer := range msg.Answers {
+		switch answer.Header.Type {
+		case dnsmessage.TypeA, dnsmessage.TypeAAAA, dnsmessage.TypeCNAME:
+		default:
+			continue
+		}
+		if cname, ok := answer.Body.(*dnsmessage.CNAMEResource); ok {
+			if r.cnames[name] == nil {
+				r.cnames[name] = make(map[string]string)
+			}
+			r.cnames[name][canonical(answer.Header.Name.String())] = canonical(cname.CNAME.String())
+		}
+		ttl := time.Duration(answer.Header.TTL) * time.Second
+		// the A and AAAA answers for a name arrive separately
+		if old, ok := r.ttls[name]; !ok || ttl < old {
+			r.ttls[name] = ttl
+		}
+	}
+}
+
+func canonical(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+type recordingConn struct {
+	*net.UDPConn
+	recorder *TTLRecorder
+}
+
+func (c *recordingConn) Read(b []byte) (int, error) {
+	n, err := c.UDPConn.Read(b)
+	if err == nil {
+		c.recorder.record(b[:n])
+	}
+	return n, err
+}
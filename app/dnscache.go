@@ -0,0 +1,46 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"time"
+)
+
+// resolution is a hostname's addresses and when they go stale, or a zero
+// time if the TTL wasn't seen.
+type resolution struct {
+	nameAddressMap
+	expires time.Time
+}
+
+// resolutionCache keeps successful resolutions between scan cycles so tight
+// intervals don't repeat identical queries. It's only used from the scan loop.
+type resolutionCache map[cfg.Hostname]resolution
+
+var dnsCache = make(resolutionCache)
+
+// lookup returns the cached resolutions still fresh at now, and the
+// hostnames that need resolving.
+func (c resolutionCache) lookup(hostnames []cfg.Hostname, now time.Time) (cached []resolution, missing []cfg.Hostname) {
+	for _, hostname := range hostnames {
+		if r, ok := c[hostname]; ok && now.Before(r.expires) {
+			r.Lookup = 0
+			cached = append(cached, r)
+			continue
+		}
+		delete(c, hostname)
+		missing = append(missing, hostname)
+	}
+	return cached, missing
+}
+
+// store caches mapping for its TTL clamped to the configured bounds. An
+// unknown TTL counts as the floor.
+func (c resolutionCache) store(mapping nameAddressMap, ttl time.Duration, known bool, bounds cfg.DNSCache, now time.Time) resolution {
+	if !known {
+		ttl = time.Duration(bounds.MinTTL)
+	}
+	ttl = min(max(ttl, time.Duration(bounds.MinTTL)), time.Duration(bounds.MaxTTL))
+	r := resolution{nameAddressMap: mapping, expires: now.Add(ttl)}
+	c[mapping.Hostname] = r
+	return r
+}
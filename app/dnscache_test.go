@@ -0,0 +1,45 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestResolutionCache(t *testing.T) {
+	now := time.Now()
+	bounds := cfg.DNSCache{Enabled: true, MinTTL: cfg.Duration(30 * time.Second), MaxTTL: cfg.Duration(time.Hour)}
+
+	tests := []struct {
+		name  string
+		ttl   time.Duration
+		known bool
+		want  time.Duration
+	}{
+		{name: "within bounds", ttl: 5 * time.Minute, known: true, want: 5 * time.Minute},
+		{name: "raised to the floor", ttl: 5 * time.Second, known: true, want: 30 * time.Second},
+		{name: "capped at the ceiling", ttl: 24 * time.Hour, known: true, want: time.Hour},
+		{name: "unknown TTL uses the floor", want: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := make(resolutionCache)
+			mapping := nameAddressMap{Hostname: "example.com", IPAddresses: []net.IP{net.ParseIP("192.0.2.1")}}
+			r := c.store(mapping, tt.ttl, tt.known, bounds, now)
+			if got := r.expires.Sub(now); got != tt.want {
+				t.Errorf("store() cached for %v, want %v", got, tt.want)
+			}
+
+			cached, missing := c.lookup([]cfg.Hostname{"example.com", "other.example.com"}, now.Add(tt.want-time.Second))
+			if len(cached) != 1 || len(missing) != 1 || missing[0] != "other.example.com" {
+				t.Errorf("lookup() before expiry = %v, %v", cached, missing)
+			}
+			cached, missing = c.lookup([]cfg.Hostname{"example.com"}, now.Add(tt.want))
+			if len(cached) != 0 || len(missing) != 1 {
+				t.Errorf("lookup() after expiry = %v, %v", cached, missing)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"fmt"
+	"time"
+)
+
+// escalationStep returns the tightest step a certificate expiring at
+// notAfter has reached, or false if it's outside every step.
+func escalationStep(steps []cfg.EscalationStep, notAfter, now time.Time) (cfg.EscalationStep, bool) {
+	var reached cfg.EscalationStep
+	ok := false
+	for _, step := range steps {
+		if notAfter.Sub(now) <= time.Duration(step.Within) {
+			reached, ok = step, true
+		}
+	}
+	return reached, ok
+}
+
+// tenantEscalation returns the escalation of tenant when it has its own,
+// and steps otherwise.
+func tenantEscalation(steps []cfg.EscalationStep, tenants []cfg.Tenant, tenant string) []cfg.EscalationStep {
+	for _, t := range tenants {
+		if t.Name == tenant && len(t.Escalation) > 0 {
+			return t.Escalation
+		}
+	}
+	return steps
+}
+
+// checkExpiry raises an alert for every scanned hostname whose leaf
+// certificate is due to expire, escalating it as expiry gets closer through
+// steps or its tenant's own escalation. Hostnames served by several
+// certificates are judged by the first to expire.
+func checkExpiry(snapshot store.Snapshot, steps []cfg.EscalationStep, tenants []cfg.Tenant, alerts *alert.Manager, now time.Time) {
+	for key, leaf := range earliestLeaves(snapshot) {
+		notAfter := leaf.NotAfter
+		step, ok := escalationStep(tenantEscalation(steps, tenants, key.tenant), notAfter, now)
+		a := alert.Alert{
+			Key:      alert.Key("expiry", key.tenant, key.hostname),
+			Severity: alert.Severity(step.Severity),
+			Route:    step.Route,
+			Tenant:   key.tenant,
+			Labels: map[string]string{
+				"hostname":    key.hostname,
+				"notAfter":    notAfter.Format(time.RFC3339),
+				"issuer":      leaf.Issuer,
+				"fingerprint": leaf.SHA256Fingerprint,
+			},
+			Since: now,
+		}
+		if ok {
+			a.Summary = expirySummary(key.hostname, notAfter, now)
+		}
+		alerts.Set(ok, a)
+	}
+}
+
+func expirySummary(hostname string, notAfter, now time.Time) string {
+	if !notAfter.After(now) {
+		return fmt.Sprintf("%s certificate expired on %s", hostname, notAfter.Format(time.DateOnly))
+	}
+	days := int(notAfter.Sub(now).Hours() / 24)
+	return fmt.Sprintf("%s certificate expires in %d days, on %s", hostname, days, notAfter.Format(time.DateOnly))
+}
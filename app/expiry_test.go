@@ -0,0 +1,84 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestCheckExpiry(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	steps := []cfg.EscalationStep{
+		{Within: cfg.Duration(30 * day), Severity: "warning", Route: "team"},
+		{Within: cfg.Duration(14 * day), Severity: "warning", Route: "manager"},
+		{Within: cfg.Duration(7 * day), Severity: "critical", Route: "page"},
+	}
+
+	tests := []struct {
+		name         string
+		notAfter     []time.Duration
+		wantFiring   bool
+		wantSeverity alert.Severity
+		wantRoute    string
+	}{
+		{name: "far from expiry", notAfter: []time.Duration{90 * day}},
+		{name: "first step", notAfter: []time.Duration{20 * day}, wantFiring: true, wantSeverity: alert.Warning, wantRoute: "team"},
+		{name: "second step", notAfter: []time.Duration{10 * day}, wantFiring: true, wantSeverity: alert.Warning, wantRoute: "manager"},
+		{name: "last step", notAfter: []time.Duration{2 * day}, wantFiring: true, wantSeverity: alert.Critical, wantRoute: "page"},
+		{name: "already expired", notAfter: []time.Duration{-day}, wantFiring: true, wantSeverity: alert.Critical, wantRoute: "page"},
+		{name: "earliest address counts", notAfter: []time.Duration{90 * day, 5 * day}, wantFiring: true, wantSeverity: alert.Critical, wantRoute: "page"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := alert.NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+			var snapshot store.Snapshot
+			for _, d := range tt.notAfter {
+				snapshot.Certificates = append(snapshot.Certificates,
+					store.Certificate{Hostname: "example.com", NotAfter: now.Add(d)},
+					store.Certificate{Hostname: "example.com", Index: 1, NotAfter: now.Add(-day)},
+				)
+			}
+			checkExpiry(snapshot, steps, nil, alerts, now)
+
+			a, firing := alerts.Get("expiry:example.com")
+			if firing != tt.wantFiring {
+				t.Fatalf("firing = %v, want %v", firing, tt.wantFiring)
+			}
+			if firing && (a.Severity != tt.wantSeverity || a.Route != tt.wantRoute) {
+				t.Errorf("alert = %s to %q, want %s to %q", a.Severity, a.Route, tt.wantSeverity, tt.wantRoute)
+			}
+		})
+	}
+}
+
+func TestCheckExpiryTenantEscalation(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	steps := []cfg.EscalationStep{{Within: cfg.Duration(14 * day), Severity: "warning", Route: "team"}}
+	tenants := []cfg.Tenant{
+		{Name: "payments", Escalation: []cfg.EscalationStep{
+			{Within: cfg.Duration(45 * day), Severity: "warning", Route: "team"},
+			{Within: cfg.Duration(21 * day), Severity: "critical", Route: "page"},
+		}},
+		{Name: "web"},
+	}
+	alerts := alert.NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	var snapshot store.Snapshot
+	for _, tenant := range []string{"payments", "web"} {
+		snapshot.Certificates = append(snapshot.Certificates, store.Certificate{Tenant: tenant, Hostname: "example.com", NotAfter: now.Add(20 * day)})
+	}
+	checkExpiry(snapshot, steps, tenants, alerts, now)
+
+	if a, ok := alerts.Get("expiry:payments/example.com"); !ok || a.Severity != alert.Critical {
+		t.Errorf("payments alert = %+v, %v, want critical by its own escalation", a, ok)
+	}
+	if _, ok := alerts.Get("expiry:web/example.com"); ok {
+		t.Error("Expected a tenant without its own escalation to use the global steps")
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"cert-tracker/aws"
+	"cert-tracker/cfg"
+	"cert-tracker/export"
+	"cert-tracker/store"
+	"net/http"
+	"time"
+)
+
+// newExporter uploads scan cycles to the configured bucket, signing with
+// the AWS credentials in the environment.
+func newExporter(config cfg.Export) (*export.Exporter, error) {
+	creds, err := aws.CredentialsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	return &export.Exporter{
+		Store: &export.S3{
+			Client:      &http.Client{Timeout: time.Minute},
+			Credentials: creds,
+			Endpoint:    endpoint,
+			Region:      config.Region,
+			Bucket:      config.Bucket,
+		},
+		Prefix: config.Prefix,
+		Format: config.Format,
+	}, nil
+}
+
+// exportSnapshot uploads snapshot, then deletes the uploads past retention.
+// A failed upload is retried with the next cycle's snapshot, not again.
+func exportSnapshot(e *export.Exporter, config cfg.Export, snapshot store.Snapshot, now time.Time) {
+	key, err := e.Export(snapshot)
+	if err != nil {
+		log.Warn("cannot export scan cycle", "bucket", config.Bucket, "error", err)
+		return
+	}
+	log.Debug("scan cycle exported", "bucket", config.Bucket, "key", key)
+	if config.Retention == 0 {
+		return
+	}
+	removed, err := e.Prune(now.Add(-time.Duration(config.Retention)))
+	if err != nil {
+		log.Warn("cannot prune exported scan cycles", "bucket", config.Bucket, "error", err)
+	}
+	if removed > 0 {
+		log.Info("pruned exported scan cycles", "bucket", config.Bucket, "removed", removed)
+	}
+}
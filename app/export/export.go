@@ -0,0 +1,79 @@
+package export
+
+import (
+	"bytes"
+	"cert-tracker/store"
+	"encoding/json"
+	"path"
+	"strings"
+	"time"
+)
+
+const timeLayout = "20060102T150405Z"
+
+// Exporter uploads each scan cycle's snapshot under Prefix, as one "json"
+// document or as "jsonl", one line per certificate.
+type Exporter struct {
+	Store  *S3
+	Prefix string
+	Format string
+}
+
+// Key is where the snapshot taken at t goes, partitioned by date so query
+// engines can skip the days they don't need.
+func (e *Exporter) Key(t time.Time) string {
+	t = t.UTC()
+	return e.Prefix + "date=" + t.Format(time.DateOnly) + "/" + t.Format(timeLayout) + "." + e.Format
+}
+
+// Export uploads snapshot and returns its key.
+func (e *Exporter) Export(snapshot store.Snapshot) (string, error) {
+	body, contentType, err := encode(snapshot, e.Format)
+	if err != nil {
+		return "", err
+	}
+	key := e.Key(snapshot.Time)
+	return key, e.Store.Put(key, body, contentType)
+}
+
+func encode(snapshot store.Snapshot, format string) ([]byte, string, error) {
+	if format != "jsonl" {
+		data, err := json.Marshal(snapshot)
+		return data, "application/json", err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, c := range snapshot.Certificates {
+		// each line stands alone, so it carries the cycle's time
+		row := struct {
+			Time time.Time `json:"time"`
+			store.Certificate
+		}{snapshot.Time, c}
+		if err := enc.Encode(row); err != nil {
+			return nil, "", err
+		}
+	}
+	return buf.Bytes(), "application/x-ndjson", nil
+}
+
+// Prune deletes the uploads of snapshots taken before cutoff, leaving
+// other objects under Prefix alone, and returns how many it deleted.
+func (e *Exporter) Prune(cutoff time.Time) (int, error) {
+	keys, err := e.Store.List(e.Prefix)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, key := range keys {
+		stamp, _, _ := strings.Cut(path.Base(key), ".")
+		t, err := time.Parse(timeLayout, stamp)
+		if err != nil || !t.Before(cutoff) {
+			continue
+		}
+		if err := e.Store.Delete(key); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,154 @@
+package export
+
+import (
+	"bytes"
+	"cert-tracker/aws"
+	"cert-tracker/store"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 keeps objects in memory and answers path-style requests for the
+// bucket "scans", listing one object per page.
+func fakeS3(t *testing.T) (*S3, map[string][]byte) {
+	t.Helper()
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") || r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+			http.Error(w, "unsigned", http.StatusForbidden)
+			return
+		}
+		key, ok := strings.CutPrefix(r.URL.Path, "/scans/")
+		if !ok {
+			http.Error(w, "no such bucket", http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			objects[key] = body
+		case http.MethodDelete:
+			delete(objects, key)
+		case http.MethodGet:
+			var keys []string
+			for k := range objects {
+				if strings.HasPrefix(k, r.URL.Query().Get("prefix")) && k > r.URL.Query().Get("continuation-token") {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			if len(keys) == 0 {
+				fmt.Fprint(w, `<ListBucketResult><IsTruncated>false</IsTruncated></ListBucketResult>`)
+				return
+			}
+			fmt.Fprintf(w, `<ListBucketResult><Contents><Key>%s</Key></Contents><IsTruncated>%t</IsTruncated><NextContinuationToken>%s</NextContinuationToken></ListBucketResult>`,
+				keys[0], len(keys) > 1, keys[0])
+		}
+	}))
+	t.Cleanup(server.Close)
+	return &S3{
+		Client:      server.Client(),
+		Credentials: aws.Credentials{AccessKeyID: "id", SecretAccessKey: "secret"},
+		Endpoint:    server.URL,
+		Region:      "us-east-1",
+		Bucket:      "scans",
+	}, objects
+}
+
+func TestExporter_Export(t *testing.T) {
+	snapshot := store.Snapshot{
+		Time: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+		Certificates: []store.Certificate{
+			{Hostname: "example.com", SHA256Fingerprint: "aa"},
+			{Hostname: "www.example.com", SHA256Fingerprint: "bb"},
+		},
+	}
+
+	tests := []struct {
+		format    string
+		wantKey   string
+		wantLines int
+	}{
+		{format: "json", wantKey: "cert-tracker/date=2025-06-01/20250601T120000Z.json", wantLines: 1},
+		{format: "jsonl", wantKey: "cert-tracker/date=2025-06-01/20250601T120000Z.jsonl", wantLines: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			s3, objects := fakeS3(t)
+			e := &Exporter{Store: s3, Prefix: "cert-tracker/", Format: tt.format}
+			key, err := e.Export(snapshot)
+			if err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			if key != tt.wantKey {
+				t.Errorf("Export() key = %q, want %q", key, tt.wantKey)
+			}
+			lines := bytes.Split(bytes.TrimSpace(objects[tt.wantKey]), []byte("\n"))
+			if len(lines) != tt.wantLines {
+				t.Fatalf("uploaded %d lines, want %d:\n%s", len(lines), tt.wantLines, objects[tt.wantKey])
+			}
+			for _, line := range lines {
+				var row map[string]any
+				if err := json.Unmarshal(line, &row); err != nil || row["time"] != "2025-06-01T12:00:00Z" {
+					t.Errorf("uploaded %s, want JSON with the snapshot time", line)
+				}
+			}
+		})
+	}
+}
+
+func TestExporter_Prune(t *testing.T) {
+	s3, objects := fakeS3(t)
+	for _, key := range []string{
+		"cert-tracker/date=2025-05-01/20250501T120000Z.json",
+		"cert-tracker/date=2025-05-31/20250531T120000Z.json",
+		"cert-tracker/date=2025-06-01/20250601T120000Z.json",
+		"cert-tracker/README",
+		"other/date=2025-05-01/20250501T120000Z.json",
+	} {
+		objects[key] = []byte("{}")
+	}
+	e := &Exporter{Store: s3, Prefix: "cert-tracker/", Format: "json"}
+
+	removed, err := e.Prune(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Prune() removed %d, want 2", removed)
+	}
+	var kept []string
+	for key := range objects {
+		kept = append(kept, key)
+	}
+	sort.Strings(kept)
+	want := []string{"cert-tracker/README", "cert-tracker/date=2025-06-01/20250601T120000Z.json", "other/date=2025-05-01/20250501T120000Z.json"}
+	if !slices.Equal(kept, want) {
+		t.Errorf("kept %v, want %v", kept, want)
+	}
+}
+
+func TestS3_url(t *testing.T) {
+	s3 := &S3{Endpoint: "https://s3.eu-west-1.amazonaws.com/", Bucket: "scans"}
+	got := s3.url("cert-tracker/date=2025-06-01/20250601T120000Z.json", nil)
+	want := "https://s3.eu-west-1.amazonaws.com/scans/cert-tracker/date%3D2025-06-01/20250601T120000Z.json"
+	if got != want {
+		t.Errorf("url() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,121 @@
+// Package export uploads scan cycles to object storage, for data lakes to
+// pick up.
+package export
+
+import (
+	"bytes"
+	"cert-tracker/aws"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3 talks to an S3-compatible bucket using path-style URLs, which AWS and
+// self-hosted stores alike accept.
+type S3 struct {
+	Client      *http.Client
+	Credentials aws.Credentials
+	// Endpoint is the store's base URL, e.g. https://s3.eu-west-1.amazonaws.com.
+	Endpoint string
+	Region   string
+	Bucket   string
+}
+
+func (s *S3) url(key string, query url.Values) string {
+	u := strings.TrimSuffix(s.Endpoint, "/") + "/" + escape(s.Bucket) + "/"
+	for i, part := range strings.Split(key, "/") {
+		if i > 0 {
+			u += "/"
+		}
+		u += escape(part)
+	}
+	if len(query) > 0 {
+		u += "?" + aws.CanonicalQuery(query)
+	}
+	return u
+}
+
+// escape encodes every byte but the unreserved ones, as SigV4 does, so the
+// path signed is the path sent. url.PathEscape leaves = and + alone.
+func escape(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func (s *S3) do(method, u string, body []byte, contentType string) ([]byte, error) {
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.Credentials.Sign(req, body, s.Region, "s3", time.Now())
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("%s %s: %s: %s", method, s.Bucket, resp.Status, bytes.TrimSpace(data))
+	}
+	return data, nil
+}
+
+// Put uploads body as the object key.
+func (s *S3) Put(key string, body []byte, contentType string) error {
+	_, err := s.do(http.MethodPut, s.url(key, nil), body, contentType)
+	return err
+}
+
+// Delete removes the object key.
+func (s *S3) Delete(key string) error {
+	_, err := s.do(http.MethodDelete, s.url(key, nil), nil, "")
+	return err
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns the keys of the objects under prefix, following pagination.
+func (s *S3) List(prefix string) ([]string, error) {
+	var keys []string
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	for {
+		data, err := s.do(http.MethodGet, s.url("", query), nil, "")
+		if err != nil {
+			return nil, err
+		}
+		var page listBucketResult
+		if err := xml.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+		for _, c := range page.Contents {
+			keys = append(keys, c.Key)
+		}
+		if !page.IsTruncated {
+			return keys, nil
+		}
+		query.Set("continuation-token", page.NextContinuationToken)
+	}
+}
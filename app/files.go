@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fileHostname names a certificate file as a target, so it goes through the
+// same checks and alerts as the hostnames that are scanned.
+func fileHostname(path string) cfg.Hostname {
+	return cfg.Hostname("file://" + path)
+}
+
+// fileCertificates reads the certificates in every file paths name. Files
+// without certificates, such as private keys, are skipped; paths that match
+// nothing or can't be read are returned as failures.
+func fileCertificates(paths []string) ([]store.Certificate, []store.Failure) {
+	var certs []store.Certificate
+	var failures []store.Failure
+	fail := func(path string, err error) {
+		log.Warn("cannot read certificate file", "path", path, "error", err)
+		failures = append(failures, store.Failure{Hostname: fileHostname(path), Error: err.Error()})
+	}
+	seen := make(map[string]bool)
+	for _, pattern := range paths {
+		matches, _ := filepath.Glob(pattern)
+		if len(matches) == 0 {
+			fail(pattern, errors.New("no such file or directory"))
+			continue
+		}
+		var files []string
+		for _, match := range matches {
+			found, err := certificateFiles(match)
+			if err != nil {
+				fail(match, err)
+				continue
+			}
+			files = append(files, found...)
+		}
+		for _, file := range files {
+			if abs, err := filepath.Abs(file); err == nil {
+				file = abs
+			}
+			if seen[file] {
+				continue
+			}
+			seen[file] = true
+			chain, err := readCertificates(file)
+			if err != nil {
+				fail(file, err)
+				continue
+			}
+			if len(chain) == 0 {
+				log.Debug("no certificates in file", "path", file)
+				continue
+			}
+			for i, cert := range chain {
+				certs = append(certs, handle(cert, i, fileHostname(file), nil))
+			}
+		}
+	}
+	return certs, failures
+}
+
+// certificateFiles returns path if it's a file, or else the files in the
+// directory. Hidden entries are left out, which skips the timestamped
+// copies Kubernetes keeps next to the files of a mounted secret.
+func certificateFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		file := filepath.Join(path, entry.Name())
+		// follow symlinks, which mounted secrets are made of
+		if info, err := os.Stat(file); err == nil && info.Mode().IsRegular() {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// readCertificates parses the PEM certificate blocks in a file, or the
+// whole file as DER if it isn't PEM. A file holding something other than
+// certificates yields none.
+func readCertificates(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Contains(data, []byte("-----BEGIN")) {
+		certs, err := x509.ParseCertificates(data)
+		if err != nil {
+			return nil, nil
+		}
+		return certs, nil
+	}
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs, nil
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+}
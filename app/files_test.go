@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileCertificates(t *testing.T) {
+	cert := createTestCertificate(t)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	fullchain := write("certs/fullchain.pem", append(append([]byte{}, certPEM...), certPEM...))
+	der := write("certs/site.der", cert.Raw)
+	write("certs/privkey.pem", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")}))
+	// a mounted Kubernetes secret: tls.crt links into a hidden directory
+	secret := filepath.Join(dir, "secret")
+	write("secret/..2025_06_01/tls.crt", certPEM)
+	if err := os.Symlink("..2025_06_01/tls.crt", filepath.Join(secret, "tls.crt")); err != nil {
+		t.Fatal(err)
+	}
+	broken := write("broken.pem", []byte("-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydGlmaWNhdGU=\n-----END CERTIFICATE-----\n"))
+
+	certs, failures := fileCertificates([]string{
+		filepath.Join(dir, "certs", "*"),
+		fullchain,
+		secret,
+		broken,
+		filepath.Join(dir, "missing", "*.pem"),
+	})
+
+	chains := make(map[string]int)
+	for _, c := range certs {
+		chains[string(c.Hostname)]++
+		if c.IPAddress != nil {
+			t.Errorf("%s has IP address %s, want none", c.Hostname, c.IPAddress)
+		}
+	}
+	want := map[string]int{
+		"file://" + fullchain: 2,
+		"file://" + der:       1,
+		"file://" + filepath.Join(secret, "tls.crt"): 1,
+	}
+	if len(chains) != len(want) {
+		t.Errorf("fileCertificates() read %v, want %v", chains, want)
+	}
+	for hostname, n := range want {
+		if chains[hostname] != n {
+			t.Errorf("fileCertificates() read %d certificates from %s, want %d", chains[hostname], hostname, n)
+		}
+	}
+	if len(failures) != 2 {
+		t.Errorf("fileCertificates() failures = %+v, want the broken file and the unmatched pattern", failures)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+func historyCommand(args []string) int {
+	flags := flag.NewFlagSet("history", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: cert-tracker history [-json] <hostname>")
+		fmt.Fprintln(flags.Output(), "prints the certificates each address of hostname served, and when")
+		flags.PrintDefaults()
+	}
+	asJSON := flags.Bool("json", false, "print the history as JSON")
+	cfg.RegisterConfigFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	hostname, err := cfg.ParseHostname(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	config, err := cfg.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if config.StoreDir == "" {
+		fmt.Fprintln(os.Stderr, "storeDir must be configured to show history")
+		return 1
+	}
+	st, err := openStore(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	history, err := st.History(hostname)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *asJSON {
+		err = json.NewEncoder(os.Stdout).Encode(history)
+	} else {
+		err = printHistory(os.Stdout, history)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func printHistory(w io.Writer, history []store.Served) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TENANT\tIP ADDRESS\tFIRST SEEN\tLAST SEEN\tSERIAL\tEXPIRES\tISSUER")
+	for _, s := range history {
+		tenant := s.Tenant
+		if tenant == "" {
+			tenant = "(default)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			tenant,
+			s.IPAddress,
+			s.FirstSeen.Format(time.RFC3339),
+			s.LastSeen.Format(time.RFC3339),
+			s.SerialNumber,
+			s.NotAfter.Format(time.DateOnly),
+			s.Issuer,
+		)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+func importCommand(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: cert-tracker import [-config file] <inventory.csv>")
+		fmt.Fprintln(flags.Output(), "columns: hostname (required), port, owner, expected expiry; other columns become tags")
+	}
+	cfg.RegisterConfigFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	if err := importInventory(flags.Arg(0)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func importInventory(path string) error {
+	config, err := cfg.Load()
+	if err != nil {
+		return err
+	}
+	if config.StoreDir == "" {
+		return errors.New("storeDir must be configured to import an inventory")
+	}
+	st, err := openStore(config)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	entries, err := store.ParseInventoryCSV(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	inventory, err := st.ImportInventory(entries)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("imported %d entries; inventory has %d entries\n", len(entries), len(inventory))
+	return nil
+}
+
+// targets returns the configured hostnames of every tenant plus any imported
+// from an inventory, with wildcards replaced by the subdomains found under
+// them.
+func targets(config cfg.Params, st *store.Store) []cfg.Hostname {
+	hostnames := slices.Clone(config.Hostnames)
+	for _, tenant := range config.Tenants {
+		for _, hostname := range tenant.Hostnames {
+			if !slices.Contains(hostnames, hostname) {
+				hostnames = append(hostnames, hostname)
+			}
+		}
+	}
+	for _, hostname := range discoveredTargets(config, time.Now()) {
+		if !slices.Contains(hostnames, hostname) {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	if config.StoreDir != "" {
+		inventory, err := st.LoadInventory()
+		if err != nil {
+			log.Warn("cannot load inventory", "error", err)
+		}
+		for _, entry := range inventory {
+			if target := entry.Target(); !slices.Contains(hostnames, target) {
+				hostnames = append(hostnames, target)
+			}
+		}
+	}
+	return expandWildcards(config, hostnames, time.Now())
+}
+
+// tenantsOf maps each hostname to the tenants that monitor it, directly or
+// through a wildcard. Hostnames outside any tenant, including inventory
+// imports, belong to the default tenant "".
+func tenantsOf(config cfg.Params, hostnames []cfg.Hostname) map[cfg.Hostname][]string {
+	tenants := make(map[cfg.Hostname][]string)
+	for _, tenant := range config.Tenants {
+		for _, hostname := range tenant.Hostnames {
+			tenants[hostname] = append(tenants[hostname], tenant.Name)
+		}
+	}
+	for _, hostname := range hostnames {
+		for _, tenant := range config.Tenants {
+			if !slices.Contains(tenants[hostname], tenant.Name) && covered(tenant.Hostnames, hostname) {
+				tenants[hostname] = append(tenants[hostname], tenant.Name)
+			}
+		}
+		if _, ok := tenants[hostname]; !ok || covered(config.Hostnames, hostname) {
+			tenants[hostname] = append(tenants[hostname], "")
+		}
+	}
+	return tenants
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"slices"
+	"testing"
+)
+
+func TestTargets(t *testing.T) {
+	dir := t.TempDir()
+	st, err := store.Open(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = st.ImportInventory([]store.InventoryEntry{
+		{Hostname: "example.com", Port: 443},
+		{Hostname: "api.example.com", Port: 443},
+		{Hostname: "mail.example.com", Port: 8443},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emptyStore, err := store.Open(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config cfg.Params
+		st     *store.Store
+		want   []cfg.Hostname
+	}{
+		{
+			name:   "no store",
+			config: cfg.Params{Hostnames: []cfg.Hostname{"example.com"}},
+			st:     st,
+			want:   []cfg.Hostname{"example.com"},
+		},
+		{
+			name:   "inventory merged without duplicates",
+			config: cfg.Params{Hostnames: []cfg.Hostname{"example.com"}, StoreDir: dir},
+			st:     st,
+			want:   []cfg.Hostname{"example.com", "api.example.com", "mail.example.com:8443"},
+		},
+		{
+			name:   "empty store",
+			config: cfg.Params{Hostnames: []cfg.Hostname{"example.com"}, StoreDir: emptyStore.Dir()},
+			st:     emptyStore,
+			want:   []cfg.Hostname{"example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := targets(tt.config, tt.st)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("targets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenantsOf(t *testing.T) {
+	config := cfg.Params{
+		Hostnames: []cfg.Hostname{"shared.example.com", "ops.example.com"},
+		Tenants: []cfg.Tenant{
+			{Name: "payments", Hostnames: []cfg.Hostname{"pay.example.com", "shared.example.com"}},
+			{Name: "web", Hostnames: []cfg.Hostname{"www.example.com", "shared.example.com"}},
+		},
+	}
+	hostnames := targets(config, nil)
+	want := []cfg.Hostname{"shared.example.com", "ops.example.com", "pay.example.com", "www.example.com"}
+	if !slices.Equal(hostnames, want) {
+		t.Fatalf("targets() = %v, want %v", hostnames, want)
+	}
+
+	tenants := tenantsOf(config, append(hostnames, "imported.example.com"))
+	for hostname, want := range map[cfg.Hostname][]string{
+		"shared.example.com":   {"payments", "web", ""},
+		"ops.example.com":      {""},
+		"pay.example.com":      {"payments"},
+		"www.example.com":      {"web"},
+		"imported.example.com": {""},
+	} {
+		if !slices.Equal(tenants[hostname], want) {
+			t.Errorf("tenantsOf()[%s] = %q, want %q", hostname, tenants[hostname], want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// keyStrength names the algorithm of cert's public key and its size in bits.
+func keyStrength(cert *x509.Certificate) (algorithm string, bits int) {
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return "RSA", pub.N.BitLen()
+	case *ecdsa.PublicKey:
+		return "ECDSA", pub.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return "Ed25519", 256
+	}
+	return cert.PublicKeyAlgorithm.String(), 0
+}
+
+// keyCurve names the curve of an ECDSA key, or returns "" for other keys.
+func keyCurve(cert *x509.Certificate) string {
+	if pub, ok := cert.PublicKey.(*ecdsa.PublicKey); ok {
+		return pub.Curve.Params().Name
+	}
+	return ""
+}
+
+// weakCrypto lists what audit finds wrong with c's key and signature.
+func weakCrypto(c store.Certificate, audit cfg.KeyAudit) []string {
+	var reasons []string
+	name := c.Subject
+	if c.Index == 0 {
+		name = "leaf"
+	}
+	switch {
+	case c.KeyAlgorithm == "RSA" && c.KeySize < audit.MinRSABits:
+		reasons = append(reasons, fmt.Sprintf("%s has a %d bit RSA key, under %d", name, c.KeySize, audit.MinRSABits))
+	case c.KeyAlgorithm == "ECDSA" && c.KeySize < audit.MinECDSABits:
+		reasons = append(reasons, fmt.Sprintf("%s has an ECDSA key on %s, under %d bits", name, c.Curve, audit.MinECDSABits))
+	case c.KeyAlgorithm == "DSA":
+		reasons = append(reasons, fmt.Sprintf("%s has a DSA key", name))
+	}
+	if c.Index > 0 && c.Subject == c.Issuer {
+		return reasons
+	}
+	for _, hash := range audit.WeakHashes {
+		if signatureHash(c.SignatureAlgorithm) == hash {
+			reasons = append(reasons, fmt.Sprintf("%s is signed with %s", name, c.SignatureAlgorithm))
+		}
+	}
+	return reasons
+}
+
+// signatureHash picks the hash out of a signature algorithm's name, e.g.
+// SHA1 from SHA1-RSA or ECDSA-SHA1.
+func signatureHash(algorithm string) string {
+	for _, part := range strings.Split(algorithm, "-") {
+		if strings.HasPrefix(part, "SHA") || strings.HasPrefix(part, "MD") {
+			return part
+		}
+	}
+	return ""
+}
+
+// checkKeys raises a weak-crypto alert for every scanned endpoint whose
+// chain has a key or signature the audit flags.
+func checkKeys(snapshot store.Snapshot, audit cfg.KeyAudit, alerts *alert.Manager, now time.Time) {
+	type endpoint struct {
+		tenant, hostname, ip string
+	}
+	reasons := make(map[endpoint][]string)
+	for _, c := range snapshot.Certificates {
+		if c.Deferred {
+			continue
+		}
+		e := endpoint{c.Tenant, string(c.Hostname), store.Address(c.IPAddress)}
+		reasons[e] = append(reasons[e], weakCrypto(c, audit)...)
+	}
+	for e, found := range reasons {
+		name := e.hostname
+		if e.ip != "" {
+			name += "@" + e.ip
+		}
+		alerts.Set(len(found) > 0, alert.Alert{
+			Key:      alert.Key("weak-crypto", e.tenant, name),
+			Severity: alert.Warning,
+			Summary:  fmt.Sprintf("%s serves weak cryptography: %s", name, strings.Join(found, "; ")),
+			Tenant:   e.tenant,
+			Labels:   map[string]string{"hostname": e.hostname, "ipAddress": e.ip},
+			Since:    now,
+		})
+	}
+}
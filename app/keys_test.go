@@ -0,0 +1,57 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWeakCrypto(t *testing.T) {
+	audit := cfg.KeyAudit{Enabled: true, MinRSABits: 2048, MinECDSABits: 256, WeakHashes: []string{"MD5", "SHA1"}}
+	tests := []struct {
+		name string
+		cert store.Certificate
+		want []string
+	}{
+		{name: "strong RSA", cert: store.Certificate{KeyAlgorithm: "RSA", KeySize: 4096, SignatureAlgorithm: "SHA256-RSA"}},
+		{name: "strong ECDSA", cert: store.Certificate{KeyAlgorithm: "ECDSA", KeySize: 384, Curve: "P-384", SignatureAlgorithm: "ECDSA-SHA384"}},
+		{name: "short RSA", cert: store.Certificate{KeyAlgorithm: "RSA", KeySize: 1024, SignatureAlgorithm: "SHA256-RSA"}, want: []string{"leaf has a 1024 bit RSA key, under 2048"}},
+		{name: "small curve", cert: store.Certificate{KeyAlgorithm: "ECDSA", KeySize: 224, Curve: "P-224", SignatureAlgorithm: "ECDSA-SHA256"}, want: []string{"leaf has an ECDSA key on P-224, under 256 bits"}},
+		{name: "SHA-1 signature", cert: store.Certificate{KeyAlgorithm: "RSA", KeySize: 2048, SignatureAlgorithm: "SHA1-RSA"}, want: []string{"leaf is signed with SHA1-RSA"}},
+		{name: "SHA-1 intermediate", cert: store.Certificate{Index: 1, Subject: "CN=Intermediate", Issuer: "CN=Root", KeyAlgorithm: "ECDSA", KeySize: 256, SignatureAlgorithm: "ECDSA-SHA1"}, want: []string{"CN=Intermediate is signed with ECDSA-SHA1"}},
+		{name: "SHA-1 root", cert: store.Certificate{Index: 2, Subject: "CN=Root", Issuer: "CN=Root", KeyAlgorithm: "RSA", KeySize: 2048, SignatureAlgorithm: "SHA1-RSA"}},
+		{name: "Ed25519", cert: store.Certificate{KeyAlgorithm: "Ed25519", KeySize: 256, SignatureAlgorithm: "Ed25519"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weakCrypto(tt.cert, audit); !slices.Equal(got, tt.want) {
+				t.Errorf("weakCrypto() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckKeys(t *testing.T) {
+	alerts := alert.NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	audit := cfg.KeyAudit{Enabled: true, MinRSABits: 2048, MinECDSABits: 256, WeakHashes: []string{"SHA1"}}
+	ip := net.ParseIP("192.0.2.1")
+	snapshot := store.Snapshot{Certificates: []store.Certificate{
+		{Hostname: "www.example.com", IPAddress: ip, KeyAlgorithm: "RSA", KeySize: 2048, SignatureAlgorithm: "SHA256-RSA"},
+		{Hostname: "www.example.com", IPAddress: ip, Index: 1, Subject: "CN=Intermediate", Issuer: "CN=Root", KeyAlgorithm: "RSA", KeySize: 2048, SignatureAlgorithm: "SHA1-RSA"},
+		{Hostname: "api.example.com", IPAddress: ip, KeyAlgorithm: "ECDSA", KeySize: 256, SignatureAlgorithm: "ECDSA-SHA256"},
+	}}
+	checkKeys(snapshot, audit, alerts, time.Now())
+	if _, ok := alerts.Get("weak-crypto:www.example.com@192.0.2.1"); !ok {
+		t.Error("no weak-crypto alert for a SHA-1 signed intermediate")
+	}
+	if _, ok := alerts.Get("weak-crypto:api.example.com@192.0.2.1"); ok {
+		t.Error("weak-crypto alert for a strong chain")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"maps"
+)
+
+// labelsFor merges the labels of every entry covering hostname, later
+// entries overriding earlier ones, or returns nil when none do.
+func labelsFor(entries []cfg.TargetLabels, hostname cfg.Hostname) map[string]string {
+	var labels map[string]string
+	for _, e := range entries {
+		if !covered(e.Hostnames, hostname) {
+			continue
+		}
+		if labels == nil {
+			labels = make(map[string]string, len(e.Labels))
+		}
+		maps.Copy(labels, e.Labels)
+	}
+	return labels
+}
+
+// targetLabeler labels alerts about a hostname with the labels configured
+// for it.
+func targetLabeler(entries []cfg.TargetLabels) alert.Labeler {
+	if len(entries) == 0 {
+		return nil
+	}
+	return func(a alert.Alert) map[string]string {
+		hostname, ok := a.Labels["hostname"]
+		if !ok {
+			return nil
+		}
+		return labelsFor(entries, cfg.Hostname(hostname))
+	}
+}
+
+// hostnameLabels returns the labels of every hostname in snapshot that has
+// any, for metrics.
+func hostnameLabels(entries []cfg.TargetLabels, snapshot store.Snapshot) map[string]map[string]string {
+	if len(entries) == 0 {
+		return nil
+	}
+	labels := make(map[string]map[string]string)
+	for _, cert := range snapshot.Certificates {
+		if _, ok := labels[string(cert.Hostname)]; ok {
+			continue
+		}
+		if l := labelsFor(entries, cert.Hostname); l != nil {
+			labels[string(cert.Hostname)] = l
+		}
+	}
+	return labels
+}
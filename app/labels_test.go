@@ -0,0 +1,46 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"maps"
+	"testing"
+)
+
+func TestLabelsFor(t *testing.T) {
+	entries := []cfg.TargetLabels{
+		{Hostnames: []cfg.Hostname{"*.example.com"}, Labels: map[string]string{"env": "prod", "team": "web"}},
+		{Hostnames: []cfg.Hostname{"pay.example.com", "checkout.example.com"}, Labels: map[string]string{"team": "payments"}},
+		{Hostnames: []cfg.Hostname{"staging.example.net"}, Labels: map[string]string{"env": "staging"}},
+	}
+
+	tests := []struct {
+		name     string
+		hostname cfg.Hostname
+		want     map[string]string
+	}{
+		{name: "wildcard", hostname: "www.example.com", want: map[string]string{"env": "prod", "team": "web"}},
+		{name: "later entries override", hostname: "pay.example.com", want: map[string]string{"env": "prod", "team": "payments"}},
+		{name: "single entry", hostname: "staging.example.net", want: map[string]string{"env": "staging"}},
+		{name: "unlabeled", hostname: "example.org"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := labelsFor(entries, tt.hostname); !maps.Equal(got, tt.want) {
+				t.Errorf("labelsFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	label := targetLabeler(entries)
+	if got := label(alert.Alert{Labels: map[string]string{"hostname": "pay.example.com"}}); got["team"] != "payments" {
+		t.Errorf("targetLabeler() = %v, want team payments", got)
+	}
+	if got := label(alert.Alert{Labels: map[string]string{"domain": "example.com"}}); got != nil {
+		t.Errorf("targetLabeler() without hostname = %v, want none", got)
+	}
+	if targetLabeler(nil) != nil {
+		t.Error("Expected no labeler without labels")
+	}
+}
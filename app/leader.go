@@ -0,0 +1,68 @@
+package main
+
+import (
+	"cert-tracker/store"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// elector campaigns for the store's lease so that only one replica scans
+// and alerts at a time.
+type elector struct {
+	st      *store.Store
+	id      string
+	ttl     time.Duration
+	leading atomic.Bool
+}
+
+func newElector(st *store.Store, ttl time.Duration) *elector {
+	hostname, _ := os.Hostname()
+	return &elector{st: st, id: fmt.Sprintf("%s/%d", hostname, os.Getpid()), ttl: ttl}
+}
+
+// try takes or renews the lease once and reports whether this replica just
+// became the leader. A failed renewal counts as lost leadership, since
+// another replica may take over the expired lease.
+func (e *elector) try(now time.Time) bool {
+	leading, err := e.st.AcquireLease(e.id, e.ttl, now)
+	if err != nil {
+		log.Warn("cannot acquire leader lease", "error", err)
+	}
+	was := e.leading.Swap(leading)
+	switch {
+	case leading && !was:
+		log.Info("became leader", "id", e.id)
+	case !leading && was:
+		log.Warn("lost leadership", "id", e.id)
+	}
+	return leading && !was
+}
+
+// campaign renews the lease well before it expires, signalling elected
+// whenever this replica takes over. A signal still pending covers a new
+// one, so renewals never wait on a main loop busy with a cycle.
+func (e *elector) campaign(elected chan<- struct{}) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		if e.try(now) {
+			select {
+			case elected <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// resign gives up the lease on shutdown, so a standby takes over without
+// waiting for it to expire.
+func (e *elector) resign() {
+	if err := e.st.ReleaseLease(e.id); err != nil {
+		log.Warn("cannot release leader lease", "error", err)
+		return
+	}
+	e.leading.Store(false)
+	log.Info("resigned leadership", "id", e.id)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"cert-tracker/store"
+	"testing"
+	"time"
+)
+
+func TestElector(t *testing.T) {
+	st, err := store.Open(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	a := &elector{st: st, id: "a", ttl: 30 * time.Second}
+	b := &elector{st: st, id: "b", ttl: 30 * time.Second}
+
+	if !a.try(now) || !a.leading.Load() {
+		t.Fatal("Expected the first replica to become leader")
+	}
+	if a.try(now.Add(10 * time.Second)) {
+		t.Error("Expected renewing the lease not to count as a new election")
+	}
+	if b.try(now.Add(20*time.Second)) || b.leading.Load() {
+		t.Error("Expected the second replica to stand by")
+	}
+	if !b.try(now.Add(time.Minute)) {
+		t.Error("Expected the standby to take over an expired lease")
+	}
+	if a.try(now.Add(time.Minute)) || a.leading.Load() {
+		t.Error("Expected the old leader to step down")
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func listCommand(args []string) int {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: cert-tracker list [flags]")
+		flags.PrintDefaults()
+	}
+	filter := store.Filter{Tags: make(map[string]string)}
+	flags.Func("expiring-within", "only certificates expiring within `duration`, e.g. 30d", func(s string) error {
+		d, err := cfg.ParseDuration(s)
+		filter.ExpiringWithin = d
+		return err
+	})
+	flags.StringVar(&filter.Tenant, "tenant", "", "only certificates of `tenant`")
+	flags.StringVar(&filter.Issuer, "issuer", "", "only certificates whose issuer contains `text`")
+	flags.Func("tag", "only targets with inventory tag `key=value` (repeatable)", func(s string) error {
+		k, v, ok := strings.Cut(s, "=")
+		if !ok {
+			return errors.New("tag must be key=value")
+		}
+		filter.Tags[k] = v
+		return nil
+	})
+	flags.BoolVar(&filter.IncludeChain, "chain", false, "include intermediate certificates")
+	format := flags.String("format", "table", "output `format`: table or json")
+	cfg.RegisterConfigFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		return 2
+	}
+
+	config, err := cfg.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if config.StoreDir == "" {
+		fmt.Fprintln(os.Stderr, "storeDir must be configured to list certificates")
+		return 1
+	}
+	st, err := openStore(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	snapshot, err := st.Latest()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	inventory, err := st.LoadInventory()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	now := time.Now()
+	results := store.Query(snapshot, inventory, filter, now)
+	if *format == "json" {
+		err = json.NewEncoder(os.Stdout).Encode(results)
+	} else {
+		err = printCertificates(os.Stdout, results, now)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func printCertificates(w io.Writer, certs []store.Certificate, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOSTNAME\tIP ADDRESS\tTARGET\tNOT AFTER\tDAYS LEFT\tISSUER")
+	for _, c := range certs {
+		daysLeft := int(c.NotAfter.Sub(now).Hours() / 24)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n",
+			c.Hostname,
+			c.IPAddress,
+			c.Target,
+			c.NotAfter.Format(time.DateOnly),
+			daysLeft,
+			c.Issuer,
+		)
+	}
+	return tw.Flush()
+}
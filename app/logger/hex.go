@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+	"reflect"
+)
+
+// hexHandler writes raw bytes in attributes, such as signatures, key
+// material and fingerprints, as hex strings instead of the base64 or number
+// arrays the slog handlers would. Named byte types like net.IP keep their
+// own formatting.
+type hexHandler struct {
+	slog.Handler
+}
+
+func (h hexHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(hexAttr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, out)
+}
+
+func (h hexHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	hexed := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		hexed[i] = hexAttr(a)
+	}
+	return hexHandler{h.Handler.WithAttrs(hexed)}
+}
+
+func (h hexHandler) WithGroup(name string) slog.Handler {
+	return hexHandler{h.Handler.WithGroup(name)}
+}
+
+// hexAttr rewrites a []byte or byte array value as hex, descending into
+// groups.
+func hexAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := v.Group()
+		hexed := make([]any, len(group))
+		for i, g := range group {
+			hexed[i] = hexAttr(g)
+		}
+		return slog.Group(a.Key, hexed...)
+	case slog.KindAny:
+		if b, ok := v.Any().([]byte); ok {
+			return slog.String(a.Key, hex.EncodeToString(b))
+		}
+		if r := reflect.ValueOf(v.Any()); r.Kind() == reflect.Array && r.Type().Elem().Kind() == reflect.Uint8 && r.Type().Elem().PkgPath() == "" {
+			b := make([]byte, r.Len())
+			reflect.Copy(reflect.ValueOf(b), r)
+			return slog.String(a.Key, hex.EncodeToString(b))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
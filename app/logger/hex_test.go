@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestHexHandler(t *testing.T) {
+	fingerprint := sha256.Sum256([]byte("leaf"))
+
+	tests := []struct {
+		name string
+		log  func(*slog.Logger)
+		want map[string]any
+	}{
+		{
+			name: "byte slice",
+			log:  func(l *slog.Logger) { l.Info("m", "signature", []byte{0xde, 0xad, 0xbe, 0xef}) },
+			want: map[string]any{"signature": "deadbeef"},
+		},
+		{
+			name: "byte array",
+			log:  func(l *slog.Logger) { l.Info("m", "fingerprint", fingerprint) },
+			want: map[string]any{"fingerprint": hex.EncodeToString(fingerprint[:])},
+		},
+		{
+			name: "named byte types keep their format",
+			log:  func(l *slog.Logger) { l.Info("m", "ipAddress", net.IPv4(192, 0, 2, 1)) },
+			want: map[string]any{"ipAddress": "192.0.2.1"},
+		},
+		{
+			name: "nested groups",
+			log: func(l *slog.Logger) {
+				l.Info("m", slog.Group("certificate", "serial", []byte{0x0a}, slog.Group("key", "spki", []byte{0x01, 0x02}, "bits", 2048)))
+			},
+			want: map[string]any{"certificate": map[string]any{"serial": "0a", "key": map[string]any{"spki": "0102", "bits": float64(2048)}}},
+		},
+		{
+			name: "attrs added with With",
+			log:  func(l *slog.Logger) { l.With("key", []byte{0xab}).Info("m") },
+			want: map[string]any{"key": "ab"},
+		},
+		{
+			name: "attrs inside WithGroup",
+			log:  func(l *slog.Logger) { l.WithGroup("chain").With("leaf", []byte{0x01}).Info("m", "root", []byte{0x02}) },
+			want: map[string]any{"chain": map[string]any{"leaf": "01", "root": "02"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			replace := func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey || a.Key == slog.MessageKey) {
+					return slog.Attr{}
+				}
+				return a
+			}
+			tt.log(slog.New(hexHandler{slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: replace})}))
+
+			var got map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("logged %s, want %v", buf.Bytes(), tt.want)
+			}
+		})
+	}
+}
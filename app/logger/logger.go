@@ -2,13 +2,39 @@ package logger
 
 import (
 	"cert-tracker/cfg"
+	"io"
 	"log/slog"
 	"os"
 )
 
-func New(config cfg.Params) *slog.Logger {
-	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+// New writes records in the configured format to the configured output,
+// with raw bytes as hex, and starts level at the configured log level.
+// Changing level later adjusts the returned logger without rebuilding it.
+func New(config cfg.Params, level *slog.LevelVar) (*slog.Logger, error) {
+	out, err := output(config.LogOutput)
+	if err != nil {
+		return nil, err
+	}
+	level.Set(config.LogLevel)
+	options := &slog.HandlerOptions{
 		AddSource: config.LogAddSource,
-		Level:     config.LogLevel,
-	}))
+		Level:     level,
+	}
+	if config.LogFormat == "text" {
+		return slog.New(hexHandler{slog.NewTextHandler(out, options)}), nil
+	}
+	return slog.New(hexHandler{slog.NewJSONHandler(out, options)}), nil
+}
+
+// output opens the configured log destination. Logs go to it for the life
+// of the process, so it is never closed.
+func output(config cfg.LogOutput) (io.Writer, error) {
+	switch config.Destination {
+	case "file":
+		return openRotatingFile(config.Path, int64(config.MaxSize)<<20, config.MaxAge, config.Backups)
+	case "syslog":
+		return openSyslog(config.Syslog, config.Tag)
+	default:
+		return os.Stdout, nil
+	}
 }
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"cert-tracker/cfg"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		format cfg.LogFormat
+		want   string
+	}{
+		{format: "", want: `"msg":"scan cycle summary","ok":3}`},
+		{format: "json", want: `"msg":"scan cycle summary","ok":3}`},
+		{format: "text", want: `level=INFO msg="scan cycle summary" ok=3`},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cert-tracker.log")
+			config := cfg.Params{
+				LogFormat: tt.format,
+				LogOutput: cfg.LogOutput{Destination: "file", Path: path, MaxSize: 1},
+			}
+			log, err := New(config, new(slog.LevelVar))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			log.Info("scan cycle summary", "ok", 3)
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(data); !strings.Contains(got, tt.want) {
+				t.Errorf("logged %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
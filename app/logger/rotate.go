@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"cert-tracker/cfg"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// rotatingFile appends to path, moving it aside to path.1 once it would grow
+// past maxSize bytes or has been open longer than maxAge. Older files shift
+// up to path.2 and so on, and those past backups are removed.
+type rotatingFile struct {
+	path    string
+	maxSize int64
+	maxAge  time.Duration
+	backups int
+	now     func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+func openRotatingFile(path string, maxSize int64, maxAge cfg.Duration, backups int) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, maxSize: maxSize, maxAge: time.Duration(maxAge), backups: backups, now: time.Now}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file, r.size, r.opened = file, info.Size(), r.now()
+	return nil
+}
+
+// Write rotates before a record that would overflow the file, so records
+// are never split across files.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tooBig := r.size > 0 && r.size+int64(len(p)) > r.maxSize
+	tooOld := r.maxAge > 0 && r.now().Sub(r.opened) >= r.maxAge
+	if tooBig || tooOld {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	if err := os.Remove(r.backup(r.backups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := r.backups - 1; i >= 0; i-- {
+		if err := os.Rename(r.backup(i), r.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return r.open()
+}
+
+// backup is the name of the i'th rotated file, where 0 is the live file.
+func (r *rotatingFile) backup(i int) string {
+	if i == 0 {
+		return r.path
+	}
+	return fmt.Sprintf("%s.%d", r.path, i)
+}
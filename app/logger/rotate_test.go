@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile(t *testing.T) {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		maxSize int64
+		maxAge  time.Duration
+		backups int
+		writes  []string
+		step    time.Duration
+		want    []string
+	}{
+		{name: "under the limit", maxSize: 100, backups: 2, writes: []string{"a\n", "b\n"}, want: []string{"a\nb\n"}},
+		{name: "rotates by size", maxSize: 4, backups: 2, writes: []string{"a\n", "b\n", "c\n"}, want: []string{"c\n", "a\nb\n"}},
+		{name: "keeps only backups", maxSize: 2, backups: 2, writes: []string{"a\n", "b\n", "c\n", "d\n"}, want: []string{"d\n", "c\n", "b\n"}},
+		{name: "no backups", maxSize: 2, writes: []string{"a\n", "b\n"}, want: []string{"b\n"}},
+		{name: "oversized record", maxSize: 2, backups: 1, writes: []string{"long\n"}, want: []string{"long\n"}},
+		{name: "rotates by age", maxSize: 100, maxAge: time.Hour, backups: 1, writes: []string{"a\n", "b\n", "c\n"}, step: 40 * time.Minute, want: []string{"c\n", "a\nb\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cert-tracker.log")
+			now := start
+			r := &rotatingFile{path: path, maxSize: tt.maxSize, maxAge: tt.maxAge, backups: tt.backups, now: func() time.Time { return now }}
+			if err := r.open(); err != nil {
+				t.Fatal(err)
+			}
+			defer r.file.Close()
+			for _, w := range tt.writes {
+				if _, err := r.Write([]byte(w)); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+				now = now.Add(tt.step)
+			}
+
+			var got []string
+			for i := 0; ; i++ {
+				data, err := os.ReadFile(r.backup(i))
+				if os.IsNotExist(err) {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, string(data))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("files = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
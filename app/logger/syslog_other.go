@@ -0,0 +1,12 @@
+//go:build !unix
+
+package logger
+
+import (
+	"errors"
+	"io"
+)
+
+func openSyslog(address, tag string) (io.Writer, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
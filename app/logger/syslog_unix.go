@@ -0,0 +1,23 @@
+//go:build unix
+
+package logger
+
+import (
+	"io"
+	"log/syslog"
+	"net/url"
+)
+
+// openSyslog connects to the syslog server at address, a udp:// or tcp://
+// URL, or to the local daemon when it's empty.
+func openSyslog(address, tag string) (io.Writer, error) {
+	var network, host string
+	if address != "" {
+		u, err := url.Parse(address)
+		if err != nil {
+			return nil, err
+		}
+		network, host = u.Scheme, u.Host
+	}
+	return syslog.Dial(network, host, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+}
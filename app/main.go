@@ -1,59 +1,717 @@
 package main
 
 import (
+	"cert-tracker/alert"
+	"cert-tracker/api"
 	"cert-tracker/cfg"
+	"cert-tracker/clock"
+	"cert-tracker/ct"
+	"cert-tracker/dns"
+	"cert-tracker/export"
 	"cert-tracker/logger"
+	"cert-tracker/metrics"
+	"cert-tracker/report"
+	"cert-tracker/results"
+	"cert-tracker/revocation"
+	"cert-tracker/starttls"
+	"cert-tracker/stepca"
+	"cert-tracker/store"
+	"cert-tracker/systemd"
 	"context"
 	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"runtime"
+	"slices"
+	"sync"
 	"time"
 )
 
-var log *slog.Logger
+var (
+	log      *slog.Logger
+	logLevel = new(slog.LevelVar)
+	// dnsTTLs sees every answer the resolver gets, to know when addresses go stale
+	dnsTTLs = dns.NewTTLRecorder()
+	// resolvers picks among several configured resolvers; nil means use the first
+	resolvers *dns.Selector
+)
+
+// resolverWindow is how many recent probes judge a resolver's health.
+const resolverWindow = 10
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "burnin":
+			os.Exit(burnInCommand(os.Args[2:]))
+		case "diff":
+			os.Exit(diffCommand(os.Args[2:]))
+		case "discover":
+			os.Exit(discoverCommand(os.Args[2:]))
+		case "history":
+			os.Exit(historyCommand(os.Args[2:]))
+		case "import":
+			os.Exit(importCommand(os.Args[2:]))
+		case "list":
+			os.Exit(listCommand(os.Args[2:]))
+		case "schema":
+			os.Exit(schemaCommand(os.Args[2:]))
+		case "validate-config":
+			os.Exit(validateConfigCommand(os.Args[2:]))
+		case "verify-deploy":
+			os.Exit(verifyDeployCommand(os.Args[2:]))
+		}
+	}
+
+	once := flag.Bool("once", false, "scan once and exit 0 if every certificate is healthy, 1 if one expires within the warning window, 2 if one has expired or fails verification or a handshake failed")
+	dryRun := flag.Bool("dry-run", false, "resolve targets and print the scan plan without connecting")
+	planFormat := flag.String("plan-format", "table", "output `format` of --dry-run: table or json")
+	fakeNow := flag.String("fake-now", "", "pretend the current time is this RFC 3339 `timestamp`, for testing alerts")
+	timeOffset := flag.String("time-offset", "", "shift the current time by this `duration`, e.g. 30d, for testing alerts")
+	cfg.RegisterConfigFlag(flag.CommandLine)
+	cfg.RegisterOverrideFlags(flag.CommandLine)
+	flag.Parse()
+
 	config := loadConfig()
-	run := func() {
-		// TODO: loop through all resolvers
-		netResolver := resolver(config.DNSresolvers[0], config.Timeout)
-		// TODO: move logging to called functions to make main more readable
-		nameAddressMappings, err := resolve(config.Hostnames, netResolver, config.Timeout)
+	ctx, stop := shutdownContext()
+	defer stop()
+	clk, err := fakeClock(*fakeNow, *timeOffset)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if clk.Offset() != 0 {
+		log.Warn("running with a simulated clock", "now", clk.Now(), "offset", clk.Offset().String())
+	}
+	st, err := openStore(config)
+	if err != nil {
+		log.Error("cannot open store", "error", err)
+		os.Exit(1)
+	}
+	if *dryRun {
+		if *planFormat != "table" && *planFormat != "json" {
+			fmt.Fprintf(os.Stderr, "unknown format %q\n", *planFormat)
+			os.Exit(2)
+		}
+		scanPlan, err := plan(ctx, config, st)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		routes, err := configuredRoutes(config)
 		if err != nil {
-			log.Warn("cannot resolve IP Addresses", "error", err)
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if *planFormat == "json" {
+			writePlanJSON(os.Stdout, scanPlan, routes)
 			return
 		}
+		printPlan(os.Stdout, scanPlan, routes)
+		return
+	}
+	toggleDebugOnSignal(config.LogLevel)
+	if len(config.DebugCapture.Hostnames) > 0 {
+		if capture, err = newDebugCapture(config.DebugCapture); err != nil {
+			log.Error("cannot set up TLS debug capture", "error", err)
+			os.Exit(1)
+		}
+		log.Warn("capturing TLS sessions for debugging; the key log decrypts their traffic",
+			"hostnames", config.DebugCapture.Hostnames,
+		)
+	}
+	resolvers = newResolverSelector(config)
+	alerts := alert.NewManager(log)
+	alerts.Mute(maintenanceMuter(config.Maintenance, clk.Now))
+	alerts.Label(targetLabeler(config.Labels))
+	notifier, err := newNotifier(config)
+	if err != nil {
+		log.Error("cannot set up notifications", "error", err)
+		os.Exit(1)
+	}
+	alerts.Watch(alertWatcher(notifier, clk.Now))
+	var responders *revocation.ResponderMonitor
+	if config.OCSPResponders.Enabled {
+		responders = revocation.NewResponderMonitor(
+			time.Duration(config.OCSPResponders.SlowAfter),
+			config.OCSPResponders.AlertAfter,
+		)
+	}
+	var crls *revocation.CRLMonitor
+	if config.CRLs.Enabled {
+		crls = revocation.NewCRLMonitor(
+			time.Duration(config.CRLs.Interval),
+			config.CRLs.AlertAfter,
+			config.CRLs.MaxSize,
+		)
+	}
+	var statuses *leafStatuses
+	if config.OCSPStatus.Enabled {
+		statuses = newLeafStatuses(
+			&http.Client{Timeout: time.Duration(config.Timeout)},
+			time.Duration(config.OCSPStatus.Interval),
+		)
+	}
+	var logLists *ct.LogListSource
+	if config.CTPolicy.Enabled {
+		logLists = &ct.LogListSource{
+			URL:    config.CTPolicy.LogListURL,
+			MaxAge: 24 * time.Hour,
+			Client: &http.Client{Timeout: time.Duration(config.Timeout)},
+		}
+	}
+	var ca *stepca.Client
+	// the newest certificate step-ca was seen issuing for each set of names
+	newestIssued := make(map[string]*x509.Certificate)
+	if config.StepCA.Enabled {
+		if ca, err = stepca.New(config.StepCA.URL, config.StepCA.RootFile, time.Duration(config.Timeout)); err != nil {
+			log.Error("cannot set up step-ca client", "error", err)
+			os.Exit(1)
+		}
+	}
+	var issuances *issuanceWatch
+	if config.CTMonitor.Enabled {
+		issuances = newIssuanceWatch(&ct.CrtSh{
+			Endpoint: config.CTMonitor.Endpoint,
+			// crt.sh is often slow to answer broad queries
+			Client: &http.Client{Timeout: time.Minute},
+		}, st, config)
+	}
+	sinks, err := newSinks(config)
+	if err != nil {
+		log.Error("cannot set up scan result sinks", "error", err)
+		os.Exit(1)
+	}
+	defer closeSinks(sinks)
+	issuers := newIssuerFetcher(&http.Client{Timeout: time.Duration(config.Timeout)})
+	var lastDeepScan, lastOriginCheck, lastIssuanceCheck time.Time
+	// the last cycle's results, to notice renewals
+	previous, _ := st.Latest()
+	statePath := config.StateCache.Path
+	cached := store.State{Seen: store.NewSeen()}
+	if statePath != "" {
+		if cached, err = st.LoadState(statePath); err != nil {
+			log.Warn("cannot load state cache", "path", statePath, "error", err)
+		}
+		if cached.Snapshot.Time.After(previous.Time) {
+			previous = cached.Snapshot
+		}
+		alerts.Restore(cached.Alerts)
+		// so Alertmanager keeps hearing about them and incidents can be
+		// resolved
+		notifier.Restore(cached.Alerts)
+	}
+	go notifier.Run(ctx)
+	// the TLS versions endpoints accepted in the last deep scan
+	protocols := protocolsFrom(previous)
+	// when each leaf certificate was first seen, and each endpoint started
+	// serving its own
+	seen := cached.Seen
+	if config.StoreDir != "" {
+		if seen, err = st.LoadSeen(); err != nil {
+			log.Warn("cannot load certificate sightings", "error", err)
+			seen = store.NewSeen()
+		}
+	}
+	completed := 0
+	// sockets systemd listens on for the API and metrics servers
+	sockets, err := systemd.Listeners()
+	if err != nil {
+		log.Error("cannot take over the sockets passed by systemd", "error", err)
+		os.Exit(1)
+	}
+	var server *api.Server
+	scanRequests := make(chan scanRequest)
+	if config.API.Listen != "" {
+		server = api.New(config.API, log)
+		server.HandleLogLevel(logLevel)
+		server.HandleAlerts(alerts)
+		server.HandleDashboard(warningWindow(config), criticalWindow(config))
+		server.HandleTargets(warningWindow(config), criticalWindow(config))
+		server.HandleScan(requestScan(scanRequests))
+		if !previous.Time.IsZero() {
+			// serve the persisted results until the first cycle completes
+			server.SetSnapshot(previous)
+		}
+		go func() {
+			serve := server.ListenAndServe
+			if ln, ok := sockets["api"]; ok {
+				serve = func() error { return server.Serve(ln) }
+			}
+			if err := serve(); err != nil {
+				log.Error("API server stopped", "error", err)
+			}
+		}()
+	}
+	var uploads *export.Exporter
+	if config.Export.Enabled {
+		if uploads, err = newExporter(config.Export); err != nil {
+			log.Error("cannot set up the export", "error", err)
+			os.Exit(1)
+		}
+	}
+	var exporter *metrics.Exporter
+	if config.Metrics.Listen != "" || config.Metrics.Textfile != "" {
+		exporter = metrics.NewExporter()
+	}
+	if config.Metrics.Listen != "" {
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", exporter)
+		srv := &http.Server{
+			Addr:              config.Metrics.Listen,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			serve := srv.ListenAndServe
+			if ln, ok := sockets["metrics"]; ok {
+				serve = func() error { return srv.Serve(ln) }
+			}
+			if err := serve(); err != nil {
+				log.Error("metrics server stopped", "error", err)
+			}
+		}()
+	}
+	for name, ln := range sockets {
+		if name != "api" && name != "metrics" {
+			log.Warn("ignoring socket passed by systemd; name it api or metrics", "name", name)
+			ln.Close()
+		}
+	}
+	var watch *watchdog
+	pingEvery := systemd.WatchdogInterval()
+	if config.Watchdog.Enabled || pingEvery > 0 {
+		watch = newWatchdog(2*time.Duration(config.ScanInterval), time.Now())
+	}
+	if config.Watchdog.Enabled {
+		go watch.watch(alerts, config.Watchdog.Exit)
+	}
+	if pingEvery > 0 {
+		go watch.ping(pingEvery)
+	}
+	// hostnames whose handshakes failed last cycle, to scan them first
+	failed := make(map[cfg.Hostname]bool)
+	pace := newPacer(time.Duration(config.ScanInterval))
+	var leader *elector
+	elected := make(chan struct{}, 1)
+	if config.LeaderElection.Enabled {
+		leader = newElector(st, time.Duration(config.LeaderElection.LeaseDuration))
+		leader.try(time.Now())
+		go leader.campaign(elected)
+	}
+	run := func() {
+		if watch != nil {
+			// a cycle that fails early still shows the loop is alive
+			defer func() { watch.beat(time.Now()) }()
+		}
+		if leader != nil && !leader.leading.Load() {
+			log.Debug("standing by for the leader")
+			return
+		}
+		snapshot := store.Snapshot{Time: clk.Now()}
+		if resolvers != nil && resolvers.Benchmark() {
+			health := resolvers.Health(resolvers.Active())
+			log.Info("switched DNS resolver",
+				"resolver", activeResolver(config),
+				"failureRate", health.FailureRate,
+				"latency", health.Latency.String(),
+			)
+		}
+		scanPlan, err := plan(ctx, config, st)
 		// retry on next scan
-		if len(nameAddressMappings) == 0 {
-			log.Warn("no name to address mappings")
+		if err != nil {
+			log.Warn("cannot plan scan", "error", err)
 			return
 		}
-		log.Info("resolved IP addresses",
-			"addresses", nameAddressMappings,
-		)
-		for _, mapping := range nameAddressMappings {
-			for _, ipAddress := range mapping.IPAddresses {
-				certificates(mapping.Hostname, ipAddress, config.Timeout)
+		scanPlan = prioritize(scanPlan, failed, previous)
+		scanPlan, carried := pace.split(scanPlan, failed, previous, clk.Now())
+		snapshot.Certificates = append(snapshot.Certificates, carried...)
+		clear(failed)
+		cycle := newProgress(len(scanPlan), time.Now())
+		cycle.Stretch = pace.stretch
+		spread := newSpreader(config.Spread, config.ScanInterval, len(scanPlan), cycle.Started)
+		publish := func() {
+			if server != nil {
+				server.SetProgress(cycle.Progress)
+			}
+		}
+		publish()
+		var chains [][]*x509.Certificate
+		var served []servedChain
+		// the first handshake with each hostname, for per-hostname checks
+		handshakes := make(map[cfg.Hostname]*tls.ConnectionState)
+		// handshakes made this cycle, to answer targets that would repeat them
+		shared := make(map[string]sharedHandshake)
+		for i := 0; i < len(scanPlan); i++ {
+			spread.wait(ctx, i)
+			if expired(scanPlan, i, time.Now()) {
+				scanPlan = reresolve(ctx, config, scanPlan, i)
+				cycle.Total = len(scanPlan)
+			}
+			target := scanPlan[i]
+			key := handshakeKey(target)
+			h, ok := shared[key]
+			if !ok || target.retry && !h.retried {
+				h.result, h.state, h.err = scan(ctx, target, config.Timeout)
+				h.retried = target.retry
+				if ctx.Err() != nil {
+					// a partial cycle would look like missing certificates
+					log.Warn("scan cycle interrupted; discarding its results",
+						"done", cycle.Done,
+						"total", cycle.Total,
+					)
+					return
+				}
+				if h.state != nil && config.SNI.ProbeDefault {
+					h.result.Chain[0].NoSNI = defaultCertificate(ctx, target, config.Timeout)
+				}
+				if h.state != nil && statuses != nil {
+					h.result.Chain[0].OCSP = statuses.status(h.state, clk.Now())
+				}
+				shared[key] = h
+			}
+			result, state, err := h.answer(target)
+			switch {
+			case state == nil && !target.retry:
+				// try once more after the rest of the cycle has had its turn
+				target.retry, target.Expires = true, time.Time{}
+				scanPlan = append(scanPlan, target)
+				cycle.Total = len(scanPlan)
+			case state == nil:
+				failed[target.Hostname] = true
+				for _, tenant := range target.Tenants {
+					snapshot.Failures = append(snapshot.Failures, store.Failure{
+						Tenant:    tenant,
+						Hostname:  target.Hostname,
+						IPAddress: target.IPAddress,
+						Error:     err.Error(),
+					})
+				}
+			}
+			if state == nil && exporter != nil {
+				exporter.HandshakeFailed(string(target.Hostname), target.IPAddress)
+			}
+			if state != nil {
+				acceptPrivateCA(config, &result, state.PeerCertificates)
+				endpoint := string(target.Hostname) + "@" + target.IPAddress.String()
+				result.FirstSeen, result.ServingSince = seen.Observe(endpoint, result.Chain[0], snapshot.Time)
+			}
+			writeResult(sinks, result)
+			if state != nil {
+				chains = append(chains, state.PeerCertificates)
+				served = append(served, servedChain{target: target, chain: state.PeerCertificates})
+				if _, ok := handshakes[target.Hostname]; !ok {
+					handshakes[target.Hostname] = state
+				}
+			}
+			for _, tenant := range target.Tenants {
+				for _, c := range result.Chain {
+					c.Tenant = tenant
+					snapshot.Certificates = append(snapshot.Certificates, c)
+				}
+			}
+			if cycle.advance(time.Now()) {
+				log.Info("scan progress",
+					"done", cycle.Done,
+					"total", cycle.Total,
+					"eta", cycle.ETA,
+				)
+			}
+			publish()
+		}
+		cycle.finish(time.Now())
+		publish()
+		if took := spread.busy(cycle.Finished.Sub(cycle.Started)); pace.adjust(took) {
+			log.Warn("scan cycle pacing changed",
+				"took", took.String(),
+				"interval", time.Duration(config.ScanInterval).String(),
+				"stretch", pace.stretch,
+			)
+		}
+		scanPlan = slices.DeleteFunc(scanPlan, func(t scanTarget) bool { return t.retry })
+		if len(config.Files.Paths) > 0 {
+			certs, failures := fileCertificates(config.Files.Paths)
+			snapshot.Certificates = append(snapshot.Certificates, certs...)
+			snapshot.Failures = append(snapshot.Failures, failures...)
+			for _, f := range failures {
+				failed[f.Hostname] = true
+			}
+		}
+		client := &http.Client{Timeout: time.Duration(config.Timeout)}
+		if responders != nil {
+			snapshot.Responders = probeResponders(client, chains, responders, alerts, clk.Now())
+		}
+		if crls != nil {
+			snapshot.CRLs = checkCRLs(client, chains, crls, alerts, clk.Now())
+		}
+		if config.CAA.Enabled {
+			snapshot.CAA = checkCAA(dnsClient(activeResolver(config), config.Timeout), config.CAA, scanPlan, handshakes, alerts, clk.Now())
+		}
+		if config.DANE.Enabled {
+			checkDANE(dnsClient(activeResolver(config), config.Timeout), served, &snapshot, alerts, clk.Now())
+		}
+		if config.SplitHorizon.Enabled {
+			compareResolvers(served)
+		}
+		snapshot.Rotations = rotations(previous, snapshot)
+		for i, r := range snapshot.Rotations {
+			snapshot.Rotations[i].Labels = labelsFor(config.Labels, r.Hostname)
+		}
+		logRotations(snapshot.Rotations)
+		notifyRotations(notifier, snapshot.Rotations, clk.Now())
+		checkVerification(snapshot, alerts, clk.Now())
+		checkNames(snapshot, alerts, clk.Now())
+		if len(config.Pins) > 0 {
+			checkPins(snapshot, config.Pins, alerts, clk.Now())
+		}
+		if statuses != nil {
+			checkOCSPStatus(snapshot, alerts, clk.Now())
+			statuses.prune(clk.Now())
+		}
+		checkConsistency(snapshot, alerts, clk.Now())
+		if ca != nil {
+			checkStepCA(ca, newestIssued, served, alerts, clk.Now())
+		}
+		if len(config.ValidityPolicies) > 0 {
+			var inventory []store.InventoryEntry
+			if config.StoreDir != "" {
+				if inventory, err = st.LoadInventory(); err != nil {
+					log.Warn("cannot load inventory", "error", err)
+				}
+			}
+			snapshot.Policies = evaluatePolicies(config.ValidityPolicies, snapshot, inventory, alerts, clk.Now())
+		}
+		if config.ChainChecks.Enabled {
+			snapshot.Chains = checkChains(served, systemRoots(), issuers, alerts, clk.Now())
+		}
+		if config.KeyAudit.Enabled {
+			checkKeys(snapshot, config.KeyAudit, alerts, clk.Now())
+		}
+		if len(config.ALPN.Expect) > 0 {
+			checkALPN(snapshot, config.ALPN, alerts, clk.Now())
+		}
+		if config.ChainSize.Enabled {
+			checkChainSize(snapshot, config.ChainSize.MaxBytes, alerts, clk.Now())
+		}
+		if config.Expiry.Enabled {
+			snapshot.Renewals = renewals(previous, snapshot, config.Expiry.Escalation, config.Tenants, clk.Now())
+			confirmRenewals(snapshot.Renewals, alerts)
+			checkExpiry(snapshot, config.Expiry.Escalation, config.Tenants, alerts, clk.Now())
+		}
+		if logLists != nil {
+			checkCTPolicy(logLists, scanPlan, handshakes, alerts, clk.Now())
+		}
+		if issuances != nil {
+			issuances.observeSnapshot(snapshot)
+			if time.Since(lastIssuanceCheck) >= time.Duration(config.CTMonitor.Interval) {
+				domains := ctDomains(config, targets(config, st))
+				issuances.check(domains, config.CTMonitor.IncludeSubdomains, time.Duration(config.CTMonitor.Grace), alerts, clk.Now())
+				lastIssuanceCheck = time.Now()
+			}
+		}
+		var versions protocolVersions
+		if config.DeepScan.Enabled && config.DeepScan.Protocols {
+			versions = protocols
+		}
+		if config.DeepScan.Enabled && time.Since(lastDeepScan) >= time.Duration(config.DeepScan.Interval) {
+			deepScan(scanPlan, config.Timeout, versions, alerts, clk.Now())
+			lastDeepScan = time.Now()
+		}
+		versions.annotate(&snapshot)
+		if config.Cloudflare.Enabled && config.Cloudflare.CheckOrigins && time.Since(lastOriginCheck) >= time.Duration(config.Cloudflare.Refresh) {
+			checkCloudflare(config, handshakes, alerts, clk.Now())
+			lastOriginCheck = time.Now()
+		}
+		if exporter != nil {
+			exported := exporter.Update(metrics.Cycle{
+				Snapshot: snapshot,
+				Duration: cycle.Finished.Sub(cycle.Started),
+				Failed:   len(failed),
+				Alerts:   alerts.Active(),
+				Labels:   hostnameLabels(config.Labels, snapshot),
+			})
+			if config.Metrics.Textfile != "" {
+				if err := metrics.WriteFile(config.Metrics.Textfile, exported); err != nil {
+					log.Warn("cannot write metrics textfile", "error", err)
+				}
+			}
+		}
+		if config.Report.Path != "" {
+			summary := report.New(snapshot, sortedHostnames(failed))
+			if err := report.WriteFile(config.Report.Path, config.Report.Format, summary); err != nil {
+				log.Warn("cannot write report", "error", err)
+			}
+		}
+		logSummary(summarize(snapshot, config, clk.Now()), cycle.Finished.Sub(cycle.Started))
+		previous = snapshot
+		completed++
+		if server != nil {
+			server.SetSnapshot(snapshot)
+		}
+		if uploads != nil {
+			exportSnapshot(uploads, config.Export, snapshot, clk.Now())
+		}
+		seen.Forget(snapshot.Time)
+		if statePath != "" {
+			saveState(st, statePath, snapshot, seen, alerts)
+		}
+		if config.StoreDir != "" {
+			if err := st.SaveSeen(seen); err != nil {
+				log.Warn("cannot save certificate sightings", "error", err)
+			}
+			path, err := st.Save(snapshot)
+			if errors.Is(err, store.ErrUnreadable) {
+				log.Warn("snapshots left out of the change index", "error", err)
+			} else if err != nil {
+				log.Error("cannot save snapshot", "error", err)
+				return
 			}
+			log.Debug("snapshot saved", "path", path)
+			prune(config, st, clk.Now())
 		}
 	}
 
+	// caught before the first cycle, since SIGUSR1 and SIGHUP would
+	// otherwise end the tracker while it runs
+	var rescan, reload <-chan os.Signal
+	if !*once {
+		rescan, reload = rescanSignals(), reloadSignals()
+	}
+	var control <-chan struct{}
+	if path := config.ControlPipe.Path; path != "" && !*once {
+		if control, err = controlPipe(path); err != nil {
+			log.Error("cannot set up control pipe", "error", err)
+			os.Exit(1)
+		}
+		log.Info("listening for commands", "pipe", path)
+	}
+
+	notifySystemd("READY=1")
 	run()
+	if *once {
+		if completed == 0 {
+			log.Error("scan cycle did not complete")
+			os.Exit(exitFailing)
+		}
+		os.Exit(onceStatus(previous, failed, func(tenant string) time.Duration {
+			return tenantWarningWindow(config, tenant)
+		}, clk.Now()))
+	}
 	ticker := time.NewTicker(time.Duration(config.ScanInterval))
 	defer ticker.Stop()
-	for range ticker.C {
+	for {
+		select {
+		case <-ticker.C:
+		case <-rescan:
+			log.Info("rescan requested", "signal", "SIGUSR1")
+		case <-control:
+			log.Info("rescan requested", "pipe", config.ControlPipe.Path)
+		case <-elected:
+		case req := <-scanRequests:
+			log.Info("scan requested", "hostname", req.hostname)
+			snapshot, err := scanHostname(ctx, config, st, sinks, req.hostname, clk.Now())
+			req.reply <- scanReply{snapshot, err}
+			continue
+		case <-ctx.Done():
+			// a second signal kills the process right away
+			stop()
+			notifySystemd("STOPPING=1")
+			if statePath != "" {
+				// keep alerts acknowledged since the last cycle
+				saveState(st, statePath, previous, seen, alerts)
+			}
+			if leader != nil && leader.leading.Load() {
+				leader.resign()
+			}
+			log.Info("shutdown", "reason", context.Cause(ctx))
+			return
+		case <-reload:
+			notifySystemd("RELOADING=1")
+			next, err := reloadConfig(config, notifier, alerts.Active())
+			notifySystemd("READY=1")
+			if err != nil {
+				log.Error("cannot reload configuration, keeping the current one",
+					"signal", "SIGHUP",
+					"error", err,
+				)
+				continue
+			}
+			if next.ScanInterval != config.ScanInterval {
+				ticker.Reset(time.Duration(next.ScanInterval))
+				pace.interval = time.Duration(next.ScanInterval)
+				if watch != nil {
+					watch.setLimit(2 * time.Duration(next.ScanInterval))
+				}
+			}
+			alerts.Mute(maintenanceMuter(next.Maintenance, clk.Now))
+			alerts.Label(targetLabeler(next.Labels))
+			// run closes over config, so the next cycle sees all of the new one
+			config = next
+			log.Info("configuration reloaded",
+				"signal", "SIGHUP",
+				"hostnames", len(config.Hostnames),
+				"scanInterval", time.Duration(config.ScanInterval).String(),
+			)
+		}
 		run()
 	}
 }
 
+// saveState writes what a restarted tracker needs to the state cache.
+func saveState(st *store.Store, path string, snapshot store.Snapshot, seen store.Seen, alerts *alert.Manager) {
+	state := store.State{Snapshot: snapshot, Seen: seen, Alerts: alerts.Active()}
+	if err := st.SaveState(path, state); err != nil {
+		log.Warn("cannot save state cache", "path", path, "error", err)
+	}
+}
+
+// notifySystemd tells systemd about the service's state when it runs as a
+// Type=notify unit.
+func notifySystemd(state string) {
+	if err := systemd.Notify(state); err != nil {
+		log.Warn("cannot notify systemd", "state", state, "error", err)
+	}
+}
+
+func prune(config cfg.Params, st *store.Store, now time.Time) {
+	if config.Retention == (cfg.Retention{}) {
+		return
+	}
+	removed, err := st.Prune(
+		time.Duration(config.Retention.Observations),
+		time.Duration(config.Retention.Changes),
+		now,
+	)
+	if err != nil {
+		log.Warn("cannot prune snapshots", "error", err)
+	}
+	if removed > 0 {
+		log.Info("pruned snapshots", "removed", removed)
+	}
+}
+
 type nameAddressMap struct {
 	Hostname    cfg.Hostname `json:"hostname"`
 	IPAddresses []net.IP     `json:"ipAddresses"`
+	// CNAMEs are the canonical names followed to the addresses, in order.
+	CNAMEs []string `json:"cnames,omitempty"`
+	// Lookup is how long resolving the addresses took, or zero when they
+	// came from the DNS cache.
+	Lookup time.Duration `json:"lookup,omitempty"`
+	// Resolvers are the addresses each resolver returned, when resolving
+	// through every one of them.
+	Resolvers map[string][]net.IP `json:"resolvers,omitempty"`
+	// Err is why the hostname couldn't be resolved.
+	Err error `json:"-"`
 }
 
 func loadConfig() cfg.Params {
@@ -71,7 +729,15 @@ func loadConfig() cfg.Params {
 		)
 		os.Exit(1)
 	}
-	log = logger.New(config)
+	configured, err := logger.New(config, logLevel)
+	if err != nil {
+		slog.New(slog.NewJSONHandler(os.Stdout, nil)).Error(
+			"failed to open log output",
+			"error", err.Error(),
+		)
+		os.Exit(1)
+	}
+	log = configured
 	log.Info(
 		"application configuration loaded",
 		"config", config,
@@ -79,56 +745,219 @@ func loadConfig() cfg.Params {
 	return config
 }
 
-func certificates(hostname cfg.Hostname, ipAddress net.IP, timeout cfg.Duration) {
-	dialer := &net.Dialer{Timeout: time.Duration(timeout)}
+const storeKeyEnv = "CERTTRACKER_STORE_KEY"
+
+// openStore reads the optional base64 encryption key from the environment.
+func openStore(config cfg.Params) (*store.Store, error) {
+	var key []byte
+	if encoded := os.Getenv(storeKeyEnv); encoded != "" {
+		var err error
+		key, err = base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", storeKeyEnv, err)
+		}
+	}
+	return store.Open(config.StoreDir, key)
+}
+
+// scan connects to target and records its chain, how long that took and
+// what went wrong. It also returns the connection state, for checks that
+// need more than the stored fields, or why no chain was captured. The
+// handshake is verified against the system roots first; if verification
+// fails, the chain is captured without it and the verification error is
+// recorded with each certificate.
+func scan(ctx context.Context, target scanTarget, timeout cfg.Duration) (results.ScanResult, *tls.ConnectionState, error) {
 	// TODO: concurrency
-	conn, err := tls.DialWithDialer(
-		dialer,
-		"tcp",
-		net.JoinHostPort(ipAddress.String(), "443"),
-		&tls.Config{
-			InsecureSkipVerify: true,
-			ServerName:         string(hostname),
-		})
+	result := results.ScanResult{
+		Time:       time.Now(),
+		Hostname:   target.Hostname,
+		ServerName: target.ServerName,
+		IPAddress:  target.IPAddress,
+		CNAMEs:     target.CNAMEs,
+		Resolvers:  target.Resolvers,
+		Tenants:    target.Tenants,
+		Labels:     target.Labels,
+	}
+	conn, stats, err := dialTLS(ctx, target, timeout, false)
+	var verifyError string
+	var verr *tls.CertificateVerificationError
+	if errors.As(err, &verr) {
+		verifyError = verr.Err.Error()
+		result.Errors = append(result.Errors, verifyError)
+		conn, stats, err = dialTLS(ctx, target, timeout, true)
+	}
+	result.Timings = results.Timings{
+		DNS:       target.Lookup,
+		Connect:   stats.connect,
+		Handshake: stats.handshake,
+		Total:     time.Since(result.Time),
+	}
 	if err != nil {
-		log.Error("connection error",
-			"error", err,
-		)
-		return
+		result.Errors = append(result.Errors, err.Error())
+		return result, nil, err
 	}
 	defer conn.Close()
 	state := conn.ConnectionState()
 	if len(state.PeerCertificates) == 0 {
-		log.Warn("no certificates",
-			"hostname", hostname,
-			"ipAddress", ipAddress,
-		)
-		return
+		err := errors.New("no certificates")
+		result.Errors = append(result.Errors, err.Error())
+		return result, nil, err
+	}
+	connection := store.Connection{
+		ServerName:       target.ServerName,
+		Version:          tls.VersionName(state.Version),
+		Resumed:          state.DidResume,
+		PeerCertificates: len(state.PeerCertificates),
+		HandshakeBytes:   stats.handshakeBytes,
+		ALPN:             state.NegotiatedProtocol,
+	}
+	for _, cert := range state.PeerCertificates {
+		connection.ChainBytes += len(cert.Raw)
 	}
 	for i, cert := range state.PeerCertificates {
-		handle(cert, i, hostname, ipAddress)
+		c := handle(cert, i, target.Hostname, target.IPAddress)
+		c.VerifyError = verifyError
+		c.Connection = connection
+		result.Chain = append(result.Chain, c)
 	}
+	result.Chain[0].Trust = classify(state.PeerCertificates, systemRoots())
+	result.Chain[0].NameMismatch = nameMismatch(state.PeerCertificates[0], target.ServerName)
+	return result, &state, nil
 }
 
-func handle(cert *x509.Certificate, index int, hostname cfg.Hostname, ipAddress net.IP) {
-	c := make(map[string]any)
+// dialStats describe a dialTLS connection: how many bytes the handshake
+// took in both directions, and how long connecting and the handshake took,
+// not counting a STARTTLS exchange between them.
+type dialStats struct {
+	handshakeBytes     int64
+	connect, handshake time.Duration
+}
 
-	c["hostname"] = hostname
-	c["ipAddress"] = ipAddress
-	c["index"] = index
+// dialTLS returns the stats gathered so far even when it fails. Cancelling
+// ctx abandons the dial, STARTTLS exchange or handshake in progress. The
+// target's own timeout, when set, replaces timeout. An empty server name
+// sends no SNI. QUIC targets are handshaken over UDP.
+func dialTLS(ctx context.Context, target scanTarget, timeout cfg.Duration, insecure bool) (tlsConn, dialStats, error) {
+	if target.Timeout > 0 {
+		timeout = target.Timeout
+	}
+	if target.Hostname.Protocol() == "quic" {
+		return dialQUIC(ctx, target, timeout, insecure)
+	}
+	hostname, ipAddress := target.Hostname, target.IPAddress
+	var stats dialStats
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout))
+	defer cancel()
+	dialer := net.Dialer{KeepAlive: time.Duration(target.KeepAlive)}
+	if target.Source != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: target.Source}
+	}
+	started := time.Now()
+	raw, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ipAddress.String(), hostname.Port()))
+	stats.connect = time.Since(started)
+	if err != nil {
+		return nil, stats, err
+	}
+	config := &tls.Config{
+		InsecureSkipVerify: insecure,
+		ServerName:         target.ServerName,
+		NextProtos:         target.ALPN,
+	}
+	if capture.matches(hostname) {
+		config.KeyLogWriter = capture.keyLog
+		recorded, err := capture.record(raw, hostname, ipAddress, time.Now())
+		if err != nil {
+			raw.Close()
+			return nil, stats, err
+		}
+		raw = recorded
+	}
+	if protocol := hostname.Protocol(); protocol != "tls" {
+		deadline, _ := ctx.Deadline()
+		raw.SetDeadline(deadline)
+		// unblocks the exchange at once on shutdown
+		stop := context.AfterFunc(ctx, func() { raw.SetDeadline(time.Now()) })
+		err := starttls.Negotiate(raw, protocol)
+		if !stop() || err != nil {
+			raw.Close()
+			return nil, stats, errors.Join(err, ctx.Err())
+		}
+		raw.SetDeadline(time.Time{})
+	}
+	counted := &countingConn{Conn: raw}
+	conn := tls.Client(counted, config)
+	started = time.Now()
+	err = conn.HandshakeContext(ctx)
+	stats.handshake, stats.handshakeBytes = time.Since(started), counted.n
+	if err != nil {
+		raw.Close()
+		return nil, stats, err
+	}
+	return conn, stats, nil
+}
+
+// countingConn counts the bytes read and written through it.
+type countingConn struct {
+	net.Conn
+	n int64
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.n += int64(n)
+	return n, err
+}
+
+func handle(cert *x509.Certificate, index int, hostname cfg.Hostname, ipAddress net.IP) store.Certificate {
+	c := store.Certificate{
+		Hostname:              hostname,
+		IPAddress:             ipAddress,
+		Index:                 index,
+		SerialNumber:          cert.SerialNumber.Text(16),
+		Subject:               cert.Subject.String(),
+		Issuer:                cert.Issuer.String(),
+		NotBefore:             cert.NotBefore,
+		NotAfter:              cert.NotAfter,
+		DNSNames:              cert.DNSNames,
+		OCSPServers:           cert.OCSPServer,
+		CRLDistributionPoints: cert.CRLDistributionPoints,
+	}
+	c.KeyAlgorithm, c.KeySize = keyStrength(cert)
+	c.Curve = keyCurve(cert)
+	c.SignatureAlgorithm = cert.SignatureAlgorithm.String()
+	c.SPKIPin = spkiPin(cert)
 
 	if index == 0 {
-		c["target"] = "leaf"
+		c.Target = "leaf"
 	} else {
-		c["target"] = "intermediate"
+		c.Target = "intermediate"
 	}
 
 	sha256Hash := sha256.Sum256(cert.Raw)
-	c["sha256Fingerprint"] = hex.EncodeToString(sha256Hash[:])
+	c.SHA256Fingerprint = hex.EncodeToString(sha256Hash[:])
+	return c
+}
 
-	log.Info("certificate scanned",
-		"details", c,
-	)
+// activeResolver is the healthiest configured resolver, or the first one
+// when there's no choice to make.
+func activeResolver(config cfg.Params) net.IP {
+	if resolvers == nil {
+		return config.DNSresolvers[0]
+	}
+	return config.DNSresolvers[resolvers.Active()]
+}
+
+func dnsClient(dnsServer net.IP, timeout cfg.Duration) dns.Client {
+	return dns.Client{
+		Address: net.JoinHostPort(dnsServer.String(), "53"),
+		Timeout: time.Duration(timeout),
+	}
 }
 
 func resolver(dnsServer net.IP, timeout cfg.Duration) *net.Resolver {
@@ -138,77 +967,89 @@ func resolver(dnsServer net.IP, timeout cfg.Duration) *net.Resolver {
 			dialer := net.Dialer{
 				Timeout: time.Duration(timeout),
 			}
-			return dialer.DialContext(
+			conn, err := dialer.DialContext(
 				ctx,
 				network,
 				net.JoinHostPort(dnsServer.String(), "53"),
 			)
+			if err != nil {
+				return nil, err
+			}
+			return dnsTTLs.Wrap(conn), nil
 		},
 	}
 }
 
-func resolve(hostnames []cfg.Hostname, resolver *net.Resolver, timeout cfg.Duration) ([]nameAddressMap, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout))
-	defer cancel()
-
-	mappings := make(chan nameAddressMap, len(hostnames))
-	errors := make(chan error, len(hostnames))
-
-	for _, hostname := range hostnames {
+// resolve looks up every hostname at once, each lookup with its own
+// timeout so a slow one can't use up the others' time. Hostnames that fail
+// to resolve are returned with Err set. It only fails when ctx is done.
+func resolve(ctx context.Context, hostnames []cfg.Hostname, resolver *net.Resolver, timeout cfg.Duration) ([]nameAddressMap, error) {
+	results := make([]nameAddressMap, len(hostnames))
+	var wg sync.WaitGroup
+	for i, hostname := range hostnames {
+		wg.Add(1)
 		go func() {
-			ipAddrs, err := resolver.LookupIPAddr(ctx, string(hostname))
-			if err != nil {
-				errors <- err
-				return
-			}
-			var addresses []net.IP
-			for _, address := range ipAddrs {
-				addresses = append(addresses, address.IP)
-				ptrs, err := resolver.LookupAddr(ctx, address.String())
-				if err != nil {
-					log.Warn("reverse lookup error",
-						"addr", address.String(),
-					)
-				}
-				for _, ptr := range ptrs {
-					log.Info("reverse DNS lookup",
-						"addr", address.String(),
-						"ptr", ptr,
-					)
-				}
-			}
-			mappings <- nameAddressMap{
-				Hostname:    hostname,
-				IPAddresses: addresses,
-			}
+			defer wg.Done()
+			results[i] = resolveHostname(ctx, hostname, resolver, time.Duration(timeout))
 		}()
 	}
-
-	var results []nameAddressMap
-	var errs []error
-	for range hostnames {
-		select {
-		case result := <-mappings:
-			results = append(results, result)
-		case err := <-errors:
-			errs = append(errs, err)
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
+	return results, nil
+}
 
-	if len(errs) > 0 && len(results) == 0 {
-		log.Warn(
-			"all DNS lookups failed; logging only first error",
-			"error", errs[0],
-		)
-		for _, err := range errs {
-			log.Debug(
-				"debug logging all DNS lookup errors",
-				"error", err,
+// resolveHostname looks up hostname, logging the reverse DNS of its
+// addresses.
+func resolveHostname(ctx context.Context, hostname cfg.Hostname, resolver *net.Resolver, timeout time.Duration) nameAddressMap {
+	mapping := nameAddressMap{Hostname: hostname}
+	lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	started := time.Now()
+	ipAddrs, err := resolver.LookupIPAddr(lookupCtx, hostname.Host())
+	mapping.Lookup = time.Since(started)
+	if err != nil {
+		mapping.Err = err
+		return mapping
+	}
+	for _, address := range ipAddrs {
+		mapping.IPAddresses = append(mapping.IPAddresses, address.IP)
+		ptrCtx, cancel := context.WithTimeout(ctx, timeout)
+		ptrs, err := resolver.LookupAddr(ptrCtx, address.String())
+		cancel()
+		if err != nil {
+			log.Warn("reverse lookup error",
+				"addr", address.String(),
+			)
+		}
+		for _, ptr := range ptrs {
+			log.Info("reverse DNS lookup",
+				"addr", address.String(),
+				"ptr", ptr,
 			)
 		}
 	}
+	return mapping
+}
 
-	return results, nil
+// fakeClock builds the clock for --fake-now or --time-offset.
+func fakeClock(fakeNow, timeOffset string) (clock.Clock, error) {
+	switch {
+	case fakeNow != "" && timeOffset != "":
+		return clock.Clock{}, errors.New("--fake-now and --time-offset are mutually exclusive")
+	case fakeNow != "":
+		t, err := time.Parse(time.RFC3339, fakeNow)
+		if err != nil {
+			return clock.Clock{}, fmt.Errorf("--fake-now: %w", err)
+		}
+		return clock.StartingAt(t), nil
+	case timeOffset != "":
+		offset, err := cfg.ParseDuration(timeOffset)
+		if err != nil {
+			return clock.Clock{}, fmt.Errorf("--time-offset: %w", err)
+		}
+		return clock.Shifted(offset), nil
+	}
+	return clock.Clock{}, nil
 }
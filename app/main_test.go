@@ -2,17 +2,25 @@ package main
 
 import (
 	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
-	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"math/big"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -112,35 +120,23 @@ func TestHandle(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Capture log output by redirecting to a test logger
-			var logOutput strings.Builder
-			testLog := slog.New(slog.NewTextHandler(&logOutput, &slog.HandlerOptions{Level: slog.LevelDebug}))
-			originalLog := log
-			log = testLog
-			defer func() { log = originalLog }()
-
-			handle(tt.cert, tt.index, tt.hostname, tt.ipAddress)
+			c := handle(tt.cert, tt.index, tt.hostname, tt.ipAddress)
 
-			// Verify the log output contains expected information
-			output := logOutput.String()
-			if !strings.Contains(output, "certificate scanned") {
-				t.Error("Expected log message 'certificate scanned'")
+			if c.Hostname != tt.hostname {
+				t.Errorf("Hostname = %s, want %s", c.Hostname, tt.hostname)
 			}
-			if !strings.Contains(output, string(tt.hostname)) {
-				t.Errorf("Expected hostname %s in log output", tt.hostname)
+			if !c.IPAddress.Equal(tt.ipAddress) {
+				t.Errorf("IPAddress = %s, want %s", c.IPAddress, tt.ipAddress)
 			}
-			if !strings.Contains(output, tt.ipAddress.String()) {
-				t.Errorf("Expected IP address %s in log output", tt.ipAddress)
-			}
-			if !strings.Contains(output, tt.wantTarget) {
-				t.Errorf("Expected target %s in log output", tt.wantTarget)
+			if c.Target != tt.wantTarget {
+				t.Errorf("Target = %s, want %s", c.Target, tt.wantTarget)
 			}
 
 			// Verify SHA256 fingerprint format
 			expectedHash := sha256.Sum256(tt.cert.Raw)
 			expectedFingerprint := hex.EncodeToString(expectedHash[:])
-			if !strings.Contains(output, expectedFingerprint) {
-				t.Errorf("Expected SHA256 fingerprint %s in log output", expectedFingerprint)
+			if c.SHA256Fingerprint != expectedFingerprint {
+				t.Errorf("SHA256Fingerprint = %s, want %s", c.SHA256Fingerprint, expectedFingerprint)
 			}
 		})
 	}
@@ -152,22 +148,21 @@ func TestResolveWithMockResolver(t *testing.T) {
 	resolver := &net.Resolver{}
 
 	tests := []struct {
-		name      string
-		hostnames []cfg.Hostname
-		timeout   cfg.Duration
-		wantErr   bool
+		name       string
+		hostnames  []cfg.Hostname
+		timeout    cfg.Duration
+		wantFailed bool
 	}{
 		{
 			name:      "empty hostnames",
 			hostnames: []cfg.Hostname{},
 			timeout:   cfg.Duration(30 * time.Second),
-			wantErr:   false,
 		},
 		{
-			name:      "single hostname with short timeout",
-			hostnames: []cfg.Hostname{"example.com"},
-			timeout:   cfg.Duration(1 * time.Nanosecond), // Very short timeout to trigger timeout
-			wantErr:   true,
+			name:       "single hostname with short timeout",
+			hostnames:  []cfg.Hostname{"example.com"},
+			timeout:    cfg.Duration(1 * time.Nanosecond), // Very short timeout to trigger timeout
+			wantFailed: true,
 		},
 	}
 
@@ -180,17 +175,18 @@ func TestResolveWithMockResolver(t *testing.T) {
 			log = testLog
 			defer func() { log = originalLog }()
 
-			results, err := resolve(tt.hostnames, resolver, tt.timeout)
+			results, err := resolve(context.Background(), tt.hostnames, resolver, tt.timeout)
 
-			if tt.wantErr && err == nil {
-				t.Error("Expected error but got none")
-			}
-			if !tt.wantErr && err != nil {
+			if err != nil {
 				t.Errorf("Expected no error but got: %v", err)
 			}
-
-			if len(tt.hostnames) == 0 && len(results) != 0 {
-				t.Errorf("Expected 0 results for empty hostnames, got %d", len(results))
+			if len(results) != len(tt.hostnames) {
+				t.Fatalf("Expected %d results, got %d", len(tt.hostnames), len(results))
+			}
+			for _, r := range results {
+				if failed := r.Err != nil; failed != tt.wantFailed {
+					t.Errorf("%s: Err = %v, want failed %v", r.Hostname, r.Err, tt.wantFailed)
+				}
 			}
 		})
 	}
@@ -204,14 +200,49 @@ func TestResolveTimeout(t *testing.T) {
 	// Use system resolver for this test
 	resolver := &net.Resolver{}
 
-	_, err := resolve(hostnames, resolver, timeout)
+	results, err := resolve(context.Background(), hostnames, resolver, timeout)
+	if err != nil {
+		t.Fatalf("Expected the timeout on the hostname, got: %v", err)
+	}
 
 	// Should get a timeout error
-	if err == nil {
-		t.Error("Expected timeout error but got none")
+	if len(results) != 1 || results[0].Err == nil {
+		t.Fatalf("Expected timeout error but got %+v", results)
+	}
+	var dnsErr *net.DNSError
+	if !errors.As(results[0].Err, &dnsErr) || !dnsErr.IsTimeout {
+		t.Errorf("Expected a DNS timeout error, got: %v", results[0].Err)
+	}
+}
+
+func TestResolveSeparateTimeouts(t *testing.T) {
+	// a lookup that hangs must not fail the others
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	hostnames := []cfg.Hostname{"slow.example.com", "localhost"}
+	started := time.Now()
+	results, err := resolve(context.Background(), hostnames, resolver, cfg.Duration(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("resolve() error = %v", err)
+	}
+	if took := time.Since(started); took > 2*time.Second {
+		t.Errorf("resolve() took %v, want about one timeout", took)
+	}
+	for i, r := range results {
+		if r.Hostname != hostnames[i] {
+			t.Errorf("results[%d] = %s, want %s", i, r.Hostname, hostnames[i])
+		}
 	}
-	if err != nil && !strings.Contains(err.Error(), "context deadline exceeded") {
-		t.Errorf("Expected context deadline exceeded error, got: %v", err)
+	if results[0].Err == nil {
+		t.Error("slow lookup succeeded")
+	}
+	if results[1].Err != nil || len(results[1].IPAddresses) == 0 {
+		t.Errorf("localhost = %+v, want addresses", results[1])
 	}
 }
 
@@ -269,45 +300,107 @@ func TestLoadConfigFlow(t *testing.T) {
 }
 
 // Helper function to create a test certificate
-func createTestCertificate(t *testing.T) *x509.Certificate {
-	// Generate a private key
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+func TestFakeClock(t *testing.T) {
+	tests := []struct {
+		name       string
+		fakeNow    string
+		timeOffset string
+		want       time.Duration
+		wantErr    bool
+	}{
+		{name: "real clock", want: 0},
+		{name: "offset in days", timeOffset: "30d", want: 30 * 24 * time.Hour},
+		{name: "fake now", fakeNow: time.Now().Add(48 * time.Hour).Format(time.RFC3339), want: 48 * time.Hour},
+		{name: "invalid timestamp", fakeNow: "tomorrow", wantErr: true},
+		{name: "invalid offset", timeOffset: "soon", wantErr: true},
+		{name: "both", fakeNow: "2030-01-01T00:00:00Z", timeOffset: "1d", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk, err := fakeClock(tt.fakeNow, tt.timeOffset)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fakeClock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := clk.Offset(); got < tt.want-time.Minute || got > tt.want+time.Minute {
+				t.Errorf("fakeClock() offset = %v, want about %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func newKey(t *testing.T) crypto.Signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
-		t.Fatalf("Failed to generate private key: %v", err)
+		t.Fatal(err)
 	}
+	return key
+}
 
-	// Create certificate template
-	template := x509.Certificate{
+// certTemplate is a template for a CA named subject, or a leaf for the
+// name subject, valid until notAfter.
+func certTemplate(subject string, isCA bool, notAfter time.Time) *x509.Certificate {
+	template := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: subject},
+		NotAfter: notAfter,
+		IsCA:     isCA,
+	}
+	if !isCA {
+		template.DNSNames = []string{subject}
+	}
+	return template
+}
+
+// issueCert signs template for key, by parent or self-signed when parent
+// is nil. A serial number or NotBefore the template leaves out is filled
+// in, and CAs may sign certificates and CRLs.
+func issueCert(t *testing.T, template *x509.Certificate, key crypto.Signer, parent *testCA) *x509.Certificate {
+	t.Helper()
+	if template.SerialNumber == nil {
+		template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	}
+	if template.NotBefore.IsZero() {
+		template.NotBefore = time.Now().Add(-48 * time.Hour)
+	}
+	if template.IsCA {
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	}
+	issuer, signer := template, key
+	if parent != nil {
+		issuer, signer = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, key.Public(), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func createTestCertificate(t *testing.T) *x509.Certificate {
+	return issueCert(t, &x509.Certificate{
 		SerialNumber: big.NewInt(1),
 		Subject: pkix.Name{
-			Organization:  []string{"Test Org"},
-			Country:       []string{"US"},
-			Province:      []string{""},
-			Locality:      []string{"Test City"},
-			StreetAddress: []string{""},
-			PostalCode:    []string{""},
+			Organization: []string{"Test Org"},
+			Country:      []string{"US"},
+			Locality:     []string{"Test City"},
 		},
 		NotBefore:   time.Now(),
 		NotAfter:    time.Now().Add(365 * 24 * time.Hour),
-		KeyUsage:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
 		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
 		DNSNames:    []string{"example.com", "test.com"},
-	}
-
-	// Create the certificate
-	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &privateKey.PublicKey, privateKey)
-	if err != nil {
-		t.Fatalf("Failed to create certificate: %v", err)
-	}
-
-	// Parse the certificate
-	cert, err := x509.ParseCertificate(certDER)
-	if err != nil {
-		t.Fatalf("Failed to parse certificate: %v", err)
-	}
-
-	return cert
+	}, newKey(t), nil)
 }
 
 func BenchmarkHandle(b *testing.B) {
@@ -333,3 +426,79 @@ func BenchmarkResolver(b *testing.B) {
 		resolver(dnsServer, timeout)
 	}
 }
+
+func TestScanRecordsHandshake(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	target := scanTarget{
+		Hostname:   cfg.Hostname(net.JoinHostPort("example.com", port)),
+		ServerName: "example.com",
+		IPAddress:  net.ParseIP(host),
+		CNAMEs:     []string{"edge.example.net"},
+		Lookup:     3 * time.Millisecond,
+		Tenants:    []string{""},
+	}
+	result, state, _ := scan(context.Background(), target, cfg.Duration(5*time.Second))
+	if state == nil || result.Failed() {
+		t.Fatal("scan() captured no chain from a server with an untrusted certificate")
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "unknown authority") {
+		t.Errorf("Errors = %q, want the verification error", result.Errors)
+	}
+	if !slices.Equal(result.CNAMEs, target.CNAMEs) {
+		t.Errorf("CNAMEs = %v, want the target's %v", result.CNAMEs, target.CNAMEs)
+	}
+	if timings := result.Timings; timings.DNS != target.Lookup || timings.Connect <= 0 || timings.Handshake <= 0 || timings.Total < timings.Connect+timings.Handshake {
+		t.Errorf("Timings = %+v, want the target's DNS lookup, and connect and handshake within the total", timings)
+	}
+	for _, c := range result.Chain {
+		if !strings.Contains(c.VerifyError, "unknown authority") {
+			t.Errorf("VerifyError = %q, want unknown authority", c.VerifyError)
+		}
+		want := store.Connection{
+			ServerName:       "example.com",
+			Version:          "TLS 1.3",
+			PeerCertificates: len(result.Chain),
+			HandshakeBytes:   c.Connection.HandshakeBytes,
+			ChainBytes:       len(server.Certificate().Raw),
+		}
+		if c.Connection != want {
+			t.Errorf("Connection = %+v, want %+v", c.Connection, want)
+		}
+		if c.Connection.HandshakeBytes <= int64(c.Connection.ChainBytes) {
+			t.Errorf("HandshakeBytes = %d, want more than the %d byte chain", c.Connection.HandshakeBytes, c.Connection.ChainBytes)
+		}
+	}
+}
+
+func TestDialTLS_Cancelled(t *testing.T) {
+	// accepts connections but never answers the handshake
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, _, err = dialTLS(ctx, scanTarget{Hostname: cfg.Hostname("example.com:" + port), ServerName: "example.com", IPAddress: net.ParseIP("127.0.0.1")}, cfg.Duration(time.Minute), true)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("dialTLS() error = %v, want context.Canceled", err)
+	}
+	if took := time.Since(start); took > 5*time.Second {
+		t.Errorf("dialTLS() took %v after cancellation", took)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"time"
+)
+
+// maintenanceMuter mutes alerts about hostnames in a maintenance window
+// open at now.
+func maintenanceMuter(windows []cfg.Maintenance, now func() time.Time) alert.Muter {
+	if len(windows) == 0 {
+		return nil
+	}
+	return func(a alert.Alert) bool {
+		hostname, ok := a.Labels["hostname"]
+		if !ok {
+			return false
+		}
+		at := now()
+		for _, w := range windows {
+			if w.Covers(at) && covered(w.Hostnames, cfg.Hostname(hostname)) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceMuter(t *testing.T) {
+	now := time.Date(2025, 7, 12, 23, 0, 0, 0, time.UTC)
+	windows := []cfg.Maintenance{
+		{Hostnames: []cfg.Hostname{"old.example.com"}, Until: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{Hostnames: []cfg.Hostname{"*.shop.example.com"}, From: now.Add(-time.Hour), Until: now.Add(time.Hour)},
+		{Hostnames: []cfg.Hostname{"api.example.com"}, From: now.Add(time.Hour), Until: now.Add(2 * time.Hour)},
+	}
+	mute := maintenanceMuter(windows, func() time.Time { return now })
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   bool
+	}{
+		{name: "snoozed", labels: map[string]string{"hostname": "old.example.com"}, want: true},
+		{name: "in a wildcard window", labels: map[string]string{"hostname": "www.shop.example.com"}, want: true},
+		{name: "window not yet open", labels: map[string]string{"hostname": "api.example.com"}, want: false},
+		{name: "not in any window", labels: map[string]string{"hostname": "www.example.com"}, want: false},
+		{name: "no hostname", labels: map[string]string{"domain": "old.example.com"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mute(alert.Alert{Labels: tt.labels}); got != tt.want {
+				t.Errorf("muted = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if maintenanceMuter(nil, time.Now) != nil {
+		t.Error("Expected no muter without maintenance windows")
+	}
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"maps"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// Exporter keeps the last cycle's metrics and the running error counts, and
+// serves them to Prometheus.
+type Exporter struct {
+	mu     sync.Mutex
+	cycle  *Cycle
+	errors map[Endpoint]int
+}
+
+func NewExporter() *Exporter {
+	return &Exporter{errors: make(map[Endpoint]int)}
+}
+
+// HandshakeFailed counts a failed handshake with an endpoint.
+func (e *Exporter) HandshakeFailed(hostname string, ipAddress net.IP) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errors[Endpoint{Hostname: hostname, IPAddress: ipAddress.String()}]++
+}
+
+// Update publishes a finished cycle and returns it with the error counts
+// filled in.
+func (e *Exporter) Update(c Cycle) Cycle {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c.HandshakeErrors = maps.Clone(e.errors)
+	e.cycle = &c
+	return c
+}
+
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	c := e.cycle
+	e.mu.Unlock()
+	if c == nil {
+		http.Error(w, "no scan has finished yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	Write(w, *c)
+}
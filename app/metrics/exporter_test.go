@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExporter(t *testing.T) {
+	e := NewExporter()
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("before any cycle: status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	e.HandshakeFailed("example.com", net.ParseIP("192.0.2.1"))
+	e.Update(testCycle())
+	e.HandshakeFailed("example.com", net.ParseIP("192.0.2.1"))
+	c := e.Update(testCycle())
+	if got := c.HandshakeErrors[Endpoint{Hostname: "example.com", IPAddress: "192.0.2.1"}]; got != 2 {
+		t.Errorf("Update() handshake errors = %d, want 2", got)
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	want := `tls_handshake_errors_total{hostname="example.com",ip_address="192.0.2.1"} 2`
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("ServeHTTP() = %d %q, want %q", rec.Code, rec.Body.String(), want)
+	}
+}
@@ -0,0 +1,176 @@
+// Package metrics renders scan results in the Prometheus text exposition
+// format.
+package metrics
+
+import (
+	"bufio"
+	"cert-tracker/alert"
+	"cert-tracker/store"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Endpoint is an address a hostname was scanned at.
+type Endpoint struct {
+	Hostname  string
+	IPAddress string
+}
+
+// Cycle is what a scan cycle produced.
+type Cycle struct {
+	Snapshot store.Snapshot
+	Duration time.Duration
+	// Failed counts the hostnames whose handshakes failed.
+	Failed int
+	Alerts []alert.Alert
+	// HandshakeErrors counts the failed handshakes with each endpoint since
+	// the tracker started.
+	HandshakeErrors map[Endpoint]int
+	// Labels are the labels configured for each hostname.
+	Labels map[string]map[string]string
+}
+
+// protocolVersions are the TLS versions deep scans probe.
+var protocolVersions = []string{"TLS 1.0", "TLS 1.1", "TLS 1.2", "TLS 1.3"}
+
+// Write renders c as metrics.
+func Write(w io.Writer, c Cycle) error {
+	b := bufio.NewWriter(w)
+	family(b, "cert_not_after_timestamp_seconds", "gauge", "Expiry of the leaf certificate served at an endpoint, as a Unix time.")
+	for _, cert := range c.Snapshot.Certificates {
+		if cert.Index != 0 {
+			continue
+		}
+		sample(b, "cert_not_after_timestamp_seconds", float64(cert.NotAfter.Unix()),
+			"tenant", cert.Tenant,
+			"hostname", string(cert.Hostname),
+			"ip_address", store.Address(cert.IPAddress),
+			"serial_number", cert.SerialNumber,
+			"issuer", cert.Issuer,
+		)
+	}
+	family(b, "cert_chain_length", "gauge", "Certificates an endpoint sent in its handshake.")
+	for _, cert := range c.Snapshot.Certificates {
+		if cert.Index != 0 {
+			continue
+		}
+		sample(b, "cert_chain_length", float64(cert.Connection.PeerCertificates),
+			"tenant", cert.Tenant,
+			"hostname", string(cert.Hostname),
+			"ip_address", store.Address(cert.IPAddress),
+		)
+	}
+	family(b, "tls_version_accepted", "gauge", "Whether an endpoint accepted a TLS version when last probed by a deep scan.")
+	for _, cert := range c.Snapshot.Certificates {
+		if cert.Index != 0 || len(cert.Protocols) == 0 {
+			continue
+		}
+		for _, version := range protocolVersions {
+			accepted := 0.0
+			if slices.Contains(cert.Protocols, version) {
+				accepted = 1
+			}
+			sample(b, "tls_version_accepted", accepted,
+				"tenant", cert.Tenant,
+				"hostname", string(cert.Hostname),
+				"ip_address", store.Address(cert.IPAddress),
+				"version", version,
+			)
+		}
+	}
+	family(b, "tls_handshake_errors_total", "counter", "Failed handshakes with an endpoint since the tracker started.")
+	endpoints := make([]Endpoint, 0, len(c.HandshakeErrors))
+	for e := range c.HandshakeErrors {
+		endpoints = append(endpoints, e)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Hostname != endpoints[j].Hostname {
+			return endpoints[i].Hostname < endpoints[j].Hostname
+		}
+		return endpoints[i].IPAddress < endpoints[j].IPAddress
+	})
+	for _, e := range endpoints {
+		sample(b, "tls_handshake_errors_total", float64(c.HandshakeErrors[e]), "hostname", e.Hostname, "ip_address", e.IPAddress)
+	}
+	family(b, "cert_target_labels", "gauge", "Labels configured for a hostname, always 1, to join onto other series.")
+	for _, hostname := range slices.Sorted(maps.Keys(c.Labels)) {
+		labels := []string{"hostname", hostname}
+		for _, name := range slices.Sorted(maps.Keys(c.Labels[hostname])) {
+			labels = append(labels, "label_"+name, c.Labels[hostname][name])
+		}
+		sample(b, "cert_target_labels", 1, labels...)
+	}
+	family(b, "scan_last_timestamp_seconds", "gauge", "When the last scan cycle started, as a Unix time.")
+	sample(b, "scan_last_timestamp_seconds", float64(c.Snapshot.Time.Unix()))
+	family(b, "scan_duration_seconds", "gauge", "How long the last scan cycle took.")
+	sample(b, "scan_duration_seconds", c.Duration.Seconds())
+	family(b, "scan_failed_hostnames", "gauge", "Hostnames whose handshakes failed in the last scan cycle.")
+	sample(b, "scan_failed_hostnames", float64(c.Failed))
+
+	firing := map[alert.Severity]int{alert.Warning: 0, alert.Critical: 0}
+	for _, a := range c.Alerts {
+		firing[a.Severity]++
+	}
+	severities := make([]string, 0, len(firing))
+	for s := range firing {
+		severities = append(severities, string(s))
+	}
+	sort.Strings(severities)
+	family(b, "alerts_firing", "gauge", "Alerts firing, by severity.")
+	for _, s := range severities {
+		sample(b, "alerts_firing", float64(firing[alert.Severity(s)]), "severity", s)
+	}
+	return b.Flush()
+}
+
+// WriteFile renders c to path through a temporary file, so a collector
+// reading path never sees it half written.
+func WriteFile(path string, c Cycle) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := Write(tmp, c); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func family(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes one value with labels given as name, value pairs.
+func sample(w io.Writer, name string, value float64, labels ...string) {
+	io.WriteString(w, name)
+	if len(labels) > 0 {
+		pairs := make([]string, 0, len(labels)/2)
+		for i := 0; i+1 < len(labels); i += 2 {
+			pairs = append(pairs, labels[i]+`="`+escape(labels[i+1])+`"`)
+		}
+		io.WriteString(w, "{"+strings.Join(pairs, ",")+"}")
+	}
+	fmt.Fprintf(w, " %g\n", value)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/store"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testCycle() Cycle {
+	return Cycle{
+		Snapshot: store.Snapshot{
+			Time: time.Unix(1700000000, 0),
+			Certificates: []store.Certificate{
+				{
+					Hostname:     "example.com",
+					IPAddress:    net.ParseIP("192.0.2.1"),
+					SerialNumber: "3ab0f",
+					Issuer:       `CN=Test "CA"`,
+					NotAfter:     time.Unix(1800000000, 0),
+					Connection:   store.Connection{PeerCertificates: 2},
+					Protocols:    []string{"TLS 1.0", "TLS 1.2", "TLS 1.3"},
+				},
+				{Hostname: "example.com", IPAddress: net.ParseIP("192.0.2.1"), Index: 1, NotAfter: time.Unix(1900000000, 0)},
+			},
+		},
+		Duration: 1500 * time.Millisecond,
+		Failed:   2,
+		Alerts:   []alert.Alert{{Key: "expiry:example.com", Severity: alert.Critical}},
+		HandshakeErrors: map[Endpoint]int{
+			{Hostname: "example.org", IPAddress: "192.0.2.9"}: 3,
+		},
+		Labels: map[string]map[string]string{
+			"example.com": {"team": "payments", "env": "prod"},
+		},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var out strings.Builder
+	if err := Write(&out, testCycle()); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"# TYPE cert_not_after_timestamp_seconds gauge\n",
+		`cert_not_after_timestamp_seconds{tenant="",hostname="example.com",ip_address="192.0.2.1",serial_number="3ab0f",issuer="CN=Test \"CA\""} 1.8e+09` + "\n",
+		`cert_chain_length{tenant="",hostname="example.com",ip_address="192.0.2.1"} 2` + "\n",
+		`tls_version_accepted{tenant="",hostname="example.com",ip_address="192.0.2.1",version="TLS 1.0"} 1` + "\n",
+		`tls_version_accepted{tenant="",hostname="example.com",ip_address="192.0.2.1",version="TLS 1.1"} 0` + "\n",
+		`tls_version_accepted{tenant="",hostname="example.com",ip_address="192.0.2.1",version="TLS 1.3"} 1` + "\n",
+		"# TYPE tls_handshake_errors_total counter\n",
+		`tls_handshake_errors_total{hostname="example.org",ip_address="192.0.2.9"} 3` + "\n",
+		`cert_target_labels{hostname="example.com",label_env="prod",label_team="payments"} 1` + "\n",
+		"scan_last_timestamp_seconds 1.7e+09\n",
+		"scan_duration_seconds 1.5\n",
+		"scan_failed_hostnames 2\n",
+		`alerts_firing{severity="critical"} 1` + "\n",
+		`alerts_firing{severity="warning"} 0` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Write() missing %q in\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "1.9e+09") {
+		t.Error("Write() included an intermediate certificate")
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cert_tracker.prom")
+	if err := WriteFile(path, testCycle()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "scan_failed_hostnames 2") {
+		t.Errorf("file = %s", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("left %d files behind, want only the metrics file", len(entries))
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/store"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// nameMismatch returns serverName when leaf isn't valid for it, or "" when
+// it is or no server name was sent.
+func nameMismatch(leaf *x509.Certificate, serverName string) string {
+	if serverName == "" || leaf.VerifyHostname(serverName) == nil {
+		return ""
+	}
+	return serverName
+}
+
+// checkNames raises an alert for every scanned endpoint whose leaf
+// certificate isn't valid for the server name it was asked for, naming the
+// names it is valid for.
+func checkNames(snapshot store.Snapshot, alerts *alert.Manager, now time.Time) {
+	for _, c := range snapshot.Certificates {
+		if c.Index != 0 {
+			continue
+		}
+		endpoint := string(c.Hostname) + "@" + c.IPAddress.String()
+		names := "no DNS names"
+		if len(c.DNSNames) > 0 {
+			names = strings.Join(c.DNSNames, ", ")
+		}
+		alerts.Set(c.NameMismatch != "", alert.Alert{
+			Key:      alert.Key("name-mismatch", c.Tenant, endpoint),
+			Severity: alert.Critical,
+			Summary:  fmt.Sprintf("%s serves a certificate for %s, not %s", endpoint, names, c.NameMismatch),
+			Tenant:   c.Tenant,
+			Labels: map[string]string{
+				"hostname":    string(c.Hostname),
+				"ipAddress":   c.IPAddress.String(),
+				"serverName":  c.NameMismatch,
+				"dnsNames":    strings.Join(c.DNSNames, ","),
+				"fingerprint": c.SHA256Fingerprint,
+			},
+			Since: now,
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/store"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNameMismatch(t *testing.T) {
+	leaf := createTestCertificate(t)
+
+	tests := []struct {
+		serverName string
+		want       string
+	}{
+		{serverName: "example.com", want: ""},
+		{serverName: "Test.com", want: ""},
+		{serverName: "www.example.com", want: "www.example.com"},
+		{serverName: "other.org", want: "other.org"},
+		{serverName: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serverName, func(t *testing.T) {
+			if got := nameMismatch(leaf, tt.serverName); got != tt.want {
+				t.Errorf("nameMismatch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckNames(t *testing.T) {
+	tests := []struct {
+		name         string
+		nameMismatch string
+		wantFiring   bool
+	}{
+		{name: "valid for its name"},
+		{name: "mismatch", nameMismatch: "www.example.com", wantFiring: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := alert.NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+			snapshot := store.Snapshot{Certificates: []store.Certificate{{
+				Hostname:     "www.example.com",
+				IPAddress:    net.ParseIP("192.0.2.1"),
+				DNSNames:     []string{"example.com", "api.example.com"},
+				NameMismatch: tt.nameMismatch,
+				VerifyError:  tt.nameMismatch,
+			}}}
+			checkNames(snapshot, alerts, time.Now())
+			checkVerification(snapshot, alerts, time.Now())
+			a, firing := alerts.Get("name-mismatch:www.example.com@192.0.2.1")
+			if firing != tt.wantFiring {
+				t.Fatalf("firing = %v, want %v", firing, tt.wantFiring)
+			}
+			if firing && !strings.Contains(a.Summary, "example.com, api.example.com, not www.example.com") {
+				t.Errorf("Summary = %q, want the names the certificate is valid for", a.Summary)
+			}
+			// the mismatch is the verification error, so it isn't alerted twice
+			if _, firing := alerts.Get("verify-failed:www.example.com@192.0.2.1"); firing {
+				t.Error("verify-failed fired for a name mismatch")
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/notify"
+	"cert-tracker/store"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// newNotifier routes events to the configured webhooks, Slack channels,
+// email addresses, incident services and Alertmanagers, globally and for
+// each tenant. It's set up even with none, so a reload can add some.
+func newNotifier(config cfg.Params) (*notify.Dispatcher, error) {
+	routes, err := configuredRoutes(config)
+	if err != nil {
+		return nil, err
+	}
+	return notify.NewDispatcher(log, routes), nil
+}
+
+// configuredRoutes builds the routes of the global notifications, then
+// those of each tenant.
+func configuredRoutes(config cfg.Params) ([]notify.Route, error) {
+	client := &http.Client{Timeout: time.Duration(config.Timeout)}
+	routes, err := notificationRoutes(config.Notifications, client)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range config.Tenants {
+		tenantRoutes, err := notificationRoutes(t.Notifications, client)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %q: %w", t.Name, err)
+		}
+		for _, r := range tenantRoutes {
+			r.Name, r.Tenant = t.Name+" "+r.Name, t.Name
+			routes = append(routes, r)
+		}
+	}
+	return routes, nil
+}
+
+// notificationRoutes builds a route for every destination in n.
+func notificationRoutes(n cfg.Notifications, client *http.Client) ([]notify.Route, error) {
+	var routes []notify.Route
+	for _, w := range n.Webhooks {
+		routes = append(routes, notify.Route{
+			Name:  w.URL,
+			Kinds: w.Events,
+			Match: w.Match,
+			Notifier: &notify.Webhook{
+				URL:     w.URL,
+				Secret:  string(w.Secret),
+				Client:  client,
+				Retries: w.Retries,
+				Backoff: time.Second,
+			},
+		})
+	}
+	for _, s := range n.Slack {
+		text, err := notify.ParseTemplate(s.Template, notify.DefaultBody)
+		if err != nil {
+			return nil, err
+		}
+		name := "slack " + s.Channel
+		if s.Token == "" {
+			name = "slack webhook"
+		}
+		routes = append(routes, notify.Route{
+			Name:  name,
+			Kinds: s.Events,
+			Match: s.Match,
+			Notifier: &notify.Slack{
+				WebhookURL: string(s.WebhookURL),
+				Token:      string(s.Token),
+				Channel:    s.Channel,
+				APIURL:     notify.SlackAPI,
+				Template:   text,
+				Client:     client,
+			},
+		})
+	}
+	for _, e := range n.Email {
+		subject, err := notify.ParseTemplate(e.Subject, notify.DefaultSubject)
+		if err != nil {
+			return nil, err
+		}
+		body, err := notify.ParseTemplate(e.Template, notify.DefaultBody)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, notify.Route{
+			Name:  "email " + strings.Join(e.To, ", "),
+			Kinds: e.Events,
+			Match: e.Match,
+			Notifier: &notify.Email{
+				Addr:     e.SMTPServer,
+				Username: e.Username,
+				Password: string(e.Password),
+				From:     e.From,
+				To:       e.To,
+				Subject:  subject,
+				Body:     body,
+			},
+		})
+	}
+	// incidents follow alerts as they fire, escalate and resolve
+	alertKinds := []string{notify.AlertFiring, notify.AlertEscalated, notify.AlertResolved}
+	for _, p := range n.PagerDuty {
+		routes = append(routes, notify.Route{
+			Name:     "pagerduty",
+			Kinds:    alertKinds,
+			Match:    p.Match,
+			Notifier: notify.NewPagerDuty(string(p.RoutingKey), p.Alerts, client),
+		})
+	}
+	for _, o := range n.Opsgenie {
+		apiURL := notify.OpsgenieUS
+		if o.Region == "eu" {
+			apiURL = notify.OpsgenieEU
+		}
+		routes = append(routes, notify.Route{
+			Name:     "opsgenie",
+			Kinds:    alertKinds,
+			Match:    o.Match,
+			Notifier: notify.NewOpsgenie(string(o.APIKey), apiURL, o.Alerts, client),
+		})
+	}
+	for _, a := range n.Alertmanager {
+		routes = append(routes, notify.Route{
+			Name:     "alertmanager " + a.URL,
+			Kinds:    alertKinds,
+			Match:    a.Match,
+			Notifier: notify.NewAlertmanager(a.URL, a.Labels, time.Duration(a.Resend), client),
+		})
+	}
+	return routes, nil
+}
+
+// alertWatcher queues an event for every alert that fires, escalates or
+// resolves.
+func alertWatcher(d *notify.Dispatcher, now func() time.Time) alert.Watcher {
+	return func(state string, a alert.Alert) {
+		d.Send(notify.AlertEvent(state, a, now()))
+	}
+}
+
+func notifyRotations(d *notify.Dispatcher, found []store.Rotation, now time.Time) {
+	for _, r := range found {
+		d.Send(notify.Event{Kind: notify.CertificateRotated, Time: now, Rotation: &r})
+	}
+}
@@ -0,0 +1,165 @@
+package notify
+
+import (
+	"cert-tracker/alert"
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Alertmanager sends alerts to the Prometheus Alertmanager at URL through
+// its v2 API. Each alert is labelled with its kind as alertname, its
+// severity and tenant, its own labels such as hostname and fingerprint,
+// and Labels. Alertmanager resolves an alert that isn't sent again before
+// it ends, so firing alerts end a few Resend intervals ahead and Run sends
+// them again every Resend, while resolved ones end when they resolve.
+type Alertmanager struct {
+	URL     string
+	Labels  map[string]string
+	Resend  time.Duration
+	Client  *http.Client
+	Retries int
+	Backoff time.Duration
+
+	mu     sync.Mutex
+	firing map[string]alert.Alert
+}
+
+func NewAlertmanager(apiURL string, labels map[string]string, resend time.Duration, client *http.Client) *Alertmanager {
+	return &Alertmanager{
+		URL:     strings.TrimSuffix(apiURL, "/"),
+		Labels:  labels,
+		Resend:  resend,
+		Client:  client,
+		Retries: 3,
+		Backoff: time.Second,
+		firing:  make(map[string]alert.Alert),
+	}
+}
+
+// postableAlert is an alert as the v2 API takes it.
+type postableAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+}
+
+func (am *Alertmanager) Notify(ctx context.Context, e Event) error {
+	if e.Alert == nil {
+		return nil
+	}
+	return am.post(ctx, am.track(e))
+}
+
+// track records e's alert as firing or resolved and returns the batch that
+// tells Alertmanager. Posting happens outside the lock, so a slow
+// Alertmanager doesn't hold up other events or the resends.
+func (am *Alertmanager) track(e Event) []postableAlert {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	a := *e.Alert
+	previous, ok := am.firing[a.Key]
+	if e.Kind == AlertResolved {
+		delete(am.firing, a.Key)
+		if ok {
+			a = previous
+		}
+		return []postableAlert{am.postable(a, e.Time)}
+	}
+	var batch []postableAlert
+	if ok && !maps.Equal(am.labels(previous), am.labels(a)) {
+		// Alertmanager tells alerts apart by their labels, so an alert
+		// whose severity or labels changed replaces the one sent before
+		batch = append(batch, am.postable(previous, e.Time))
+	}
+	am.firing[a.Key] = a
+	return append(batch, am.postable(a, e.Time.Add(am.lifetime())))
+}
+
+// Restore resends the alerts still firing from before a restart with the
+// others, so Alertmanager doesn't resolve them.
+func (am *Alertmanager) Restore(alerts []alert.Alert) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	for _, a := range alerts {
+		am.firing[a.Key] = a
+	}
+}
+
+// Run sends the firing alerts again every Resend until ctx is done.
+func (am *Alertmanager) Run(ctx context.Context) {
+	ticker := time.NewTicker(am.Resend)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			am.resend(ctx, now)
+		}
+	}
+}
+
+func (am *Alertmanager) resend(ctx context.Context, now time.Time) error {
+	batch := am.firingBatch(now)
+	if len(batch) == 0 {
+		return nil
+	}
+	return am.post(ctx, batch)
+}
+
+// firingBatch returns the firing alerts, to end a lifetime after now.
+func (am *Alertmanager) firingBatch(now time.Time) []postableAlert {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	var batch []postableAlert
+	for _, key := range slices.Sorted(maps.Keys(am.firing)) {
+		batch = append(batch, am.postable(am.firing[key], now.Add(am.lifetime())))
+	}
+	return batch
+}
+
+// lifetime is how long Alertmanager keeps a firing alert open without
+// hearing about it again, long enough to ride out a few failed resends.
+func (am *Alertmanager) lifetime() time.Duration {
+	return 4 * am.Resend
+}
+
+func (am *Alertmanager) labels(a alert.Alert) map[string]string {
+	// like Prometheus' external labels, Labels don't override the alert's
+	labels := maps.Clone(am.Labels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	kind, _, _ := strings.Cut(a.Key, ":")
+	labels["alertname"], labels["severity"] = kind, string(a.Severity)
+	if a.Tenant != "" {
+		labels["tenant"] = a.Tenant
+	}
+	for k, v := range a.Labels {
+		if v != "" {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+func (am *Alertmanager) postable(a alert.Alert, ends time.Time) postableAlert {
+	return postableAlert{
+		Labels:      am.labels(a),
+		Annotations: map[string]string{"summary": a.Summary},
+		StartsAt:    a.Since,
+		EndsAt:      ends,
+	}
+}
+
+func (am *Alertmanager) post(ctx context.Context, batch []postableAlert) error {
+	return retry(ctx, am.Retries, am.Backoff, func() (bool, error) {
+		return postJSON(ctx, am.Client, am.URL+"/api/v2/alerts", nil, batch)
+	})
+}
@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"cert-tracker/alert"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAlertmanager_Notify(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v2/alerts" {
+			t.Errorf("got %s %s", r.Method, r.URL.Path)
+		}
+		var alerts []postableAlert
+		json.NewDecoder(r.Body).Decode(&alerts)
+		for _, a := range alerts {
+			state := "firing"
+			if !a.EndsAt.After(a.StartsAt.Add(time.Hour)) {
+				state = "ended"
+			}
+			got = append(got, state+" "+a.Labels["alertname"]+" "+a.Labels["severity"]+" "+a.Labels["hostname"]+" "+a.Labels["fingerprint"]+" "+a.Labels["env"])
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	am := NewAlertmanager(srv.URL+"/", map[string]string{"env": "prod", "severity": "ignored"}, time.Hour, srv.Client())
+
+	since := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	expiry := func(state string, severity alert.Severity, fingerprint string) Event {
+		return Event{Kind: "alert." + state, Time: since, Alert: &alert.Alert{
+			Key:      "expiry:www.example.com",
+			Severity: severity,
+			Summary:  "www.example.com expires soon",
+			Labels:   map[string]string{"hostname": "www.example.com", "fingerprint": fingerprint},
+			Since:    since,
+		}}
+	}
+	steps := []struct {
+		name  string
+		event Event
+		want  []string
+	}{
+		{name: "fires", event: expiry(alert.Firing, alert.Warning, "ab12"), want: []string{"firing expiry warning www.example.com ab12 prod"}},
+		{name: "escalates", event: expiry(alert.Escalated, alert.Critical, "ab12"), want: []string{
+			"ended expiry warning www.example.com ab12 prod",
+			"firing expiry critical www.example.com ab12 prod",
+		}},
+		{name: "resolves", event: expiry(alert.Resolved, alert.Critical, "cd34"), want: []string{"ended expiry critical www.example.com ab12 prod"}},
+		{name: "rotation", event: Event{Kind: CertificateRotated}},
+	}
+	for _, step := range steps {
+		got = nil
+		if err := am.Notify(context.Background(), step.event); err != nil {
+			t.Errorf("%s: Notify() error = %v", step.name, err)
+		}
+		if !slices.Equal(got, step.want) {
+			t.Errorf("%s: sent %q, want %q", step.name, got, step.want)
+		}
+	}
+
+	// firing alerts are sent again, resolved ones aren't
+	am.Notify(context.Background(), expiry(alert.Firing, alert.Warning, "ef56"))
+	got = nil
+	if err := am.resend(context.Background(), since); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"firing expiry warning www.example.com ef56 prod"}; !slices.Equal(got, want) {
+		t.Errorf("resent %q, want %q", got, want)
+	}
+	am.Notify(context.Background(), expiry(alert.Resolved, alert.Warning, "ef56"))
+	got = nil
+	am.resend(context.Background(), since)
+	if len(got) != 0 {
+		t.Errorf("resent %q after every alert resolved", got)
+	}
+}
+
+func TestAlertmanager_SlowPostDoesNotBlock(t *testing.T) {
+	received, release := make(chan struct{}), make(chan struct{})
+	var posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first post hangs until the test is done
+		if posts.Add(1) == 1 {
+			close(received)
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	defer close(release)
+	am := NewAlertmanager(srv.URL, nil, time.Hour, srv.Client())
+
+	since := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	go am.Notify(context.Background(), Event{Kind: AlertFiring, Time: since, Alert: &alert.Alert{Key: "expiry:www.example.com", Severity: alert.Warning, Since: since}})
+	<-received
+
+	done := make(chan error)
+	go func() {
+		done <- am.resend(context.Background(), since.Add(time.Hour))
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("resend() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("resend() waited for a hanging post")
+	}
+}
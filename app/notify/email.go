@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Email sends each event through the SMTP server at Addr, from From to
+// every address in To, with a subject and plain text body rendered from
+// Subject and Body. Username and Password, when set, authenticate with
+// PLAIN, which net/smtp only allows over TLS or to localhost.
+type Email struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+	To       []string
+	Subject  *template.Template
+	Body     *template.Template
+}
+
+func (m *Email) Notify(ctx context.Context, e Event) error {
+	msg := NewMessage(e)
+	subject, err := render(m.Subject, msg)
+	if err != nil {
+		return err
+	}
+	body, err := render(m.Body, msg)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if m.Username != "" {
+		host, _, _ := net.SplitHostPort(m.Addr)
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+	// smtp.SendMail can't be cancelled, so it gets its own goroutine
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(m.Addr, auth, m.From, m.To, m.compose(subject, body, e.Time)) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Email) compose(subject, body string, now time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(m.To, ", "))
+	// a template could put a line break in the subject, starting a header
+	subject = strings.Join(strings.Fields(subject), " ")
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP accepts one message and hands over its envelope and data.
+func fakeSMTP(t *testing.T) (string, <-chan []string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		text := textproto.NewConn(conn)
+		text.PrintfLine("220 mail.example.com ESMTP")
+		var got []string
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.Fields(line + " ")[0]); cmd {
+			case "EHLO", "HELO":
+				text.PrintfLine("250 mail.example.com")
+			case "MAIL", "RCPT":
+				got = append(got, line)
+				text.PrintfLine("250 OK")
+			case "DATA":
+				text.PrintfLine("354 go ahead")
+				data, _ := text.ReadDotBytes()
+				got = append(got, string(data))
+				text.PrintfLine("250 queued")
+			case "QUIT":
+				text.PrintfLine("221 bye")
+				received <- got
+				return
+			default:
+				text.PrintfLine("250 OK")
+			}
+		}
+	}()
+	return ln.Addr().String(), received
+}
+
+func TestEmail_Notify(t *testing.T) {
+	addr, received := fakeSMTP(t)
+	subject, _ := ParseTemplate("", DefaultSubject)
+	body, _ := ParseTemplate("", DefaultBody)
+	m := &Email{
+		Addr:    addr,
+		From:    "cert-tracker@example.com",
+		To:      []string{"ops@example.com", "sec@example.com"},
+		Subject: subject,
+		Body:    body,
+	}
+	if err := m.Notify(context.Background(), expiringEvent(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	got := <-received
+	if len(got) != 4 || got[0] != "MAIL FROM:<cert-tracker@example.com>" ||
+		got[1] != "RCPT TO:<ops@example.com>" || got[2] != "RCPT TO:<sec@example.com>" {
+		t.Fatalf("envelope = %q", got)
+	}
+	for _, want := range []string{
+		"To: ops@example.com, sec@example.com\n",
+		"Subject: Firing: www.example.com certificate expires in 30 days, on 2025-07-01\n",
+		"Days to expiry: 30 (2025-07-01)\n",
+		"SHA-256 fingerprint: 9f86d081884c7d65\n",
+	} {
+		if !strings.Contains(got[3], want) {
+			t.Errorf("message lacks %q:\n%s", want, got[3])
+		}
+	}
+}
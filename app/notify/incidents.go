@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"bytes"
+	"cert-tracker/alert"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// incidents decides when an incident service should open and close
+// incidents. Every critical alert of the chosen kinds about a hostname
+// shares one incident, opened with the first and resolved with the last,
+// however many addresses or cycles report it.
+type incidents struct {
+	kinds []string
+	// the critical alerts behind each open incident
+	open map[string][]string
+}
+
+// incident actions
+const (
+	trigger = "trigger"
+	resolve = "resolve"
+)
+
+// track returns what to do about e, if anything, and the incident's key.
+func (in *incidents) track(e Event) (action, key string) {
+	if e.Alert == nil {
+		return "", ""
+	}
+	a := e.Alert
+	kind, _, _ := strings.Cut(a.Key, ":")
+	if !slices.Contains(in.kinds, kind) {
+		return "", ""
+	}
+	subject := a.Labels["hostname"]
+	if subject == "" {
+		subject = a.Key
+	}
+	key = alert.Key(kind, a.Tenant, subject)
+	if in.open == nil {
+		in.open = make(map[string][]string)
+	}
+	alerts := in.open[key]
+	if e.Kind != AlertResolved && a.Severity == alert.Critical {
+		if slices.Contains(alerts, a.Key) {
+			return "", key
+		}
+		in.open[key] = append(alerts, a.Key)
+		if len(alerts) > 0 {
+			return "", key
+		}
+		return trigger, key
+	}
+	i := slices.Index(alerts, a.Key)
+	if i < 0 {
+		return "", key
+	}
+	in.open[key] = slices.Delete(alerts, i, i+1)
+	if len(in.open[key]) > 0 {
+		return "", key
+	}
+	delete(in.open, key)
+	return resolve, key
+}
+
+// Restore tracks the critical alerts still firing from before a restart as
+// open incidents, so their resolves are sent.
+func (in *incidents) Restore(alerts []alert.Alert) {
+	for _, a := range alerts {
+		in.track(Event{Kind: AlertFiring, Alert: &a})
+	}
+}
+
+// forget undoes the tracking of a trigger that couldn't be delivered, so
+// the next critical alert tries again.
+func (in *incidents) forget(key string) {
+	delete(in.open, key)
+}
+
+// retry calls post until it succeeds, fails for good or has been retried
+// retries times, backoff apart and doubling each time.
+func retry(ctx context.Context, retries int, backoff time.Duration, post func() (bool, error)) error {
+	for attempt := 0; ; attempt++ {
+		again, err := post()
+		if err == nil || !again || attempt == retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// postJSON POSTs payload to url and reports whether a failure is worth
+// retrying.
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, payload any) (bool, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cert-tracker")
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return false, fmt.Errorf("%s returned %s", url, resp.Status)
+}
@@ -0,0 +1,166 @@
+package notify
+
+import (
+	"cert-tracker/alert"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func incidentEvent(state, key, hostname string, severity alert.Severity) Event {
+	return AlertEvent(state, alert.Alert{
+		Key:      key,
+		Severity: severity,
+		Summary:  key,
+		Labels:   map[string]string{"hostname": hostname},
+	}, time.Now())
+}
+
+func TestPagerDuty_Notify(t *testing.T) {
+	var got []string
+	status := http.StatusAccepted
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event struct {
+			RoutingKey  string         `json:"routing_key"`
+			EventAction string         `json:"event_action"`
+			DedupKey    string         `json:"dedup_key"`
+			Payload     map[string]any `json:"payload"`
+		}
+		json.NewDecoder(r.Body).Decode(&event)
+		if event.RoutingKey != "R0UT1NG" || event.EventAction == trigger && event.Payload["severity"] != "critical" {
+			t.Errorf("event = %+v", event)
+		}
+		got = append(got, event.EventAction+" "+event.DedupKey)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	p := NewPagerDuty("R0UT1NG", []string{"expiry", "verify-failed"}, srv.Client())
+	p.URL, p.Retries = srv.URL, 0
+
+	steps := []struct {
+		name  string
+		event Event
+		want  []string
+	}{
+		{name: "warning", event: incidentEvent(alert.Firing, "expiry:www.example.com", "www.example.com", alert.Warning)},
+		{name: "critical", event: incidentEvent(alert.Escalated, "expiry:www.example.com", "www.example.com", alert.Critical), want: []string{"trigger expiry:www.example.com"}},
+		{name: "first address fails", event: incidentEvent(alert.Firing, "verify-failed:www.example.com@192.0.2.1", "www.example.com", alert.Critical), want: []string{"trigger verify-failed:www.example.com"}},
+		{name: "second address fails", event: incidentEvent(alert.Firing, "verify-failed:www.example.com@192.0.2.2", "www.example.com", alert.Critical)},
+		{name: "first address recovers", event: incidentEvent(alert.Resolved, "verify-failed:www.example.com@192.0.2.1", "www.example.com", alert.Critical)},
+		{name: "second address recovers", event: incidentEvent(alert.Resolved, "verify-failed:www.example.com@192.0.2.2", "www.example.com", alert.Critical), want: []string{"resolve verify-failed:www.example.com"}},
+		{name: "renewed", event: incidentEvent(alert.Resolved, "expiry:www.example.com", "www.example.com", alert.Critical), want: []string{"resolve expiry:www.example.com"}},
+		{name: "other kind", event: incidentEvent(alert.Firing, "ct-policy:www.example.com", "www.example.com", alert.Critical)},
+		{name: "rotation", event: Event{Kind: CertificateRotated}},
+	}
+	for _, step := range steps {
+		got = nil
+		if err := p.Notify(context.Background(), step.event); err != nil {
+			t.Errorf("%s: Notify() error = %v", step.name, err)
+		}
+		if !slices.Equal(got, step.want) {
+			t.Errorf("%s: sent %q, want %q", step.name, got, step.want)
+		}
+	}
+
+	// an undelivered trigger is tried again with the next alert
+	status = http.StatusBadRequest
+	event := incidentEvent(alert.Firing, "expiry:api.example.com", "api.example.com", alert.Critical)
+	if err := p.Notify(context.Background(), event); err == nil {
+		t.Error("Notify() succeeded against a failing endpoint")
+	}
+	status, got = http.StatusAccepted, nil
+	if err := p.Notify(context.Background(), event); err != nil || !slices.Equal(got, []string{"trigger expiry:api.example.com"}) {
+		t.Errorf("retried trigger sent %q, error = %v", got, err)
+	}
+}
+
+func TestOpsgenie_Notify(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "GenieKey k3y" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		got = append(got, r.URL.RequestURI()+" "+asString(body["alias"])+" "+asString(body["priority"]))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+	o := NewOpsgenie("k3y", srv.URL, []string{"expiry"}, srv.Client())
+
+	o.Notify(context.Background(), incidentEvent(alert.Firing, "expiry:www.example.com", "www.example.com", alert.Critical))
+	o.Notify(context.Background(), incidentEvent(alert.Resolved, "expiry:www.example.com", "www.example.com", alert.Critical))
+	want := []string{
+		"/v2/alerts expiry:www.example.com P1",
+		"/v2/alerts/expiry:www.example.com/close?identifierType=alias  ",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+}
+
+func asString(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+func TestDispatcher_Restore(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/alerts" {
+			var alerts []postableAlert
+			json.NewDecoder(r.Body).Decode(&alerts)
+			for _, a := range alerts {
+				got = append(got, "alertmanager "+a.Labels["alertname"]+" ends "+a.EndsAt.Format(time.RFC3339))
+			}
+		} else {
+			var event struct {
+				EventAction string `json:"event_action"`
+				DedupKey    string `json:"dedup_key"`
+			}
+			json.NewDecoder(r.Body).Decode(&event)
+			got = append(got, "pagerduty "+event.EventAction+" "+event.DedupKey)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+	pd := NewPagerDuty("R0UT1NG", []string{"expiry"}, srv.Client())
+	pd.URL, pd.Retries = srv.URL+"/pagerduty", 0
+	am := NewAlertmanager(srv.URL, nil, time.Minute, srv.Client())
+	am.Retries = 0
+	alertKinds := []string{AlertFiring, AlertEscalated, AlertResolved}
+	d := NewDispatcher(slog.New(slog.NewTextHandler(io.Discard, nil)), []Route{
+		{Name: "pagerduty", Kinds: alertKinds, Notifier: pd},
+		{Name: "alertmanager", Kinds: alertKinds, Notifier: am},
+	})
+
+	// restarted with a critical alert still firing: nobody is notified again
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	firing := alert.Alert{Key: "expiry:www.example.com", Severity: alert.Critical, Labels: map[string]string{"hostname": "www.example.com"}, Since: now.Add(-time.Hour)}
+	d.Restore([]alert.Alert{firing})
+	if len(got) != 0 {
+		t.Fatalf("Restore() sent %q", got)
+	}
+
+	// Alertmanager keeps hearing about it
+	if err := am.resend(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alertmanager expiry ends " + now.Add(4*time.Minute).Format(time.RFC3339)}; !slices.Equal(got, want) {
+		t.Errorf("resent %q, want %q", got, want)
+	}
+
+	// and its resolve reaches both
+	got = nil
+	d.deliver(context.Background(), AlertEvent(alert.Resolved, firing, now))
+	want := []string{"pagerduty resolve expiry:www.example.com", "alertmanager expiry ends " + now.Format(time.RFC3339)}
+	if !slices.Equal(got, want) {
+		t.Errorf("resolve sent %q, want %q", got, want)
+	}
+}
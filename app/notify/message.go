@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Message is what Slack and email templates see of an event. Hostname,
+// NotAfter, DaysLeft, Issuer and Fingerprint are set when the event is
+// about a certificate; DaysLeft is negative once it has expired.
+type Message struct {
+	Kind        string
+	Time        time.Time
+	Title       string
+	Severity    string
+	Summary     string
+	Tenant      string
+	Hostname    string
+	NotAfter    time.Time
+	DaysLeft    int
+	Issuer      string
+	Fingerprint string
+}
+
+// DefaultSubject and DefaultBody are the templates used unless a channel
+// configures its own.
+const (
+	DefaultSubject = `{{.Title}}`
+	DefaultBody    = `{{.Title}}
+{{with .Hostname}}
+Hostname: {{.}}{{end}}{{if not .NotAfter.IsZero}}
+Days to expiry: {{.DaysLeft}} ({{.NotAfter.Format "2006-01-02"}}){{end}}{{with .Issuer}}
+Issuer: {{.}}{{end}}{{with .Fingerprint}}
+SHA-256 fingerprint: {{.}}{{end}}
+`
+)
+
+// NewMessage gathers what's worth saying about e.
+func NewMessage(e Event) Message {
+	m := Message{Kind: e.Kind, Time: e.Time}
+	switch {
+	case e.Alert != nil:
+		a := e.Alert
+		m.Severity, m.Summary, m.Tenant = string(a.Severity), a.Summary, a.Tenant
+		m.Hostname, m.Issuer, m.Fingerprint = a.Labels["hostname"], a.Labels["issuer"], a.Labels["fingerprint"]
+		if notAfter, err := time.Parse(time.RFC3339, a.Labels["notAfter"]); err == nil {
+			m.NotAfter = notAfter
+			m.DaysLeft = int(notAfter.Sub(e.Time).Hours() / 24)
+		}
+		if m.Summary == "" {
+			m.Summary = a.Key
+		}
+		state := strings.TrimPrefix(e.Kind, "alert.")
+		m.Title = strings.ToUpper(state[:1]) + state[1:] + ": " + m.Summary
+	case e.Rotation != nil:
+		r := e.Rotation
+		m.Tenant, m.Hostname = r.Tenant, string(r.Hostname)
+		m.Issuer, m.Fingerprint = r.NewIssuer, r.NewFingerprint
+		m.Summary = m.Hostname + " certificate rotated"
+		m.Title = "Rotated: " + m.Summary
+	}
+	return m
+}
+
+// ParseTemplate parses text as a message template, or fallback when text is
+// empty.
+func ParseTemplate(text, fallback string) (*template.Template, error) {
+	if text == "" {
+		text = fallback
+	}
+	return template.New("message").Parse(text)
+}
+
+func render(t *template.Template, m Message) (string, error) {
+	var b bytes.Buffer
+	if err := t.Execute(&b, m); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
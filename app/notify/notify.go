@@ -0,0 +1,213 @@
+// Package notify delivers alerts and certificate events to the places people
+// watch.
+package notify
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/store"
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Event kinds.
+const (
+	AlertFiring        = "alert.firing"
+	AlertEscalated     = "alert.escalated"
+	AlertResolved      = "alert.resolved"
+	CertificateRotated = "certificate.rotated"
+)
+
+// Event is something to tell people about. Alert or Rotation is set to
+// match Kind.
+type Event struct {
+	Kind     string          `json:"kind"`
+	Time     time.Time       `json:"time"`
+	Alert    *alert.Alert    `json:"alert,omitempty"`
+	Rotation *store.Rotation `json:"rotation,omitempty"`
+}
+
+// labels are the labels of the alert or rotation.
+func (e Event) labels() map[string]string {
+	switch {
+	case e.Alert != nil:
+		return e.Alert.Labels
+	case e.Rotation != nil:
+		return e.Rotation.Labels
+	}
+	return nil
+}
+
+// tenant is the tenant of the alert or rotation.
+func (e Event) tenant() string {
+	switch {
+	case e.Alert != nil:
+		return e.Alert.Tenant
+	case e.Rotation != nil:
+		return e.Rotation.Tenant
+	}
+	return ""
+}
+
+// AlertEvent turns an alert transition into an event.
+func AlertEvent(state string, a alert.Alert, now time.Time) Event {
+	return Event{Kind: "alert." + state, Time: now, Alert: &a}
+}
+
+// Notifier delivers events to one destination.
+type Notifier interface {
+	Notify(ctx context.Context, e Event) error
+}
+
+// Runner is a Notifier with work of its own to do in the background,
+// which the Dispatcher runs alongside its deliveries.
+type Runner interface {
+	Run(ctx context.Context)
+}
+
+// Restorer is a Notifier that keeps track of firing alerts, and picks up
+// those still firing from before a restart without notifying anyone.
+type Restorer interface {
+	Restore(alerts []alert.Alert)
+}
+
+// Route sends the events of the listed kinds, or all events if none are
+// listed, to a notifier. With Match, only events whose labels include all
+// of its labels are sent, and with Tenant only that tenant's events.
+type Route struct {
+	Name     string
+	Kinds    []string
+	Match    map[string]string
+	Tenant   string
+	Notifier Notifier
+}
+
+func (r Route) wants(e Event) bool {
+	if len(r.Kinds) > 0 && !slices.Contains(r.Kinds, e.Kind) {
+		return false
+	}
+	if r.Tenant != "" && e.tenant() != r.Tenant {
+		return false
+	}
+	labels := e.labels()
+	for name, value := range r.Match {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// queueSize bounds the events waiting for delivery; a slow destination
+// shouldn't hold up scans or grow memory without limit.
+const queueSize = 1000
+
+// Dispatcher delivers events in the background, one at a time, so slow or
+// retrying destinations don't hold up scanning.
+type Dispatcher struct {
+	log    *slog.Logger
+	routes []Route
+	queue  chan queued
+}
+
+// queued is an event to deliver, or routes to switch to.
+type queued struct {
+	event   Event
+	reroute *reroute
+}
+
+type reroute struct {
+	routes []Route
+	firing []alert.Alert
+}
+
+func NewDispatcher(log *slog.Logger, routes []Route) *Dispatcher {
+	return &Dispatcher{log: log, routes: routes, queue: make(chan queued, queueSize)}
+}
+
+// Send queues e for delivery, dropping it if the queue is full.
+func (d *Dispatcher) Send(e Event) {
+	select {
+	case d.queue <- queued{event: e}:
+	default:
+		d.log.Warn("notification dropped; queue full", "kind", e.Kind)
+	}
+}
+
+// Reroute replaces the routes, e.g. after a config reload. Events sent
+// before it are delivered on the old routes and those sent after on the
+// new ones, whose notifiers are restored with the alerts firing in between
+// and whose runners replace the old ones. Unlike Send it waits for room in
+// the queue.
+func (d *Dispatcher) Reroute(routes []Route, firing []alert.Alert) {
+	d.queue <- queued{reroute: &reroute{routes: routes, firing: firing}}
+}
+
+// Restore hands the alerts still firing from before a restart to the
+// notifiers that track them, each getting those its route wants, so they
+// keep resending them and hear when they resolve. It must be called before
+// Run.
+func (d *Dispatcher) Restore(alerts []alert.Alert) {
+	for _, r := range d.routes {
+		restorer, ok := r.Notifier.(Restorer)
+		if !ok {
+			continue
+		}
+		var wanted []alert.Alert
+		for _, a := range alerts {
+			if r.wants(Event{Kind: AlertFiring, Alert: &a}) {
+				wanted = append(wanted, a)
+			}
+		}
+		restorer.Restore(wanted)
+	}
+}
+
+// Run delivers queued events until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	stop := d.runRoutes(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			stop()
+			return
+		case q := <-d.queue:
+			if q.reroute == nil {
+				d.deliver(ctx, q.event)
+				continue
+			}
+			stop()
+			d.routes = q.reroute.routes
+			d.Restore(q.reroute.firing)
+			stop = d.runRoutes(ctx)
+		}
+	}
+}
+
+// runRoutes runs the routes' Runner notifiers until the returned function
+// is called.
+func (d *Dispatcher) runRoutes(ctx context.Context) context.CancelFunc {
+	ctx, cancel := context.WithCancel(ctx)
+	for _, r := range d.routes {
+		if runner, ok := r.Notifier.(Runner); ok {
+			go runner.Run(ctx)
+		}
+	}
+	return cancel
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, e Event) {
+	for _, r := range d.routes {
+		if !r.wants(e) {
+			continue
+		}
+		if err := r.Notifier.Notify(ctx, e); err != nil {
+			d.log.Warn("notification failed",
+				"notifier", r.Name,
+				"kind", e.Kind,
+				"error", err,
+			)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Opsgenie API endpoints by region.
+const (
+	OpsgenieUS = "https://api.opsgenie.com"
+	OpsgenieEU = "https://api.eu.opsgenie.com"
+)
+
+// opsgenieMessageLimit is the longest message Opsgenie keeps.
+const opsgenieMessageLimit = 130
+
+// Opsgenie opens and closes alerts through the Alert API at URL, for the
+// critical alerts of the listed kinds, deduplicated per hostname by alias.
+type Opsgenie struct {
+	APIKey  string
+	URL     string
+	Client  *http.Client
+	Retries int
+	Backoff time.Duration
+	incidents
+}
+
+func NewOpsgenie(apiKey, apiURL string, kinds []string, client *http.Client) *Opsgenie {
+	return &Opsgenie{
+		APIKey:    apiKey,
+		URL:       apiURL,
+		Client:    client,
+		Retries:   3,
+		Backoff:   time.Second,
+		incidents: incidents{kinds: kinds},
+	}
+}
+
+func (o *Opsgenie) Notify(ctx context.Context, e Event) error {
+	action, key := o.track(e)
+	if action == "" {
+		return nil
+	}
+	endpoint := o.URL + "/v2/alerts"
+	payload := map[string]any{"source": "cert-tracker"}
+	if action == trigger {
+		m := NewMessage(e)
+		message := m.Summary
+		if r := []rune(message); len(r) > opsgenieMessageLimit {
+			message = string(r[:opsgenieMessageLimit-1]) + "…"
+		}
+		details := map[string]string{"tenant": m.Tenant, "issuer": m.Issuer, "fingerprint": m.Fingerprint}
+		if !m.NotAfter.IsZero() {
+			details["notAfter"] = m.NotAfter.Format(time.RFC3339)
+		}
+		payload["message"] = message
+		payload["alias"] = key
+		payload["description"] = m.Summary
+		payload["entity"] = m.Hostname
+		payload["priority"] = "P1"
+		payload["details"] = details
+	} else {
+		endpoint += "/" + url.PathEscape(key) + "/close?identifierType=alias"
+	}
+	header := http.Header{"Authorization": {"GenieKey " + o.APIKey}}
+	err := retry(ctx, o.Retries, o.Backoff, func() (bool, error) {
+		return postJSON(ctx, o.Client, endpoint, header, payload)
+	})
+	if err != nil && action == trigger {
+		o.forget(key)
+	}
+	return err
+}
@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// PagerDutyEvents is the PagerDuty Events API v2 endpoint.
+const PagerDutyEvents = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDuty opens and resolves incidents through the Events API v2 at URL,
+// for the critical alerts of the listed kinds, deduplicated per hostname.
+type PagerDuty struct {
+	RoutingKey string
+	URL        string
+	Client     *http.Client
+	Retries    int
+	Backoff    time.Duration
+	incidents
+}
+
+func NewPagerDuty(routingKey string, kinds []string, client *http.Client) *PagerDuty {
+	return &PagerDuty{
+		RoutingKey: routingKey,
+		URL:        PagerDutyEvents,
+		Client:     client,
+		Retries:    3,
+		Backoff:    time.Second,
+		incidents:  incidents{kinds: kinds},
+	}
+}
+
+func (p *PagerDuty) Notify(ctx context.Context, e Event) error {
+	action, key := p.track(e)
+	if action == "" {
+		return nil
+	}
+	event := map[string]any{
+		"routing_key":  p.RoutingKey,
+		"event_action": action,
+		"dedup_key":    key,
+	}
+	if action == trigger {
+		m := NewMessage(e)
+		details := map[string]any{"tenant": m.Tenant, "issuer": m.Issuer, "fingerprint": m.Fingerprint}
+		if !m.NotAfter.IsZero() {
+			details["notAfter"], details["daysLeft"] = m.NotAfter, m.DaysLeft
+		}
+		event["payload"] = map[string]any{
+			"summary":        m.Summary,
+			"source":         m.Hostname,
+			"severity":       "critical",
+			"timestamp":      m.Time,
+			"component":      "cert-tracker",
+			"custom_details": details,
+		}
+	}
+	err := retry(ctx, p.Retries, p.Backoff, func() (bool, error) {
+		return postJSON(ctx, p.Client, p.URL, nil, event)
+	})
+	if err != nil && action == trigger {
+		p.forget(key)
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"text/template"
+)
+
+// SlackAPI is where bot tokens post messages.
+const SlackAPI = "https://slack.com/api"
+
+// Slack posts each event as a message rendered from Template, either to an
+// incoming WebhookURL or, with a bot Token, to Channel through the Web API
+// at APIURL.
+type Slack struct {
+	WebhookURL string
+	Token      string
+	Channel    string
+	APIURL     string
+	Template   *template.Template
+	Client     *http.Client
+}
+
+func (s *Slack) Notify(ctx context.Context, e Event) error {
+	text, err := render(s.Template, NewMessage(e))
+	if err != nil {
+		return err
+	}
+	if s.Token == "" {
+		return s.post(ctx, s.WebhookURL, map[string]string{"text": text}, nil)
+	}
+	// the Web API answers 200 with ok false when it refuses a message
+	var answer struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := s.post(ctx, s.APIURL+"/chat.postMessage", map[string]string{"channel": s.Channel, "text": text}, &answer); err != nil {
+		return err
+	}
+	if !answer.OK {
+		return fmt.Errorf("slack: %s", answer.Error)
+	}
+	return nil
+}
+
+func (s *Slack) post(ctx context.Context, url string, payload map[string]string, answer any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("User-Agent", "cert-tracker")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("slack returned %s", resp.Status)
+	}
+	if answer == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(answer); err != nil {
+		return errors.Join(errors.New("slack: unreadable answer"), err)
+	}
+	return nil
+}
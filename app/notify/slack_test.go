@@ -0,0 +1,97 @@
+package notify
+
+import (
+	"cert-tracker/alert"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func expiringEvent(now time.Time) Event {
+	return AlertEvent(alert.Firing, alert.Alert{
+		Key:      "expiry:www.example.com",
+		Severity: alert.Warning,
+		Summary:  "www.example.com certificate expires in 30 days, on 2025-07-01",
+		Labels: map[string]string{
+			"hostname":    "www.example.com",
+			"notAfter":    "2025-07-01T00:00:00Z",
+			"issuer":      "CN=R11,O=Let's Encrypt,C=US",
+			"fingerprint": "9f86d081884c7d65",
+		},
+	}, now)
+}
+
+func TestNewMessage(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	tmpl, err := ParseTemplate("", DefaultBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := render(tmpl, NewMessage(expiringEvent(now)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Firing: www.example.com certificate expires in 30 days, on 2025-07-01
+
+Hostname: www.example.com
+Days to expiry: 30 (2025-07-01)
+Issuer: CN=R11,O=Let's Encrypt,C=US
+SHA-256 fingerprint: 9f86d081884c7d65
+`
+	if got != want {
+		t.Errorf("rendered:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSlack_Notify(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		answer  string
+		status  int
+		wantErr bool
+	}{
+		{name: "incoming webhook", status: http.StatusOK, answer: "ok"},
+		{name: "incoming webhook refused", status: http.StatusNotFound, wantErr: true},
+		{name: "bot token", token: "xoxb-1", status: http.StatusOK, answer: `{"ok": true}`},
+		{name: "bot token refused", token: "xoxb-1", status: http.StatusOK, answer: `{"ok": false, "error": "channel_not_found"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				wantPath, wantAuth := "/hook", ""
+				if tt.token != "" {
+					wantPath, wantAuth = "/chat.postMessage", "Bearer "+tt.token
+				}
+				if r.URL.Path != wantPath || r.Header.Get("Authorization") != wantAuth {
+					t.Errorf("request to %s with %q, want %s with %q", r.URL.Path, r.Header.Get("Authorization"), wantPath, wantAuth)
+				}
+				json.NewDecoder(r.Body).Decode(&got)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.answer))
+			}))
+			defer srv.Close()
+
+			tmpl, _ := ParseTemplate("{{.Hostname}} expires in {{.DaysLeft}} days", DefaultBody)
+			s := &Slack{Token: tt.token, Channel: "#certs", APIURL: srv.URL, Template: tmpl, Client: srv.Client()}
+			if tt.token == "" {
+				s.WebhookURL = srv.URL + "/hook"
+			}
+			err := s.Notify(context.Background(), expiringEvent(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got["text"] != "www.example.com expires in 30 days" {
+				t.Errorf("text = %q", got["text"])
+			}
+			if tt.token != "" && got["channel"] != "#certs" {
+				t.Errorf("payload = %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Webhook POSTs each event as JSON to URL. With a Secret, requests carry
+// X-Cert-Tracker-Timestamp and X-Cert-Tracker-Signature, the hex HMAC-SHA256
+// of the timestamp, a dot and the body, prefixed with "sha256=".
+type Webhook struct {
+	URL    string
+	Secret string
+	Client *http.Client
+	// Retries is how many more attempts a failed delivery gets, Backoff
+	// apart and doubling each time.
+	Retries int
+	Backoff time.Duration
+}
+
+func (w *Webhook) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	backoff := w.Backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(ctx, body, time.Now())
+		if err == nil || !retry || attempt == w.Retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post reports whether a failure is worth retrying.
+func (w *Webhook) post(ctx context.Context, body []byte, now time.Time) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cert-tracker")
+	if w.Secret != "" {
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		req.Header.Set("X-Cert-Tracker-Timestamp", timestamp)
+		req.Header.Set("X-Cert-Tracker-Signature", "sha256="+Sign(w.Secret, timestamp, body))
+	}
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return false, fmt.Errorf("webhook returned %s", resp.Status)
+}
+
+// Sign computes the signature a receiver should compare against, in
+// constant time, after checking the timestamp is recent.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/store"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhook_Notify(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		retries   int
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "delivered", statuses: []int{200}, retries: 3, wantCalls: 1},
+		{name: "retried after server error", statuses: []int{503, 429, 204}, retries: 3, wantCalls: 3},
+		{name: "retries exhausted", statuses: []int{500, 500, 500}, retries: 2, wantCalls: 3, wantErr: true},
+		{name: "client error not retried", statuses: []int{400}, retries: 3, wantCalls: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				timestamp := r.Header.Get("X-Cert-Tracker-Timestamp")
+				if got, want := r.Header.Get("X-Cert-Tracker-Signature"), "sha256="+Sign("s3cret", timestamp, body); got != want {
+					t.Errorf("signature = %q, want %q", got, want)
+				}
+				var e Event
+				if err := json.Unmarshal(body, &e); err != nil || e.Kind != AlertFiring || e.Alert == nil {
+					t.Errorf("body = %s, error = %v", body, err)
+				}
+				w.WriteHeader(tt.statuses[calls])
+				calls++
+			}))
+			defer srv.Close()
+
+			w := &Webhook{URL: srv.URL, Secret: "s3cret", Client: srv.Client(), Retries: tt.retries, Backoff: time.Millisecond}
+			e := AlertEvent(alert.Firing, alert.Alert{Key: "expiry:example.com", Severity: alert.Critical}, time.Now())
+			err := w.Notify(context.Background(), e)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+type recorder chan Event
+
+func (r recorder) Notify(_ context.Context, e Event) error {
+	r <- e
+	return nil
+}
+
+func TestDispatcher_Routes(t *testing.T) {
+	rotations, everything := make(recorder, 10), make(recorder, 10)
+	d := NewDispatcher(nil, []Route{
+		{Name: "rotations", Kinds: []string{CertificateRotated}, Notifier: rotations},
+		{Name: "everything", Notifier: everything},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	d.Send(Event{Kind: AlertFiring})
+	d.Send(Event{Kind: CertificateRotated})
+	for _, want := range []string{AlertFiring, CertificateRotated} {
+		if e := <-everything; e.Kind != want {
+			t.Errorf("everything got %q, want %q", e.Kind, want)
+		}
+	}
+	if e := <-rotations; e.Kind != CertificateRotated {
+		t.Errorf("rotations got %q, want %q", e.Kind, CertificateRotated)
+	}
+	select {
+	case e := <-rotations:
+		t.Errorf("rotations got unexpected %q", e.Kind)
+	default:
+	}
+}
+
+func TestRoute_Match(t *testing.T) {
+	payments := Route{Match: map[string]string{"team": "payments", "env": "prod"}}
+	paymentsAlert := &alert.Alert{Labels: map[string]string{"hostname": "pay.example.com", "team": "payments", "env": "prod"}}
+
+	tests := []struct {
+		name  string
+		route Route
+		event Event
+		want  bool
+	}{
+		{name: "no match", route: Route{}, event: Event{Kind: AlertFiring, Alert: &alert.Alert{}}, want: true},
+		{name: "matching alert", route: payments, event: Event{Kind: AlertFiring, Alert: paymentsAlert}, want: true},
+		{name: "other team", route: payments, event: Event{Kind: AlertFiring, Alert: &alert.Alert{Labels: map[string]string{"team": "web", "env": "prod"}}}, want: false},
+		{name: "missing label", route: payments, event: Event{Kind: AlertFiring, Alert: &alert.Alert{Labels: map[string]string{"team": "payments"}}}, want: false},
+		{name: "matching rotation", route: payments, event: Event{Kind: CertificateRotated, Rotation: &store.Rotation{Labels: map[string]string{"team": "payments", "env": "prod"}}}, want: true},
+		{name: "kind still applies", route: Route{Kinds: []string{CertificateRotated}, Match: payments.Match}, event: Event{Kind: AlertFiring, Alert: paymentsAlert}, want: false},
+		{name: "tenant alert", route: Route{Tenant: "payments"}, event: Event{Kind: AlertFiring, Alert: &alert.Alert{Tenant: "payments"}}, want: true},
+		{name: "other tenant", route: Route{Tenant: "payments"}, event: Event{Kind: AlertFiring, Alert: &alert.Alert{Tenant: "web"}}, want: false},
+		{name: "tenant rotation", route: Route{Tenant: "payments"}, event: Event{Kind: CertificateRotated, Rotation: &store.Rotation{Tenant: "payments"}}, want: true},
+		{name: "global route hears every tenant", route: Route{}, event: Event{Kind: AlertFiring, Alert: &alert.Alert{Tenant: "web"}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.route.wants(tt.event); got != tt.want {
+				t.Errorf("wants() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/revocation"
+	"cert-tracker/store"
+	"crypto/x509"
+	"net/http"
+	"sort"
+	"time"
+)
+
+type ocspQuery struct {
+	cert, issuer *x509.Certificate
+}
+
+// responderQueries picks one certificate and its issuer to query each OCSP
+// responder with, so a responder is probed once per cycle however many
+// certificates name it.
+func responderQueries(chains [][]*x509.Certificate) map[string]ocspQuery {
+	queries := make(map[string]ocspQuery)
+	for _, chain := range chains {
+		// the root is rarely served, so the last certificate can't be queried
+		for i := 0; i+1 < len(chain); i++ {
+			for _, url := range chain[i].OCSPServer {
+				if _, ok := queries[url]; !ok {
+					queries[url] = ocspQuery{cert: chain[i], issuer: chain[i+1]}
+				}
+			}
+		}
+	}
+	return queries
+}
+
+// probeResponders probes every responder once and raises or clears its alerts.
+func probeResponders(client *http.Client, chains [][]*x509.Certificate, monitor *revocation.ResponderMonitor, alerts *alert.Manager, now time.Time) []store.ResponderProbe {
+	queries := responderQueries(chains)
+	urls := make([]string, 0, len(queries))
+	for url := range queries {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	var probes []store.ResponderProbe
+	for _, url := range urls {
+		q := queries[url]
+		probe := revocation.ProbeOCSP(client, url, q.cert, q.issuer)
+		probes = append(probes, probe)
+		log.Debug("OCSP responder probed", "probe", probe)
+
+		slow, unreachable := monitor.Observe(probe)
+		labels := map[string]string{"responder": url}
+		alerts.Set(unreachable, alert.Alert{
+			Key:      "ocsp-unreachable:" + url,
+			Severity: alert.Critical,
+			Summary:  "OCSP responder " + url + " is unreachable: " + probe.Error,
+			Labels:   labels,
+			Since:    now,
+		})
+		alerts.Set(slow, alert.Alert{
+			Key:      "ocsp-slow:" + url,
+			Severity: alert.Warning,
+			Summary:  "OCSP responder " + url + " took " + probe.Latency.Round(time.Millisecond).String(),
+			Labels:   labels,
+			Since:    now,
+		})
+	}
+	return probes
+}
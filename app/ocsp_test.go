@@ -0,0 +1,33 @@
+package main
+
+import (
+	"crypto/x509"
+	"testing"
+)
+
+func TestResponderQueries(t *testing.T) {
+	leafA := &x509.Certificate{OCSPServer: []string{"http://r3.o.example"}}
+	leafB := &x509.Certificate{OCSPServer: []string{"http://r3.o.example"}}
+	intermediate := &x509.Certificate{OCSPServer: []string{"http://root.o.example"}}
+	root := &x509.Certificate{}
+	lone := &x509.Certificate{OCSPServer: []string{"http://lone.o.example"}}
+
+	queries := responderQueries([][]*x509.Certificate{
+		{leafA, intermediate, root},
+		{leafB, intermediate},
+		{lone},
+	})
+
+	if len(queries) != 2 {
+		t.Fatalf("responderQueries() = %v, want 2 responders", queries)
+	}
+	if q := queries["http://r3.o.example"]; q.cert != leafA || q.issuer != intermediate {
+		t.Errorf("Expected the first certificate naming a responder to be queried, got %+v", q)
+	}
+	if q := queries["http://root.o.example"]; q.cert != intermediate || q.issuer != root {
+		t.Errorf("Expected the intermediate to be queried with the root as issuer, got %+v", q)
+	}
+	if _, ok := queries["http://lone.o.example"]; ok {
+		t.Error("Expected a certificate without its issuer to be skipped")
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/revocation"
+	"cert-tracker/store"
+	"crypto/sha256"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type checkedStatus struct {
+	status  store.OCSPStatus
+	checked time.Time
+}
+
+// leafStatuses looks up the revocation status of served leaf certificates,
+// asking a responder about each certificate at most once per interval.
+type leafStatuses struct {
+	client   *http.Client
+	interval time.Duration
+	// by the SHA-256 of the leaf
+	cache map[[32]byte]checkedStatus
+}
+
+func newLeafStatuses(client *http.Client, interval time.Duration) *leafStatuses {
+	return &leafStatuses{client: client, interval: interval, cache: make(map[[32]byte]checkedStatus)}
+}
+
+// status prefers a valid stapled response, which costs nothing, over asking
+// the leaf's responder. It returns nil when the status can't be checked:
+// the issuer wasn't served or the leaf names no responder.
+func (l *leafStatuses) status(state *tls.ConnectionState, now time.Time) *store.OCSPStatus {
+	chain := state.PeerCertificates
+	if len(chain) < 2 {
+		return nil
+	}
+	leaf, issuer := chain[0], chain[1]
+	if len(state.OCSPResponse) > 0 {
+		stapled := revocation.StapledStatus(state.OCSPResponse, leaf, issuer)
+		if stapled.Error == "" {
+			return &stapled
+		}
+		log.Debug("ignoring invalid stapled OCSP response", "subject", leaf.Subject.String(), "error", stapled.Error)
+	}
+	if len(leaf.OCSPServer) == 0 {
+		return nil
+	}
+	key := sha256.Sum256(leaf.Raw)
+	if c, ok := l.cache[key]; ok && l.fresh(c, now) {
+		return &c.status
+	}
+	status := revocation.LeafStatus(l.client, leaf.OCSPServer[0], leaf, issuer)
+	// failed lookups are tried again next cycle
+	if status.Error == "" {
+		l.cache[key] = checkedStatus{status: status, checked: now}
+	}
+	return &status
+}
+
+func (l *leafStatuses) fresh(c checkedStatus, now time.Time) bool {
+	if !c.status.NextUpdate.IsZero() && !now.Before(c.status.NextUpdate) {
+		return false
+	}
+	return now.Sub(c.checked) < l.interval
+}
+
+// prune forgets statuses that would be asked for again anyway, so rotated
+// certificates don't pile up.
+func (l *leafStatuses) prune(now time.Time) {
+	for key, c := range l.cache {
+		if !l.fresh(c, now) {
+			delete(l.cache, key)
+		}
+	}
+}
+
+// checkOCSPStatus raises an alert for every endpoint serving a revoked leaf
+// certificate.
+func checkOCSPStatus(snapshot store.Snapshot, alerts *alert.Manager, now time.Time) {
+	for _, c := range snapshot.Certificates {
+		if c.Index != 0 || c.OCSP == nil || c.OCSP.Error != "" {
+			continue
+		}
+		endpoint := string(c.Hostname) + "@" + c.IPAddress.String()
+		alerts.Set(c.OCSP.Status == "revoked", alert.Alert{
+			Key:      alert.Key("ocsp-revoked", c.Tenant, endpoint),
+			Severity: alert.Critical,
+			Summary:  fmt.Sprintf("%s serves certificate %s, revoked at %s", endpoint, c.SerialNumber, c.OCSP.RevokedAt.Format(time.RFC3339)),
+			Tenant:   c.Tenant,
+			Labels: map[string]string{
+				"hostname":    string(c.Hostname),
+				"ipAddress":   c.IPAddress.String(),
+				"fingerprint": c.SHA256Fingerprint,
+			},
+			Since: now,
+		})
+	}
+}
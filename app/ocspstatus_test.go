@@ -0,0 +1,66 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/store"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestCheckOCSPStatus(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		ocsp       *store.OCSPStatus
+		wantFiring bool
+	}{
+		{name: "not checked"},
+		{name: "good", ocsp: &store.OCSPStatus{Source: "stapled", Status: "good"}},
+		{name: "unknown", ocsp: &store.OCSPStatus{Source: "http://ocsp.example.com", Status: "unknown"}},
+		{name: "revoked", ocsp: &store.OCSPStatus{Source: "http://ocsp.example.com", Status: "revoked", RevokedAt: now.Add(-time.Hour)}, wantFiring: true},
+		{name: "lookup failed", ocsp: &store.OCSPStatus{Source: "http://ocsp.example.com", Error: "connection refused"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := alert.NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+			snapshot := store.Snapshot{Certificates: []store.Certificate{{
+				Hostname:  "example.com",
+				IPAddress: net.ParseIP("192.0.2.1"),
+				OCSP:      tt.ocsp,
+			}}}
+			checkOCSPStatus(snapshot, alerts, now)
+			if _, firing := alerts.Get("ocsp-revoked:example.com@192.0.2.1"); firing != tt.wantFiring {
+				t.Errorf("firing = %v, want %v", firing, tt.wantFiring)
+			}
+		})
+	}
+}
+
+func TestLeafStatuses_Fresh(t *testing.T) {
+	now := time.Now()
+	l := newLeafStatuses(nil, time.Hour)
+	tests := []struct {
+		name string
+		c    checkedStatus
+		want bool
+	}{
+		{name: "recent", c: checkedStatus{checked: now.Add(-time.Minute)}, want: true},
+		{name: "past interval", c: checkedStatus{checked: now.Add(-2 * time.Hour)}},
+		{
+			name: "past nextUpdate",
+			c:    checkedStatus{status: store.OCSPStatus{NextUpdate: now.Add(-time.Second)}, checked: now.Add(-time.Minute)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := l.fresh(tt.c, now); got != tt.want {
+				t.Errorf("fresh() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
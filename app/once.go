@@ -0,0 +1,79 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"time"
+)
+
+// Exit codes of --once, from best to worst.
+const (
+	exitHealthy  = 0
+	exitExpiring = 1
+	exitFailing  = 2
+)
+
+// defaultWarningWindow is the widest default expiry escalation step, used
+// by --once when expiry alerts aren't configured.
+const defaultWarningWindow = 30 * 24 * time.Hour
+
+// defaultCriticalWindow is the default critical expiry escalation step.
+const defaultCriticalWindow = 7 * 24 * time.Hour
+
+func warningWindow(config cfg.Params) time.Duration {
+	return tenantWarningWindow(config, "")
+}
+
+// tenantWarningWindow is the widest expiry escalation step for the
+// certificates of tenant, from its own escalation if it has one.
+func tenantWarningWindow(config cfg.Params, tenant string) time.Duration {
+	steps := tenantEscalation(config.Expiry.Escalation, config.Tenants, tenant)
+	if len(steps) == 0 {
+		return defaultWarningWindow
+	}
+	// steps are sorted widest first
+	return time.Duration(steps[0].Within)
+}
+
+// criticalWindow is the widest critical expiry escalation step, or the
+// narrowest step if none is critical.
+func criticalWindow(config cfg.Params) time.Duration {
+	steps := config.Expiry.Escalation
+	if len(steps) == 0 {
+		return defaultCriticalWindow
+	}
+	for _, step := range steps {
+		if step.Severity == "critical" {
+			return time.Duration(step.Within)
+		}
+	}
+	return time.Duration(steps[len(steps)-1].Within)
+}
+
+// onceStatus judges a single scan cycle for cron and CI: it fails when a
+// handshake failed or a leaf certificate has expired or fails verification,
+// and warns when one expires within the window of its tenant.
+func onceStatus(snapshot store.Snapshot, failed map[cfg.Hostname]bool, window func(tenant string) time.Duration, now time.Time) int {
+	status := exitHealthy
+	for hostname := range failed {
+		log.Error("handshake failed", "hostname", hostname)
+		status = exitFailing
+	}
+	for _, c := range snapshot.Certificates {
+		if c.Index != 0 {
+			continue
+		}
+		switch {
+		case !c.NotAfter.After(now):
+			log.Error("certificate expired", "hostname", c.Hostname, "ipAddress", c.IPAddress, "notAfter", c.NotAfter)
+			status = exitFailing
+		case c.VerifyError != "":
+			log.Error("certificate fails verification", "hostname", c.Hostname, "ipAddress", c.IPAddress, "error", c.VerifyError)
+			status = exitFailing
+		case c.NotAfter.Sub(now) <= window(c.Tenant):
+			log.Warn("certificate expiring", "hostname", c.Hostname, "ipAddress", c.IPAddress, "notAfter", c.NotAfter)
+			status = max(status, exitExpiring)
+		}
+	}
+	return status
+}
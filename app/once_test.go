@@ -0,0 +1,60 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"testing"
+	"time"
+)
+
+func TestOnceStatus(t *testing.T) {
+	now := time.Now()
+	leaf := func(notAfter time.Duration, verifyError string) store.Certificate {
+		return store.Certificate{Hostname: "example.com", NotAfter: now.Add(notAfter), VerifyError: verifyError}
+	}
+	tests := []struct {
+		name   string
+		certs  []store.Certificate
+		failed map[cfg.Hostname]bool
+		want   int
+	}{
+		{name: "healthy", certs: []store.Certificate{leaf(60*24*time.Hour, "")}, want: exitHealthy},
+		{name: "expiring", certs: []store.Certificate{leaf(60*24*time.Hour, ""), leaf(10*24*time.Hour, "")}, want: exitExpiring},
+		{name: "expired", certs: []store.Certificate{leaf(10*24*time.Hour, ""), leaf(-time.Hour, "")}, want: exitFailing},
+		{name: "fails verification", certs: []store.Certificate{leaf(60*24*time.Hour, "x509: certificate signed by unknown authority")}, want: exitFailing},
+		{
+			name:   "handshake failed",
+			certs:  []store.Certificate{leaf(60*24*time.Hour, "")},
+			failed: map[cfg.Hostname]bool{"example.org": true},
+			want:   exitFailing,
+		},
+		{
+			name:  "inside the tenant's wider window",
+			certs: []store.Certificate{leaf(60*24*time.Hour, ""), {Tenant: "payments", Hostname: "pay.example.com", NotAfter: now.Add(40 * 24 * time.Hour)}},
+			want:  exitExpiring,
+		},
+		{
+			name:  "expired intermediate ignored",
+			certs: []store.Certificate{leaf(60*24*time.Hour, ""), {Hostname: "example.com", Index: 1, NotAfter: now.Add(-time.Hour)}},
+			want:  exitHealthy,
+		},
+	}
+
+	config := cfg.Params{
+		Expiry: cfg.Expiry{Escalation: []cfg.EscalationStep{{Within: cfg.Duration(30 * 24 * time.Hour), Severity: "warning"}}},
+		Tenants: []cfg.Tenant{{
+			Name:       "payments",
+			Escalation: []cfg.EscalationStep{{Within: cfg.Duration(45 * 24 * time.Hour), Severity: "warning"}},
+		}},
+	}
+	window := func(tenant string) time.Duration { return tenantWarningWindow(config, tenant) }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := onceStatus(store.Snapshot{Certificates: tt.certs}, tt.failed, window, now)
+			if got != tt.want {
+				t.Errorf("onceStatus() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"hash/fnv"
+	"time"
+)
+
+const (
+	// stretch low-priority targets once a cycle takes this much of the interval
+	pacerHigh = 0.8
+	// and relax again once cycles fit comfortably
+	pacerLow   = 0.4
+	maxStretch = 8
+	// targets whose certificate expires further out than this can wait
+	lowPriorityAfter = 30 * 24 * time.Hour
+)
+
+// pacer keeps scan cycles from piling up. When a cycle takes nearly the
+// whole interval, targets that can wait are scanned only every stretch
+// cycles, each in a different cycle to spread the load.
+type pacer struct {
+	interval time.Duration
+	stretch  int
+	cycle    int
+}
+
+func newPacer(interval time.Duration) *pacer {
+	return &pacer{interval: interval, stretch: 1}
+}
+
+// adjust doubles or halves the stretch after a cycle that took took, and
+// reports whether it changed.
+func (p *pacer) adjust(took time.Duration) bool {
+	p.cycle++
+	before := p.stretch
+	switch {
+	case float64(took) > pacerHigh*float64(p.interval):
+		p.stretch = min(2*p.stretch, maxStretch)
+	case float64(took) < pacerLow*float64(p.interval):
+		p.stretch = max(p.stretch/2, 1)
+	}
+	return p.stretch != before
+}
+
+// split defers the low-priority hostnames whose turn it isn't this cycle:
+// those that didn't fail and whose certificate was last seen expiring well
+// in the future. Their certificates from previous are carried forward,
+// marked Deferred.
+func (p *pacer) split(targets []scanTarget, failed map[cfg.Hostname]bool, previous store.Snapshot, now time.Time) ([]scanTarget, []store.Certificate) {
+	if p.stretch == 1 {
+		return targets, nil
+	}
+	expires := leafExpiry(previous)
+	deferred := make(map[cfg.Hostname]bool)
+	var scan []scanTarget
+	for _, t := range targets {
+		e, seen := expires[t.Hostname]
+		if seen && !failed[t.Hostname] && e.Sub(now) > lowPriorityAfter && !p.due(t.Hostname) {
+			deferred[t.Hostname] = true
+			continue
+		}
+		scan = append(scan, t)
+	}
+	var carried []store.Certificate
+	for _, c := range previous.Certificates {
+		if deferred[c.Hostname] {
+			c.Deferred = true
+			carried = append(carried, c)
+		}
+	}
+	return scan, carried
+}
+
+// due reports whether it's hostname's turn this cycle.
+func (p *pacer) due(hostname cfg.Hostname) bool {
+	h := fnv.New32a()
+	h.Write([]byte(hostname))
+	return int(h.Sum32()%uint32(p.stretch)) == p.cycle%p.stretch
+}
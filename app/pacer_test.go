@@ -0,0 +1,85 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestPacerAdjust(t *testing.T) {
+	p := newPacer(10 * time.Minute)
+	tests := []struct {
+		took    time.Duration
+		want    int
+		changed bool
+	}{
+		{took: 5 * time.Minute, want: 1},
+		{took: 9 * time.Minute, want: 2, changed: true},
+		{took: 9 * time.Minute, want: 4, changed: true},
+		{took: 20 * time.Minute, want: 8, changed: true},
+		{took: 20 * time.Minute, want: 8},
+		{took: 5 * time.Minute, want: 8},
+		{took: 3 * time.Minute, want: 4, changed: true},
+	}
+	for i, tt := range tests {
+		if changed := p.adjust(tt.took); changed != tt.changed || p.stretch != tt.want {
+			t.Errorf("cycle %d: adjust(%v) = %v, stretch %d, want %v, %d", i, tt.took, changed, p.stretch, tt.changed, tt.want)
+		}
+	}
+}
+
+func TestPacerSplit(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+	var targets []scanTarget
+	previous := store.Snapshot{}
+	for _, h := range []string{"a.example.com", "b.example.com", "c.example.com", "d.example.com"} {
+		targets = append(targets, scanTarget{Hostname: cfg.Hostname(h), IPAddress: net.ParseIP("192.0.2.1")})
+		previous.Certificates = append(previous.Certificates, store.Certificate{Hostname: cfg.Hostname(h), NotAfter: now.Add(90 * day)})
+	}
+	targets = append(targets,
+		scanTarget{Hostname: "expiring.example.com"},
+		scanTarget{Hostname: "down.example.com"},
+		scanTarget{Hostname: "new.example.com"},
+	)
+	previous.Certificates = append(previous.Certificates,
+		store.Certificate{Hostname: "expiring.example.com", NotAfter: now.Add(10 * day)},
+		store.Certificate{Hostname: "down.example.com", NotAfter: now.Add(90 * day)},
+	)
+	failed := map[cfg.Hostname]bool{"down.example.com": true}
+
+	p := &pacer{stretch: 1}
+	if scan, carried := p.split(targets, failed, previous, now); len(scan) != len(targets) || carried != nil {
+		t.Errorf("split() without stretch deferred %d targets", len(targets)-len(scan))
+	}
+
+	p.stretch = 2
+	scanned := make(map[cfg.Hostname]int)
+	for range 2 {
+		scan, carried := p.split(targets, failed, previous, now)
+		if len(scan)+len(carried) != len(targets) {
+			t.Errorf("split() scanned %d and carried %d of %d targets", len(scan), len(carried), len(targets))
+		}
+		for _, c := range carried {
+			if !c.Deferred {
+				t.Errorf("carried certificate %s not marked deferred", c.Hostname)
+			}
+		}
+		for _, target := range scan {
+			scanned[target.Hostname]++
+		}
+		p.cycle++
+	}
+	for _, h := range []cfg.Hostname{"expiring.example.com", "down.example.com", "new.example.com"} {
+		if scanned[h] != 2 {
+			t.Errorf("%s scanned %d times in 2 cycles, want every cycle", h, scanned[h])
+		}
+	}
+	for _, h := range []cfg.Hostname{"a.example.com", "b.example.com", "c.example.com", "d.example.com"} {
+		if scanned[h] != 1 {
+			t.Errorf("%s scanned %d times in 2 cycles, want once", h, scanned[h])
+		}
+	}
+}
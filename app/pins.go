@@ -0,0 +1,60 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// spkiPin is the base64 SHA-256 of cert's public key.
+func spkiPin(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// checkPins raises an alert for every scanned endpoint of a pinned
+// hostname whose chain has no certificate with one of the hostname's pins,
+// whether the leaf was reissued with a new key or someone is intercepting
+// the connection.
+func checkPins(snapshot store.Snapshot, pins cfg.Pins, alerts *alert.Manager, now time.Time) {
+	type endpoint struct {
+		tenant, hostname, ip string
+	}
+	leaves := make(map[endpoint]store.Certificate)
+	pinned := make(map[endpoint]bool)
+	for _, c := range snapshot.Certificates {
+		want, ok := pins[c.Hostname]
+		if !ok || c.Deferred {
+			continue
+		}
+		e := endpoint{c.Tenant, string(c.Hostname), c.IPAddress.String()}
+		if c.Index == 0 {
+			leaves[e] = c
+		}
+		if slices.Contains(want, c.SPKIPin) {
+			pinned[e] = true
+		}
+	}
+	for e, leaf := range leaves {
+		name := e.hostname + "@" + e.ip
+		alerts.Set(!pinned[e], alert.Alert{
+			Key:      alert.Key("pin-mismatch", e.tenant, name),
+			Severity: alert.Critical,
+			Summary:  fmt.Sprintf("%s serves a chain matching none of its pins; the leaf's key is pinned as %s", name, leaf.SPKIPin),
+			Tenant:   e.tenant,
+			Labels: map[string]string{
+				"hostname":    e.hostname,
+				"ipAddress":   e.ip,
+				"fingerprint": leaf.SHA256Fingerprint,
+				"spkiPin":     leaf.SPKIPin,
+			},
+			Since: now,
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSPKIPin(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	cert := server.Certificate()
+	// the pin covers the SubjectPublicKeyInfo, not the whole certificate
+	key, err := x509.MarshalPKIXPublicKey(cert.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256(key)
+	if got, want := spkiPin(cert), base64.StdEncoding.EncodeToString(hash[:]); got != want {
+		t.Errorf("spkiPin() = %s, want %s", got, want)
+	}
+}
+
+func TestCheckPins(t *testing.T) {
+	now := time.Now()
+	const (
+		leafPin   = "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+		caPin     = "YLh1dUR9y6Kja30RrAn7JKnbQG/uEtLMkBgFF2Fuihg="
+		rogue     = "jNQXAC9IVRXHbu4QvUYrFaQ7kz1pEXArDpMcMsTHDNM="
+		rogueRoot = "s8S1TCa3p8HMk4A/aEBqbDvvt/eyTBdMTQ4ZMzWfUzI="
+	)
+	pins := cfg.Pins{"www.example.com": {leafPin, caPin}}
+	tests := []struct {
+		name       string
+		hostname   cfg.Hostname
+		chain      []string
+		wantFiring bool
+	}{
+		{name: "leaf pinned", hostname: "www.example.com", chain: []string{leafPin, rogueRoot}},
+		{name: "reissued under the pinned CA", hostname: "www.example.com", chain: []string{rogue, caPin}},
+		{name: "intercepted", hostname: "www.example.com", chain: []string{rogue, rogueRoot}, wantFiring: true},
+		{name: "not pinned", hostname: "api.example.com", chain: []string{rogue, rogueRoot}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := alert.NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+			var snapshot store.Snapshot
+			for i, pin := range tt.chain {
+				snapshot.Certificates = append(snapshot.Certificates, store.Certificate{
+					Hostname:  tt.hostname,
+					IPAddress: net.ParseIP("192.0.2.1"),
+					Index:     i,
+					SPKIPin:   pin,
+				})
+			}
+			checkPins(snapshot, pins, alerts, now)
+			if _, firing := alerts.Get("pin-mismatch:" + string(tt.hostname) + "@192.0.2.1"); firing != tt.wantFiring {
+				t.Errorf("firing = %v, want %v", firing, tt.wantFiring)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/notify"
+	"cert-tracker/store"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// scanTarget is one endpoint a scan cycle connects to. Results are recorded
+// once for each tenant monitoring the hostname.
+type scanTarget struct {
+	Hostname   cfg.Hostname `json:"hostname"`
+	IPAddress  net.IP       `json:"ipAddress"`
+	Port       string       `json:"port"`
+	Protocol   string       `json:"protocol"`
+	ServerName string       `json:"serverName"`
+	ALPN       []string     `json:"alpn,omitempty"`
+	Tenants    []string     `json:"tenants"`
+	// Timeout, when set, replaces the global timeout for this target, and
+	// Source and KeepAlive configure its dialer.
+	Timeout   cfg.Duration `json:"timeout,omitempty"`
+	Source    net.IP       `json:"source,omitempty"`
+	KeepAlive cfg.Duration `json:"keepAlive,omitempty"`
+	// CNAMEs are the canonical names Hostname resolved through, naming the
+	// CDN or host that actually terminates TLS.
+	CNAMEs []string `json:"cnames,omitempty"`
+	// Resolvers are the resolvers that returned IPAddress, when resolving
+	// through every one of them.
+	Resolvers []string `json:"resolvers,omitempty"`
+	// Lookup is how long resolving Hostname took.
+	Lookup time.Duration `json:"lookup,omitempty"`
+	// Expires is when the DNS answer for Hostname runs out, or zero if its
+	// TTL wasn't seen.
+	Expires time.Time `json:"expires,omitzero"`
+	// Labels are the labels configured for Hostname.
+	Labels map[string]string `json:"labels,omitempty"`
+	// retry marks a second attempt at an endpoint that failed this cycle.
+	retry bool
+}
+
+// plan resolves every target to the endpoints the next scan cycle will use.
+func plan(ctx context.Context, config cfg.Params, st *store.Store) ([]scanTarget, error) {
+	hostnames := targets(config, st)
+	if len(hostnames) == 0 {
+		// nothing to resolve, e.g. when only files are monitored
+		return nil, nil
+	}
+	return planHostnames(ctx, config, hostnames, tenantsOf(config, hostnames))
+}
+
+func planHostnames(ctx context.Context, config cfg.Params, hostnames []cfg.Hostname, tenants map[cfg.Hostname][]string) ([]scanTarget, error) {
+	now := time.Now()
+	var resolutions []resolution
+	if config.DNSCache.Enabled {
+		resolutions, hostnames = dnsCache.lookup(hostnames, now)
+		if len(resolutions) > 0 {
+			log.Debug("using cached IP addresses", "hostnames", len(resolutions))
+		}
+	}
+	if len(hostnames) > 0 {
+		fresh, err := lookupHostnames(ctx, config, hostnames, now)
+		if err != nil {
+			return nil, err
+		}
+		resolutions = append(resolutions, fresh...)
+	}
+	if len(resolutions) == 0 {
+		return nil, errors.New("no name to address mappings")
+	}
+
+	var targets []scanTarget
+	for _, r := range resolutions {
+		allowed := slices.DeleteFunc(slices.Clone(r.IPAddresses), func(ip net.IP) bool {
+			return !config.AddressFamily.Allows(ip)
+		})
+		if len(allowed) == 0 && len(r.IPAddresses) > 0 {
+			log.Warn("hostname has no addresses in the configured family",
+				"hostname", r.Hostname,
+				"addressFamily", config.AddressFamily,
+				"addresses", r.IPAddresses,
+			)
+		}
+		for _, ipAddress := range allowed {
+			target := scanTarget{
+				Hostname:   r.Hostname,
+				IPAddress:  ipAddress,
+				Port:       r.Hostname.Port(),
+				Protocol:   r.Hostname.Protocol(),
+				ServerName: config.SNI.ServerName(r.Hostname),
+				ALPN:       config.ALPN.For(r.Hostname),
+				Tenants:    tenants[r.Hostname],
+				CNAMEs:     r.CNAMEs,
+				Resolvers:  r.resolversOf(ipAddress),
+				Lookup:     r.Lookup,
+				Expires:    r.expires,
+				Labels:     labelsFor(config.Labels, r.Hostname),
+			}
+			applyDialer(config, &target)
+			targets = append(targets, target)
+		}
+	}
+	return targets, nil
+}
+
+// lookupHostnames asks the resolver, or every resolver for split-horizon
+// DNS, noting when each answer's TTL runs out.
+func lookupHostnames(ctx context.Context, config cfg.Params, hostnames []cfg.Hostname, now time.Time) ([]resolution, error) {
+	for _, hostname := range hostnames {
+		dnsTTLs.Forget(hostname.Host())
+	}
+	var nameAddressMappings []nameAddressMap
+	var err error
+	if config.SplitHorizon.Enabled {
+		nameAddressMappings, err = resolveEach(ctx, config, hostnames)
+	} else {
+		nameAddressMappings, err = resolve(ctx, hostnames, resolver(activeResolver(config), config.Timeout), config.Timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve IP Addresses: %w", err)
+	}
+	var failures []error
+	nameAddressMappings = slices.DeleteFunc(nameAddressMappings, func(m nameAddressMap) bool {
+		if m.Err != nil {
+			log.Warn("cannot resolve hostname", "hostname", m.Hostname, "error", m.Err)
+			failures = append(failures, m.Err)
+		}
+		return m.Err != nil
+	})
+	switch {
+	case len(nameAddressMappings) > 0:
+	case len(failures) == 1:
+		return nil, fmt.Errorf("cannot resolve IP Addresses: %w", failures[0])
+	case len(failures) > 1:
+		return nil, fmt.Errorf("cannot resolve IP Addresses: all %d lookups failed", len(failures))
+	}
+	for i, mapping := range nameAddressMappings {
+		nameAddressMappings[i].CNAMEs = dnsTTLs.CNAMEs(mapping.Hostname.Host())
+	}
+	log.Info("resolved IP addresses",
+		"addresses", nameAddressMappings,
+	)
+
+	var resolutions []resolution
+	for _, mapping := range nameAddressMappings {
+		ttl, ok := dnsTTLs.TTL(mapping.Hostname.Host())
+		switch {
+		case config.DNSCache.Enabled:
+			resolutions = append(resolutions, dnsCache.store(mapping, ttl, ok, config.DNSCache, now))
+		case ok:
+			resolutions = append(resolutions, resolution{nameAddressMap: mapping, expires: now.Add(ttl)})
+		default:
+			resolutions = append(resolutions, resolution{nameAddressMap: mapping})
+		}
+	}
+	return resolutions, nil
+}
+
+// expired reports whether targets[i] is the first endpoint of its hostname
+// and the DNS answer it came from has run out.
+func expired(targets []scanTarget, i int, now time.Time) bool {
+	t := targets[i]
+	if i > 0 && targets[i-1].Hostname == t.Hostname {
+		return false
+	}
+	return !t.Expires.IsZero() && now.After(t.Expires)
+}
+
+// reresolve looks up the hostname of targets[i] again, so a long cycle
+// doesn't scan addresses a CDN has since moved away from. When the lookup
+// fails the old addresses are scanned rather than none.
+func reresolve(ctx context.Context, config cfg.Params, targets []scanTarget, i int) []scanTarget {
+	hostname := targets[i].Hostname
+	fresh, err := planHostnames(ctx, config, []cfg.Hostname{hostname}, map[cfg.Hostname][]string{
+		hostname: targets[i].Tenants,
+	})
+	if err != nil {
+		log.Warn("cannot re-resolve expired hostname, scanning previous addresses",
+			"hostname", hostname,
+			"error", err,
+		)
+		for j := i; j < len(targets) && targets[j].Hostname == hostname; j++ {
+			targets[j].Expires = time.Time{}
+		}
+		return targets
+	}
+	log.Info("re-resolved hostname after its DNS TTL expired",
+		"hostname", hostname,
+		"endpoints", len(fresh),
+	)
+	return replaceHostname(targets, i, fresh)
+}
+
+// replaceHostname swaps the endpoints of the hostname starting at
+// targets[i] for fresh ones.
+func replaceHostname(targets []scanTarget, i int, fresh []scanTarget) []scanTarget {
+	end := i
+	for end < len(targets) && targets[end].Hostname == targets[i].Hostname {
+		end++
+	}
+	return slices.Concat(targets[:i], fresh, targets[end:])
+}
+
+// planRoute is a notification route as the plan shows it.
+type planRoute struct {
+	Name   string            `json:"name"`
+	Tenant string            `json:"tenant,omitempty"`
+	Events []string          `json:"events,omitempty"`
+	Match  map[string]string `json:"match,omitempty"`
+}
+
+func planRoutes(routes []notify.Route) []planRoute {
+	planned := make([]planRoute, len(routes))
+	for i, r := range routes {
+		planned[i] = planRoute{Name: r.Name, Tenant: r.Tenant, Events: r.Kinds, Match: r.Match}
+	}
+	return planned
+}
+
+// writePlanJSON prints the plan for scripts, with the endpoints and the
+// notification routes.
+func writePlanJSON(w io.Writer, targets []scanTarget, routes []notify.Route) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Endpoints     []scanTarget `json:"endpoints"`
+		Notifications []planRoute  `json:"notifications"`
+	}{targets, planRoutes(routes)})
+}
+
+func printPlan(w io.Writer, targets []scanTarget, routes []notify.Route) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOSTNAME\tIP ADDRESS\tPORT\tPROTOCOL\tSERVER NAME\tTENANTS")
+	for _, t := range targets {
+		tenants := make([]string, len(t.Tenants))
+		for i, tenant := range t.Tenants {
+			tenants[i] = tenant
+			if tenant == "" {
+				tenants[i] = "(default)"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.Hostname,
+			t.IPAddress,
+			t.Port,
+			t.Protocol,
+			t.ServerName,
+			strings.Join(tenants, ","),
+		)
+	}
+	fmt.Fprintf(tw, "\n%d endpoints would be scanned\n", len(targets))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nNOTIFIER\tTENANT\tEVENTS\tMATCH")
+	for _, r := range routes {
+		tenant, events, match := r.Tenant, strings.Join(r.Kinds, ","), "-"
+		if tenant == "" {
+			tenant = "(all)"
+		}
+		if events == "" {
+			events = "(all)"
+		}
+		if len(r.Match) > 0 {
+			pairs := make([]string, 0, len(r.Match))
+			for _, name := range slices.Sorted(maps.Keys(r.Match)) {
+				pairs = append(pairs, name+"="+r.Match[name])
+			}
+			match = strings.Join(pairs, ",")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Name, tenant, events, match)
+	}
+	fmt.Fprintf(tw, "\n%d notifiers would be used\n", len(routes))
+	return tw.Flush()
+}
+
+func sortedHostnames[V any](m map[cfg.Hostname]V) []cfg.Hostname {
+	hostnames := make([]cfg.Hostname, 0, len(m))
+	for h := range m {
+		hostnames = append(hostnames, h)
+	}
+	slices.Sort(hostnames)
+	return hostnames
+}
+
+func tenantsByHostname(targets []scanTarget) map[cfg.Hostname][]string {
+	tenants := make(map[cfg.Hostname][]string)
+	for _, t := range targets {
+		tenants[t.Hostname] = t.Tenants
+	}
+	return tenants
+}
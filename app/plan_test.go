@@ -0,0 +1,105 @@
+package main
+
+import (
+	"cert-tracker/notify"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrintPlan(t *testing.T) {
+	targets := []scanTarget{
+		{Hostname: "example.com", IPAddress: net.ParseIP("192.0.2.1"), Port: "443", Protocol: "tls", Tenants: []string{""}},
+		{Hostname: "pay.example.com", IPAddress: net.ParseIP("2001:db8::1"), Port: "443", Protocol: "tls", Tenants: []string{"payments", "web"}},
+	}
+	routes := []notify.Route{
+		{Name: "https://hooks.example.com/certs"},
+		{Name: "payments pagerduty", Tenant: "payments", Kinds: []string{notify.AlertFiring, notify.AlertResolved}, Match: map[string]string{"env": "prod", "team": "payments"}},
+	}
+
+	var out strings.Builder
+	if err := printPlan(&out, targets, routes); err != nil {
+		t.Fatalf("printPlan() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 11 {
+		t.Fatalf("Expected endpoints and notifiers with their summaries, got %d lines:\n%s", len(lines), out.String())
+	}
+	for i, want := range [][]string{
+		1: {"example.com", "192.0.2.1", "443", "tls", "(default)"},
+		2: {"pay.example.com", "2001:db8::1", "payments,web"},
+		7: {"https://hooks.example.com/certs", "(all)", "-"},
+		8: {"payments pagerduty", "payments", "alert.firing,alert.resolved", "env=prod,team=payments"},
+	} {
+		for _, w := range want {
+			if !strings.Contains(lines[i], w) {
+				t.Errorf("Expected %q in row %q", w, lines[i])
+			}
+		}
+	}
+	if !strings.HasPrefix(lines[4], "2 endpoints") {
+		t.Errorf("Expected endpoint count, got %q", lines[4])
+	}
+	if !strings.HasPrefix(lines[10], "2 notifiers") {
+		t.Errorf("Expected notifier count, got %q", lines[10])
+	}
+
+	out.Reset()
+	if err := writePlanJSON(&out, targets, routes); err != nil {
+		t.Fatalf("writePlanJSON() error = %v", err)
+	}
+	var got struct {
+		Endpoints     []scanTarget `json:"endpoints"`
+		Notifications []planRoute  `json:"notifications"`
+	}
+	if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+		t.Fatalf("writePlanJSON() wrote invalid JSON: %v", err)
+	}
+	if len(got.Endpoints) != 2 || len(got.Notifications) != 2 {
+		t.Fatalf("writePlanJSON() = %+v", got)
+	}
+	if n := got.Notifications[1]; n.Tenant != "payments" || len(n.Events) != 2 || n.Match["team"] != "payments" {
+		t.Errorf("notifications[1] = %+v", n)
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Now()
+	targets := []scanTarget{
+		{Hostname: "cdn.example.com", IPAddress: net.ParseIP("192.0.2.1"), Expires: now.Add(-time.Second)},
+		{Hostname: "cdn.example.com", IPAddress: net.ParseIP("192.0.2.2"), Expires: now.Add(-time.Second)},
+		{Hostname: "static.example.com", IPAddress: net.ParseIP("192.0.2.3"), Expires: now.Add(time.Hour)},
+		{Hostname: "unknown.example.com", IPAddress: net.ParseIP("192.0.2.4")},
+	}
+
+	for i, want := range []bool{true, false, false, false} {
+		if got := expired(targets, i, now); got != want {
+			t.Errorf("expired(%d) = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestReplaceHostname(t *testing.T) {
+	targets := []scanTarget{
+		{Hostname: "a.example.com", IPAddress: net.ParseIP("192.0.2.1")},
+		{Hostname: "cdn.example.com", IPAddress: net.ParseIP("192.0.2.2")},
+		{Hostname: "cdn.example.com", IPAddress: net.ParseIP("192.0.2.3")},
+		{Hostname: "z.example.com", IPAddress: net.ParseIP("192.0.2.4")},
+	}
+	fresh := []scanTarget{
+		{Hostname: "cdn.example.com", IPAddress: net.ParseIP("198.51.100.1")},
+	}
+
+	got := replaceHostname(targets, 1, fresh)
+	var addresses []string
+	for _, target := range got {
+		addresses = append(addresses, target.IPAddress.String())
+	}
+	want := "192.0.2.1 198.51.100.1 192.0.2.4"
+	if strings.Join(addresses, " ") != want {
+		t.Errorf("replaceHostname() = %v, want %s", addresses, want)
+	}
+}
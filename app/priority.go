@@ -0,0 +1,47 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"cmp"
+	"slices"
+	"time"
+)
+
+// prioritize orders targets so the most important checks finish first when
+// a cycle runs long: hostnames that failed last cycle, then hostnames never
+// scanned, then the rest by how soon their certificate expires. Each
+// hostname's endpoints stay together.
+func prioritize(targets []scanTarget, failed map[cfg.Hostname]bool, previous store.Snapshot) []scanTarget {
+	expires := leafExpiry(previous)
+	rank := func(t scanTarget) int {
+		switch _, seen := expires[t.Hostname]; {
+		case failed[t.Hostname]:
+			return 0
+		case !seen:
+			return 1
+		}
+		return 2
+	}
+	sorted := slices.Clone(targets)
+	slices.SortStableFunc(sorted, func(a, b scanTarget) int {
+		return cmp.Or(
+			cmp.Compare(rank(a), rank(b)),
+			expires[a.Hostname].Compare(expires[b.Hostname]),
+		)
+	})
+	return sorted
+}
+
+// leafExpiry returns when the first leaf certificate of each hostname in
+// snapshot expires, across tenants.
+func leafExpiry(snapshot store.Snapshot) map[cfg.Hostname]time.Time {
+	expires := make(map[cfg.Hostname]time.Time)
+	for key, leaf := range earliestLeaves(snapshot) {
+		hostname := cfg.Hostname(key.hostname)
+		if e, ok := expires[hostname]; !ok || leaf.NotAfter.Before(e) {
+			expires[hostname] = leaf.NotAfter
+		}
+	}
+	return expires
+}
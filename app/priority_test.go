@@ -0,0 +1,39 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"net"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPrioritize(t *testing.T) {
+	now := time.Now()
+	target := func(hostname, ip string) scanTarget {
+		return scanTarget{Hostname: cfg.Hostname(hostname), IPAddress: net.ParseIP(ip)}
+	}
+	targets := []scanTarget{
+		target("later.example.com", "192.0.2.1"),
+		target("soon.example.com", "192.0.2.2"),
+		target("soon.example.com", "192.0.2.3"),
+		target("new.example.com", "192.0.2.4"),
+		target("down.example.com", "192.0.2.5"),
+	}
+	previous := store.Snapshot{Certificates: []store.Certificate{
+		{Hostname: "later.example.com", NotAfter: now.Add(60 * 24 * time.Hour)},
+		{Hostname: "soon.example.com", NotAfter: now.Add(5 * 24 * time.Hour)},
+		{Hostname: "down.example.com", NotAfter: now.Add(90 * 24 * time.Hour)},
+	}}
+	failed := map[cfg.Hostname]bool{"down.example.com": true}
+
+	var got []string
+	for _, t := range prioritize(targets, failed, previous) {
+		got = append(got, t.IPAddress.String())
+	}
+	want := []string{"192.0.2.5", "192.0.2.4", "192.0.2.2", "192.0.2.3", "192.0.2.1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("prioritize() = %v, want %v", got, want)
+	}
+}
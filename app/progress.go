@@ -0,0 +1,39 @@
+package main
+
+import (
+	"cert-tracker/api"
+	"time"
+)
+
+const progressLogInterval = 30 * time.Second
+
+// progress tracks a scan cycle so a slow cycle can be told apart from a hung one.
+type progress struct {
+	api.Progress
+	lastLog time.Time
+}
+
+func newProgress(total int, now time.Time) *progress {
+	return &progress{
+		Progress: api.Progress{Running: true, Started: now, Total: total},
+		lastLog:  now,
+	}
+}
+
+// advance records a finished target and reports whether a progress event is due.
+func (p *progress) advance(now time.Time) bool {
+	p.Done++
+	perTarget := now.Sub(p.Started) / time.Duration(p.Done)
+	p.ETA = now.Add(perTarget * time.Duration(p.Total-p.Done))
+	if now.Sub(p.lastLog) < progressLogInterval {
+		return false
+	}
+	p.lastLog = now
+	return true
+}
+
+func (p *progress) finish(now time.Time) {
+	p.Running = false
+	p.ETA = time.Time{}
+	p.Finished = now
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	p := newProgress(4, start)
+
+	if p.advance(start.Add(10 * time.Second)) {
+		t.Error("Expected no progress event before the log interval")
+	}
+	if want := start.Add(40 * time.Second); !p.ETA.Equal(want) {
+		t.Errorf("ETA = %v, want %v", p.ETA, want)
+	}
+
+	if !p.advance(start.Add(40 * time.Second)) {
+		t.Error("Expected a progress event after the log interval")
+	}
+	if p.Done != 2 {
+		t.Errorf("Done = %d, want 2", p.Done)
+	}
+	if want := start.Add(80 * time.Second); !p.ETA.Equal(want) {
+		t.Errorf("ETA = %v, want %v", p.ETA, want)
+	}
+	if p.advance(start.Add(50 * time.Second)) {
+		t.Error("Expected no progress event so soon after the previous one")
+	}
+
+	p.finish(start.Add(60 * time.Second))
+	if p.Running || !p.ETA.IsZero() || p.Finished.IsZero() {
+		t.Errorf("finished progress = %+v", p.Progress)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"cert-tracker/store"
+	"cert-tracker/tlsprobe"
+	"crypto/tls"
+	"net"
+	"slices"
+	"time"
+)
+
+// protocolVersions are the TLS versions each endpoint, hostname@address,
+// accepted in its last deep scan. They're recorded on every snapshot until
+// the next one.
+type protocolVersions map[string][]string
+
+// protocolsFrom picks up the versions recorded in snapshot, so a restart
+// doesn't lose them until the next deep scan.
+func protocolsFrom(snapshot store.Snapshot) protocolVersions {
+	p := make(protocolVersions)
+	for _, c := range snapshot.Certificates {
+		if c.Index == 0 && len(c.Protocols) > 0 {
+			p[string(c.Hostname)+"@"+store.Address(c.IPAddress)] = c.Protocols
+		}
+	}
+	return p
+}
+
+// probe records the versions t accepts.
+func (p protocolVersions) probe(t scanTarget, timeout time.Duration) error {
+	accepted, err := tlsprobe.AcceptedVersions(net.JoinHostPort(t.IPAddress.String(), t.Port), t.Hostname.Host(), timeout)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(accepted))
+	for _, v := range accepted {
+		names = append(names, tls.VersionName(v))
+	}
+	p[string(t.Hostname)+"@"+t.IPAddress.String()] = names
+	log.Debug("TLS versions probed",
+		"hostname", t.Hostname,
+		"ipAddress", t.IPAddress,
+		"versions", names,
+	)
+	return nil
+}
+
+// annotate records the versions on the leaf certificates in snapshot.
+func (p protocolVersions) annotate(snapshot *store.Snapshot) {
+	for i, c := range snapshot.Certificates {
+		if c.Index != 0 {
+			continue
+		}
+		if versions, ok := p[string(c.Hostname)+"@"+store.Address(c.IPAddress)]; ok {
+			snapshot.Certificates[i].Protocols = slices.Clone(versions)
+		}
+	}
+}
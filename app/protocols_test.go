@@ -0,0 +1,47 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProtocolVersions(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.TLS = &tls.Config{MinVersion: tls.VersionTLS11, MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "https://"))
+	target := scanTarget{Hostname: cfg.Hostname("www.example.com:" + port), IPAddress: net.ParseIP(host), Port: port, Protocol: "tls"}
+
+	// carried over from the last snapshot until probed again
+	protocols := protocolsFrom(store.Snapshot{Certificates: []store.Certificate{
+		{Hostname: target.Hostname, IPAddress: target.IPAddress, Protocols: []string{"TLS 1.0"}},
+		{Hostname: "api.example.com", IPAddress: net.ParseIP("192.0.2.2"), Protocols: []string{"TLS 1.3"}},
+		{Hostname: "api.example.com", IPAddress: net.ParseIP("192.0.2.2"), Index: 1, Protocols: []string{"TLS 1.0"}},
+	}})
+	if err := protocols.probe(target, 2*time.Second); err != nil {
+		t.Fatalf("probe() error = %v", err)
+	}
+
+	snapshot := store.Snapshot{Certificates: []store.Certificate{
+		{Hostname: target.Hostname, IPAddress: target.IPAddress},
+		{Hostname: target.Hostname, IPAddress: target.IPAddress, Index: 1},
+		{Hostname: "api.example.com", IPAddress: net.ParseIP("192.0.2.2")},
+		{Hostname: "new.example.com", IPAddress: net.ParseIP("192.0.2.3")},
+	}}
+	protocols.annotate(&snapshot)
+	want := [][]string{{"TLS 1.1", "TLS 1.2"}, nil, {"TLS 1.3"}, nil}
+	for i, c := range snapshot.Certificates {
+		if !slices.Equal(c.Protocols, want[i]) {
+			t.Errorf("certificate %d protocols = %q, want %q", i, c.Protocols, want[i])
+		}
+	}
+}
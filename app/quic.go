@@ -0,0 +1,74 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// tlsConn is what scans use of a finished handshake, over TCP or QUIC.
+type tlsConn interface {
+	ConnectionState() tls.ConnectionState
+	Close() error
+}
+
+// quicConn adapts a QUIC connection to tlsConn. It owns the UDP socket
+// the connection was dialed from.
+type quicConn struct {
+	conn *quic.Conn
+	udp  *net.UDPConn
+}
+
+func (c quicConn) ConnectionState() tls.ConnectionState {
+	return c.conn.ConnectionState().TLS
+}
+
+func (c quicConn) Close() error {
+	err := c.conn.CloseWithError(0, "")
+	return errors.Join(err, c.udp.Close())
+}
+
+// dialQUIC makes a QUIC handshake with target over UDP. QUIC can't do
+// without an application protocol, so h3 is offered unless the target's
+// ALPN names others. Handshake bytes aren't counted.
+func dialQUIC(ctx context.Context, target scanTarget, timeout cfg.Duration, insecure bool) (tlsConn, dialStats, error) {
+	var stats dialStats
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout))
+	defer cancel()
+	alpn := target.ALPN
+	if len(alpn) == 0 {
+		alpn = []string{"h3"}
+	}
+	config := &tls.Config{
+		InsecureSkipVerify: insecure,
+		ServerName:         target.ServerName,
+		NextProtos:         alpn,
+	}
+	if capture.matches(target.Hostname) {
+		config.KeyLogWriter = capture.keyLog
+	}
+	udp, err := net.ListenUDP("udp", &net.UDPAddr{IP: target.Source})
+	if err != nil {
+		return nil, stats, err
+	}
+	remote := &net.UDPAddr{IP: target.IPAddress}
+	remote.Port, _ = strconv.Atoi(target.Hostname.Port())
+	var quicConfig quic.Config
+	if target.KeepAlive > 0 {
+		quicConfig.KeepAlivePeriod = time.Duration(target.KeepAlive)
+	}
+	started := time.Now()
+	conn, err := quic.Dial(ctx, udp, remote, config, &quicConfig)
+	stats.handshake = time.Since(started)
+	if err != nil {
+		udp.Close()
+		return nil, stats, err
+	}
+	return quicConn{conn: conn, udp: udp}, stats, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+func TestScanQUIC(t *testing.T) {
+	// borrow the test server's certificate for example.com
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	server.Close()
+	ln, err := quic.ListenAddr("127.0.0.1:0", &tls.Config{Certificates: server.TLS.Certificates, NextProtos: []string{"h3"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept(context.Background())
+			if err != nil {
+				return
+			}
+			go func() {
+				<-conn.Context().Done()
+			}()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	target := scanTarget{
+		Hostname:   cfg.Hostname("quic://example.com:" + port),
+		ServerName: "example.com",
+		IPAddress:  net.ParseIP("127.0.0.1"),
+	}
+	result, state, err := scan(context.Background(), target, cfg.Duration(5*time.Second))
+	if state == nil {
+		t.Fatalf("scan() error = %v", err)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "unknown authority") {
+		t.Errorf("Errors = %q, want the verification error", result.Errors)
+	}
+	if c := result.Chain[0]; c.SHA256Fingerprint == "" || c.Connection.ALPN != "h3" || c.Connection.Version != "TLS 1.3" {
+		t.Errorf("leaf = %+v, want the certificate served over QUIC", c)
+	}
+}
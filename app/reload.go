@@ -0,0 +1,94 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/dns"
+	"cert-tracker/notify"
+	"fmt"
+	"net"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// startupOnly are the config fields read once when the tracker starts, to
+// open the store, start listeners and set up clients. Reloading keeps their
+// running values.
+var startupOnly = []string{
+	"storeDir",
+	"api",
+	"leaderElection",
+	"watchdog",
+	"ocspResponders",
+	"crls",
+	"ocspStatus",
+	"ctPolicy",
+	"ctMonitor",
+	"stepCA",
+	"metrics",
+	"debugCapture",
+	"logAddSource",
+	"logFormat",
+	"logOutput",
+	"export",
+	"results",
+	"stateCache",
+	"controlPipe",
+}
+
+// reloadConfig reads the config again, as the tracker does at startup, and
+// switches notifier to its notifications, handing them the alerts still
+// firing. When it doesn't load or validate, the running config is kept.
+func reloadConfig(running cfg.Params, notifier *notify.Dispatcher, firing []alert.Alert) (cfg.Params, error) {
+	next, err := cfg.Load()
+	if err != nil {
+		return running, err
+	}
+	if changed := pinStartupFields(running, &next); len(changed) > 0 {
+		log.Warn("configuration changes need a restart to take effect", "fields", changed)
+	}
+	routes, err := configuredRoutes(next)
+	if err != nil {
+		return running, fmt.Errorf("notifications: %w", err)
+	}
+	notifier.Reroute(routes, firing)
+	if next.Timeout != running.Timeout || !slices.EqualFunc(next.DNSresolvers, running.DNSresolvers, net.IP.Equal) {
+		resolvers = newResolverSelector(next)
+	}
+	if next.LogLevel != running.LogLevel {
+		logLevel.Set(next.LogLevel)
+	}
+	return next, nil
+}
+
+// pinStartupFields copies the startupOnly fields of running into next,
+// returning the names of those that differed.
+func pinStartupFields(running cfg.Params, next *cfg.Params) []string {
+	var changed []string
+	r, n := reflect.ValueOf(running), reflect.ValueOf(next).Elem()
+	for i := range r.NumField() {
+		name, _, _ := strings.Cut(r.Type().Field(i).Tag.Get("json"), ",")
+		if !slices.Contains(startupOnly, name) {
+			continue
+		}
+		if !reflect.DeepEqual(r.Field(i).Interface(), n.Field(i).Interface()) {
+			changed = append(changed, name)
+			n.Field(i).Set(r.Field(i))
+		}
+	}
+	return changed
+}
+
+// newResolverSelector picks among several configured resolvers, or returns
+// nil when there's only one.
+func newResolverSelector(config cfg.Params) *dns.Selector {
+	if len(config.DNSresolvers) < 2 {
+		return nil
+	}
+	var clients []dns.Client
+	for _, ip := range config.DNSresolvers {
+		clients = append(clients, dnsClient(ip, config.Timeout))
+	}
+	return dns.NewSelector(clients, resolverWindow)
+}
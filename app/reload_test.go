@@ -0,0 +1,155 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/notify"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPinStartupFields(t *testing.T) {
+	running := cfg.Params{
+		Hostnames:    []cfg.Hostname{"example.com"},
+		ScanInterval: cfg.Duration(time.Hour),
+		StoreDir:     "/var/lib/cert-tracker",
+		API:          cfg.API{Listen: ":8080"},
+	}
+	tests := []struct {
+		name        string
+		next        cfg.Params
+		want        cfg.Params
+		wantChanged []string
+	}{
+		{
+			name: "reloadable changes",
+			next: cfg.Params{
+				Hostnames:    []cfg.Hostname{"example.com", "example.org"},
+				ScanInterval: cfg.Duration(time.Minute),
+				StoreDir:     "/var/lib/cert-tracker",
+				API:          cfg.API{Listen: ":8080"},
+			},
+			want: cfg.Params{
+				Hostnames:    []cfg.Hostname{"example.com", "example.org"},
+				ScanInterval: cfg.Duration(time.Minute),
+				StoreDir:     "/var/lib/cert-tracker",
+				API:          cfg.API{Listen: ":8080"},
+			},
+		},
+		{
+			name: "startup changes kept",
+			next: cfg.Params{
+				Hostnames:    []cfg.Hostname{"example.com"},
+				ScanInterval: cfg.Duration(time.Hour),
+				StoreDir:     "/tmp/store",
+				API:          cfg.API{Listen: ":9090"},
+			},
+			want:        running,
+			wantChanged: []string{"storeDir", "api"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := tt.next
+			changed := pinStartupFields(running, &next)
+			if !reflect.DeepEqual(changed, tt.wantChanged) {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(next, tt.want) {
+				t.Errorf("next = %+v, want %+v", next, tt.want)
+			}
+		})
+	}
+}
+
+func TestReloadConfigReroutesNotifications(t *testing.T) {
+	delivered := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e notify.Event
+		json.NewDecoder(r.Body).Decode(&e)
+		delivered <- r.URL.Path + " " + e.Alert.Key
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.RegisterConfigFlag(flags)
+	t.Cleanup(func() { flags.Set("config", flags.Lookup("config").DefValue) })
+	if err := flags.Parse([]string{"--config", path}); err != nil {
+		t.Fatal(err)
+	}
+	load := func(config string) cfg.Params {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		params, err := cfg.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return params
+	}
+
+	running := load(fmt.Sprintf(`{
+		"dnsResolvers": ["8.8.8.8"],
+		"hostnames": ["www.example.com"],
+		"tenants": [{ "name": "shop", "hostnames": ["shop.example.com"] }],
+		"notifications": { "webhooks": [{ "url": "%s/web", "match": { "team": "web" } }] }
+	}`, srv.URL))
+	notifier, err := newNotifier(running)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go notifier.Run(ctx)
+
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	send := func(key, tenant, team string) {
+		notifier.Send(notify.AlertEvent(alert.Firing, alert.Alert{Key: key, Tenant: tenant, Labels: map[string]string{"team": team}}, now))
+	}
+	send("shop-before", "shop", "shop")
+	send("web-before", "", "web")
+
+	// the global webhook now matches the shop team, and the tenant gets one
+	load(fmt.Sprintf(`{
+		"dnsResolvers": ["8.8.8.8"],
+		"hostnames": ["www.example.com"],
+		"tenants": [{
+			"name": "shop",
+			"hostnames": ["shop.example.com"],
+			"notifications": { "webhooks": [{ "url": "%[1]s/shop" }] }
+		}],
+		"notifications": { "webhooks": [{ "url": "%[1]s/web", "match": { "team": "shop" } }] }
+	}`, srv.URL))
+	if _, err := reloadConfig(running, notifier, nil); err != nil {
+		t.Fatalf("reloadConfig() error = %v", err)
+	}
+	send("shop-after", "shop", "shop")
+	send("web-after", "", "web")
+
+	var got []string
+	for range 3 {
+		select {
+		case d := <-delivered:
+			got = append(got, d)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("delivered %q, waiting for more", got)
+		}
+	}
+	want := []string{"/web web-before", "/web shop-after", "/shop shop-after"}
+	if !slices.Equal(got, want) {
+		t.Errorf("delivered %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"sort"
+	"time"
+)
+
+// earliestLeaves returns the first leaf certificate to expire for every
+// hostname in snapshot.
+func earliestLeaves(snapshot store.Snapshot) map[tenantHostname]store.Certificate {
+	earliest := make(map[tenantHostname]store.Certificate)
+	for _, c := range snapshot.Certificates {
+		key := tenantHostname{c.Tenant, string(c.Hostname)}
+		if e, ok := earliest[key]; c.Index == 0 && (!ok || c.NotAfter.Before(e.NotAfter)) {
+			earliest[key] = c
+		}
+	}
+	return earliest
+}
+
+// renewals finds the hostnames whose leaf certificate was due to expire
+// within the first expiry escalation step in previous, the global steps' or
+// its tenant's own, and has since been replaced by one that expires later.
+func renewals(previous, current store.Snapshot, steps []cfg.EscalationStep, tenants []cfg.Tenant, now time.Time) []store.Renewal {
+	before := earliestLeaves(previous)
+	var found []store.Renewal
+	for key, renewed := range earliestLeaves(current) {
+		old, ok := before[key]
+		if !ok || !renewed.NotAfter.After(old.NotAfter) {
+			continue
+		}
+		// steps are sorted widest first
+		window := time.Duration(tenantEscalation(steps, tenants, key.tenant)[0].Within)
+		if old.NotAfter.Sub(now) > window {
+			continue
+		}
+		found = append(found, store.Renewal{
+			Tenant:         key.tenant,
+			Hostname:       renewed.Hostname,
+			OldFingerprint: old.SHA256Fingerprint,
+			NewFingerprint: renewed.SHA256Fingerprint,
+			OldNotAfter:    old.NotAfter,
+			NewNotAfter:    renewed.NotAfter,
+			Overlap:        old.NotAfter.Sub(renewed.NotBefore),
+		})
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Tenant != found[j].Tenant {
+			return found[i].Tenant < found[j].Tenant
+		}
+		return found[i].Hostname < found[j].Hostname
+	})
+	return found
+}
+
+// confirmRenewals logs every renewal and resolves its expiry alert, closing
+// the loop instead of letting the alert go quiet.
+func confirmRenewals(found []store.Renewal, alerts *alert.Manager) {
+	for _, r := range found {
+		log.Info("certificate renewed",
+			"tenant", r.Tenant,
+			"hostname", r.Hostname,
+			"oldNotAfter", r.OldNotAfter,
+			"newNotAfter", r.NewNotAfter,
+			"overlap", r.Overlap.String(),
+		)
+		alerts.Resolve(alert.Key("expiry", r.Tenant, string(r.Hostname)))
+	}
+}
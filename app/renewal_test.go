@@ -0,0 +1,70 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestRenewals(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	steps := []cfg.EscalationStep{{Within: cfg.Duration(30 * day), Severity: "warning"}}
+	// payments are alerted earlier, so their renewals are confirmed earlier
+	tenants := []cfg.Tenant{{Name: "payments", Escalation: []cfg.EscalationStep{{Within: cfg.Duration(45 * day), Severity: "warning"}}}}
+	leaf := func(fingerprint string, notBefore, notAfter time.Duration) store.Certificate {
+		return store.Certificate{
+			Hostname:          "example.com",
+			SHA256Fingerprint: fingerprint,
+			NotBefore:         now.Add(notBefore),
+			NotAfter:          now.Add(notAfter),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		tenant      string
+		old         store.Certificate
+		renewed     store.Certificate
+		wantOverlap time.Duration
+		wantRenewal bool
+	}{
+		{name: "renewed inside the window", old: leaf("old", -80*day, 10*day), renewed: leaf("new", -day, 89*day), wantRenewal: true, wantOverlap: 11 * day},
+		{name: "renewed after expiry", old: leaf("old", -90*day, -2*day), renewed: leaf("new", 0, 90*day), wantRenewal: true, wantOverlap: -2 * day},
+		{name: "rotated outside the window", old: leaf("old", -10*day, 80*day), renewed: leaf("new", 0, 90*day)},
+		{name: "unchanged", old: leaf("old", -80*day, 10*day), renewed: leaf("old", -80*day, 10*day)},
+		{name: "replaced by a shorter-lived certificate", old: leaf("old", -80*day, 10*day), renewed: leaf("new", 0, 5*day)},
+		{name: "renewed inside the tenant's window", tenant: "payments", old: leaf("old", -50*day, 40*day), renewed: leaf("new", -day, 89*day), wantRenewal: true, wantOverlap: 41 * day},
+		{name: "rotated outside the tenant's window", tenant: "payments", old: leaf("old", -40*day, 50*day), renewed: leaf("new", -day, 89*day)},
+		{name: "outside the global window", tenant: "shop", old: leaf("old", -50*day, 40*day), renewed: leaf("new", -day, 89*day)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.old.Tenant, tt.renewed.Tenant = tt.tenant, tt.tenant
+			previous := store.Snapshot{Certificates: []store.Certificate{tt.old}}
+			current := store.Snapshot{Certificates: []store.Certificate{tt.renewed}}
+			found := renewals(previous, current, steps, tenants, now)
+			if (len(found) == 1) != tt.wantRenewal {
+				t.Fatalf("renewals() = %+v, want renewal %v", found, tt.wantRenewal)
+			}
+			if tt.wantRenewal && found[0].Overlap != tt.wantOverlap {
+				t.Errorf("Overlap = %v, want %v", found[0].Overlap, tt.wantOverlap)
+			}
+		})
+	}
+}
+
+func TestConfirmRenewalsResolvesExpiryAlert(t *testing.T) {
+	alerts := alert.NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	alerts.Fire(alert.Alert{Key: "expiry:payments/pay.example.com", Severity: alert.Critical})
+
+	confirmRenewals([]store.Renewal{{Tenant: "payments", Hostname: "pay.example.com"}}, alerts)
+	if _, ok := alerts.Get("expiry:payments/pay.example.com"); ok {
+		t.Error("Expected the expiry alert to resolve on renewal")
+	}
+}
@@ -0,0 +1,183 @@
+// Package report writes a machine-readable summary of a scan cycle, one
+// entry per scanned endpoint.
+package report
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Endpoint statuses, worst last.
+const (
+	Valid       = "valid"
+	Invalid     = "invalid"
+	Revoked     = "revoked"
+	Expired     = "expired"
+	Unreachable = "unreachable"
+)
+
+// Report is what a scan cycle found.
+type Report struct {
+	Time      time.Time  `json:"time"`
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// Endpoint is the leaf certificate one address served for a hostname, with
+// the fingerprints of the whole chain, leaf first. Unreachable endpoints only
+// have a hostname and status.
+type Endpoint struct {
+	Tenant            string       `json:"tenant,omitempty"`
+	Hostname          cfg.Hostname `json:"hostname"`
+	IPAddress         string       `json:"ipAddress,omitempty"`
+	Status            string       `json:"status"`
+	Subject           string       `json:"subject,omitempty"`
+	Issuer            string       `json:"issuer,omitempty"`
+	SerialNumber      string       `json:"serialNumber,omitempty"`
+	NotBefore         time.Time    `json:"notBefore,omitzero"`
+	NotAfter          time.Time    `json:"notAfter,omitzero"`
+	ChainFingerprints []string     `json:"chainFingerprints,omitempty"`
+	VerifyError       string       `json:"verifyError,omitempty"`
+	OCSPStatus        string       `json:"ocspStatus,omitempty"`
+	Deferred          bool         `json:"deferred,omitempty"`
+}
+
+type endpointKey struct {
+	tenant, hostname, ipAddress string
+}
+
+// New summarizes snapshot. failed are the hostnames no handshake succeeded
+// with.
+func New(snapshot store.Snapshot, failed []cfg.Hostname) Report {
+	var order []endpointKey
+	chains := make(map[endpointKey][]store.Certificate)
+	for _, c := range snapshot.Certificates {
+		key := endpointKey{c.Tenant, string(c.Hostname), store.Address(c.IPAddress)}
+		if _, ok := chains[key]; !ok {
+			order = append(order, key)
+		}
+		chains[key] = append(chains[key], c)
+	}
+
+	r := Report{Time: snapshot.Time}
+	for _, key := range order {
+		chain := chains[key]
+		slices.SortFunc(chain, func(a, b store.Certificate) int { return cmp.Compare(a.Index, b.Index) })
+		leaf := chain[0]
+		e := Endpoint{
+			Tenant:       leaf.Tenant,
+			Hostname:     leaf.Hostname,
+			IPAddress:    key.ipAddress,
+			Status:       status(leaf, snapshot.Time),
+			Subject:      leaf.Subject,
+			Issuer:       leaf.Issuer,
+			SerialNumber: leaf.SerialNumber,
+			NotBefore:    leaf.NotBefore,
+			NotAfter:     leaf.NotAfter,
+			VerifyError:  leaf.VerifyError,
+			Deferred:     leaf.Deferred,
+		}
+		if leaf.OCSP != nil {
+			e.OCSPStatus = leaf.OCSP.Status
+		}
+		for _, c := range chain {
+			e.ChainFingerprints = append(e.ChainFingerprints, c.SHA256Fingerprint)
+		}
+		r.Endpoints = append(r.Endpoints, e)
+	}
+	for _, hostname := range failed {
+		r.Endpoints = append(r.Endpoints, Endpoint{Hostname: hostname, Status: Unreachable})
+	}
+	return r
+}
+
+func status(leaf store.Certificate, now time.Time) string {
+	switch {
+	case !leaf.NotAfter.After(now):
+		return Expired
+	case leaf.OCSP != nil && leaf.OCSP.Status == "revoked":
+		return Revoked
+	case leaf.VerifyError != "":
+		return Invalid
+	}
+	return Valid
+}
+
+func WriteJSON(w io.Writer, r Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+var csvHeader = []string{
+	"tenant", "hostname", "ip_address", "status", "subject", "issuer", "serial_number",
+	"not_before", "not_after", "chain_fingerprints", "verify_error", "ocsp_status", "deferred",
+}
+
+// WriteCSV writes one row per endpoint. Chain fingerprints are separated by
+// spaces.
+func WriteCSV(w io.Writer, r Report) error {
+	cw := csv.NewWriter(w)
+	cw.Write(csvHeader)
+	for _, e := range r.Endpoints {
+		cw.Write([]string{
+			e.Tenant,
+			string(e.Hostname),
+			e.IPAddress,
+			e.Status,
+			e.Subject,
+			e.Issuer,
+			e.SerialNumber,
+			formatTime(e.NotBefore),
+			formatTime(e.NotAfter),
+			strings.Join(e.ChainFingerprints, " "),
+			e.VerifyError,
+			e.OCSPStatus,
+			strconv.FormatBool(e.Deferred),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// WriteFile replaces the file at path atomically, so readers never see a
+// partial report. format is "json" or "csv".
+func WriteFile(path, format string, r Report) error {
+	write := WriteJSON
+	if format == "csv" {
+		write = WriteCSV
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := write(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
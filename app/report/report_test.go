@@ -0,0 +1,85 @@
+package report
+
+import (
+	"bytes"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"encoding/csv"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	now := time.Now()
+	ip := net.ParseIP("192.0.2.1")
+	leaf := func(hostname cfg.Hostname, notAfter time.Duration) store.Certificate {
+		return store.Certificate{Hostname: hostname, IPAddress: ip, SHA256Fingerprint: "leaf-" + string(hostname), NotAfter: now.Add(notAfter)}
+	}
+	invalid := leaf("invalid.example.com", time.Hour)
+	invalid.VerifyError = "x509: certificate signed by unknown authority"
+	revoked := leaf("revoked.example.com", time.Hour)
+	revoked.OCSP = &store.OCSPStatus{Source: "stapled", Status: "revoked"}
+	snapshot := store.Snapshot{Time: now, Certificates: []store.Certificate{
+		{Hostname: "example.com", IPAddress: ip, Index: 1, SHA256Fingerprint: "intermediate"},
+		leaf("example.com", time.Hour),
+		leaf("expired.example.com", -time.Hour),
+		invalid,
+		revoked,
+	}}
+
+	r := New(snapshot, []cfg.Hostname{"down.example.com"})
+	want := map[cfg.Hostname]string{
+		"example.com":         Valid,
+		"expired.example.com": Expired,
+		"invalid.example.com": Invalid,
+		"revoked.example.com": Revoked,
+		"down.example.com":    Unreachable,
+	}
+	if len(r.Endpoints) != len(want) {
+		t.Fatalf("New() has %d endpoints, want %d", len(r.Endpoints), len(want))
+	}
+	for _, e := range r.Endpoints {
+		if e.Status != want[e.Hostname] {
+			t.Errorf("%s status = %q, want %q", e.Hostname, e.Status, want[e.Hostname])
+		}
+	}
+	if got := r.Endpoints[0].ChainFingerprints; !reflect.DeepEqual(got, []string{"leaf-example.com", "intermediate"}) {
+		t.Errorf("chain fingerprints = %v, want leaf first", got)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	r := Report{Time: time.Now(), Endpoints: []Endpoint{
+		{Hostname: "example.com", IPAddress: "192.0.2.1", Status: Valid, ChainFingerprints: []string{"aa", "bb"}},
+		{Hostname: "down.example.com", Status: Unreachable},
+	}}
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "report.json")
+	if err := WriteFile(path, "json", r); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	var got Report
+	if err := json.Unmarshal(data, &got); err != nil || len(got.Endpoints) != 2 {
+		t.Errorf("JSON report = %s, error = %v", data, err)
+	}
+
+	path = filepath.Join(dir, "report.csv")
+	if err := WriteFile(path, "csv", r); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(path)
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil || len(rows) != 3 {
+		t.Fatalf("CSV report = %s, error = %v", data, err)
+	}
+	if rows[1][1] != "example.com" || rows[1][9] != "aa bb" || rows[2][3] != Unreachable {
+		t.Errorf("CSV rows = %q", rows[1:])
+	}
+}
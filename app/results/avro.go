@@ -0,0 +1,276 @@
+package results
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AvroSchema is the Avro schema of results published with the "avro"
+// encoding. It carries the scan and the fields of each certificate that
+// inventories look up; the JSON encoding carries everything.
+const AvroSchema = `{
+  "type": "record",
+  "name": "ScanResult",
+  "namespace": "certtracker",
+  "fields": [
+    {"name": "time", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "hostname", "type": "string"},
+    {"name": "serverName", "type": "string"},
+    {"name": "ipAddress", "type": "string"},
+    {"name": "cnames", "type": {"type": "array", "items": "string"}},
+    {"name": "tenants", "type": {"type": "array", "items": "string"}},
+    {"name": "chain", "type": {"type": "array", "items": {
+      "type": "record",
+      "name": "Certificate",
+      "fields": [
+        {"name": "index", "type": "int"},
+        {"name": "target", "type": "string"},
+        {"name": "sha256Fingerprint", "type": "string"},
+        {"name": "serialNumber", "type": "string"},
+        {"name": "subject", "type": "string"},
+        {"name": "issuer", "type": "string"},
+        {"name": "notBefore", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+        {"name": "notAfter", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+        {"name": "dnsNames", "type": {"type": "array", "items": "string"}},
+        {"name": "keyAlgorithm", "type": "string"},
+        {"name": "keySize", "type": "int"},
+        {"name": "signatureAlgorithm", "type": "string"},
+        {"name": "trust", "type": "string"},
+        {"name": "verifyError", "type": "string"},
+        {"name": "nameMismatch", "type": "string"}
+      ]
+    }}},
+    {"name": "timings", "type": {
+      "type": "record",
+      "name": "Timings",
+      "fields": [
+        {"name": "dns", "type": "long"},
+        {"name": "connect", "type": "long"},
+        {"name": "handshake", "type": "long"},
+        {"name": "total", "type": "long"}
+      ]
+    }},
+    {"name": "errors", "type": {"type": "array", "items": "string"}},
+    {"name": "sharedWith", "type": "string"},
+    {"name": "resolvers", "type": {"type": "array", "items": "string"}},
+    {"name": "firstSeen", "type": ["null", {"type": "long", "logicalType": "timestamp-millis"}]},
+    {"name": "servingSince", "type": ["null", {"type": "long", "logicalType": "timestamp-millis"}]},
+    {"name": "labels", "type": {"type": "map", "values": "string"}}
+  ]
+}
+`
+
+// avroFingerprint is the CRC-64-AVRO fingerprint of AvroSchema, which
+// prefixes every message so consumers can look the schema up.
+var avroFingerprint = fingerprint(canonicalForm(AvroSchema))
+
+// EncodeAvro encodes r with AvroSchema in Avro's single-object encoding:
+// a two byte marker, the schema's fingerprint, then the binary record.
+func EncodeAvro(r ScanResult) ([]byte, error) {
+	w := avroWriter{b: []byte{0xc3, 0x01}}
+	w.b = binary.LittleEndian.AppendUint64(w.b, avroFingerprint)
+	w.time(r.Time)
+	w.string(string(r.Hostname))
+	w.string(r.ServerName)
+	w.ip(r.IPAddress)
+	w.strings(r.CNAMEs)
+	w.strings(r.Tenants)
+	if len(r.Chain) > 0 {
+		w.long(int64(len(r.Chain)))
+	}
+	for _, c := range r.Chain {
+		w.long(int64(c.Index))
+		w.string(c.Target)
+		w.string(c.SHA256Fingerprint)
+		w.string(c.SerialNumber)
+		w.string(c.Subject)
+		w.string(c.Issuer)
+		w.time(c.NotBefore)
+		w.time(c.NotAfter)
+		w.strings(c.DNSNames)
+		w.string(c.KeyAlgorithm)
+		w.long(int64(c.KeySize))
+		w.string(c.SignatureAlgorithm)
+		w.string(c.Trust)
+		w.string(c.VerifyError)
+		w.string(c.NameMismatch)
+	}
+	w.long(0)
+	w.long(int64(r.Timings.DNS))
+	w.long(int64(r.Timings.Connect))
+	w.long(int64(r.Timings.Handshake))
+	w.long(int64(r.Timings.Total))
+	w.strings(r.Errors)
+	w.string(string(r.SharedWith))
+	w.strings(r.Resolvers)
+	w.optionalTime(r.FirstSeen)
+	w.optionalTime(r.ServingSince)
+	w.stringMap(r.Labels)
+	return w.b, nil
+}
+
+type avroWriter struct {
+	b []byte
+}
+
+// long appends v zig-zag encoded as a varint, which is how Avro encodes
+// both ints and longs.
+func (w *avroWriter) long(v int64) {
+	w.b = binary.AppendVarint(w.b, v)
+}
+
+func (w *avroWriter) string(s string) {
+	w.long(int64(len(s)))
+	w.b = append(w.b, s...)
+}
+
+// strings appends an array of strings as a single block.
+func (w *avroWriter) strings(ss []string) {
+	if len(ss) > 0 {
+		w.long(int64(len(ss)))
+		for _, s := range ss {
+			w.string(s)
+		}
+	}
+	w.long(0)
+}
+
+// stringMap appends a map of strings as a single block, sorted by key so
+// the same map always encodes the same way.
+func (w *avroWriter) stringMap(m map[string]string) {
+	if len(m) > 0 {
+		w.long(int64(len(m)))
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			w.string(k)
+			w.string(m[k])
+		}
+	}
+	w.long(0)
+}
+
+func (w *avroWriter) time(t time.Time) {
+	w.long(t.UnixMilli())
+}
+
+// optionalTime appends a ["null", timestamp-millis] union, null when t is
+// zero.
+func (w *avroWriter) optionalTime(t time.Time) {
+	if t.IsZero() {
+		w.long(0)
+		return
+	}
+	w.long(1)
+	w.time(t)
+}
+
+func (w *avroWriter) ip(ip net.IP) {
+	if ip == nil {
+		w.string("")
+		return
+	}
+	w.string(ip.String())
+}
+
+// canonicalForm returns the Parsing Canonical Form of schema, which is what
+// gets fingerprinted. It covers the records, arrays, maps, unions and
+// primitives AvroSchema uses.
+func canonicalForm(schema string) string {
+	var s any
+	if err := json.Unmarshal([]byte(schema), &s); err != nil {
+		panic(err)
+	}
+	var b strings.Builder
+	writeCanonical(&b, s, "")
+	return b.String()
+}
+
+func writeCanonical(b *strings.Builder, s any, namespace string) {
+	quote := func(s string) {
+		q, _ := json.Marshal(s)
+		b.Write(q)
+	}
+	switch s := s.(type) {
+	case string:
+		if !strings.Contains(s, ".") && namespace != "" && !isPrimitive(s) {
+			s = namespace + "." + s
+		}
+		quote(s)
+	case []any:
+		b.WriteByte('[')
+		for i, branch := range s {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeCanonical(b, branch, namespace)
+		}
+		b.WriteByte(']')
+	case map[string]any:
+		switch s["type"] {
+		case "record":
+			name, _ := s["name"].(string)
+			if ns, ok := s["namespace"].(string); ok {
+				namespace = ns
+			}
+			if !strings.Contains(name, ".") && namespace != "" {
+				name = namespace + "." + name
+			}
+			b.WriteString(`{"name":`)
+			quote(name)
+			b.WriteString(`,"type":"record","fields":[`)
+			fields, _ := s["fields"].([]any)
+			for i, f := range fields {
+				field, _ := f.(map[string]any)
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				b.WriteString(`{"name":`)
+				quote(field["name"].(string))
+				b.WriteString(`,"type":`)
+				writeCanonical(b, field["type"], namespace)
+				b.WriteByte('}')
+			}
+			b.WriteString("]}")
+		case "array":
+			b.WriteString(`{"type":"array","items":`)
+			writeCanonical(b, s["items"], namespace)
+			b.WriteByte('}')
+		case "map":
+			b.WriteString(`{"type":"map","values":`)
+			writeCanonical(b, s["values"], namespace)
+			b.WriteByte('}')
+		default:
+			writeCanonical(b, s["type"], namespace)
+		}
+	}
+}
+
+func isPrimitive(s string) bool {
+	switch s {
+	case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+		return true
+	}
+	return false
+}
+
+// fingerprint is the CRC-64-AVRO (Rabin) fingerprint of s.
+func fingerprint(s string) uint64 {
+	const empty = 0xc15d213aa4d7a795
+	var table [256]uint64
+	for i := range table {
+		fp := uint64(i)
+		for range 8 {
+			fp = (fp >> 1) ^ (empty & -(fp & 1))
+		}
+		table[i] = fp
+	}
+	fp := uint64(empty)
+	for i := 0; i < len(s); i++ {
+		fp = (fp >> 8) ^ table[byte(fp)^s[i]]
+	}
+	return fp
+}
@@ -0,0 +1,97 @@
+package results
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"text/template"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+// Encoder serializes a result for a message queue.
+type Encoder func(ScanResult) ([]byte, error)
+
+// NewEncoder returns the encoder for encoding, "json" or "avro".
+func NewEncoder(encoding string) Encoder {
+	if encoding == "avro" {
+		return EncodeAvro
+	}
+	return func(r ScanResult) ([]byte, error) {
+		return json.Marshal(r)
+	}
+}
+
+// ParseTopic parses text as a topic or subject template over a result.
+func ParseTopic(text string) (*template.Template, error) {
+	return template.New("topic").Parse(text)
+}
+
+func topic(t *template.Template, r ScanResult) (string, error) {
+	var b bytes.Buffer
+	if err := t.Execute(&b, r); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// KafkaWriter is the part of a *kafka.Writer the Kafka sink uses.
+type KafkaWriter interface {
+	WriteMessages(context.Context, ...kafka.Message) error
+	Close() error
+}
+
+// Kafka publishes each result to the topic Topic renders for it, keyed by
+// hostname so a hostname's results stay in order on one partition.
+type Kafka struct {
+	Writer KafkaWriter
+	Topic  *template.Template
+	Encode Encoder
+}
+
+func (k *Kafka) Write(r ScanResult) error {
+	name, err := topic(k.Topic, r)
+	if err != nil {
+		return err
+	}
+	value, err := k.Encode(r)
+	if err != nil {
+		return err
+	}
+	return k.Writer.WriteMessages(context.Background(), kafka.Message{
+		Topic: name,
+		Key:   []byte(r.Hostname),
+		Value: value,
+	})
+}
+
+func (k *Kafka) Close() error {
+	return k.Writer.Close()
+}
+
+// NATS publishes each result on the subject Subject renders for it.
+type NATS struct {
+	Conn    *nats.Conn
+	Subject *template.Template
+	Encode  Encoder
+}
+
+func (n *NATS) Write(r ScanResult) error {
+	subject, err := topic(n.Subject, r)
+	if err != nil {
+		return err
+	}
+	data, err := n.Encode(r)
+	if err != nil {
+		return err
+	}
+	return n.Conn.Publish(subject, data)
+}
+
+// Close flushes what's been published and closes the connection.
+func (n *NATS) Close() error {
+	err := n.Conn.Flush()
+	n.Conn.Close()
+	return err
+}
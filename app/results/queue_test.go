@@ -0,0 +1,239 @@
+package results
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   uint64
+	}{
+		{schema: `"null"`, want: 0x63dd24e7cc258f8a},
+		{schema: `"string"`, want: 0x8f014872634503c7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			if got := fingerprint(canonicalForm(tt.schema)); got != tt.want {
+				t.Errorf("fingerprint() = %#x, want %#x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalForm(t *testing.T) {
+	schema := `{
+	  "type": "record", "name": "Scan", "namespace": "certtracker", "doc": "dropped",
+	  "fields": [
+	    {"name": "time", "type": {"type": "long", "logicalType": "timestamp-millis"}, "doc": "dropped"},
+	    {"name": "timings", "type": {"type": "record", "name": "Timings", "fields": [{"name": "total", "type": "long"}]}},
+	    {"name": "previous", "type": ["null", "Timings"]},
+	    {"name": "errors", "type": {"type": "array", "items": "string"}},
+	    {"name": "labels", "type": {"type": "map", "values": "string"}}
+	  ]
+	}`
+	want := `{"name":"certtracker.Scan","type":"record","fields":[` +
+		`{"name":"time","type":"long"},` +
+		`{"name":"timings","type":{"name":"certtracker.Timings","type":"record","fields":[{"name":"total","type":"long"}]}},` +
+		`{"name":"previous","type":["null","certtracker.Timings"]},` +
+		`{"name":"errors","type":{"type":"array","items":"string"}},` +
+		`{"name":"labels","type":{"type":"map","values":"string"}}]}`
+	if got := canonicalForm(schema); got != want {
+		t.Errorf("canonicalForm() = %s\nwant %s", got, want)
+	}
+}
+
+// avroReader reads back what avroWriter wrote.
+type avroReader struct {
+	b []byte
+}
+
+func (r *avroReader) long() int64 {
+	v, n := binary.Varint(r.b)
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *avroReader) string() string {
+	n := r.long()
+	s := string(r.b[:n])
+	r.b = r.b[n:]
+	return s
+}
+
+func (r *avroReader) strings() []string {
+	var ss []string
+	n := r.long()
+	if n == 0 {
+		return nil
+	}
+	for range n {
+		ss = append(ss, r.string())
+	}
+	if r.long() != 0 {
+		panic("array of more than one block")
+	}
+	return ss
+}
+
+func TestEncodeAvro(t *testing.T) {
+	var schema any
+	if err := json.Unmarshal([]byte(AvroSchema), &schema); err != nil {
+		t.Fatalf("AvroSchema isn't JSON: %v", err)
+	}
+
+	data, err := EncodeAvro(scanned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[0] != 0xc3 || data[1] != 0x01 || binary.LittleEndian.Uint64(data[2:10]) != avroFingerprint {
+		t.Fatalf("EncodeAvro() header = %x", data[:10])
+	}
+	r := &avroReader{b: data[10:]}
+	if got := r.long(); got != scanned.Time.UnixMilli() {
+		t.Errorf("time = %d, want %d", got, scanned.Time.UnixMilli())
+	}
+	if hostname, serverName, ip := r.string(), r.string(), r.string(); hostname != "www.example.com" || serverName != "www.example.com" || ip != "192.0.2.1" {
+		t.Errorf("hostname, serverName, ipAddress = %q, %q, %q", hostname, serverName, ip)
+	}
+	if cnames, tenants := r.strings(), r.strings(); len(cnames) != 0 || len(tenants) != 1 || tenants[0] != "" {
+		t.Errorf("cnames, tenants = %q, %q", cnames, tenants)
+	}
+	if n := r.long(); n != 1 {
+		t.Fatalf("chain has %d certificates, want 1", n)
+	}
+	if index, target, fingerprint := r.long(), r.string(), r.string(); index != 0 || target != "leaf" || fingerprint != "ab12" {
+		t.Errorf("chain[0] = %d, %q, %q", index, target, fingerprint)
+	}
+}
+
+type fakeKafka struct {
+	messages []kafka.Message
+	err      error
+}
+
+func (f *fakeKafka) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	f.messages = append(f.messages, msgs...)
+	return f.err
+}
+
+func (f *fakeKafka) Close() error {
+	return nil
+}
+
+func TestKafka(t *testing.T) {
+	tests := []struct {
+		name      string
+		topic     string
+		encoding  string
+		wantTopic string
+		wantErr   bool
+	}{
+		{name: "json", topic: "cert-tracker.results", encoding: "json", wantTopic: "cert-tracker.results"},
+		{name: "avro", topic: "cert-tracker.results", encoding: "avro", wantTopic: "cert-tracker.results"},
+		{name: "topic per hostname", topic: "certs.{{.Hostname}}", encoding: "json", wantTopic: "certs.www.example.com"},
+		{name: "bad template", topic: "certs.{{.Nope}}", encoding: "json", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topic, err := ParseTopic(tt.topic)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writer := &fakeKafka{}
+			sink := &Kafka{Writer: writer, Topic: topic, Encode: NewEncoder(tt.encoding)}
+			err = sink.Write(scanned)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Write() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			m := writer.messages[0]
+			if m.Topic != tt.wantTopic || string(m.Key) != "www.example.com" {
+				t.Errorf("published to %q with key %q, want %q keyed by hostname", m.Topic, m.Key, tt.wantTopic)
+			}
+			var result ScanResult
+			if isAvro := m.Value[0] == 0xc3; isAvro != (tt.encoding == "avro") || !isAvro && json.Unmarshal(m.Value, &result) != nil {
+				t.Errorf("published %q, want %s", m.Value, tt.encoding)
+			}
+		})
+	}
+}
+
+// fakeNATS accepts one NATS client and sends each message it publishes to
+// the returned channel as "subject payload".
+func fakeNATS(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	published := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(conn, "INFO {\"server_id\":\"fake\",\"version\":\"2.10.0\",\"max_payload\":1048576}\r\n")
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(line)
+			switch {
+			case len(fields) == 0:
+			case fields[0] == "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+			case fields[0] == "PUB" && len(fields) == 3:
+				n, _ := strconv.Atoi(fields[2])
+				payload := make([]byte, n+2)
+				if _, err := io.ReadFull(r, payload); err != nil {
+					return
+				}
+				published <- fields[1] + " " + string(payload[:n])
+			}
+		}
+	}()
+	return "nats://" + ln.Addr().String(), published
+}
+
+func TestNATS(t *testing.T) {
+	url, published := fakeNATS(t)
+	conn, err := nats.Connect(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, _ := ParseTopic("certs.{{with index .Tenants 0}}{{.}}{{else}}default{{end}}")
+	sink := &NATS{Conn: conn, Subject: subject, Encode: NewEncoder("json")}
+	if err := sink.Write(scanned); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	got := <-published
+	subj, payload, _ := strings.Cut(got, " ")
+	var result ScanResult
+	if subj != "certs.default" || json.Unmarshal([]byte(payload), &result) != nil || result.Hostname != scanned.Hostname {
+		t.Errorf("published %q", got)
+	}
+}
@@ -0,0 +1,61 @@
+// Package results records what scanning each target found and hands the
+// records to sinks, for anything downstream that wants them one at a time
+// rather than as a whole snapshot.
+package results
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"net"
+	"time"
+)
+
+// ScanResult is the outcome of one handshake with one address of a target.
+// Chain is empty when the handshake failed; Errors says why. A chain that
+// failed verification is still recorded, with the verification error.
+type ScanResult struct {
+	Time       time.Time           `json:"time"`
+	Hostname   cfg.Hostname        `json:"hostname"`
+	ServerName string              `json:"serverName"`
+	IPAddress  net.IP              `json:"ipAddress"`
+	CNAMEs     []string            `json:"cnames,omitempty"`
+	Tenants    []string            `json:"tenants,omitempty"`
+	Chain      []store.Certificate `json:"chain"`
+	Timings    Timings             `json:"timings"`
+	Errors     []string            `json:"errors,omitempty"`
+	// SharedWith names the hostname whose handshake answered this target
+	// too, because both send the same server name to the same address.
+	// Timings then cover only DNS.
+	SharedWith cfg.Hostname `json:"sharedWith,omitempty"`
+	// Resolvers are the DNS resolvers that returned IPAddress, when
+	// targets are resolved through every resolver.
+	Resolvers []string `json:"resolvers,omitempty"`
+	// FirstSeen is when the leaf certificate was first seen on any
+	// endpoint, and ServingSince when this endpoint started serving it, as
+	// far back as the tracker remembers and to within a scan interval.
+	FirstSeen    time.Time `json:"firstSeen,omitzero"`
+	ServingSince time.Time `json:"servingSince,omitzero"`
+	// Labels are the labels configured for Hostname.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Timings break down how long a scan took. DNS covers resolving the
+// hostname, zero when its addresses were cached, and isn't part of Total.
+// Connect covers the TCP connection and Handshake the TLS handshake of the
+// last attempt, after any STARTTLS exchange; Total covers every attempt.
+type Timings struct {
+	DNS       time.Duration `json:"dns"`
+	Connect   time.Duration `json:"connect"`
+	Handshake time.Duration `json:"handshake"`
+	Total     time.Duration `json:"total"`
+}
+
+// Failed reports whether no chain was captured.
+func (r ScanResult) Failed() bool {
+	return len(r.Chain) == 0
+}
+
+// Sink receives every scan result as it's made.
+type Sink interface {
+	Write(ScanResult) error
+}
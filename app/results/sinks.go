@@ -0,0 +1,125 @@
+package results
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Log logs a line for every certificate in a result's chain, or the errors
+// of a failed scan.
+type Log struct {
+	Logger *slog.Logger
+}
+
+func (l *Log) Write(r ScanResult) error {
+	if r.Failed() {
+		l.Logger.Error("connection error",
+			"hostname", r.Hostname,
+			"ipAddress", r.IPAddress,
+			"cnames", r.CNAMEs,
+			"errors", r.Errors,
+		)
+		return nil
+	}
+	if len(r.Errors) > 0 {
+		l.Logger.Warn("certificate verification failed",
+			"hostname", r.Hostname,
+			"ipAddress", r.IPAddress,
+			"cnames", r.CNAMEs,
+			"errors", r.Errors,
+		)
+	}
+	if r.SharedWith != "" {
+		l.Logger.Info("certificate scanned",
+			"hostname", r.Hostname,
+			"ipAddress", r.IPAddress,
+			"sharedWith", r.SharedWith,
+			"sha256Fingerprint", r.Chain[0].SHA256Fingerprint,
+		)
+		return nil
+	}
+	for _, c := range r.Chain {
+		l.Logger.Info("certificate scanned",
+			"details", c,
+		)
+	}
+	l.Logger.Debug("scan timings",
+		"hostname", r.Hostname,
+		"ipAddress", r.IPAddress,
+		"dns", r.Timings.DNS.String(),
+		"connect", r.Timings.Connect.String(),
+		"handshake", r.Timings.Handshake.String(),
+		"total", r.Timings.Total.String(),
+	)
+	return nil
+}
+
+// JSON writes each result as a line of JSON to W, such as stdout.
+type JSON struct {
+	mu sync.Mutex
+	W  io.Writer
+}
+
+func (j *JSON) Write(r ScanResult) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.W.Write(append(line, '\n'))
+	return err
+}
+
+// File appends each result as a line of JSON to a file.
+type File struct {
+	JSON
+	f *os.File
+}
+
+// OpenFile opens path for appending, creating it if needed.
+func OpenFile(path string) (*File, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &File{JSON: JSON{W: f}, f: f}, nil
+}
+
+func (f *File) Close() error {
+	return f.f.Close()
+}
+
+// HTTP POSTs each result as JSON to URL.
+type HTTP struct {
+	URL    string
+	Client *http.Client
+}
+
+func (h *HTTP) Write(r ScanResult) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cert-tracker")
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", h.URL, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package results
+
+import (
+	"bytes"
+	"cert-tracker/store"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	scanned = ScanResult{
+		Time:       time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+		Hostname:   "www.example.com",
+		ServerName: "www.example.com",
+		IPAddress:  net.ParseIP("192.0.2.1"),
+		Tenants:    []string{""},
+		Chain:      []store.Certificate{{Hostname: "www.example.com", Target: "leaf", SHA256Fingerprint: "ab12"}},
+		Timings:    Timings{DNS: 5 * time.Millisecond, Connect: 20 * time.Millisecond, Handshake: 40 * time.Millisecond, Total: 60 * time.Millisecond},
+	}
+	failed = ScanResult{
+		Time:      time.Date(2025, 6, 1, 12, 0, 1, 0, time.UTC),
+		Hostname:  "api.example.com",
+		IPAddress: net.ParseIP("192.0.2.2"),
+		Errors:    []string{"connection refused"},
+	}
+)
+
+func TestLog(t *testing.T) {
+	var out bytes.Buffer
+	sink := &Log{Logger: slog.New(slog.NewTextHandler(&out, nil))}
+	reused := scanned
+	reused.Hostname, reused.SharedWith = "shop.example.com", scanned.Hostname
+	for _, r := range []ScanResult{scanned, failed, reused} {
+		if err := sink.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "certificate scanned") || !strings.Contains(lines[0], "ab12") ||
+		!strings.Contains(lines[1], "connection error") || !strings.Contains(lines[1], "connection refused") ||
+		!strings.Contains(lines[2], "sharedWith="+string(scanned.Hostname)) {
+		t.Errorf("Log wrote:\n%s", out.String())
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	for _, r := range []ScanResult{scanned, failed} {
+		// reopened, as after a restart
+		f, err := OpenFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Write(r); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []ScanResult
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var r ScanResult
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		got = append(got, r)
+	}
+	if len(got) != 2 || got[0].Chain[0].SHA256Fingerprint != "ab12" || got[0].Timings != scanned.Timings || got[1].Errors[0] != "connection refused" {
+		t.Errorf("File wrote %+v", got)
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	var received []ScanResult
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var result ScanResult
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" || json.Unmarshal(body, &result) != nil {
+			t.Errorf("got %s %s %q", r.Method, r.Header.Get("Content-Type"), body)
+		}
+		received = append(received, result)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := &HTTP{URL: server.URL, Client: server.Client()}
+	if err := sink.Write(scanned); err != nil {
+		t.Errorf("Write() error = %v", err)
+	}
+	status = http.StatusServiceUnavailable
+	if err := sink.Write(failed); err == nil {
+		t.Error("Write() succeeded against a failing endpoint")
+	}
+	if len(received) != 2 || received[0].Hostname != scanned.Hostname || received[1].Hostname != failed.Hostname {
+		t.Errorf("endpoint received %+v", received)
+	}
+}
@@ -0,0 +1,110 @@
+package revocation
+
+import (
+	"cert-tracker/store"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// even the largest public CRLs are far below this
+const maxCRLSize = 256 << 20
+
+// FetchCRL downloads and parses the CRL at url, recording how long it took,
+// how big it is and when the issuer promises the next update.
+func FetchCRL(client *http.Client, url string) store.CRLFetch {
+	fetch := store.CRLFetch{URL: url}
+	start := time.Now()
+	crl, size, err := downloadCRL(client, url)
+	fetch.Latency = time.Since(start)
+	fetch.Size = size
+	if err != nil {
+		fetch.Error = err.Error()
+		return fetch
+	}
+	fetch.ThisUpdate = crl.ThisUpdate
+	fetch.NextUpdate = crl.NextUpdate
+	fetch.Entries = len(crl.RevokedCertificateEntries)
+	return fetch
+}
+
+func downloadCRL(client *http.Client, url string) (*x509.RevocationList, int64, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("CRL distribution point returned %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCRLSize))
+	if err != nil {
+		return nil, int64(len(body)), err
+	}
+	der := body
+	// a few CAs serve PEM despite RFC 5280 asking for DER
+	if block, _ := pem.Decode(body); block != nil {
+		der = block.Bytes
+	}
+	crl, err := x509.ParseRevocationList(der)
+	return crl, int64(len(body)), err
+}
+
+// CRLVerdict is what a CRLMonitor concluded from a fetch.
+type CRLVerdict struct {
+	Unreachable bool
+	Stale       bool
+	Oversized   bool
+	// size change since the previous successful fetch
+	Growth int64
+}
+
+// CRLMonitor decides when each CRL is due and judges the fetched result. A
+// CRL is unreachable after alertAfter failed fetches in a row, stale once its
+// nextUpdate has passed and oversized beyond maxSize bytes.
+type CRLMonitor struct {
+	interval   time.Duration
+	alertAfter int
+	maxSize    int64
+	fetched    map[string]time.Time
+	failed     map[string]int
+	sizes      map[string]int64
+}
+
+func NewCRLMonitor(interval time.Duration, alertAfter int, maxSize int64) *CRLMonitor {
+	return &CRLMonitor{
+		interval:   interval,
+		alertAfter: alertAfter,
+		maxSize:    maxSize,
+		fetched:    make(map[string]time.Time),
+		failed:     make(map[string]int),
+		sizes:      make(map[string]int64),
+	}
+}
+
+// Due reports whether the CRL at url should be fetched again.
+func (m *CRLMonitor) Due(url string, now time.Time) bool {
+	last, ok := m.fetched[url]
+	return !ok || now.Sub(last) >= m.interval
+}
+
+func (m *CRLMonitor) Observe(f store.CRLFetch, now time.Time) CRLVerdict {
+	m.fetched[f.URL] = now
+	if f.Error != "" {
+		m.failed[f.URL]++
+		return CRLVerdict{Unreachable: m.failed[f.URL] >= m.alertAfter}
+	}
+	m.failed[f.URL] = 0
+	v := CRLVerdict{
+		Stale:     !f.NextUpdate.IsZero() && now.After(f.NextUpdate),
+		Oversized: m.maxSize > 0 && f.Size > m.maxSize,
+	}
+	if previous, ok := m.sizes[f.URL]; ok {
+		v.Growth = f.Size - previous
+	}
+	m.sizes[f.URL] = f.Size
+	return v
+}
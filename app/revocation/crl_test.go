@@ -0,0 +1,97 @@
+package revocation
+
+import (
+	"cert-tracker/store"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchCRL(t *testing.T) {
+	_, issuer, issuerKey := testChain(t)
+	nextUpdate := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-time.Hour),
+		NextUpdate: nextUpdate,
+		RevokedCertificateEntries: []x509.RevocationListEntry{
+			{SerialNumber: big.NewInt(7), RevocationTime: time.Now().Add(-time.Hour)},
+		},
+	}, issuer, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/der.crl", func(w http.ResponseWriter, r *http.Request) { w.Write(der) })
+	mux.HandleFunc("/pem.crl", func(w http.ResponseWriter, r *http.Request) {
+		pem.Encode(w, &pem.Block{Type: "X509 CRL", Bytes: der})
+	})
+	mux.HandleFunc("/garbage.crl", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("not a crl")) })
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{path: "/der.crl"},
+		{path: "/pem.crl"},
+		{path: "/garbage.crl", wantErr: true},
+		{path: "/missing.crl", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			fetch := FetchCRL(http.DefaultClient, server.URL+tt.path)
+			if (fetch.Error != "") != tt.wantErr {
+				t.Fatalf("FetchCRL() error = %q, wantErr %v", fetch.Error, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if fetch.Size == 0 || fetch.Entries != 1 || !fetch.NextUpdate.Equal(nextUpdate) {
+				t.Errorf("FetchCRL() = %+v", fetch)
+			}
+		})
+	}
+}
+
+func TestCRLMonitor(t *testing.T) {
+	const url = "http://crl.example.com/r3.crl"
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	fresh := store.CRLFetch{URL: url, Size: 1000, NextUpdate: now.Add(time.Hour)}
+
+	m := NewCRLMonitor(time.Hour, 2, 1500)
+	if !m.Due(url, now) {
+		t.Error("Expected a CRL never fetched to be due")
+	}
+	if v := m.Observe(fresh, now); v != (CRLVerdict{}) {
+		t.Errorf("Observe(fresh) = %+v, want no findings", v)
+	}
+	if m.Due(url, now.Add(30*time.Minute)) {
+		t.Error("Expected a CRL fetched within the interval not to be due")
+	}
+
+	grown := fresh
+	grown.Size = 2000
+	if v := m.Observe(grown, now.Add(time.Hour)); !v.Oversized || v.Growth != 1000 {
+		t.Errorf("Observe(grown) = %+v, want oversized with growth 1000", v)
+	}
+	if v := m.Observe(fresh, now.Add(2*time.Hour)); !v.Stale {
+		t.Errorf("Observe() past nextUpdate = %+v, want stale", v)
+	}
+
+	failed := store.CRLFetch{URL: url, Error: "timeout"}
+	if v := m.Observe(failed, now); v.Unreachable {
+		t.Error("Expected a single failed fetch not to be unreachable")
+	}
+	if v := m.Observe(failed, now); !v.Unreachable {
+		t.Error("Expected failed fetches in a row to be unreachable")
+	}
+}
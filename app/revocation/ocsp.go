@@ -0,0 +1,123 @@
+// Package revocation queries the OCSP responders and CRLs that certificates
+// point at.
+package revocation
+
+import (
+	"bytes"
+	"cert-tracker/store"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// responses are a few KB; anything much larger is not an OCSP response
+const maxResponseSize = 1 << 20
+
+// ProbeOCSP asks the responder at url for the status of cert and measures how
+// long it takes to get a well-formed answer.
+func ProbeOCSP(client *http.Client, url string, cert, issuer *x509.Certificate) store.ResponderProbe {
+	probe := store.ResponderProbe{URL: url}
+	start := time.Now()
+	_, err := queryOCSP(client, url, cert, issuer)
+	probe.Latency = time.Since(start)
+	if err != nil {
+		probe.Error = err.Error()
+	}
+	return probe
+}
+
+// LeafStatus asks the responder at url whether cert has been revoked.
+func LeafStatus(client *http.Client, url string, cert, issuer *x509.Certificate) store.OCSPStatus {
+	resp, err := queryOCSP(client, url, cert, issuer)
+	return ocspStatus(url, resp, err)
+}
+
+// StapledStatus reads the OCSP response a server stapled to its handshake.
+func StapledStatus(stapled []byte, cert, issuer *x509.Certificate) store.OCSPStatus {
+	resp, err := ocsp.ParseResponseForCert(stapled, cert, issuer)
+	return ocspStatus("stapled", resp, err)
+}
+
+var ocspStatuses = map[int]string{
+	ocsp.Good:    "good",
+	ocsp.Revoked: "revoked",
+	ocsp.Unknown: "unknown",
+}
+
+func ocspStatus(source string, resp *ocsp.Response, err error) store.OCSPStatus {
+	if err != nil {
+		return store.OCSPStatus{Source: source, Error: err.Error()}
+	}
+	status := store.OCSPStatus{
+		Source:     source,
+		Status:     ocspStatuses[resp.Status],
+		ThisUpdate: resp.ThisUpdate,
+		NextUpdate: resp.NextUpdate,
+	}
+	if resp.Status == ocsp.Revoked {
+		status.RevokedAt = resp.RevokedAt
+		status.RevocationReason = resp.RevocationReason
+	}
+	return status
+}
+
+func queryOCSP(client *http.Client, url string, cert, issuer *x509.Certificate) (*ocsp.Response, error) {
+	request, err := ocsp.CreateRequest(cert, issuer, &ocsp.RequestOptions{Hash: crypto.SHA1})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Post(url, "application/ocsp-request", bytes.NewReader(request))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OCSP responder returned %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	return ocsp.ParseResponseForCert(body, cert, issuer)
+}
+
+// ResponderMonitor turns a responder's probes over time into verdicts. A
+// responder is flagged after alertAfter bad probes in a row, so a single
+// blip doesn't raise an alert.
+type ResponderMonitor struct {
+	slowAfter  time.Duration
+	alertAfter int
+	slow       map[string]int
+	failed     map[string]int
+}
+
+func NewResponderMonitor(slowAfter time.Duration, alertAfter int) *ResponderMonitor {
+	return &ResponderMonitor{
+		slowAfter:  slowAfter,
+		alertAfter: alertAfter,
+		slow:       make(map[string]int),
+		failed:     make(map[string]int),
+	}
+}
+
+// Observe records p and reports whether its responder is currently slow or
+// unreachable.
+func (m *ResponderMonitor) Observe(p store.ResponderProbe) (slow, unreachable bool) {
+	switch {
+	case p.Error != "":
+		m.failed[p.URL]++
+		m.slow[p.URL] = 0
+	case p.Latency > m.slowAfter:
+		m.failed[p.URL] = 0
+		m.slow[p.URL]++
+	default:
+		m.failed[p.URL] = 0
+		m.slow[p.URL] = 0
+	}
+	return m.slow[p.URL] >= m.alertAfter, m.failed[p.URL] >= m.alertAfter
+}
@@ -0,0 +1,181 @@
+package revocation
+
+import (
+	"cert-tracker/store"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// testChain returns a leaf certificate, its issuer and the issuer's key.
+func testChain(t *testing.T) (leaf, issuer *x509.Certificate, issuerKey *ecdsa.PrivateKey) {
+	t.Helper()
+	issuerKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &issuerKey.PublicKey, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, _ = x509.ParseCertificate(der)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err = x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}, issuer, &leafKey.PublicKey, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ = x509.ParseCertificate(der)
+	return leaf, issuer, issuerKey
+}
+
+// newResponder serves signed OCSP responses with the given status.
+func newResponder(t *testing.T, issuer *x509.Certificate, issuerKey *ecdsa.PrivateKey, status int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req, err := ocsp.ParseRequest(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp, err := ocsp.CreateResponse(issuer, issuer, ocsp.Response{
+			Status:       status,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+			RevokedAt:    time.Now().Add(-time.Minute),
+		}, issuerKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/ocsp-response")
+		w.Write(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProbeOCSP(t *testing.T) {
+	leaf, issuer, issuerKey := testChain(t)
+	responder := newResponder(t, issuer, issuerKey, ocsp.Good)
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+
+	probe := ProbeOCSP(http.DefaultClient, responder.URL, leaf, issuer)
+	if probe.Error != "" || probe.Latency <= 0 {
+		t.Errorf("ProbeOCSP() = %+v, want a successful probe", probe)
+	}
+	if probe := ProbeOCSP(http.DefaultClient, broken.URL, leaf, issuer); probe.Error == "" {
+		t.Error("Expected an error from a responder returning 503")
+	}
+}
+
+func TestResponderMonitor(t *testing.T) {
+	const url = "http://ocsp.example.com"
+	ok := store.ResponderProbe{URL: url, Latency: 100 * time.Millisecond}
+	slowProbe := store.ResponderProbe{URL: url, Latency: 3 * time.Second}
+	failed := store.ResponderProbe{URL: url, Error: "connection refused"}
+
+	tests := []struct {
+		name            string
+		probes          []store.ResponderProbe
+		wantSlow        bool
+		wantUnreachable bool
+	}{
+		{name: "healthy", probes: []store.ResponderProbe{ok, ok}},
+		{name: "single slow probe", probes: []store.ResponderProbe{ok, slowProbe}},
+		{name: "slow in a row", probes: []store.ResponderProbe{slowProbe, slowProbe}, wantSlow: true},
+		{name: "single failure", probes: []store.ResponderProbe{ok, failed}},
+		{name: "failures in a row", probes: []store.ResponderProbe{failed, failed}, wantUnreachable: true},
+		{name: "recovered", probes: []store.ResponderProbe{failed, failed, ok}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewResponderMonitor(2*time.Second, 2)
+			var slow, unreachable bool
+			for _, p := range tt.probes {
+				slow, unreachable = m.Observe(p)
+			}
+			if slow != tt.wantSlow || unreachable != tt.wantUnreachable {
+				t.Errorf("Observe() = %v, %v, want %v, %v", slow, unreachable, tt.wantSlow, tt.wantUnreachable)
+			}
+		})
+	}
+}
+
+func TestLeafStatus(t *testing.T) {
+	leaf, issuer, issuerKey := testChain(t)
+	tests := []struct {
+		name       string
+		status     int
+		wantStatus string
+	}{
+		{name: "good", status: ocsp.Good, wantStatus: "good"},
+		{name: "revoked", status: ocsp.Revoked, wantStatus: "revoked"},
+		{name: "unknown", status: ocsp.Unknown, wantStatus: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responder := newResponder(t, issuer, issuerKey, tt.status)
+			got := LeafStatus(http.DefaultClient, responder.URL, leaf, issuer)
+			if got.Status != tt.wantStatus || got.Error != "" || got.Source != responder.URL {
+				t.Errorf("LeafStatus() = %+v, want status %q", got, tt.wantStatus)
+			}
+			if got.NextUpdate.IsZero() || got.RevokedAt.IsZero() != (tt.status != ocsp.Revoked) {
+				t.Errorf("LeafStatus() times = %+v", got)
+			}
+		})
+	}
+}
+
+func TestStapledStatus(t *testing.T) {
+	leaf, issuer, issuerKey := testChain(t)
+	stapled, err := ocsp.CreateResponse(issuer, issuer, ocsp.Response{
+		Status:       ocsp.Good,
+		SerialNumber: leaf.SerialNumber,
+		ThisUpdate:   time.Now().Add(-time.Minute),
+		NextUpdate:   time.Now().Add(time.Hour),
+	}, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := StapledStatus(stapled, leaf, issuer); got.Status != "good" || got.Source != "stapled" {
+		t.Errorf("StapledStatus() = %+v, want good", got)
+	}
+	if got := StapledStatus([]byte("garbage"), leaf, issuer); got.Error == "" {
+		t.Errorf("StapledStatus() = %+v, want an error", got)
+	}
+}
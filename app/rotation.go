@@ -0,0 +1,75 @@
+package main
+
+import (
+	"cert-tracker/store"
+	"slices"
+	"sort"
+)
+
+// rotations finds the endpoints serving a different leaf certificate than in
+// previous.
+func rotations(previous, current store.Snapshot) []store.Rotation {
+	type endpoint struct {
+		tenant, hostname, ipAddress string
+	}
+	before := make(map[endpoint]store.Certificate)
+	for _, c := range previous.Certificates {
+		if c.Index == 0 {
+			before[endpoint{c.Tenant, string(c.Hostname), c.IPAddress.String()}] = c
+		}
+	}
+	var found []store.Rotation
+	for _, c := range current.Certificates {
+		old, ok := before[endpoint{c.Tenant, string(c.Hostname), c.IPAddress.String()}]
+		if c.Index != 0 || !ok || old.SHA256Fingerprint == c.SHA256Fingerprint {
+			continue
+		}
+		found = append(found, store.Rotation{
+			Tenant:         c.Tenant,
+			Hostname:       c.Hostname,
+			IPAddress:      c.IPAddress,
+			OldFingerprint: old.SHA256Fingerprint,
+			NewFingerprint: c.SHA256Fingerprint,
+			OldSerial:      old.SerialNumber,
+			NewSerial:      c.SerialNumber,
+			OldIssuer:      old.Issuer,
+			NewIssuer:      c.Issuer,
+			AddedNames:     missing(c.DNSNames, old.DNSNames),
+			RemovedNames:   missing(old.DNSNames, c.DNSNames),
+		})
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Tenant != found[j].Tenant {
+			return found[i].Tenant < found[j].Tenant
+		}
+		return found[i].Hostname < found[j].Hostname
+	})
+	return found
+}
+
+// missing returns the names in a that aren't in b.
+func missing(a, b []string) []string {
+	var names []string
+	for _, name := range a {
+		if !slices.Contains(b, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func logRotations(found []store.Rotation) {
+	for _, r := range found {
+		log.Info("certificate rotated",
+			"tenant", r.Tenant,
+			"hostname", r.Hostname,
+			"ipAddress", r.IPAddress,
+			"oldSerial", r.OldSerial,
+			"newSerial", r.NewSerial,
+			"oldIssuer", r.OldIssuer,
+			"newIssuer", r.NewIssuer,
+			"addedNames", r.AddedNames,
+			"removedNames", r.RemovedNames,
+		)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"cert-tracker/store"
+	"net"
+	"slices"
+	"testing"
+)
+
+func TestRotations(t *testing.T) {
+	leaf := func(ip, fingerprint, serial string, names ...string) store.Certificate {
+		return store.Certificate{
+			Hostname:          "example.com",
+			IPAddress:         net.ParseIP(ip),
+			SHA256Fingerprint: fingerprint,
+			SerialNumber:      serial,
+			Issuer:            "CN=R3",
+			DNSNames:          names,
+		}
+	}
+	previous := store.Snapshot{Certificates: []store.Certificate{
+		leaf("192.0.2.1", "aa", "1", "example.com", "www.example.com"),
+		leaf("192.0.2.2", "aa", "1", "example.com", "www.example.com"),
+		{Hostname: "example.com", IPAddress: net.ParseIP("192.0.2.1"), Index: 1, SHA256Fingerprint: "ca"},
+	}}
+	current := store.Snapshot{Certificates: []store.Certificate{
+		leaf("192.0.2.1", "bb", "2", "example.com", "api.example.com"),
+		leaf("192.0.2.2", "aa", "1", "example.com", "www.example.com"),
+		leaf("192.0.2.3", "cc", "3", "example.com"),
+		{Hostname: "example.com", IPAddress: net.ParseIP("192.0.2.1"), Index: 1, SHA256Fingerprint: "cb"},
+	}}
+
+	got := rotations(previous, current)
+	if len(got) != 1 {
+		t.Fatalf("rotations() = %+v, want one rotation", got)
+	}
+	r := got[0]
+	if !r.IPAddress.Equal(net.ParseIP("192.0.2.1")) || r.OldSerial != "1" || r.NewSerial != "2" ||
+		!slices.Equal(r.AddedNames, []string{"api.example.com"}) || !slices.Equal(r.RemovedNames, []string{"www.example.com"}) {
+		t.Errorf("rotations() = %+v", r)
+	}
+	if got := rotations(store.Snapshot{}, current); len(got) != 0 {
+		t.Errorf("rotations() without a previous cycle = %+v, want none", got)
+	}
+}
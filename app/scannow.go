@@ -0,0 +1,84 @@
+package main
+
+import (
+	"cert-tracker/api"
+	"cert-tracker/cfg"
+	"cert-tracker/results"
+	"cert-tracker/store"
+	"context"
+	"slices"
+	"time"
+)
+
+// scanRequest asks the main loop to scan one hostname between cycles, so
+// the scan sees the same config as the cycles do.
+type scanRequest struct {
+	hostname cfg.Hostname
+	reply    chan scanReply
+}
+
+type scanReply struct {
+	snapshot store.Snapshot
+	err      error
+}
+
+// requestScan returns a scan function for the API that hands hostnames to
+// the main loop through requests and waits for the results.
+func requestScan(requests chan<- scanRequest) func(context.Context, cfg.Hostname) (store.Snapshot, error) {
+	return func(ctx context.Context, hostname cfg.Hostname) (store.Snapshot, error) {
+		req := scanRequest{hostname: hostname, reply: make(chan scanReply, 1)}
+		select {
+		case requests <- req:
+		case <-ctx.Done():
+			return store.Snapshot{}, ctx.Err()
+		}
+		select {
+		case r := <-req.reply:
+			return r.snapshot, r.err
+		case <-ctx.Done():
+			return store.Snapshot{}, ctx.Err()
+		}
+	}
+}
+
+// scanHostname resolves and scans every address of a monitored hostname
+// once, without retries or the checks of a full cycle.
+func scanHostname(ctx context.Context, config cfg.Params, st *store.Store, sinks []results.Sink, hostname cfg.Hostname, now time.Time) (store.Snapshot, error) {
+	if !slices.Contains(targets(config, st), hostname) {
+		return store.Snapshot{}, api.ErrUnknownTarget
+	}
+	hostnames := []cfg.Hostname{hostname}
+	scanPlan, err := planHostnames(ctx, config, hostnames, tenantsOf(config, hostnames))
+	if err != nil {
+		return store.Snapshot{}, err
+	}
+	snapshot := store.Snapshot{Time: now}
+	for _, target := range scanPlan {
+		result, state, err := scan(ctx, target, config.Timeout)
+		if ctx.Err() != nil {
+			return store.Snapshot{}, ctx.Err()
+		}
+		if state != nil {
+			acceptPrivateCA(config, &result, state.PeerCertificates)
+		}
+		if state != nil && config.SNI.ProbeDefault {
+			result.Chain[0].NoSNI = defaultCertificate(ctx, target, config.Timeout)
+		}
+		writeResult(sinks, result)
+		for _, tenant := range target.Tenants {
+			if state == nil {
+				snapshot.Failures = append(snapshot.Failures, store.Failure{
+					Tenant:    tenant,
+					Hostname:  target.Hostname,
+					IPAddress: target.IPAddress,
+					Error:     err.Error(),
+				})
+			}
+			for _, c := range result.Chain {
+				c.Tenant = tenant
+				snapshot.Certificates = append(snapshot.Certificates, c)
+			}
+		}
+	}
+	return snapshot, nil
+}
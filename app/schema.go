@@ -0,0 +1,34 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/results"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func schemaCommand(args []string) int {
+	flags := flag.NewFlagSet("schema", flag.ContinueOnError)
+	avro := flags.Bool("avro", false, "print the Avro schema of published scan results instead")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: cert-tracker schema [-avro] > config.schema.json")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *avro {
+		fmt.Print(results.AvroSchema)
+		return 0
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(cfg.Schema()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"cert-tracker/results"
+	"crypto/tls"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+// handshakeKey is what a handshake's outcome depends on: the address it's
+// made from, the address, port and protocol connected to, and the server
+// name and ALPN protocols sent. Hostnames behind a
+// CDN edge or shared load balancer that send the same server name get the
+// same answer, so one handshake per key is enough.
+func handshakeKey(t scanTarget) string {
+	return t.Source.String() + ">" + net.JoinHostPort(t.IPAddress.String(), t.Port) + "/" + t.Protocol + "/" + t.ServerName + "/" + strings.Join(t.ALPN, ",")
+}
+
+// sharedHandshake is a cycle's handshake with one key, the result already
+// carrying its default certificate and OCSP status when those are checked.
+type sharedHandshake struct {
+	result results.ScanResult
+	state  *tls.ConnectionState
+	err    error
+	// retried marks a second attempt, which targets being retried share
+	retried bool
+}
+
+// answer returns the handshake's result as if it had been made for target,
+// with a chain of its own to adjust.
+func (h sharedHandshake) answer(target scanTarget) (results.ScanResult, *tls.ConnectionState, error) {
+	r := h.result
+	r.Errors = slices.Clone(r.Errors)
+	r.Chain = slices.Clone(r.Chain)
+	if r.Hostname == target.Hostname {
+		return r, h.state, h.err
+	}
+	r.SharedWith = h.result.Hostname
+	r.Time = time.Now()
+	r.Hostname = target.Hostname
+	r.CNAMEs = target.CNAMEs
+	r.Resolvers = target.Resolvers
+	r.Tenants = target.Tenants
+	r.Labels = target.Labels
+	r.Timings = results.Timings{DNS: target.Lookup}
+	for i := range r.Chain {
+		r.Chain[i].Hostname = target.Hostname
+	}
+	return r, h.state, h.err
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/results"
+	"cert-tracker/store"
+	"net"
+	"testing"
+)
+
+func TestHandshakeKey(t *testing.T) {
+	base := scanTarget{Hostname: "www.example.com", IPAddress: net.ParseIP("192.0.2.1"), Port: "443", ServerName: "www.example.com"}
+	tests := []struct {
+		name   string
+		change func(*scanTarget)
+		same   bool
+	}{
+		{name: "other hostname, same server name", change: func(t *scanTarget) { t.Hostname = "shop.example.com" }, same: true},
+		{name: "other server name", change: func(t *scanTarget) { t.ServerName = "shop.example.com" }},
+		{name: "other address", change: func(t *scanTarget) { t.IPAddress = net.ParseIP("192.0.2.2") }},
+		{name: "other port", change: func(t *scanTarget) { t.Port = "8443" }},
+		{name: "other protocol", change: func(t *scanTarget) { t.Protocol = "smtp" }},
+	}
+	for _, tt := range tests {
+		other := base
+		tt.change(&other)
+		if same := handshakeKey(base) == handshakeKey(other); same != tt.same {
+			t.Errorf("%s: same key = %v, want %v", tt.name, same, tt.same)
+		}
+	}
+}
+
+func TestSharedHandshakeAnswer(t *testing.T) {
+	h := sharedHandshake{result: results.ScanResult{
+		Hostname: "www.example.com",
+		Tenants:  []string{""},
+		Labels:   map[string]string{"team": "web"},
+		Chain:    []store.Certificate{{Hostname: "www.example.com", SHA256Fingerprint: "aa", VerifyError: "unknown authority"}},
+		Errors:   []string{"unknown authority"},
+		Timings:  results.Timings{Total: 1},
+	}}
+
+	own, _, _ := h.answer(scanTarget{Hostname: "www.example.com"})
+	if own.SharedWith != "" || own.Timings.Total != 1 {
+		t.Errorf("answer() for its own hostname = %+v, want the result unchanged", own)
+	}
+	own.Chain[0].VerifyError = ""
+	own.Errors[0] = ""
+
+	got, _, _ := h.answer(scanTarget{Hostname: "shop.example.com", Tenants: []string{"shop"}, CNAMEs: []string{"edge.example.net"}, Labels: map[string]string{"team": "shop"}, Lookup: 2})
+	if got.Hostname != "shop.example.com" || got.SharedWith != "www.example.com" || got.Tenants[0] != "shop" || got.CNAMEs[0] != "edge.example.net" || got.Labels["team"] != "shop" {
+		t.Errorf("answer() = %+v, want it addressed to shop.example.com", got)
+	}
+	if got.Timings != (results.Timings{DNS: 2}) {
+		t.Errorf("Timings = %+v, want only its own DNS lookup for a shared handshake", got.Timings)
+	}
+	if c := got.Chain[0]; c.Hostname != cfg.Hostname("shop.example.com") || c.VerifyError != "unknown authority" || got.Errors[0] != "unknown authority" {
+		t.Errorf("Chain = %+v, errors %q, want the shared chain for shop.example.com, unaffected by changes to other answers", got.Chain, got.Errors)
+	}
+}
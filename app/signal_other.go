@@ -0,0 +1,20 @@
+//go:build !unix
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+)
+
+func toggleDebugOnSignal(configured slog.Level) {}
+
+func rescanSignals() <-chan os.Signal { return nil }
+
+func reloadSignals() <-chan os.Signal { return nil }
+
+func shutdownContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
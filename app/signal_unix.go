@@ -0,0 +1,47 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// toggleDebugOnSignal switches between debug and the configured level on SIGUSR2.
+func toggleDebugOnSignal(configured slog.Level) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR2)
+	go func() {
+		for range signals {
+			next := slog.LevelDebug
+			if logLevel.Level() == slog.LevelDebug {
+				next = configured
+			}
+			logLevel.Set(next)
+			log.Info("log level changed", "to", next, "signal", "SIGUSR2")
+		}
+	}()
+}
+
+// rescanSignals delivers SIGUSR1, which asks for an immediate scan cycle.
+func rescanSignals() <-chan os.Signal {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	return signals
+}
+
+// reloadSignals delivers SIGHUP, which asks for the config to be read again.
+func reloadSignals() <-chan os.Signal {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	return signals
+}
+
+// shutdownContext is cancelled by SIGINT or SIGTERM, which end the tracker
+// once in-flight connections are abandoned.
+func shutdownContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package main
+
+import (
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRescanSignals(t *testing.T) {
+	requests := rescanSignals()
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case sig := <-requests:
+		if sig != syscall.SIGUSR1 {
+			t.Errorf("got %v, want SIGUSR1", sig)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no rescan requested")
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/results"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+// newSinks sets up the configured result sinks, opening the results file.
+func newSinks(config cfg.Params) ([]results.Sink, error) {
+	var sinks []results.Sink
+	for _, name := range config.Results.Sinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, &results.Log{Logger: log})
+		case "stdout":
+			sinks = append(sinks, &results.JSON{W: os.Stdout})
+		case "file":
+			f, err := results.OpenFile(config.Results.File)
+			if err != nil {
+				closeSinks(sinks)
+				return nil, err
+			}
+			sinks = append(sinks, f)
+		case "http":
+			sinks = append(sinks, &results.HTTP{
+				URL:    config.Results.URL,
+				Client: &http.Client{Timeout: time.Duration(config.Timeout)},
+			})
+		case "kafka":
+			sinks = append(sinks, newKafka(config.Results.Kafka, time.Duration(config.Timeout)))
+		case "nats":
+			n, err := newNATS(config.Results.NATS)
+			if err != nil {
+				closeSinks(sinks)
+				return nil, err
+			}
+			sinks = append(sinks, n)
+		}
+	}
+	return sinks, nil
+}
+
+func newKafka(k cfg.Kafka, timeout time.Duration) *results.Kafka {
+	transport := &kafka.Transport{DialTimeout: timeout}
+	if k.TLS {
+		transport.TLS = &tls.Config{}
+	}
+	if k.Username != "" {
+		transport.SASL = plain.Mechanism{Username: k.Username, Password: string(k.Password)}
+	}
+	// the topic was checked when the config was read
+	topic, _ := results.ParseTopic(k.Topic)
+	return &results.Kafka{
+		Writer: &kafka.Writer{
+			Addr:      kafka.TCP(k.Brokers...),
+			Balancer:  &kafka.Hash{},
+			Transport: transport,
+			// results trickle in as targets are scanned, so don't hold them
+			// back for a fuller batch
+			BatchTimeout: 10 * time.Millisecond,
+			WriteTimeout: timeout,
+		},
+		Topic:  topic,
+		Encode: results.NewEncoder(k.Encoding),
+	}
+}
+
+// newNATS connects to the NATS server, retrying in the background if it
+// can't be reached yet. Results published meanwhile are buffered.
+func newNATS(n cfg.NATS) (*results.NATS, error) {
+	conn, err := nats.Connect(n.URL,
+		nats.Name("cert-tracker"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, err
+	}
+	subject, _ := results.ParseTopic(n.Subject)
+	return &results.NATS{Conn: conn, Subject: subject, Encode: results.NewEncoder(n.Encoding)}, nil
+}
+
+func closeSinks(sinks []results.Sink) {
+	for _, s := range sinks {
+		if c, ok := s.(io.Closer); ok {
+			c.Close()
+		}
+	}
+}
+
+// writeResult hands result to every sink, logging the ones that fail.
+func writeResult(sinks []results.Sink, result results.ScanResult) {
+	for _, s := range sinks {
+		if err := s.Write(result); err != nil {
+			log.Warn("cannot write scan result",
+				"hostname", result.Hostname,
+				"error", err,
+			)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// defaultCertificate connects to target again without SNI, capturing the
+// certificate the server falls back to. Nothing is verified: the point is to
+// see what misconfigured clients get.
+func defaultCertificate(ctx context.Context, target scanTarget, timeout cfg.Duration) *store.DefaultCertificate {
+	target.ServerName, target.ALPN = "", nil
+	conn, _, err := dialTLS(ctx, target, timeout, true)
+	if err != nil {
+		return &store.DefaultCertificate{Error: err.Error()}
+	}
+	defer conn.Close()
+	peers := conn.ConnectionState().PeerCertificates
+	if len(peers) == 0 {
+		return &store.DefaultCertificate{Error: "no certificates"}
+	}
+	leaf := peers[0]
+	fingerprint := sha256.Sum256(leaf.Raw)
+	d := &store.DefaultCertificate{
+		SHA256Fingerprint: hex.EncodeToString(fingerprint[:]),
+		Subject:           leaf.Subject.String(),
+		Issuer:            leaf.Issuer.String(),
+		DNSNames:          leaf.DNSNames,
+		NotAfter:          leaf.NotAfter,
+	}
+	log.Debug("captured default certificate",
+		"hostname", target.Hostname,
+		"ipAddress", target.IPAddress,
+		"subject", d.Subject,
+	)
+	return d
+}
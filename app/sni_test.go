@@ -0,0 +1,48 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServerNameSent(t *testing.T) {
+	sent := make(chan string, 3)
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.TLS = &tls.Config{GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		sent <- hello.ServerName
+		return nil, nil
+	}}
+	server.StartTLS()
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	target := scanTarget{
+		Hostname:   cfg.Hostname("lb.example.com:" + port),
+		IPAddress:  net.ParseIP(host),
+		ServerName: "www.example.com",
+	}
+
+	result, _, _ := scan(context.Background(), target, cfg.Duration(5*time.Second))
+	if result.Failed() {
+		t.Fatal("scan() captured no chain")
+	}
+	// the verified handshake fails, so the name is sent twice
+	for range 2 {
+		if got := <-sent; got != "www.example.com" {
+			t.Errorf("server name sent = %q, want the override", got)
+		}
+	}
+
+	d := defaultCertificate(context.Background(), target, cfg.Duration(5*time.Second))
+	if got := <-sent; got != "" {
+		t.Errorf("server name sent = %q, want none", got)
+	}
+	if d.Error != "" || d.SHA256Fingerprint != result.Chain[0].SHA256Fingerprint {
+		t.Errorf("defaultCertificate() = %+v, want the server's certificate", d)
+	}
+}
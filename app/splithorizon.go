@@ -0,0 +1,126 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+)
+
+// resolveEach resolves hostnames through every configured resolver and
+// merges the answers, keeping each resolver's addresses apart.
+func resolveEach(ctx context.Context, config cfg.Params, hostnames []cfg.Hostname) ([]nameAddressMap, error) {
+	var mappings []nameAddressMap
+	// the index in mappings of each hostname
+	merged := make(map[cfg.Hostname]int)
+	var errs []error
+	// why each hostname failed, through each resolver
+	failures := make(map[cfg.Hostname][]error)
+	for _, server := range config.DNSresolvers {
+		answers, err := resolve(ctx, hostnames, resolver(server, config.Timeout), config.Timeout)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("resolver %s: %w", server, err))
+			continue
+		}
+		for _, answer := range answers {
+			if answer.Err != nil {
+				failures[answer.Hostname] = append(failures[answer.Hostname], fmt.Errorf("resolver %s: %w", server, answer.Err))
+				continue
+			}
+			i, ok := merged[answer.Hostname]
+			if !ok {
+				i = len(mappings)
+				merged[answer.Hostname] = i
+				mappings = append(mappings, nameAddressMap{Hostname: answer.Hostname, Resolvers: make(map[string][]net.IP)})
+			}
+			m := &mappings[i]
+			m.Resolvers[server.String()] = answer.IPAddresses
+			for _, ip := range answer.IPAddresses {
+				if !slices.ContainsFunc(m.IPAddresses, ip.Equal) {
+					m.IPAddresses = append(m.IPAddresses, ip)
+				}
+			}
+			m.Lookup = max(m.Lookup, answer.Lookup)
+		}
+	}
+	if len(mappings) == 0 && len(failures) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		log.Warn("cannot resolve targets through every resolver", "error", err)
+	}
+	for _, hostname := range hostnames {
+		if _, ok := merged[hostname]; ok {
+			for _, err := range failures[hostname] {
+				log.Warn("cannot resolve hostname through every resolver", "hostname", hostname, "error", err)
+			}
+		} else if len(failures[hostname]) > 0 {
+			mappings = append(mappings, nameAddressMap{Hostname: hostname, Err: errors.Join(failures[hostname]...)})
+		}
+	}
+	return mappings, nil
+}
+
+// resolversOf returns the resolvers whose answer included ip, sorted, or
+// none when the hostname wasn't resolved through each resolver.
+func (m nameAddressMap) resolversOf(ip net.IP) []string {
+	var servers []string
+	for server, addresses := range m.Resolvers {
+		if slices.ContainsFunc(addresses, ip.Equal) {
+			servers = append(servers, server)
+		}
+	}
+	sort.Strings(servers)
+	return servers
+}
+
+// compareResolvers logs the hostnames whose resolvers lead to different
+// leaf certificates, such as an internal certificate behind split-horizon
+// DNS.
+func compareResolvers(served []servedChain) {
+	// the leaf fingerprints reached through each resolver, by hostname
+	reached := make(map[cfg.Hostname]map[string][]string)
+	for _, s := range served {
+		if len(s.target.Resolvers) == 0 {
+			continue
+		}
+		sum := sha256.Sum256(s.chain[0].Raw)
+		fingerprint := hex.EncodeToString(sum[:])
+		if reached[s.target.Hostname] == nil {
+			reached[s.target.Hostname] = make(map[string][]string)
+		}
+		for _, server := range s.target.Resolvers {
+			if fingerprints := reached[s.target.Hostname][server]; !slices.Contains(fingerprints, fingerprint) {
+				reached[s.target.Hostname][server] = append(fingerprints, fingerprint)
+			}
+		}
+	}
+	for hostname, byResolver := range reached {
+		if !resolversAgree(byResolver) {
+			log.Warn("resolvers lead to different certificates",
+				"hostname", hostname,
+				"certificates", byResolver,
+			)
+		}
+	}
+}
+
+// resolversAgree reports whether every resolver led to the same set of
+// fingerprints. It sorts them in place.
+func resolversAgree(byResolver map[string][]string) bool {
+	var first []string
+	for _, fingerprints := range byResolver {
+		sort.Strings(fingerprints)
+		if first == nil {
+			first = fingerprints
+		} else if !slices.Equal(first, fingerprints) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net"
+	"slices"
+	"testing"
+)
+
+func TestResolversOf(t *testing.T) {
+	m := nameAddressMap{
+		Hostname: "intranet.example.com",
+		Resolvers: map[string][]net.IP{
+			"10.0.0.53": {net.ParseIP("10.0.0.5")},
+			"1.1.1.1":   {net.ParseIP("203.0.113.5"), net.ParseIP("2001:db8::5")},
+			"8.8.8.8":   {net.ParseIP("203.0.113.5")},
+		},
+	}
+
+	tests := []struct {
+		ip   string
+		want []string
+	}{
+		{ip: "10.0.0.5", want: []string{"10.0.0.53"}},
+		{ip: "203.0.113.5", want: []string{"1.1.1.1", "8.8.8.8"}},
+		{ip: "2001:db8::5", want: []string{"1.1.1.1"}},
+		{ip: "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := m.resolversOf(net.ParseIP(tt.ip)); !slices.Equal(got, tt.want) {
+				t.Errorf("resolversOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := (nameAddressMap{}).resolversOf(net.ParseIP("10.0.0.5")); got != nil {
+		t.Errorf("resolversOf() without per-resolver answers = %v, want none", got)
+	}
+}
+
+func TestResolversAgree(t *testing.T) {
+	tests := []struct {
+		name       string
+		byResolver map[string][]string
+		want       bool
+	}{
+		{name: "one resolver", byResolver: map[string][]string{"1.1.1.1": {"aa"}}, want: true},
+		{name: "same certificate", byResolver: map[string][]string{"1.1.1.1": {"aa"}, "10.0.0.53": {"aa"}}, want: true},
+		{name: "same certificates in another order", byResolver: map[string][]string{"1.1.1.1": {"aa", "bb"}, "10.0.0.53": {"bb", "aa"}}, want: true},
+		{name: "internal certificate", byResolver: map[string][]string{"1.1.1.1": {"aa"}, "10.0.0.53": {"cc"}}, want: false},
+		{name: "extra certificate", byResolver: map[string][]string{"1.1.1.1": {"aa"}, "10.0.0.53": {"aa", "cc"}}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolversAgree(tt.byResolver); got != tt.want {
+				t.Errorf("resolversAgree() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
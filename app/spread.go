@@ -0,0 +1,78 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// spreader gives each of a cycle's targets its own turn, evenly spaced
+// across the spread window plus some jitter, so hundreds of targets don't
+// hit load balancers and egress in one burst.
+type spreader struct {
+	start  time.Time
+	slot   time.Duration
+	jitter time.Duration
+	turns  int
+	// time spent waiting for turns, which isn't the cycle running long
+	waited time.Duration
+}
+
+// newSpreader spreads targets across the configured window, or half of
+// interval; nil, which doesn't wait, unless spreading is enabled.
+func newSpreader(spread cfg.Spread, interval cfg.Duration, targets int, start time.Time) *spreader {
+	if !spread.Enabled || targets == 0 {
+		return nil
+	}
+	window := time.Duration(spread.Window)
+	if window == 0 {
+		window = time.Duration(interval) / 2
+	}
+	return &spreader{
+		start:  start,
+		slot:   window / time.Duration(targets),
+		jitter: time.Duration(spread.Jitter),
+		turns:  targets,
+	}
+}
+
+// turn is when the i'th target is due. Targets added during the cycle, such
+// as retries, are due at once.
+func (s *spreader) turn(i int) time.Time {
+	if i >= s.turns {
+		return time.Time{}
+	}
+	t := s.start.Add(time.Duration(i) * s.slot)
+	if s.jitter > 0 {
+		t = t.Add(rand.N(s.jitter))
+	}
+	return t
+}
+
+// wait sleeps until the i'th target's turn or ctx is done.
+func (s *spreader) wait(ctx context.Context, i int) {
+	if s == nil {
+		return
+	}
+	d := time.Until(s.turn(i))
+	if d <= 0 {
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	started := time.Now()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	s.waited += time.Since(started)
+}
+
+// busy is how long the cycle spent scanning rather than waiting.
+func (s *spreader) busy(took time.Duration) time.Duration {
+	if s == nil {
+		return took
+	}
+	return took - s.waited
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSpreaderTurn(t *testing.T) {
+	start := time.Now()
+	interval := cfg.Duration(10 * time.Minute)
+	tests := []struct {
+		name   string
+		spread cfg.Spread
+		i      int
+		from   time.Time
+		to     time.Time
+	}{
+		{name: "first", spread: cfg.Spread{Enabled: true}, i: 0, from: start, to: start},
+		{name: "half the interval", spread: cfg.Spread{Enabled: true}, i: 50, from: start.Add(2*time.Minute + 30*time.Second), to: start.Add(2*time.Minute + 30*time.Second)},
+		{name: "window", spread: cfg.Spread{Enabled: true, Window: cfg.Duration(time.Minute)}, i: 99, from: start.Add(59400 * time.Millisecond), to: start.Add(59400 * time.Millisecond)},
+		{name: "jitter", spread: cfg.Spread{Enabled: true, Jitter: cfg.Duration(5 * time.Second)}, i: 10, from: start.Add(30 * time.Second), to: start.Add(35 * time.Second)},
+		{name: "retry", spread: cfg.Spread{Enabled: true}, i: 100},
+	}
+	for _, tt := range tests {
+		s := newSpreader(tt.spread, interval, 100, start)
+		if got := s.turn(tt.i); got.Before(tt.from) || got.After(tt.to) {
+			t.Errorf("%s: turn(%d) = %v, want between %v and %v", tt.name, tt.i, got.Sub(start), tt.from.Sub(start), tt.to.Sub(start))
+		}
+	}
+}
+
+func TestSpreaderWait(t *testing.T) {
+	if s := newSpreader(cfg.Spread{}, cfg.Duration(time.Minute), 10, time.Now()); s != nil {
+		t.Fatalf("newSpreader() = %+v, want nil when disabled", s)
+	}
+
+	s := newSpreader(cfg.Spread{Enabled: true, Window: cfg.Duration(time.Second)}, cfg.Duration(time.Minute), 10, time.Now())
+	s.wait(context.Background(), 1)
+	if s.waited < 50*time.Millisecond {
+		t.Errorf("waited %v for the second of 10 turns in 1s, want about 100ms", s.waited)
+	}
+	if busy := s.busy(time.Second); busy > time.Second-s.waited {
+		t.Errorf("busy() = %v, want the wait left out", busy)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	began := time.Now()
+	s.wait(ctx, 9)
+	if took := time.Since(began); took > 100*time.Millisecond {
+		t.Errorf("wait() took %v after the context was done", took)
+	}
+}
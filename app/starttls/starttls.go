@@ -0,0 +1,224 @@
+// Package starttls upgrades plaintext connections to the point where a TLS
+// handshake can start.
+package starttls
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// Negotiate asks the server on conn to start TLS, speaking protocol, one of
+// smtp, imap, pop3, ldap or postgres. The handshake can begin once it
+// returns nil.
+func Negotiate(conn net.Conn, protocol string) error {
+	var err error
+	switch protocol {
+	case "smtp":
+		err = smtp(conn)
+	case "imap":
+		err = imap(conn)
+	case "pop3":
+		err = pop3(conn)
+	case "ldap":
+		err = ldap(conn)
+	case "postgres":
+		err = postgres(conn)
+	default:
+		return fmt.Errorf("unknown STARTTLS protocol %q", protocol)
+	}
+	if err != nil {
+		return fmt.Errorf("%s STARTTLS: %w", protocol, err)
+	}
+	return nil
+}
+
+// The text protocols are read a line at a time. Servers say nothing after
+// agreeing to start TLS, so the buffer never holds handshake bytes.
+
+func smtp(conn net.Conn) error {
+	r := bufio.NewReader(conn)
+	if err := smtpReply(r, "220"); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(conn, "EHLO cert-tracker\r\n"); err != nil {
+		return err
+	}
+	if err := smtpReply(r, "250"); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(conn, "STARTTLS\r\n"); err != nil {
+		return err
+	}
+	return smtpReply(r, "220")
+}
+
+// smtpReply reads a possibly multiline reply, which must have code.
+func smtpReply(r *bufio.Reader, code string) error {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(line, code) {
+			return fmt.Errorf("unexpected reply %q", strings.TrimSpace(line))
+		}
+		// "250-" continues the reply, "250 " ends it
+		if len(line) < 4 || line[3] != '-' {
+			return nil
+		}
+	}
+}
+
+func imap(conn net.Conn) error {
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "* OK") {
+		return fmt.Errorf("unexpected greeting %q", strings.TrimSpace(line))
+	}
+	if _, err := io.WriteString(conn, "a1 STARTTLS\r\n"); err != nil {
+		return err
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		// untagged responses may come first
+		if strings.HasPrefix(line, "* ") {
+			continue
+		}
+		if !strings.HasPrefix(line, "a1 OK") {
+			return fmt.Errorf("unexpected response %q", strings.TrimSpace(line))
+		}
+		return nil
+	}
+}
+
+func pop3(conn net.Conn) error {
+	r := bufio.NewReader(conn)
+	if err := pop3Reply(r); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(conn, "STLS\r\n"); err != nil {
+		return err
+	}
+	return pop3Reply(r)
+}
+
+func pop3Reply(r *bufio.Reader) error {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "+OK") {
+		return fmt.Errorf("unexpected response %q", strings.TrimSpace(line))
+	}
+	return nil
+}
+
+// ldapStartTLS is an LDAPMessage with ID 1 holding the StartTLS
+// ExtendedRequest of RFC 4511.
+var ldapStartTLS = append([]byte{0x30, 0x1d, 0x02, 0x01, 0x01, 0x77, 0x18, 0x80, 0x16}, "1.3.6.1.4.1.1466.20037"...)
+
+func ldap(conn net.Conn) error {
+	if _, err := conn.Write(ldapStartTLS); err != nil {
+		return err
+	}
+	message, err := readBER(conn, 0x30)
+	if err != nil {
+		return err
+	}
+	// skip the message ID
+	rest, err := skipBER(message)
+	if err != nil {
+		return err
+	}
+	// the ExtendedResponse starts with its resultCode
+	if len(rest) < 5 || rest[0] != 0x78 {
+		return errors.New("unexpected response")
+	}
+	response, err := contents(rest)
+	if err != nil {
+		return err
+	}
+	if len(response) < 3 || response[0] != 0x0a || response[1] != 0x01 {
+		return errors.New("response has no result code")
+	}
+	if code := response[2]; code != 0 {
+		return fmt.Errorf("server refused with result code %d", code)
+	}
+	return nil
+}
+
+// readBER reads one BER element with the given tag from r and returns its
+// contents.
+func readBER(r io.Reader, tag byte) ([]byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if header[0] != tag {
+		return nil, fmt.Errorf("unexpected tag %#x", header[0])
+	}
+	length := int(header[1])
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 3 {
+			return nil, errors.New("unsupported length")
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		length = 0
+		for _, c := range b {
+			length = length<<8 | int(c)
+		}
+	}
+	body := make([]byte, length)
+	_, err := io.ReadFull(r, body)
+	return body, err
+}
+
+// contents returns the contents of the BER element at the start of b.
+func contents(b []byte) ([]byte, error) {
+	r := strings.NewReader(string(b))
+	return readBER(r, b[0])
+}
+
+// skipBER returns what follows the BER element at the start of b.
+func skipBER(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	r := strings.NewReader(string(b))
+	if _, err := readBER(r, b[0]); err != nil {
+		return nil, err
+	}
+	return b[len(b)-r.Len():], nil
+}
+
+// postgresSSLRequest is the length and the SSLRequest code 80877103.
+var postgresSSLRequest = binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, 8), 80877103)
+
+func postgres(conn net.Conn) error {
+	if _, err := conn.Write(postgresSSLRequest); err != nil {
+		return err
+	}
+	answer := make([]byte, 1)
+	if _, err := io.ReadFull(conn, answer); err != nil {
+		return err
+	}
+	if answer[0] != 'S' {
+		return errors.New("server doesn't accept TLS")
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package starttls
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+// serve runs a fake server that reads each expected client message and then
+// writes the matching reply.
+func serve(t *testing.T, conn net.Conn, greeting string, exchanges [][2]string) {
+	t.Helper()
+	go func() {
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		if greeting != "" {
+			io.WriteString(conn, greeting)
+		}
+		for _, e := range exchanges {
+			got := make([]byte, len(e[0]))
+			if _, err := io.ReadFull(r, got); err != nil || string(got) != e[0] {
+				return
+			}
+			io.WriteString(conn, e[1])
+		}
+	}()
+}
+
+func TestNegotiate(t *testing.T) {
+	ldapSuccess := "\x30\x0c\x02\x01\x01\x78\x07\x0a\x01\x00\x04\x00\x04\x00"
+	ldapRefused := "\x30\x0c\x02\x01\x01\x78\x07\x0a\x01\x02\x04\x00\x04\x00"
+	tests := []struct {
+		name      string
+		protocol  string
+		greeting  string
+		exchanges [][2]string
+		wantErr   bool
+	}{
+		{
+			name:     "smtp",
+			protocol: "smtp",
+			greeting: "220 mail.example.com ESMTP\r\n",
+			exchanges: [][2]string{
+				{"EHLO cert-tracker\r\n", "250-mail.example.com\r\n250-PIPELINING\r\n250 STARTTLS\r\n"},
+				{"STARTTLS\r\n", "220 2.0.0 Ready to start TLS\r\n"},
+			},
+		},
+		{
+			name:     "smtp refused",
+			protocol: "smtp",
+			greeting: "220 mail.example.com ESMTP\r\n",
+			exchanges: [][2]string{
+				{"EHLO cert-tracker\r\n", "250 mail.example.com\r\n"},
+				{"STARTTLS\r\n", "502 5.5.1 Unrecognized command\r\n"},
+			},
+			wantErr: true,
+		},
+		{
+			name:      "imap",
+			protocol:  "imap",
+			greeting:  "* OK [CAPABILITY IMAP4rev1 STARTTLS] ready\r\n",
+			exchanges: [][2]string{{"a1 STARTTLS\r\n", "* NOTE\r\na1 OK Begin TLS negotiation now\r\n"}},
+		},
+		{
+			name:      "pop3",
+			protocol:  "pop3",
+			greeting:  "+OK POP3 ready\r\n",
+			exchanges: [][2]string{{"STLS\r\n", "+OK Begin TLS\r\n"}},
+		},
+		{
+			name:      "ldap",
+			protocol:  "ldap",
+			exchanges: [][2]string{{string(ldapStartTLS), ldapSuccess}},
+		},
+		{
+			name:      "ldap refused",
+			protocol:  "ldap",
+			exchanges: [][2]string{{string(ldapStartTLS), ldapRefused}},
+			wantErr:   true,
+		},
+		{
+			name:      "postgres",
+			protocol:  "postgres",
+			exchanges: [][2]string{{string(postgresSSLRequest), "S"}},
+		},
+		{
+			name:      "postgres without TLS",
+			protocol:  "postgres",
+			exchanges: [][2]string{{string(postgresSSLRequest), "N"}},
+			wantErr:   true,
+		},
+		{name: "unknown protocol", protocol: "gopher", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			serve(t, server, tt.greeting, tt.exchanges)
+			err := Negotiate(client, tt.protocol)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Negotiate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLDAPStartTLSRequest(t *testing.T) {
+	// the request must be a well-formed BER element
+	body, err := readBER(bytes.NewReader(ldapStartTLS), 0x30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(body), "1.3.6.1.4.1.1466.20037") {
+		t.Errorf("request body = %q", body)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bufio"
+	"cert-tracker/cfg"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDialTLSWithSTARTTLS(t *testing.T) {
+	// borrow a certificate from a test server
+	https := httptest.NewTLSServer(http.NotFoundHandler())
+	defer https.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		io.WriteString(conn, "220 mail.example.com ESMTP\r\n")
+		r.ReadString('\n')
+		io.WriteString(conn, "250 STARTTLS\r\n")
+		r.ReadString('\n')
+		io.WriteString(conn, "220 Ready to start TLS\r\n")
+		tls.Server(conn, https.TLS).Handshake()
+	}()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	hostname, err := cfg.ParseHostname("smtp://mail.example.com:" + port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, stats, err := dialTLS(context.Background(), scanTarget{Hostname: hostname, ServerName: hostname.Host(), IPAddress: net.ParseIP("127.0.0.1")}, cfg.Duration(5*time.Second), true)
+	if err != nil {
+		t.Fatalf("dialTLS() error = %v", err)
+	}
+	defer conn.Close()
+	if len(conn.ConnectionState().PeerCertificates) == 0 || stats.handshakeBytes == 0 {
+		t.Errorf("dialTLS() state = %+v, %d handshake bytes", conn.ConnectionState(), stats.handshakeBytes)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/stepca"
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// servedChain is the chain an endpoint sent in a handshake.
+type servedChain struct {
+	target scanTarget
+	chain  []*x509.Certificate
+}
+
+// namesKey identifies the names a certificate was issued for, whatever order
+// they are listed in.
+func namesKey(cert *x509.Certificate) string {
+	names := slices.Clone(cert.DNSNames)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	if len(names) == 0 {
+		names = []string{cert.Subject.CommonName}
+	}
+	slices.Sort(names)
+	return strings.Join(names, ",")
+}
+
+// checkStepCA raises an alert for every endpoint serving a certificate the CA
+// issued and since revoked, or one superseded by a newer certificate for the
+// same names. step-ca can't list what it issued, so newest remembers the
+// latest issuance seen on any endpoint, across cycles.
+func checkStepCA(ca *stepca.Client, newest map[string]*x509.Certificate, served []servedChain, alerts *alert.Manager, now time.Time) {
+	var issued []servedChain
+	for _, s := range served {
+		if !ca.Issued(s.chain) {
+			continue
+		}
+		issued = append(issued, s)
+		leaf := s.chain[0]
+		key := namesKey(leaf)
+		if n, ok := newest[key]; !ok || leaf.NotBefore.After(n.NotBefore) {
+			newest[key] = leaf
+		}
+	}
+	var revoked map[string]bool
+	if crl, err := ca.CRL(); err != nil {
+		log.Warn("cannot fetch step-ca CRL", "error", err)
+	} else {
+		revoked = make(map[string]bool)
+		for _, entry := range crl.RevokedCertificateEntries {
+			revoked[entry.SerialNumber.String()] = true
+		}
+	}
+	for _, s := range issued {
+		leaf := s.chain[0]
+		endpoint := string(s.target.Hostname) + "@" + s.target.IPAddress.String()
+		serial := leaf.SerialNumber.String()
+		replacement := newest[namesKey(leaf)]
+		superseded := replacement.NotBefore.After(leaf.NotBefore)
+		labels := map[string]string{
+			"hostname":     string(s.target.Hostname),
+			"ipAddress":    s.target.IPAddress.String(),
+			"serialNumber": serial,
+		}
+		for _, tenant := range s.target.Tenants {
+			// without a CRL, revocation alerts stay as they were
+			if revoked != nil {
+				alerts.Set(revoked[serial], alert.Alert{
+					Key:      alert.Key("stepca-revoked", tenant, endpoint),
+					Severity: alert.Critical,
+					Summary:  fmt.Sprintf("%s serves certificate %s, which step-ca revoked", endpoint, serial),
+					Tenant:   tenant,
+					Labels:   labels,
+					Since:    now,
+				})
+			}
+			alerts.Set(superseded, alert.Alert{
+				Key:      alert.Key("stepca-superseded", tenant, endpoint),
+				Severity: alert.Warning,
+				Summary: fmt.Sprintf("%s serves certificate %s, superseded by %s issued %s",
+					endpoint, serial, replacement.SerialNumber, replacement.NotBefore.Format(time.RFC3339)),
+				Tenant: tenant,
+				Labels: labels,
+				Since:  now,
+			})
+		}
+	}
+}
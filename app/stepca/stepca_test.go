@@ -0,0 +1,133 @@
+package stepca
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// issue creates a certificate signed by parent, or self-signed if parent is
+// nil. Names ending in "CA" get a CA certificate.
+func issue(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if strings.HasSuffix(name, "CA") {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func writeRoot(t *testing.T, root *x509.Certificate) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "root_ca.crt")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: root.Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestClient_Issued(t *testing.T) {
+	root, rootKey := issue(t, "Smallstep Root CA", nil, nil)
+	intermediate, intermediateKey := issue(t, "Smallstep Intermediate CA", root, rootKey)
+	leaf, _ := issue(t, "svc.internal", intermediate, intermediateKey)
+	otherRoot, otherKey := issue(t, "Other Root CA", nil, nil)
+	other, _ := issue(t, "svc.internal", otherRoot, otherKey)
+
+	ca, err := New("https://ca.internal", writeRoot(t, root), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		chain []*x509.Certificate
+		want  bool
+	}{
+		{name: "issued", chain: []*x509.Certificate{leaf, intermediate}, want: true},
+		{name: "missing intermediate", chain: []*x509.Certificate{leaf}},
+		{name: "other CA", chain: []*x509.Certificate{other}},
+		{name: "no chain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ca.Issued(tt.chain); got != tt.want {
+				t.Errorf("Issued() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_CRL(t *testing.T) {
+	root, rootKey := issue(t, "Smallstep Root CA", nil, nil)
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-time.Hour),
+		NextUpdate: time.Now().Add(time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{
+			{SerialNumber: big.NewInt(7), RevocationTime: time.Now().Add(-time.Hour)},
+		},
+	}, root, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/crl" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(der)
+	}))
+	defer server.Close()
+
+	ca, err := New(server.URL+"/", writeRoot(t, server.Certificate()), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crl, err := ca.CRL()
+	if err != nil {
+		t.Fatalf("CRL() error = %v", err)
+	}
+	if len(crl.RevokedCertificateEntries) != 1 || crl.RevokedCertificateEntries[0].SerialNumber.Int64() != 7 {
+		t.Errorf("CRL() entries = %+v", crl.RevokedCertificateEntries)
+	}
+
+	// a server the root doesn't vouch for is refused
+	untrusted, err := New(server.URL, writeRoot(t, root), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := untrusted.CRL(); err == nil {
+		t.Error("CRL() from an untrusted server succeeded")
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/stepca"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckStepCA(t *testing.T) {
+	now := time.Now()
+	rootKey := newKey(t)
+	root := issueCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Smallstep Root CA"},
+		NotBefore:    now.Add(-24 * time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		IsCA:         true,
+	}, rootKey, nil)
+	issuer := &testCA{root, rootKey}
+	leaf := func(serial int64, issued time.Time) *x509.Certificate {
+		return issueCert(t, &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			DNSNames:     []string{"svc.internal"},
+			NotBefore:    issued,
+			NotAfter:     issued.Add(24 * time.Hour),
+		}, newKey(t), issuer)
+	}
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(1),
+		ThisUpdate:                now.Add(-time.Hour),
+		NextUpdate:                now.Add(time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{{SerialNumber: big.NewInt(3), RevocationTime: now}},
+	}, root, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write(crl) }))
+	defer server.Close()
+	// the root file vouches for the test server as well as the CA's leaves
+	rootFile := filepath.Join(t.TempDir(), "root_ca.crt")
+	pems := append(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: root.Raw}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})...,
+	)
+	if err := os.WriteFile(rootFile, pems, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ca, err := stepca.New(server.URL, rootFile, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoint := func(ip string, cert *x509.Certificate) servedChain {
+		return servedChain{
+			target: scanTarget{Hostname: "svc.internal", IPAddress: net.ParseIP(ip), Tenants: []string{""}},
+			chain:  []*x509.Certificate{cert},
+		}
+	}
+	alerts := alert.NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	newest := make(map[string]*x509.Certificate)
+	checkStepCA(ca, newest, []servedChain{
+		endpoint("192.0.2.1", leaf(2, now.Add(-time.Hour))),
+		endpoint("192.0.2.2", leaf(1, now.Add(-2*time.Hour))),
+		endpoint("192.0.2.3", leaf(3, now.Add(-time.Hour))),
+	}, alerts, now)
+
+	tests := []struct {
+		key        string
+		wantFiring bool
+	}{
+		{key: "stepca-superseded:svc.internal@192.0.2.1"},
+		{key: "stepca-revoked:svc.internal@192.0.2.1"},
+		{key: "stepca-superseded:svc.internal@192.0.2.2", wantFiring: true},
+		{key: "stepca-revoked:svc.internal@192.0.2.3", wantFiring: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if _, firing := alerts.Get(tt.key); firing != tt.wantFiring {
+				t.Errorf("firing = %v, want %v", firing, tt.wantFiring)
+			}
+		})
+	}
+
+	// the replacement is remembered after the endpoint serving it is gone
+	checkStepCA(ca, newest, []servedChain{endpoint("192.0.2.2", leaf(1, now.Add(-2*time.Hour)))}, alerts, now)
+	if _, firing := alerts.Get("stepca-superseded:svc.internal@192.0.2.2"); !firing {
+		t.Error("superseded alert cleared once the newer certificate wasn't served")
+	}
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"cert-tracker/cfg"
+	"net"
+)
+
+// snapshotFile is how a snapshot is written. Behind CDNs and shared load
+// balancers one certificate is served under many hostnames, so only its
+// first sighting is written in full; the rest name it by fingerprint.
+type snapshotFile struct {
+	Snapshot
+	Certificates []any `json:"certificates"`
+}
+
+// sighting is a certificate written as a reference to an earlier one with
+// the same fingerprint, keeping only what depends on where it was served.
+type sighting struct {
+	Tenant            string              `json:"tenant,omitempty"`
+	Hostname          cfg.Hostname        `json:"hostname"`
+	IPAddress         net.IP              `json:"ipAddress"`
+	Index             int                 `json:"index"`
+	Target            string              `json:"target"`
+	SHA256Fingerprint string              `json:"sha256Fingerprint"`
+	Trust             string              `json:"trust,omitempty"`
+	VerifyError       string              `json:"verifyError,omitempty"`
+	NameMismatch      string              `json:"nameMismatch,omitempty"`
+	Connection        Connection          `json:"connection,omitzero"`
+	NoSNI             *DefaultCertificate `json:"noSNI,omitempty"`
+	OCSP              *OCSPStatus         `json:"ocsp,omitempty"`
+	Deferred          bool                `json:"deferred,omitempty"`
+	Protocols         []string            `json:"protocols,omitempty"`
+	DANE              string              `json:"dane,omitempty"`
+}
+
+func (snapshot Snapshot) file() snapshotFile {
+	f := snapshotFile{Snapshot: snapshot, Certificates: make([]any, 0, len(snapshot.Certificates))}
+	written := make(map[string]bool)
+	for _, c := range snapshot.Certificates {
+		if c.SHA256Fingerprint == "" || !written[c.SHA256Fingerprint] {
+			written[c.SHA256Fingerprint] = true
+			f.Certificates = append(f.Certificates, c)
+			continue
+		}
+		f.Certificates = append(f.Certificates, sighting{
+			Tenant:            c.Tenant,
+			Hostname:          c.Hostname,
+			IPAddress:         c.IPAddress,
+			Index:             c.Index,
+			Target:            c.Target,
+			SHA256Fingerprint: c.SHA256Fingerprint,
+			Trust:             c.Trust,
+			VerifyError:       c.VerifyError,
+			NameMismatch:      c.NameMismatch,
+			Connection:        c.Connection,
+			NoSNI:             c.NoSNI,
+			OCSP:              c.OCSP,
+			Deferred:          c.Deferred,
+			Protocols:         c.Protocols,
+			DANE:              c.DANE,
+		})
+	}
+	return f
+}
+
+// resolveSightings fills in the certificates written as sightings from the
+// first one with their fingerprint. Snapshots written before sightings
+// existed have none.
+func resolveSightings(certs []Certificate) {
+	first := make(map[string]Certificate)
+	for i, c := range certs {
+		if c.SHA256Fingerprint == "" {
+			continue
+		}
+		full, ok := first[c.SHA256Fingerprint]
+		if !ok {
+			first[c.SHA256Fingerprint] = c
+			continue
+		}
+		if !c.NotAfter.IsZero() {
+			continue
+		}
+		full.Tenant = c.Tenant
+		full.Hostname = c.Hostname
+		full.IPAddress = c.IPAddress
+		full.Index = c.Index
+		full.Target = c.Target
+		full.Trust = c.Trust
+		full.VerifyError = c.VerifyError
+		full.NameMismatch = c.NameMismatch
+		full.Connection = c.Connection
+		full.NoSNI = c.NoSNI
+		full.OCSP = c.OCSP
+		full.Deferred = c.Deferred
+		full.Protocols = c.Protocols
+		full.DANE = c.DANE
+		certs[i] = full
+	}
+}
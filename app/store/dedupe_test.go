@@ -0,0 +1,60 @@
+package store
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSaveWritesSharedCertificatesOnce(t *testing.T) {
+	st := newTestStore(t)
+	notAfter := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	shared := Certificate{
+		Hostname:          "www.example.com",
+		IPAddress:         net.ParseIP("192.0.2.1"),
+		Target:            "leaf",
+		SHA256Fingerprint: "aa",
+		Subject:           "CN=*.example.com",
+		Issuer:            "CN=Test CA",
+		NotAfter:          notAfter,
+		DNSNames:          []string{"*.example.com"},
+		Trust:             "public",
+		Connection:        Connection{ServerName: "www.example.com", Version: "TLS 1.3"},
+	}
+	other := shared
+	other.Tenant = "shop"
+	other.Hostname = "shop.example.com"
+	other.VerifyError = "x509: certificate has expired"
+	other.NameMismatch = "shop.example.net"
+	other.Connection.ServerName = "shop.example.com"
+	other.Protocols = []string{"TLS 1.2"}
+	other.DANE = "invalid"
+	intermediate := Certificate{Hostname: "www.example.com", Index: 1, Target: "intermediate", SHA256Fingerprint: "bb", Subject: "CN=Test CA", NotAfter: notAfter}
+	snapshot := Snapshot{
+		Time:         time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+		Certificates: []Certificate{shared, intermediate, other},
+	}
+
+	path, err := st.Save(snapshot)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(data, []byte("CN=*.example.com")); n != 1 {
+		t.Errorf("subject written %d times, want once", n)
+	}
+
+	loaded, err := st.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Certificates, snapshot.Certificates) {
+		t.Errorf("Load() certificates = %+v, want %+v", loaded.Certificates, snapshot.Certificates)
+	}
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"cert-tracker/cfg"
+	"net"
+	"sort"
+)
+
+const (
+	TargetAdded      = "targetAdded"
+	TargetRemoved    = "targetRemoved"
+	CertificateAdded = "certificateAdded"
+	Rotated          = "rotated"
+	IssuerChanged    = "issuerChanged"
+)
+
+type Change struct {
+	Kind      string       `json:"kind"`
+	Hostname  cfg.Hostname `json:"hostname"`
+	IPAddress net.IP       `json:"ipAddress,omitempty"`
+	Old       string       `json:"old,omitempty"`
+	New       string       `json:"new,omitempty"`
+}
+
+type endpoint struct {
+	tenant    string
+	hostname  cfg.Hostname
+	ipAddress string
+}
+
+func leaves(s Snapshot) map[endpoint]Certificate {
+	m := make(map[endpoint]Certificate)
+	for _, c := range s.Certificates {
+		if c.Index == 0 {
+			m[endpoint{c.Tenant, c.Hostname, c.IPAddress.String()}] = c
+		}
+	}
+	return m
+}
+
+func hostnames(s Snapshot) map[cfg.Hostname]bool {
+	m := make(map[cfg.Hostname]bool)
+	for _, c := range s.Certificates {
+		m[c.Hostname] = true
+	}
+	return m
+}
+
+// Diff reports what changed between an older snapshot a and a newer snapshot b.
+func Diff(a, b Snapshot) []Change {
+	var changes []Change
+
+	oldHosts, newHosts := hostnames(a), hostnames(b)
+	for h := range newHosts {
+		if !oldHosts[h] {
+			changes = append(changes, Change{Kind: TargetAdded, Hostname: h})
+		}
+	}
+	for h := range oldHosts {
+		if !newHosts[h] {
+			changes = append(changes, Change{Kind: TargetRemoved, Hostname: h})
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range a.Certificates {
+		seen[c.SHA256Fingerprint] = true
+	}
+	reported := make(map[string]bool)
+	for _, c := range b.Certificates {
+		if seen[c.SHA256Fingerprint] || reported[c.SHA256Fingerprint] {
+			continue
+		}
+		reported[c.SHA256Fingerprint] = true
+		changes = append(changes, Change{
+			Kind:      CertificateAdded,
+			Hostname:  c.Hostname,
+			IPAddress: c.IPAddress,
+			New:       c.SHA256Fingerprint,
+		})
+	}
+
+	oldLeaves := leaves(a)
+	for key, newLeaf := range leaves(b) {
+		oldLeaf, ok := oldLeaves[key]
+		if !ok {
+			continue
+		}
+		if oldLeaf.SHA256Fingerprint != newLeaf.SHA256Fingerprint {
+			changes = append(changes, Change{
+				Kind:      Rotated,
+				Hostname:  newLeaf.Hostname,
+				IPAddress: newLeaf.IPAddress,
+				Old:       oldLeaf.SHA256Fingerprint,
+				New:       newLeaf.SHA256Fingerprint,
+			})
+		}
+		if oldLeaf.Issuer != newLeaf.Issuer {
+			changes = append(changes, Change{
+				Kind:      IssuerChanged,
+				Hostname:  newLeaf.Hostname,
+				IPAddress: newLeaf.IPAddress,
+				Old:       oldLeaf.Issuer,
+				New:       newLeaf.Issuer,
+			})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Hostname != changes[j].Hostname {
+			return changes[i].Hostname < changes[j].Hostname
+		}
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].IPAddress.String() < changes[j].IPAddress.String()
+	})
+	return changes
+}
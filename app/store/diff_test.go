@@ -0,0 +1,101 @@
+package store
+
+import (
+	"cert-tracker/cfg"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	ip := net.ParseIP("192.0.2.1")
+	leaf := func(hostname cfg.Hostname, fingerprint, issuer string) Certificate {
+		return Certificate{
+			Hostname:          hostname,
+			IPAddress:         ip,
+			Index:             0,
+			Target:            "leaf",
+			SHA256Fingerprint: fingerprint,
+			Issuer:            issuer,
+		}
+	}
+
+	tests := []struct {
+		name  string
+		a, b  []Certificate
+		kinds []string
+	}{
+		{
+			name:  "identical snapshots",
+			a:     []Certificate{leaf("example.com", "aa", "CN=CA 1")},
+			b:     []Certificate{leaf("example.com", "aa", "CN=CA 1")},
+			kinds: nil,
+		},
+		{
+			name:  "rotated by same issuer",
+			a:     []Certificate{leaf("example.com", "aa", "CN=CA 1")},
+			b:     []Certificate{leaf("example.com", "bb", "CN=CA 1")},
+			kinds: []string{CertificateAdded, Rotated},
+		},
+		{
+			name:  "rotated to new issuer",
+			a:     []Certificate{leaf("example.com", "aa", "CN=CA 1")},
+			b:     []Certificate{leaf("example.com", "bb", "CN=CA 2")},
+			kinds: []string{CertificateAdded, IssuerChanged, Rotated},
+		},
+		{
+			name: "target added",
+			a:    []Certificate{leaf("example.com", "aa", "CN=CA 1")},
+			b: []Certificate{
+				leaf("example.com", "aa", "CN=CA 1"),
+				{Hostname: "new.example.com", IPAddress: ip, SHA256Fingerprint: "aa"},
+			},
+			kinds: []string{TargetAdded},
+		},
+		{
+			name:  "target removed",
+			a:     []Certificate{leaf("example.com", "aa", "CN=CA 1")},
+			b:     nil,
+			kinds: []string{TargetRemoved},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			changes := Diff(
+				Snapshot{Time: now.Add(-time.Hour), Certificates: tt.a},
+				Snapshot{Time: now, Certificates: tt.b},
+			)
+
+			if len(changes) != len(tt.kinds) {
+				t.Fatalf("Diff() = %+v, want kinds %v", changes, tt.kinds)
+			}
+			for i, c := range changes {
+				if c.Kind != tt.kinds[i] {
+					t.Errorf("Diff()[%d].Kind = %s, want %s", i, c.Kind, tt.kinds[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDiffRotationDetails(t *testing.T) {
+	ip := net.ParseIP("2001:db8::1")
+	a := Snapshot{Certificates: []Certificate{{Hostname: "example.com", IPAddress: ip, SHA256Fingerprint: "aa"}}}
+	b := Snapshot{Certificates: []Certificate{{Hostname: "example.com", IPAddress: ip, SHA256Fingerprint: "bb"}}}
+
+	for _, c := range Diff(a, b) {
+		if c.Kind != Rotated {
+			continue
+		}
+		if c.Old != "aa" || c.New != "bb" {
+			t.Errorf("rotation old/new = %s/%s, want aa/bb", c.Old, c.New)
+		}
+		if !c.IPAddress.Equal(ip) {
+			t.Errorf("rotation ipAddress = %v, want %v", c.IPAddress, ip)
+		}
+		return
+	}
+	t.Error("expected a rotation change")
+}
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+)
+
+// magic marks encrypted files so plaintext stores can be migrated in place.
+var magic = []byte("cert-tracker:aes-gcm:v1\n")
+
+var ErrEncrypted = errors.New("store file is encrypted but no key is configured")
+
+func (s *Store) seal(plaintext []byte) []byte {
+	if s.aead == nil {
+		return plaintext
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	// crypto/rand.Read never returns an error
+	rand.Read(nonce)
+	out := append(bytes.Clone(magic), nonce...)
+	return s.aead.Seal(out, nonce, plaintext, magic)
+}
+
+// open decrypts data written by seal. Plaintext files are returned unchanged
+// so existing stores stay readable after a key is configured.
+func (s *Store) open(data []byte) ([]byte, error) {
+	ciphertext, ok := bytes.CutPrefix(data, magic)
+	if !ok {
+		return data, nil
+	}
+	if s.aead == nil {
+		return nil, ErrEncrypted
+	}
+	if len(ciphertext) < s.aead.NonceSize() {
+		return nil, errors.New("encrypted store file is truncated")
+	}
+	nonce, ciphertext := ciphertext[:s.aead.NonceSize()], ciphertext[s.aead.NonceSize():]
+	return s.aead.Open(nil, nonce, ciphertext, magic)
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestEncryptedStore(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{0x42}, 32)
+	st, err := Open(dir, key)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	taken := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	path, err := st.Save(testSnapshot(taken))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte("example.com")) {
+		t.Error("Expected hostnames not to appear in the encrypted file")
+	}
+
+	s, err := st.Latest()
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if len(s.Certificates) != 1 || s.Certificates[0].Hostname != "example.com" {
+		t.Errorf("Latest() = %+v", s)
+	}
+
+	withoutKey, err := Open(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := withoutKey.Latest(); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("Latest() without key error = %v, want ErrEncrypted", err)
+	}
+
+	wrongKey, err := Open(dir, bytes.Repeat([]byte{0x24}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wrongKey.Latest(); err == nil {
+		t.Error("Expected error decrypting with the wrong key")
+	}
+}
+
+func TestEncryptedStoreReadsPlaintext(t *testing.T) {
+	dir := t.TempDir()
+	plain, err := Open(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.Save(testSnapshot(time.Now())); err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := Open(dir, bytes.Repeat([]byte{0x42}, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := encrypted.Latest(); err != nil {
+		t.Errorf("Expected plaintext snapshot to stay readable, got %v", err)
+	}
+}
+
+func TestOpenRejectsBadKey(t *testing.T) {
+	if _, err := Open(t.TempDir(), []byte("short")); err == nil {
+		t.Error("Expected error for invalid AES key length")
+	}
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"cert-tracker/cfg"
+	"sort"
+	"time"
+)
+
+// Served is a stretch of snapshots in which an endpoint served the same leaf
+// certificate.
+type Served struct {
+	Tenant            string       `json:"tenant,omitempty"`
+	Hostname          cfg.Hostname `json:"hostname"`
+	IPAddress         string       `json:"ipAddress"`
+	SHA256Fingerprint string       `json:"sha256Fingerprint"`
+	SerialNumber      string       `json:"serialNumber"`
+	Issuer            string       `json:"issuer"`
+	NotAfter          time.Time    `json:"notAfter"`
+	FirstSeen         time.Time    `json:"firstSeen"`
+	LastSeen          time.Time    `json:"lastSeen"`
+}
+
+// History returns the leaf certificates each endpoint of hostname served in
+// turn, from the change index rather than the snapshots. The FirstSeen of an
+// endpoint's last entry is when its certificate last changed, to within a
+// scan interval. A certificate served again after another counts anew.
+func (s *Store) History(hostname cfg.Hostname) ([]Served, error) {
+	entries, err := s.indexEntries()
+	if err != nil {
+		return nil, err
+	}
+	var latest time.Time
+	if times, err := s.List(); err == nil && len(times) > 0 {
+		latest = times[len(times)-1]
+	}
+	var history []Served
+	// the index in history of each endpoint's current certificate
+	current := make(map[endpoint]int)
+	for _, entry := range entries {
+		for _, l := range entry.Leaves {
+			if l.Hostname != hostname {
+				continue
+			}
+			e := l.endpoint()
+			if i, ok := current[e]; ok {
+				history[i].LastSeen = entry.Previous
+				delete(current, e)
+			}
+			if l.SHA256Fingerprint == "" {
+				continue
+			}
+			current[e] = len(history)
+			history = append(history, Served{
+				Tenant:            l.Tenant,
+				Hostname:          l.Hostname,
+				IPAddress:         l.IPAddress,
+				SHA256Fingerprint: l.SHA256Fingerprint,
+				SerialNumber:      l.SerialNumber,
+				Issuer:            l.Issuer,
+				NotAfter:          l.NotAfter,
+				FirstSeen:         entry.Time,
+			})
+		}
+	}
+	// still served in the latest snapshot
+	for _, i := range current {
+		history[i].LastSeen = latest
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		a, b := history[i], history[j]
+		if a.Tenant != b.Tenant {
+			return a.Tenant < b.Tenant
+		}
+		if a.IPAddress != b.IPAddress {
+			return a.IPAddress < b.IPAddress
+		}
+		return a.FirstSeen.Before(b.FirstSeen)
+	})
+	return history, nil
+}
+
+// SerialNumbers returns the serial number of every leaf certificate the
+// change index recorded being served.
+func (s *Store) SerialNumbers() (map[string]bool, error) {
+	entries, err := s.indexEntries()
+	if err != nil {
+		return nil, err
+	}
+	serials := make(map[string]bool)
+	for _, entry := range entries {
+		for _, l := range entry.Leaves {
+			if l.SerialNumber != "" {
+				serials[l.SerialNumber] = true
+			}
+		}
+	}
+	return serials, nil
+}
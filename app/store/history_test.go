@@ -0,0 +1,109 @@
+package store
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	leaf := func(ip, fingerprint string) Certificate {
+		return Certificate{Hostname: "example.com", IPAddress: net.ParseIP(ip), SHA256Fingerprint: fingerprint}
+	}
+	snapshots := [][]Certificate{
+		{leaf("192.0.2.1", "aa"), leaf("192.0.2.2", "aa")},
+		{leaf("192.0.2.1", "aa"), leaf("192.0.2.2", "aa"), {Hostname: "other.example.com", IPAddress: net.ParseIP("192.0.2.9"), SHA256Fingerprint: "cc"}},
+		{leaf("192.0.2.1", "bb"), leaf("192.0.2.2", "aa")},
+		{leaf("192.0.2.1", "bb"), leaf("192.0.2.2", "bb")},
+		{leaf("192.0.2.1", "bb")},
+		{leaf("192.0.2.1", "bb")},
+	}
+	type span struct {
+		ip, fingerprint string
+		first, last     int
+	}
+	want := []span{
+		{"192.0.2.1", "aa", 0, 1},
+		{"192.0.2.1", "bb", 2, 5},
+		{"192.0.2.2", "aa", 0, 2},
+		{"192.0.2.2", "bb", 3, 3},
+	}
+
+	tests := []struct {
+		name string
+		key  []byte
+		// noIndex removes the change index, as in a store from before it
+		noIndex bool
+	}{
+		{name: "change index"},
+		{name: "encrypted change index", key: bytes.Repeat([]byte{0x42}, 32)},
+		{name: "no change index yet", noIndex: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := Open(t.TempDir(), tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, certs := range snapshots {
+				if _, err := st.Save(Snapshot{Time: start.Add(time.Duration(i) * time.Hour), Certificates: certs}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.noIndex {
+				if err := os.Remove(filepath.Join(st.Dir(), indexName)); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				// the index answers without reading any snapshot
+				for i := range snapshots {
+					if err := os.WriteFile(filepath.Join(st.Dir(), fileName(start.Add(time.Duration(i)*time.Hour))), []byte("{"), 0o644); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			history, err := st.History("example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(history) != len(want) {
+				t.Fatalf("History() = %+v, want %d entries", history, len(want))
+			}
+			for i, w := range want {
+				got := history[i]
+				if got.IPAddress != w.ip || got.SHA256Fingerprint != w.fingerprint ||
+					!got.FirstSeen.Equal(start.Add(time.Duration(w.first)*time.Hour)) ||
+					!got.LastSeen.Equal(start.Add(time.Duration(w.last)*time.Hour)) {
+					t.Errorf("History()[%d] = %+v, want %+v", i, got, w)
+				}
+			}
+		})
+	}
+}
+
+func TestSerialNumbers(t *testing.T) {
+	st := newTestStore(t)
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	snapshots := [][]Certificate{
+		{{Hostname: "example.com", SerialNumber: "aa"}, {Hostname: "example.com", Index: 1, SerialNumber: "ca"}},
+		{{Hostname: "example.com", SerialNumber: "bb"}},
+	}
+	for i, certs := range snapshots {
+		if _, err := st.Save(Snapshot{Time: start.Add(time.Duration(i) * time.Hour), Certificates: certs}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	serials, err := st.SerialNumbers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(serials) != 2 || !serials["aa"] || !serials["bb"] {
+		t.Errorf("SerialNumbers() = %v, want the two leaves", serials)
+	}
+}
@@ -0,0 +1,257 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"cert-tracker/cfg"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// indexName is the store's change index. Save appends a line for every
+// snapshot that differs from the one before it, with the leaf certificates
+// endpoints started or stopped serving, so retention and history don't
+// have to read the snapshots.
+const indexName = "changes.jsonl"
+
+// ErrUnreadable reports the snapshots that couldn't be read while building
+// the change index. They count as changes.
+var ErrUnreadable = errors.New("unreadable snapshots")
+
+type indexEntry struct {
+	Time time.Time `json:"time"`
+	// Previous is when the snapshot before was taken, the last time the
+	// endpoints in Leaves were seen serving what they served before.
+	Previous time.Time `json:"previous,omitzero"`
+	// Observation marks an entry only recording endpoints that went away,
+	// which retention doesn't count as a change.
+	Observation bool        `json:"observation,omitempty"`
+	Changes     []Change    `json:"changes,omitempty"`
+	Leaves      []indexLeaf `json:"leaves,omitempty"`
+}
+
+// indexLeaf is the leaf certificate an endpoint started serving, or with
+// no fingerprint, an endpoint no longer served.
+type indexLeaf struct {
+	Tenant            string       `json:"tenant,omitempty"`
+	Hostname          cfg.Hostname `json:"hostname"`
+	IPAddress         string       `json:"ipAddress"`
+	SHA256Fingerprint string       `json:"sha256Fingerprint,omitempty"`
+	SerialNumber      string       `json:"serialNumber,omitempty"`
+	Issuer            string       `json:"issuer,omitempty"`
+	NotAfter          time.Time    `json:"notAfter,omitzero"`
+}
+
+func (l indexLeaf) endpoint() endpoint {
+	return endpoint{l.Tenant, l.Hostname, l.IPAddress}
+}
+
+// changedLeaves returns the endpoints of b serving a leaf they didn't in a,
+// and those of a gone from b.
+func changedLeaves(a, b Snapshot) []indexLeaf {
+	var changed []indexLeaf
+	oldLeaves, newLeaves := leaves(a), leaves(b)
+	for e, c := range newLeaves {
+		if old, ok := oldLeaves[e]; ok && old.SHA256Fingerprint == c.SHA256Fingerprint && old.SerialNumber == c.SerialNumber {
+			continue
+		}
+		changed = append(changed, indexLeaf{
+			Tenant:            c.Tenant,
+			Hostname:          c.Hostname,
+			IPAddress:         e.ipAddress,
+			SHA256Fingerprint: c.SHA256Fingerprint,
+			SerialNumber:      c.SerialNumber,
+			Issuer:            c.Issuer,
+			NotAfter:          c.NotAfter,
+		})
+	}
+	for e := range oldLeaves {
+		if _, ok := newLeaves[e]; !ok {
+			changed = append(changed, indexLeaf{Tenant: e.tenant, Hostname: e.hostname, IPAddress: e.ipAddress})
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		a, b := changed[i], changed[j]
+		if a.Hostname != b.Hostname {
+			return a.Hostname < b.Hostname
+		}
+		if a.Tenant != b.Tenant {
+			return a.Tenant < b.Tenant
+		}
+		return a.IPAddress < b.IPAddress
+	})
+	return changed
+}
+
+// nextEntry returns the entry for current following previous, or false if
+// nothing changed. Without previous, current is the first snapshot and
+// counts as a change.
+func nextEntry(previous *Snapshot, current Snapshot) (indexEntry, bool) {
+	entry := indexEntry{Time: current.Time}
+	if previous == nil {
+		entry.Leaves = changedLeaves(Snapshot{}, current)
+		return entry, true
+	}
+	entry.Previous = previous.Time
+	entry.Changes = Diff(*previous, current)
+	entry.Leaves = changedLeaves(*previous, current)
+	entry.Observation = len(entry.Changes) == 0
+	return entry, len(entry.Changes) > 0 || len(entry.Leaves) > 0
+}
+
+func (s *Store) indexPath() string {
+	return filepath.Join(s.dir, indexName)
+}
+
+// indexChange appends an entry for snapshot if it differs from the latest
+// snapshot saved before it, building the index first if there's none.
+func (s *Store) indexChange(snapshot Snapshot) (skipped []error, err error) {
+	if skipped, err = s.ensureIndex(); err != nil {
+		return skipped, err
+	}
+	previous, err := s.Latest()
+	var entry indexEntry
+	switch {
+	case err == nil:
+		var changed bool
+		if entry, changed = nextEntry(&previous, snapshot); !changed {
+			return skipped, nil
+		}
+	case errors.Is(err, ErrNoSnapshot):
+		entry, _ = nextEntry(nil, snapshot)
+	default:
+		// without its predecessor it counts as a change, the safe side for
+		// retention, but there's nothing to compare its leaves with
+		entry = indexEntry{Time: snapshot.Time}
+	}
+	return skipped, s.appendIndex(entry)
+}
+
+// appendIndex writes entry as a line of its own.
+func (s *Store) appendIndex(entry indexEntry) error {
+	data, err := s.indexLine(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.indexPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// indexLine encodes entry as a line, sealed like the store's files and
+// base64 encoded when encrypted.
+func (s *Store) indexLine(entry indexEntry) ([]byte, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	if s.aead != nil {
+		data = []byte(base64.StdEncoding.EncodeToString(s.seal(data)))
+	}
+	return append(data, '\n'), nil
+}
+
+// readIndex returns the index entries, oldest first.
+func (s *Store) readIndex() ([]indexEntry, error) {
+	f, err := os.Open(s.indexPath())
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []indexEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		data := scanner.Bytes()
+		if !bytes.HasPrefix(data, []byte("{")) {
+			sealed, err := base64.StdEncoding.DecodeString(string(data))
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", indexName, line, err)
+			}
+			if data, err = s.open(sealed); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", indexName, line, err)
+			}
+		}
+		var entry indexEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", indexName, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// indexEntries returns the index entries, or for a store whose index Save
+// hasn't built yet, those it would build, without writing them.
+func (s *Store) indexEntries() ([]indexEntry, error) {
+	entries, err := s.readIndex()
+	if errors.Is(err, os.ErrNotExist) {
+		entries, _, err = s.buildIndex()
+	}
+	return entries, err
+}
+
+// ensureIndex writes the index of a store that has none yet.
+func (s *Store) ensureIndex() (skipped []error, err error) {
+	if _, err := os.Stat(s.indexPath()); !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, err
+	}
+	entries, skipped, err := s.buildIndex()
+	if err != nil {
+		return skipped, err
+	}
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		line, err := s.indexLine(entry)
+		if err != nil {
+			return skipped, err
+		}
+		buf.Write(line)
+	}
+	return skipped, writeFile(s.indexPath(), buf.Bytes())
+}
+
+// buildIndex reads every snapshot once to index them. Snapshots that can't
+// be read count as changes, as does the next one, which is compared with
+// the last that could be read. They are returned as skipped.
+func (s *Store) buildIndex() (entries []indexEntry, skipped []error, err error) {
+	times, err := s.List()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var previous *Snapshot
+	afterUnreadable := false
+	for _, t := range times {
+		current, err := s.Load(filepath.Join(s.dir, fileName(t)))
+		if err != nil {
+			skipped = append(skipped, err)
+			entries = append(entries, indexEntry{Time: t})
+			afterUnreadable = true
+			continue
+		}
+		if entry, changed := nextEntry(previous, current); changed || afterUnreadable {
+			entry.Observation = entry.Observation && !afterUnreadable
+			entries = append(entries, entry)
+		}
+		previous, afterUnreadable = &current, false
+	}
+	return entries, skipped, nil
+}
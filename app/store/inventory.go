@@ -0,0 +1,170 @@
+package store
+
+import (
+	"cert-tracker/cfg"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const inventoryFile = "inventory.json"
+
+const defaultPort = 443
+
+type InventoryEntry struct {
+	Hostname       cfg.Hostname      `json:"hostname"`
+	Port           int               `json:"port"`
+	Owner          string            `json:"owner,omitempty"`
+	ExpectedExpiry time.Time         `json:"expectedExpiry,omitzero"`
+	Tags           map[string]string `json:"tags,omitempty"`
+}
+
+// Target returns the entry as a scan target, carrying the port unless it is
+// the default.
+func (e InventoryEntry) Target() cfg.Hostname {
+	if e.Port == defaultPort {
+		return e.Hostname
+	}
+	return cfg.Hostname(net.JoinHostPort(string(e.Hostname), strconv.Itoa(e.Port)))
+}
+
+// ParseInventoryCSV reads a spreadsheet export with a header row. The hostname
+// column is required; port, owner and expected expiry are optional and any
+// other column is kept as a tag.
+func ParseInventoryCSV(r io.Reader) ([]InventoryEntry, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read CSV header: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	hasHostname := false
+	for i, name := range header {
+		columns[i] = normalizeColumn(name)
+		if columns[i] == "hostname" || columns[i] == "host" {
+			hasHostname = true
+		}
+	}
+	if !hasHostname {
+		return nil, errors.New("CSV header has no hostname column")
+	}
+
+	var entries []InventoryEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		entry := InventoryEntry{Port: defaultPort}
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			if err := entry.set(columns[i], header[i], value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		if entry.Hostname == "" {
+			return nil, fmt.Errorf("line %d: missing hostname", line)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func normalizeColumn(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(name)
+}
+
+func (e *InventoryEntry) set(column, header, value string) error {
+	switch column {
+	case "hostname", "host":
+		hostname, err := cfg.ParseHostname(value)
+		if err != nil {
+			return fmt.Errorf("invalid hostname %q: %w", value, err)
+		}
+		e.Hostname = hostname
+	case "port":
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port %q", value)
+		}
+		e.Port = port
+	case "owner":
+		e.Owner = value
+	case "expectedexpiry", "expiry":
+		expiry, err := parseDate(value)
+		if err != nil {
+			return fmt.Errorf("invalid expected expiry %q: %w", value, err)
+		}
+		e.ExpectedExpiry = expiry
+	default:
+		if e.Tags == nil {
+			e.Tags = make(map[string]string)
+		}
+		e.Tags[strings.TrimSpace(header)] = value
+	}
+	return nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func (s *Store) LoadInventory() ([]InventoryEntry, error) {
+	var entries []InventoryEntry
+	err := s.readJSON(filepath.Join(s.dir, inventoryFile), &entries)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return entries, err
+}
+
+// ImportInventory merges entries into the stored inventory, replacing existing
+// entries for the same hostname and port, and returns the merged inventory.
+func (s *Store) ImportInventory(entries []InventoryEntry) ([]InventoryEntry, error) {
+	existing, err := s.LoadInventory()
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		hostname cfg.Hostname
+		port     int
+	}
+	merged := make(map[key]InventoryEntry)
+	for _, e := range append(existing, entries...) {
+		merged[key{e.Hostname, e.Port}] = e
+	}
+	result := make([]InventoryEntry, 0, len(merged))
+	for _, e := range merged {
+		result = append(result, e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Hostname != result[j].Hostname {
+			return result[i].Hostname < result[j].Hostname
+		}
+		return result[i].Port < result[j].Port
+	})
+
+	return result, s.writeJSON(filepath.Join(s.dir, inventoryFile), result)
+}
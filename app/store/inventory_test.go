@@ -0,0 +1,144 @@
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseInventoryCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []InventoryEntry
+		wantErr bool
+	}{
+		{
+			name: "all columns with tags",
+			input: "Hostname,Port,Owner,Expected Expiry,team\n" +
+				"example.com,8443,alice,2025-09-01,payments\n",
+			want: []InventoryEntry{{
+				Hostname:       "example.com",
+				Port:           8443,
+				Owner:          "alice",
+				ExpectedExpiry: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+				Tags:           map[string]string{"team": "payments"},
+			}},
+		},
+		{
+			name:  "hostname only defaults port",
+			input: "hostname\nexample.com\napi.example.com\n",
+			want: []InventoryEntry{
+				{Hostname: "example.com", Port: 443},
+				{Hostname: "api.example.com", Port: 443},
+			},
+		},
+		{
+			name:  "empty optional cells",
+			input: "hostname,port,owner\nexample.com,,\n",
+			want:  []InventoryEntry{{Hostname: "example.com", Port: 443}},
+		},
+		{
+			name:    "missing hostname column",
+			input:   "name,port\nexample.com,443\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing hostname value",
+			input:   "hostname,owner\n,alice\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid hostname",
+			input:   "hostname\n192.168.1.1\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid port",
+			input:   "hostname,port\nexample.com,70000\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid expiry",
+			input:   "hostname,expected_expiry\nexample.com,next week\n",
+			wantErr: true,
+		},
+		{
+			name:    "empty input",
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInventoryCSV(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseInventoryCSV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseInventoryCSV() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if g.Hostname != w.Hostname || g.Port != w.Port || g.Owner != w.Owner || !g.ExpectedExpiry.Equal(w.ExpectedExpiry) {
+					t.Errorf("entry %d = %+v, want %+v", i, g, w)
+				}
+				for k, v := range w.Tags {
+					if g.Tags[k] != v {
+						t.Errorf("entry %d tag %s = %q, want %q", i, k, g.Tags[k], v)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestImportInventoryMerges(t *testing.T) {
+	st := newTestStore(t)
+
+	if entries, err := st.LoadInventory(); err != nil || entries != nil {
+		t.Fatalf("LoadInventory() on empty store = %v, %v", entries, err)
+	}
+
+	first := []InventoryEntry{
+		{Hostname: "example.com", Port: 443, Owner: "alice"},
+		{Hostname: "example.com", Port: 8443},
+	}
+	if _, err := st.ImportInventory(first); err != nil {
+		t.Fatalf("ImportInventory() error = %v", err)
+	}
+
+	second := []InventoryEntry{
+		{Hostname: "example.com", Port: 443, Owner: "bob"},
+		{Hostname: "api.example.com", Port: 443},
+	}
+	merged, err := st.ImportInventory(second)
+	if err != nil {
+		t.Fatalf("ImportInventory() error = %v", err)
+	}
+	if len(merged) != 3 {
+		t.Fatalf("merged inventory has %d entries, want 3: %+v", len(merged), merged)
+	}
+
+	loaded, err := st.LoadInventory()
+	if err != nil {
+		t.Fatalf("LoadInventory() error = %v", err)
+	}
+	for _, e := range loaded {
+		if e.Hostname == "example.com" && e.Port == 443 && e.Owner != "bob" {
+			t.Errorf("expected later import to replace owner, got %q", e.Owner)
+		}
+	}
+	if loaded[0].Hostname != "api.example.com" {
+		t.Errorf("expected inventory sorted by hostname, got %+v", loaded)
+	}
+
+	// inventory must not be mistaken for a snapshot
+	if times, _ := st.List(); len(times) != 0 {
+		t.Errorf("List() = %v, want no snapshots", times)
+	}
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// leasePrefix starts the name of each lease file, followed by its epoch.
+// Every change to the lease, including a renewal, creates the next epoch's
+// file, and only one replica can create it.
+const leasePrefix = "leader."
+
+// Lease names the replica that does the scanning for everyone sharing the
+// store directory, until Expires.
+type Lease struct {
+	Holder  string    `json:"holder"`
+	Expires time.Time `json:"expires"`
+}
+
+// AcquireLease takes or renews the lease for holder unless another replica
+// holds an unexpired one, and reports whether holder is the leader.
+func (s *Store) AcquireLease(holder string, ttl time.Duration, now time.Time) (bool, error) {
+	current, epoch, err := s.currentLease()
+	if errors.Is(err, os.ErrNotExist) {
+		// the lease file was superseded while being read; try again next
+		// time
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if current.Holder != holder && now.Before(current.Expires) {
+		return false, nil
+	}
+	return s.nextLease(epoch, Lease{Holder: holder, Expires: now.Add(ttl)})
+}
+
+// ReleaseLease gives up the lease if holder has it, so a standby can take
+// over without waiting for it to expire.
+func (s *Store) ReleaseLease(holder string) error {
+	current, epoch, err := s.currentLease()
+	if err != nil || current.Holder != holder {
+		return err
+	}
+	_, err = s.nextLease(epoch, Lease{})
+	return err
+}
+
+// currentLease reads the lease with the highest epoch, or returns an empty
+// lease at epoch 0 when there's none yet, even if the store directory
+// hasn't been created.
+func (s *Store) currentLease() (Lease, int64, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return Lease{}, 0, nil
+	}
+	if err != nil {
+		return Lease{}, 0, err
+	}
+	var epoch int64
+	for _, entry := range entries {
+		if e, ok := leaseEpoch(entry.Name()); ok && e > epoch {
+			epoch = e
+		}
+	}
+	var current Lease
+	if epoch == 0 {
+		return current, 0, nil
+	}
+	err = s.readJSON(s.leasePath(epoch), &current)
+	return current, epoch, err
+}
+
+// nextLease creates the lease file of the epoch after epoch, reporting
+// false if another replica created it first. Older epochs are then removed.
+func (s *Store) nextLease(epoch int64, lease Lease) (bool, error) {
+	data, err := json.Marshal(lease)
+	if err != nil {
+		return false, err
+	}
+	// only the leader saves snapshots, so the first lease may have to create
+	// the store directory
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return false, err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".lease-*")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(s.seal(data)); err != nil {
+		tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+	// linking fails if the name exists, unlike renaming, so exactly one
+	// replica wins each epoch
+	if err := os.Link(tmp.Name(), s.leasePath(epoch+1)); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	for e := epoch; e > 0; e-- {
+		if err := os.Remove(s.leasePath(e)); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+	}
+	return true, nil
+}
+
+func (s *Store) leasePath(epoch int64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s%d%s", leasePrefix, epoch, fileExtension))
+}
+
+func leaseEpoch(name string) (int64, bool) {
+	rest, ok := strings.CutPrefix(name, leasePrefix)
+	if !ok {
+		return 0, false
+	}
+	rest, ok = strings.CutSuffix(rest, fileExtension)
+	if !ok {
+		return 0, false
+	}
+	epoch, err := strconv.ParseInt(rest, 10, 64)
+	return epoch, err == nil && epoch > 0
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAcquireLease(t *testing.T) {
+	s, err := Open(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	ttl := 30 * time.Second
+
+	tests := []struct {
+		name   string
+		holder string
+		after  time.Duration
+		want   bool
+	}{
+		{name: "first replica takes the lease", holder: "a", want: true},
+		{name: "second replica stands by", holder: "b", after: 10 * time.Second},
+		{name: "holder renews", holder: "a", after: 20 * time.Second, want: true},
+		{name: "renewal extended the lease", holder: "b", after: 40 * time.Second},
+		{name: "standby takes over an expired lease", holder: "b", after: 51 * time.Second, want: true},
+		{name: "old leader is now standby", holder: "a", after: 60 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.AcquireLease(tt.holder, ttl, now.Add(tt.after))
+			if err != nil {
+				t.Fatalf("AcquireLease() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("AcquireLease(%q) = %v, want %v", tt.holder, got, tt.want)
+			}
+		})
+	}
+
+	if err := s.ReleaseLease("a"); err != nil {
+		t.Fatalf("ReleaseLease() by a standby error = %v", err)
+	}
+	if ok, _ := s.AcquireLease("a", ttl, now.Add(61*time.Second)); ok {
+		t.Error("Expected a standby's release to leave the lease alone")
+	}
+	if err := s.ReleaseLease("b"); err != nil {
+		t.Fatalf("ReleaseLease() error = %v", err)
+	}
+	if ok, _ := s.AcquireLease("a", ttl, now.Add(62*time.Second)); !ok {
+		t.Error("Expected a released lease to be free")
+	}
+}
+
+func TestAcquireLeaseRace(t *testing.T) {
+	s, err := Open(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	if ok, err := s.AcquireLease("old", time.Second, now); !ok || err != nil {
+		t.Fatalf("AcquireLease() = %v, %v", ok, err)
+	}
+
+	// replicas racing for the expired lease elect exactly one leader
+	var wg sync.WaitGroup
+	var leaders atomic.Int32
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := s.AcquireLease(fmt.Sprintf("replica-%d", i), time.Minute, now.Add(time.Hour))
+			if err != nil {
+				t.Error(err)
+			}
+			if ok {
+				leaders.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := leaders.Load(); got != 1 {
+		t.Errorf("%d replicas became leader, want 1", got)
+	}
+}
+
+func TestAcquireLeaseNewStoreDir(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "store"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first deployment's store directory doesn't exist until a leader
+	// saves a snapshot
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	ok, err := s.AcquireLease("a", time.Minute, now)
+	if !ok || err != nil {
+		t.Fatalf("AcquireLease() = %v, %v, want the lease", ok, err)
+	}
+	if ok, err := s.AcquireLease("b", time.Minute, now.Add(time.Second)); ok || err != nil {
+		t.Errorf("AcquireLease() by a second replica = %v, %v, want it to stand by", ok, err)
+	}
+}
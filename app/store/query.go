@@ -0,0 +1,62 @@
+package store
+
+import (
+	"cert-tracker/cfg"
+	"strings"
+	"time"
+)
+
+type Filter struct {
+	Tenant         string
+	ExpiringWithin time.Duration
+	Issuer         string
+	Tags           map[string]string
+	IncludeChain   bool
+}
+
+// Query returns the certificates in the snapshot matching every filter field
+// that is set. Tags are looked up in the inventory by hostname and port; the
+// entry's owner is available as the "owner" tag.
+func Query(s Snapshot, inventory []InventoryEntry, f Filter, now time.Time) []Certificate {
+	tags := make(map[cfg.Hostname]map[string]string)
+	for _, entry := range inventory {
+		t := make(map[string]string, len(entry.Tags)+1)
+		for k, v := range entry.Tags {
+			t[k] = v
+		}
+		if entry.Owner != "" {
+			t["owner"] = entry.Owner
+		}
+		tags[entry.Target()] = t
+	}
+
+	var results []Certificate
+	for _, c := range s.Certificates {
+		if c.Index != 0 && !f.IncludeChain {
+			continue
+		}
+		if f.Tenant != "" && c.Tenant != f.Tenant {
+			continue
+		}
+		if f.ExpiringWithin > 0 && c.NotAfter.Sub(now) > f.ExpiringWithin {
+			continue
+		}
+		if f.Issuer != "" && !strings.Contains(strings.ToLower(c.Issuer), strings.ToLower(f.Issuer)) {
+			continue
+		}
+		if !hasTags(tags[c.Hostname], f.Tags) {
+			continue
+		}
+		results = append(results, c)
+	}
+	return results
+}
+
+func hasTags(have, want map[string]string) bool {
+	for k, v := range want {
+		if have[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuery(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	snapshot := Snapshot{
+		Time: now,
+		Certificates: []Certificate{
+			{Hostname: "pay.example.com", Index: 0, SHA256Fingerprint: "pay", Issuer: "CN=R3,O=Let's Encrypt,C=US", NotAfter: now.Add(10 * 24 * time.Hour)},
+			{Hostname: "pay.example.com", Index: 1, SHA256Fingerprint: "pay-ca", Issuer: "CN=ISRG Root X1", NotAfter: now.Add(365 * 24 * time.Hour)},
+			{Tenant: "web", Hostname: "www.example.com", Index: 0, SHA256Fingerprint: "www", Issuer: "CN=DigiCert TLS RSA SHA256 2020 CA1", NotAfter: now.Add(90 * 24 * time.Hour)},
+			{Hostname: "pay.example.com:8443", Index: 0, SHA256Fingerprint: "pay-admin", Issuer: "CN=Internal CA", NotAfter: now.Add(365 * 24 * time.Hour)},
+		},
+	}
+	inventory := []InventoryEntry{
+		{Hostname: "pay.example.com", Port: 443, Owner: "alice", Tags: map[string]string{"team": "payments"}},
+		{Hostname: "www.example.com", Port: 443, Tags: map[string]string{"team": "web"}},
+		{Hostname: "pay.example.com", Port: 8443, Tags: map[string]string{"team": "payments-admin"}},
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{name: "no filter returns leaves", filter: Filter{}, want: []string{"pay", "www", "pay-admin"}},
+		{name: "include chain", filter: Filter{IncludeChain: true}, want: []string{"pay", "pay-ca", "www", "pay-admin"}},
+		{name: "expiring within", filter: Filter{ExpiringWithin: 30 * 24 * time.Hour}, want: []string{"pay"}},
+		{name: "issuer case insensitive", filter: Filter{Issuer: "let's encrypt"}, want: []string{"pay"}},
+		{name: "tag", filter: Filter{Tags: map[string]string{"team": "web"}}, want: []string{"www"}},
+		{name: "tag by port", filter: Filter{Tags: map[string]string{"team": "payments-admin"}}, want: []string{"pay-admin"}},
+		{name: "owner tag", filter: Filter{Tags: map[string]string{"owner": "alice"}}, want: []string{"pay"}},
+		{name: "tenant", filter: Filter{Tenant: "web"}, want: []string{"www"}},
+		{name: "combined filters", filter: Filter{Issuer: "DigiCert", Tags: map[string]string{"team": "payments"}}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Query(snapshot, inventory, tt.filter, now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Query() returned %d certificates, want %v", len(got), tt.want)
+			}
+			for i, c := range got {
+				if c.SHA256Fingerprint != tt.want[i] {
+					t.Errorf("Query()[%d] = %s, want %s", i, c.SHA256Fingerprint, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Prune removes snapshots older than the retention periods and returns how
+// many were removed. Snapshots the change index lists differ from their
+// predecessor (rotation, new target, ...) and use the changes period; all
+// others use the observations period. A zero period keeps snapshots
+// forever. The latest snapshot is always kept.
+//
+// Snapshots are told apart by name and the index Save keeps, so Prune reads
+// none of them. Those it can't remove are skipped and reported in the
+// error, without stopping the others being pruned.
+func (s *Store) Prune(observations, changes time.Duration, now time.Time) (int, error) {
+	entries, err := s.readIndex()
+	if err != nil {
+		return 0, err
+	}
+	changed := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.Observation {
+			changed[fileName(entry.Time)] = true
+		}
+	}
+	times, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	var skipped []error
+	for i, t := range times {
+		if i == len(times)-1 {
+			break
+		}
+		period := observations
+		if changed[fileName(t)] {
+			period = changes
+		}
+		if period == 0 || now.Sub(t) <= period {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, fileName(t))); err != nil {
+			skipped = append(skipped, err)
+			continue
+		}
+		removed++
+	}
+	if len(skipped) > 0 {
+		return removed, fmt.Errorf("skipped %d snapshots: %w", len(skipped), errors.Join(skipped...))
+	}
+	return removed, nil
+}
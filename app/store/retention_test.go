@@ -0,0 +1,138 @@
+package store
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPrune(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	observed := func(fingerprint string, age time.Duration) Snapshot {
+		return Snapshot{
+			Time: now.Add(-age),
+			Certificates: []Certificate{{
+				Hostname:          "example.com",
+				IPAddress:         net.ParseIP("192.0.2.1"),
+				SHA256Fingerprint: fingerprint,
+			}},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		observations time.Duration
+		changes      time.Duration
+		want         []time.Duration
+	}{
+		{
+			name:         "keep changes forever",
+			observations: 90 * day,
+			changes:      0,
+			want:         []time.Duration{200 * day, 150 * day, 10 * day, 1 * day},
+		},
+		{
+			name:         "expire old changes",
+			observations: 90 * day,
+			changes:      180 * day,
+			want:         []time.Duration{150 * day, 10 * day, 1 * day},
+		},
+		{
+			name:         "no retention",
+			observations: 0,
+			changes:      0,
+			want:         []time.Duration{200 * day, 190 * day, 150 * day, 100 * day, 10 * day, 1 * day},
+		},
+		{
+			name:         "latest always kept",
+			observations: time.Hour,
+			changes:      time.Hour,
+			want:         []time.Duration{1 * day},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newTestStore(t)
+			for _, s := range []Snapshot{
+				observed("aa", 200*day), // first snapshot counts as a change
+				observed("aa", 190*day),
+				observed("bb", 150*day), // rotation
+				observed("bb", 100*day),
+				observed("bb", 10*day),
+				observed("bb", 1*day),
+			} {
+				if _, err := st.Save(s); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if _, err := st.Prune(tt.observations, tt.changes, now); err != nil {
+				t.Fatalf("Prune() error = %v", err)
+			}
+
+			times, err := st.List()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(times) != len(tt.want) {
+				t.Fatalf("kept %v, want ages %v", times, tt.want)
+			}
+			for i, age := range tt.want {
+				if !times[i].Equal(now.Add(-age)) {
+					t.Errorf("kept[%d] = %v, want %v", i, times[i], now.Add(-age))
+				}
+			}
+		})
+	}
+}
+
+func TestPruneUnreadable(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	st := newTestStore(t)
+	observed := func(fingerprint string, age time.Duration) Snapshot {
+		return Snapshot{
+			Time:         now.Add(-age),
+			Certificates: []Certificate{{Hostname: "example.com", IPAddress: net.ParseIP("192.0.2.1"), SHA256Fingerprint: fingerprint}},
+		}
+	}
+	for _, s := range []Snapshot{observed("aa", 200*day), observed("aa", 190*day), observed("bb", 150*day), observed("bb", 100*day)} {
+		if _, err := st.Save(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a store from before the change index, with a snapshot gone bad
+	if err := os.Remove(filepath.Join(st.Dir(), indexName)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(st.Dir(), fileName(now.Add(-190*day))), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := st.Save(observed("bb", day)); !errors.Is(err, ErrUnreadable) {
+		t.Fatalf("Save() error = %v, want ErrUnreadable for the bad snapshot", err)
+	}
+	// the bad snapshot and the one after it count as changes, and nothing is
+	// read while pruning
+	if err := os.WriteFile(filepath.Join(st.Dir(), fileName(now.Add(-100*day))), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := st.Prune(90*day, 365*day, now)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	times, err := st.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{now.Add(-200 * day), now.Add(-190 * day), now.Add(-150 * day), now.Add(-day)}
+	if removed != 1 || !slices.EqualFunc(times, want, time.Time.Equal) {
+		t.Errorf("Prune() removed %d, kept %v, want %v", removed, times, want)
+	}
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const seenFile = "seen.json"
+
+// SeenCertificate is when a leaf certificate was first and last seen.
+// NotAfter lets it be forgotten once the certificate has expired.
+type SeenCertificate struct {
+	SHA256Fingerprint string    `json:"sha256Fingerprint"`
+	NotAfter          time.Time `json:"notAfter"`
+	FirstSeen         time.Time `json:"firstSeen"`
+	LastSeen          time.Time `json:"lastSeen"`
+}
+
+// Seen tracks each leaf certificate by fingerprint across every endpoint,
+// and, by "hostname@ip", the one each endpoint has served since it last
+// changed.
+type Seen struct {
+	Certificates map[string]SeenCertificate `json:"certificates"`
+	Endpoints    map[string]SeenCertificate `json:"endpoints"`
+}
+
+func NewSeen() Seen {
+	return Seen{
+		Certificates: make(map[string]SeenCertificate),
+		Endpoints:    make(map[string]SeenCertificate),
+	}
+}
+
+// Observe records endpoint serving leaf at t, and returns when the
+// certificate was first seen anywhere and since when the endpoint has
+// served it.
+func (s Seen) Observe(endpoint string, leaf Certificate, t time.Time) (firstSeen, servingSince time.Time) {
+	seen := func(c SeenCertificate, ok bool) SeenCertificate {
+		if !ok || c.SHA256Fingerprint != leaf.SHA256Fingerprint {
+			c = SeenCertificate{SHA256Fingerprint: leaf.SHA256Fingerprint, NotAfter: leaf.NotAfter, FirstSeen: t}
+		}
+		c.LastSeen = t
+		return c
+	}
+	cert := seen(s.Certificates[leaf.SHA256Fingerprint], true)
+	s.Certificates[leaf.SHA256Fingerprint] = cert
+	served, ok := s.Endpoints[endpoint]
+	served = seen(served, ok)
+	s.Endpoints[endpoint] = served
+	return cert.FirstSeen, served.FirstSeen
+}
+
+// Forget drops certificates that have expired by t and weren't seen at t,
+// anywhere or at an endpoint.
+func (s Seen) Forget(t time.Time) {
+	forget := func(seen map[string]SeenCertificate) {
+		for key, c := range seen {
+			if c.NotAfter.Before(t) && c.LastSeen.Before(t) {
+				delete(seen, key)
+			}
+		}
+	}
+	forget(s.Certificates)
+	forget(s.Endpoints)
+}
+
+// LoadSeen reads the sightings saved by SaveSeen, or none if there are
+// none yet.
+func (s *Store) LoadSeen() (Seen, error) {
+	seen := NewSeen()
+	err := s.readJSON(filepath.Join(s.dir, seenFile), &seen)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewSeen(), nil
+	}
+	if seen.Certificates == nil {
+		seen.Certificates = make(map[string]SeenCertificate)
+	}
+	if seen.Endpoints == nil {
+		seen.Endpoints = make(map[string]SeenCertificate)
+	}
+	return seen, err
+}
+
+func (s *Store) SaveSeen(seen Seen) error {
+	return s.writeJSON(filepath.Join(s.dir, seenFile), seen)
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeen(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	hour := func(i int) time.Time { return start.Add(time.Duration(i) * time.Hour) }
+	aa := Certificate{SHA256Fingerprint: "aa", NotAfter: hour(100)}
+	bb := Certificate{SHA256Fingerprint: "bb", NotAfter: hour(200)}
+
+	tests := []struct {
+		endpoint         string
+		leaf             Certificate
+		at               int
+		wantFirst, since int
+	}{
+		{endpoint: "example.com@192.0.2.1", leaf: aa, at: 0, wantFirst: 0, since: 0},
+		{endpoint: "example.com@192.0.2.2", leaf: aa, at: 1, wantFirst: 0, since: 1},
+		{endpoint: "example.com@192.0.2.1", leaf: aa, at: 2, wantFirst: 0, since: 0},
+		{endpoint: "example.com@192.0.2.1", leaf: bb, at: 3, wantFirst: 3, since: 3},
+		{endpoint: "example.com@192.0.2.2", leaf: bb, at: 4, wantFirst: 3, since: 4},
+		// serving a certificate again counts anew at the endpoint
+		{endpoint: "example.com@192.0.2.1", leaf: aa, at: 5, wantFirst: 0, since: 5},
+	}
+
+	seen := NewSeen()
+	for _, tt := range tests {
+		first, since := seen.Observe(tt.endpoint, tt.leaf, hour(tt.at))
+		if !first.Equal(hour(tt.wantFirst)) || !since.Equal(hour(tt.since)) {
+			t.Errorf("Observe(%s, %s) at hour %d = %v, %v, want hours %d, %d", tt.endpoint, tt.leaf.SHA256Fingerprint, tt.at, first, since, tt.wantFirst, tt.since)
+		}
+	}
+
+	st := newTestStore(t)
+	if err := st.SaveSeen(seen); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := st.LoadSeen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Certificates) != 2 || len(loaded.Endpoints) != 2 {
+		t.Fatalf("LoadSeen() = %+v, want the saved sightings", loaded)
+	}
+
+	// aa has expired and is no longer served; bb is still served
+	loaded.Observe("example.com@192.0.2.2", bb, hour(150))
+	loaded.Forget(hour(150))
+	if _, ok := loaded.Certificates["aa"]; ok {
+		t.Error("Forget() kept an expired certificate")
+	}
+	if _, ok := loaded.Endpoints["example.com@192.0.2.1"]; ok {
+		t.Error("Forget() kept an endpoint's expired certificate")
+	}
+	if _, ok := loaded.Certificates["bb"]; !ok {
+		t.Error("Forget() dropped a certificate still served")
+	}
+}
+
+func TestLoadSeen_Missing(t *testing.T) {
+	seen, err := newTestStore(t).LoadSeen()
+	if err != nil || seen.Certificates == nil || seen.Endpoints == nil {
+		t.Errorf("LoadSeen() = %+v, %v, want empty sightings", seen, err)
+	}
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"cert-tracker/alert"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// State is what the tracker needs to carry on after a restart: the last
+// cycle's results, to notice rotations, the certificates seen so far, and
+// the alerts already firing, which mustn't notify again.
+type State struct {
+	Snapshot Snapshot      `json:"snapshot"`
+	Seen     Seen          `json:"seen"`
+	Alerts   []alert.Alert `json:"alerts,omitempty"`
+}
+
+// stateFile is how State is written, with the snapshot deduplicated like
+// snapshot files are.
+type stateFile struct {
+	Snapshot snapshotFile  `json:"snapshot"`
+	Seen     Seen          `json:"seen"`
+	Alerts   []alert.Alert `json:"alerts,omitempty"`
+}
+
+// LoadState reads the state saved at path by SaveState, or an empty one if
+// there is none yet.
+func (s *Store) LoadState(path string) (State, error) {
+	var state State
+	err := s.readJSON(path, &state)
+	if errors.Is(err, os.ErrNotExist) {
+		return State{Seen: NewSeen()}, nil
+	}
+	if err != nil {
+		return State{Seen: NewSeen()}, err
+	}
+	resolveSightings(state.Snapshot.Certificates)
+	if state.Seen.Certificates == nil {
+		state.Seen.Certificates = make(map[string]SeenCertificate)
+	}
+	if state.Seen.Endpoints == nil {
+		state.Seen.Endpoints = make(map[string]SeenCertificate)
+	}
+	return state, nil
+}
+
+// SaveState writes state to path, which need not be inside the store
+// directory, encrypted like the store's files.
+func (s *Store) SaveState(path string, state State) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(stateFile{
+		Snapshot: state.Snapshot.file(),
+		Seen:     state.Seen,
+		Alerts:   state.Alerts,
+	})
+	if err != nil {
+		return err
+	}
+	return writeFile(path, s.seal(data))
+}
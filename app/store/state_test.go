@@ -0,0 +1,52 @@
+package store
+
+import (
+	"cert-tracker/alert"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	st, err := Open(t.TempDir(), make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "cache", "state.json")
+
+	empty, err := st.LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState() before saving error = %v", err)
+	}
+	if !empty.Snapshot.Time.IsZero() || empty.Seen.Certificates == nil || len(empty.Alerts) > 0 {
+		t.Errorf("LoadState() before saving = %+v, want an empty state", empty)
+	}
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	leaf := Certificate{Hostname: "www.example.com", Target: "www.example.com", SHA256Fingerprint: "aa", Subject: "CN=www.example.com", NotAfter: now.Add(90 * 24 * time.Hour)}
+	other := leaf
+	other.Hostname, other.Target = "shop.example.com", "shop.example.com"
+	seen := NewSeen()
+	seen.Observe("www.example.com@192.0.2.1", leaf, now)
+	state := State{
+		Snapshot: Snapshot{Time: now, Certificates: []Certificate{leaf, other}},
+		Seen:     seen,
+		Alerts:   []alert.Alert{{Key: "expiry:www.example.com", Severity: alert.Warning, Summary: "expires soon", Since: now}},
+	}
+	if err := st.SaveState(path, state); err != nil {
+		t.Fatalf("SaveState() error = %v", err)
+	}
+	got, err := st.LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, state) {
+		t.Errorf("LoadState() = %+v, want %+v", got, state)
+	}
+
+	plain, _ := Open(t.TempDir(), nil)
+	if _, err := plain.LoadState(path); err == nil {
+		t.Error("LoadState() read an encrypted state without the key")
+	}
+}
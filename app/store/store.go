@@ -0,0 +1,394 @@
+package store
+
+import (
+	"cert-tracker/cfg"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	fileExtension = ".json"
+	timeLayout    = "20060102T150405Z"
+)
+
+var ErrNoSnapshot = errors.New("no snapshot found")
+
+type Certificate struct {
+	Tenant                string       `json:"tenant,omitempty"`
+	Hostname              cfg.Hostname `json:"hostname"`
+	IPAddress             net.IP       `json:"ipAddress"`
+	Index                 int          `json:"index"`
+	Target                string       `json:"target"`
+	SHA256Fingerprint     string       `json:"sha256Fingerprint"`
+	SerialNumber          string       `json:"serialNumber"`
+	Subject               string       `json:"subject"`
+	Issuer                string       `json:"issuer"`
+	NotBefore             time.Time    `json:"notBefore"`
+	NotAfter              time.Time    `json:"notAfter"`
+	DNSNames              []string     `json:"dnsNames,omitempty"`
+	OCSPServers           []string     `json:"ocspServers,omitempty"`
+	CRLDistributionPoints []string     `json:"crlDistributionPoints,omitempty"`
+	// KeyAlgorithm is RSA, ECDSA or Ed25519, and KeySize the key's strength
+	// in bits: the modulus for RSA, the curve for ECDSA.
+	KeyAlgorithm string `json:"keyAlgorithm,omitempty"`
+	KeySize      int    `json:"keySize,omitempty"`
+	// Curve names an ECDSA key's curve, e.g. P-256.
+	Curve string `json:"curve,omitempty"`
+	// SignatureAlgorithm is how the issuer signed the certificate, e.g.
+	// SHA256-RSA or ECDSA-SHA384.
+	SignatureAlgorithm string `json:"signatureAlgorithm,omitempty"`
+	// SPKIPin is the base64 SHA-256 of the certificate's public key, as
+	// pinned in the pins config.
+	SPKIPin string `json:"spkiPin,omitempty"`
+	// Trust says who vouches for a leaf: a "public" CA in the system roots,
+	// a "private" CA outside them, or nobody when it's "self-signed".
+	Trust string `json:"trust,omitempty"`
+	// VerifyError is why the chain failed verification against the system
+	// roots, verbatim, or empty if it passed.
+	VerifyError string `json:"verifyError,omitempty"`
+	// NameMismatch is the server name a leaf was asked for but isn't valid
+	// for, if any; DNSNames are the names it is valid for.
+	NameMismatch string `json:"nameMismatch,omitempty"`
+	// Connection is the handshake the certificate was served in.
+	Connection Connection `json:"connection,omitzero"`
+	// NoSNI is the certificate the address serves to clients that send no
+	// server name, if probed.
+	NoSNI *DefaultCertificate `json:"noSNI,omitempty"`
+	// OCSP is the leaf certificate's revocation status, if checked.
+	OCSP *OCSPStatus `json:"ocsp,omitempty"`
+	// Deferred marks a certificate carried forward from an earlier cycle
+	// because its target wasn't due for a scan.
+	Deferred bool `json:"deferred,omitempty"`
+	// Protocols are the TLS versions the endpoint accepted when last
+	// probed, e.g. "TLS 1.2", recorded on the leaf.
+	Protocols []string `json:"protocols,omitempty"`
+	// DANE is how the leaf compares with the endpoint's TLSA records, when
+	// it publishes any: "valid", "insecure" when the records matched but
+	// weren't authenticated with DNSSEC, or "invalid".
+	DANE string `json:"dane,omitempty"`
+}
+
+// Connection records what was negotiated in a handshake, for forensics.
+type Connection struct {
+	ServerName string `json:"serverName"`
+	Version    string `json:"version"`
+	Resumed    bool   `json:"resumed"`
+	// PeerCertificates counts the certificates the server sent.
+	PeerCertificates int `json:"peerCertificates"`
+	// HandshakeBytes were sent and received during the handshake, and
+	// ChainBytes is the DER size of the certificates sent.
+	HandshakeBytes int64 `json:"handshakeBytes"`
+	ChainBytes     int   `json:"chainBytes"`
+	// ALPN is the application protocol the server selected, if any were
+	// offered.
+	ALPN string `json:"alpn,omitempty"`
+}
+
+// ResponderProbe is one request made to an OCSP responder during a scan cycle.
+type ResponderProbe struct {
+	URL     string        `json:"url"`
+	Latency time.Duration `json:"latency"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// Address formats the IP address of a certificate or failure, which is
+// "" for those read from files.
+func Address(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+// Failure is an endpoint no handshake succeeded with in a cycle.
+type Failure struct {
+	Tenant    string       `json:"tenant,omitempty"`
+	Hostname  cfg.Hostname `json:"hostname"`
+	IPAddress net.IP       `json:"ipAddress"`
+	Error     string       `json:"error"`
+}
+
+// DefaultCertificate is the leaf certificate a server falls back to when a
+// client sends no SNI, or why it couldn't be captured.
+type DefaultCertificate struct {
+	SHA256Fingerprint string    `json:"sha256Fingerprint,omitempty"`
+	Subject           string    `json:"subject,omitempty"`
+	Issuer            string    `json:"issuer,omitempty"`
+	DNSNames          []string  `json:"dnsNames,omitempty"`
+	NotAfter          time.Time `json:"notAfter,omitzero"`
+	Error             string    `json:"error,omitempty"`
+}
+
+// OCSPStatus is what an OCSP response says about a certificate. Source is
+// "stapled" when the server sent the response in the handshake, or else the
+// responder's URL.
+type OCSPStatus struct {
+	Source           string    `json:"source"`
+	Status           string    `json:"status,omitempty"`
+	ThisUpdate       time.Time `json:"thisUpdate,omitzero"`
+	NextUpdate       time.Time `json:"nextUpdate,omitzero"`
+	RevokedAt        time.Time `json:"revokedAt,omitzero"`
+	RevocationReason int       `json:"revocationReason,omitempty"`
+	Error            string    `json:"error,omitempty"`
+}
+
+// CRLFetch is one download of a CRL distribution point.
+type CRLFetch struct {
+	URL        string        `json:"url"`
+	Latency    time.Duration `json:"latency"`
+	Size       int64         `json:"size"`
+	Entries    int           `json:"entries"`
+	ThisUpdate time.Time     `json:"thisUpdate,omitzero"`
+	NextUpdate time.Time     `json:"nextUpdate,omitzero"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// Renewal is a hostname's expiring leaf certificate replaced by one that
+// expires later. Overlap is how long both were valid, negative if the old one
+// had already expired.
+type Renewal struct {
+	Tenant         string        `json:"tenant,omitempty"`
+	Hostname       cfg.Hostname  `json:"hostname"`
+	OldFingerprint string        `json:"oldFingerprint"`
+	NewFingerprint string        `json:"newFingerprint"`
+	OldNotAfter    time.Time     `json:"oldNotAfter"`
+	NewNotAfter    time.Time     `json:"newNotAfter"`
+	Overlap        time.Duration `json:"overlap"`
+}
+
+// Rotation is an endpoint whose leaf certificate was replaced between two
+// scan cycles.
+type Rotation struct {
+	Tenant         string       `json:"tenant,omitempty"`
+	Hostname       cfg.Hostname `json:"hostname"`
+	IPAddress      net.IP       `json:"ipAddress"`
+	OldFingerprint string       `json:"oldFingerprint"`
+	NewFingerprint string       `json:"newFingerprint"`
+	OldSerial      string       `json:"oldSerial"`
+	NewSerial      string       `json:"newSerial"`
+	OldIssuer      string       `json:"oldIssuer"`
+	NewIssuer      string       `json:"newIssuer"`
+	// AddedNames and RemovedNames are how the DNS names changed.
+	AddedNames   []string `json:"addedNames,omitempty"`
+	RemovedNames []string `json:"removedNames,omitempty"`
+	// Labels are the hostname's labels from the config.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// PolicyReport is how many of the targets a policy covers comply
+// with it, for audit reporting.
+type PolicyReport struct {
+	Policy     string            `json:"policy"`
+	Targets    int               `json:"targets"`
+	Compliant  int               `json:"compliant"`
+	Compliance float64           `json:"compliance"`
+	Violations []PolicyViolation `json:"violations,omitempty"`
+}
+
+// PolicyViolation is a target whose certificate breaks its policy, with a
+// reason for each rule broken.
+type PolicyViolation struct {
+	Tenant    string        `json:"tenant,omitempty"`
+	Hostname  cfg.Hostname  `json:"hostname"`
+	NotAfter  time.Time     `json:"notAfter"`
+	Remaining time.Duration `json:"remaining"`
+	Reasons   []string      `json:"reasons"`
+}
+
+// Snapshot is every certificate observed during one scan cycle.
+type Snapshot struct {
+	Time         time.Time        `json:"time"`
+	Certificates []Certificate    `json:"certificates"`
+	Responders   []ResponderProbe `json:"responders,omitempty"`
+	CRLs         []CRLFetch       `json:"crls,omitempty"`
+	Renewals     []Renewal        `json:"renewals,omitempty"`
+	Rotations    []Rotation       `json:"rotations,omitempty"`
+	Policies     []PolicyReport   `json:"policies,omitempty"`
+	Failures     []Failure        `json:"failures,omitempty"`
+	Chains       []ChainFinding   `json:"chains,omitempty"`
+	CAA          []CAAPolicy      `json:"caa,omitempty"`
+}
+
+// ChainFinding is something wrong with the chain an endpoint serves, even
+// though clients that build their own paths may still accept it.
+type ChainFinding struct {
+	Tenant    string       `json:"tenant,omitempty"`
+	Hostname  cfg.Hostname `json:"hostname"`
+	IPAddress net.IP       `json:"ipAddress"`
+	Kind      string       `json:"kind"`
+	Detail    string       `json:"detail"`
+}
+
+// CAAPolicy is the CAA record set governing a hostname, found at Domain,
+// and whether it authorizes the CA that issued the hostname's leaf.
+type CAAPolicy struct {
+	Hostname cfg.Hostname `json:"hostname"`
+	// Domain is empty when no domain up the tree has records, and any CA
+	// may issue.
+	Domain string `json:"domain,omitempty"`
+	// Issue are the CAs authorized to issue for the name, and IssueWild for
+	// wildcards when the records say so separately; ";" stands for a record
+	// forbidding issuance. Without issue records any CA may issue.
+	Issue     []string `json:"issue,omitempty"`
+	IssueWild []string `json:"issueWild,omitempty"`
+	Iodef     []string `json:"iodef,omitempty"`
+	Issuer    string   `json:"issuer"`
+	// Status is "authorized" or "unauthorized", or empty when the issuer's
+	// CAA identity is unknown.
+	Status   string   `json:"status,omitempty"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+type Store struct {
+	dir  string
+	aead cipher.AEAD
+}
+
+// Open returns a store in dir. When key is set, files are encrypted with
+// AES-GCM; it must be 16, 24 or 32 bytes long.
+func Open(dir string, key []byte) (*Store, error) {
+	s := &Store{dir: dir}
+	if len(key) == 0 {
+		return s, nil
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	s.aead, err = cipher.NewGCM(block)
+	return s, err
+}
+
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+func fileName(t time.Time) string {
+	return t.UTC().Format(timeLayout) + fileExtension
+}
+
+// Save writes snapshot, after adding it to the change index if it differs
+// from the latest one. When older snapshots couldn't be read to build the
+// index, snapshot is still saved and the error wraps ErrUnreadable.
+func (s *Store) Save(snapshot Snapshot) (string, error) {
+	path := filepath.Join(s.dir, fileName(snapshot.Time))
+	skipped, err := s.indexChange(snapshot)
+	if err != nil {
+		return path, err
+	}
+	if err := s.writeJSON(path, snapshot.file()); err != nil {
+		return path, err
+	}
+	if len(skipped) > 0 {
+		return path, fmt.Errorf("%w: %w", ErrUnreadable, errors.Join(skipped...))
+	}
+	return path, nil
+}
+
+func (s *Store) writeJSON(path string, v any) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(path, s.seal(data))
+}
+
+func (s *Store) readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	data, err = s.open(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeFile writes then renames so readers never see a partial file. The
+// temporary file gets a unique name, so concurrent writers, such as
+// replicas sharing the directory, don't write over each other's.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load reads a snapshot file, which need not be inside the store directory.
+func (s *Store) Load(path string) (Snapshot, error) {
+	var snapshot Snapshot
+	if err := s.readJSON(path, &snapshot); err != nil {
+		return Snapshot{}, err
+	}
+	resolveSightings(snapshot.Certificates)
+	return snapshot, nil
+}
+
+// List returns the snapshot times in the directory, oldest first.
+func (s *Store) List() ([]time.Time, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var times []time.Time
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), fileExtension)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		t, err := time.Parse(timeLayout, name)
+		if err != nil {
+			continue
+		}
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times, nil
+}
+
+// At loads the most recent snapshot taken at or before t.
+func (s *Store) At(t time.Time) (Snapshot, error) {
+	times, err := s.List()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	for i := len(times) - 1; i >= 0; i-- {
+		if !times[i].After(t) {
+			return s.Load(filepath.Join(s.dir, fileName(times[i])))
+		}
+	}
+	return Snapshot{}, ErrNoSnapshot
+}
+
+func (s *Store) Latest() (Snapshot, error) {
+	times, err := s.List()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if len(times) == 0 {
+		return Snapshot{}, ErrNoSnapshot
+	}
+	return s.Load(filepath.Join(s.dir, fileName(times[len(times)-1])))
+}
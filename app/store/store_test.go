@@ -0,0 +1,142 @@
+package store
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testSnapshot(t time.Time) Snapshot {
+	return Snapshot{
+		Time: t,
+		Certificates: []Certificate{
+			{
+				Hostname:          "example.com",
+				IPAddress:         net.ParseIP("192.0.2.1"),
+				Index:             0,
+				Target:            "leaf",
+				SHA256Fingerprint: "aa",
+				Issuer:            "CN=Test CA",
+			},
+		},
+	}
+}
+
+func newTestStore(t *testing.T) *Store {
+	s, err := Open(t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	st := newTestStore(t)
+	taken := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	path, err := st.Save(testSnapshot(taken))
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if filepath.Base(path) != "20250601T120000Z.json" {
+		t.Errorf("Save() path = %s, want file named after snapshot time", path)
+	}
+
+	s, err := st.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !s.Time.Equal(taken) {
+		t.Errorf("Load() time = %v, want %v", s.Time, taken)
+	}
+	if len(s.Certificates) != 1 || s.Certificates[0].SHA256Fingerprint != "aa" {
+		t.Errorf("Load() certificates = %+v", s.Certificates)
+	}
+	if !s.Certificates[0].IPAddress.Equal(net.ParseIP("192.0.2.1")) {
+		t.Errorf("Load() ipAddress = %v", s.Certificates[0].IPAddress)
+	}
+}
+
+func TestListIgnoresUnrelatedFiles(t *testing.T) {
+	st := newTestStore(t)
+	first := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+
+	// save out of order to check sorting
+	for _, taken := range []time.Time{second, first} {
+		if _, err := st.Save(testSnapshot(taken)); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(st.Dir(), "notes.json"), []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	times, err := st.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(times) != 2 {
+		t.Fatalf("List() returned %d times, want 2", len(times))
+	}
+	if !times[0].Equal(first) || !times[1].Equal(second) {
+		t.Errorf("List() = %v, want oldest first", times)
+	}
+}
+
+func TestAt(t *testing.T) {
+	st := newTestStore(t)
+	first := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	for _, taken := range []time.Time{first, second} {
+		if _, err := st.Save(testSnapshot(taken)); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		want    time.Time
+		wantErr error
+	}{
+		{name: "exact match", at: first, want: first},
+		{name: "between snapshots", at: first.Add(30 * time.Minute), want: first},
+		{name: "after latest", at: second.Add(time.Hour), want: second},
+		{name: "before earliest", at: first.Add(-time.Minute), wantErr: ErrNoSnapshot},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := st.At(tt.at)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("At() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !s.Time.Equal(tt.want) {
+				t.Errorf("At() time = %v, want %v", s.Time, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatest(t *testing.T) {
+	st := newTestStore(t)
+	if _, err := st.Latest(); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("Latest() on empty dir error = %v, want ErrNoSnapshot", err)
+	}
+
+	taken := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := st.Save(testSnapshot(taken)); err != nil {
+		t.Fatal(err)
+	}
+	s, err := st.Latest()
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if !s.Time.Equal(taken) {
+		t.Errorf("Latest() time = %v, want %v", s.Time, taken)
+	}
+}
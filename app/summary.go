@@ -0,0 +1,80 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"net"
+	"slices"
+	"time"
+)
+
+// expiring is a leaf certificate in a cycle summary.
+type expiring struct {
+	Hostname  cfg.Hostname `json:"hostname"`
+	IPAddress net.IP       `json:"ipAddress,omitempty"`
+	NotAfter  time.Time    `json:"notAfter"`
+}
+
+// cycleSummary counts a cycle's endpoints by status and lists the leaves
+// closest to expiry, for a single line per cycle instead of one per
+// certificate.
+type cycleSummary struct {
+	OK       int
+	Warning  int
+	Critical int
+	Error    int
+	Soonest  []expiring
+}
+
+// summarize judges each endpoint once, whatever tenants share it: an error
+// when its handshake failed or its leaf fails verification, critical when
+// the leaf expired or expires within the critical window, a warning within
+// the warning window, and ok otherwise.
+func summarize(snapshot store.Snapshot, config cfg.Params, now time.Time) cycleSummary {
+	var s cycleSummary
+	seen := make(map[string]bool)
+	endpoint := func(hostname cfg.Hostname, ip net.IP) bool {
+		key := string(hostname) + "@" + ip.String()
+		if seen[key] {
+			return false
+		}
+		seen[key] = true
+		return true
+	}
+	for _, f := range snapshot.Failures {
+		if endpoint(f.Hostname, f.IPAddress) {
+			s.Error++
+		}
+	}
+	for _, c := range snapshot.Certificates {
+		if c.Index != 0 || !endpoint(c.Hostname, c.IPAddress) {
+			continue
+		}
+		left := c.NotAfter.Sub(now)
+		switch {
+		case c.VerifyError != "" && left > 0:
+			s.Error++
+		case left <= criticalWindow(config):
+			s.Critical++
+		case left <= warningWindow(config):
+			s.Warning++
+		default:
+			s.OK++
+		}
+		s.Soonest = append(s.Soonest, expiring{Hostname: c.Hostname, IPAddress: c.IPAddress, NotAfter: c.NotAfter})
+	}
+	slices.SortStableFunc(s.Soonest, func(a, b expiring) int { return a.NotAfter.Compare(b.NotAfter) })
+	s.Soonest = s.Soonest[:min(len(s.Soonest), config.Summary.Soonest)]
+	return s
+}
+
+func logSummary(s cycleSummary, took time.Duration) {
+	log.Info("scan cycle summary",
+		"ok", s.OK,
+		"warning", s.Warning,
+		"critical", s.Critical,
+		"error", s.Error,
+		"soonest", s.Soonest,
+		"duration", took.String(),
+	)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+	ip := net.ParseIP("192.0.2.1")
+	leaf := func(hostname cfg.Hostname, tenant string, left time.Duration, verifyError string) store.Certificate {
+		return store.Certificate{Tenant: tenant, Hostname: hostname, IPAddress: ip, NotAfter: now.Add(left), VerifyError: verifyError}
+	}
+	snapshot := store.Snapshot{
+		Certificates: []store.Certificate{
+			leaf("ok.example.com", "", 90*day, ""),
+			leaf("ok.example.com", "shop", 90*day, ""),
+			{Hostname: "ok.example.com", IPAddress: ip, Index: 1, NotAfter: now.Add(2 * day)},
+			leaf("soon.example.com", "", 20*day, ""),
+			leaf("urgent.example.com", "", 3*day, ""),
+			leaf("expired.example.com", "", -day, "x509: certificate has expired"),
+			leaf("untrusted.example.com", "", 60*day, "x509: certificate signed by unknown authority"),
+		},
+		Failures: []store.Failure{{Hostname: "down.example.com", IPAddress: ip, Error: "connection refused"}},
+	}
+	config := cfg.Params{Summary: cfg.Summary{Soonest: 3}}
+
+	got := summarize(snapshot, config, now)
+	want := cycleSummary{
+		OK:       1,
+		Warning:  1,
+		Critical: 2,
+		Error:    2,
+		Soonest: []expiring{
+			{Hostname: "expired.example.com", IPAddress: ip, NotAfter: now.Add(-day)},
+			{Hostname: "urgent.example.com", IPAddress: ip, NotAfter: now.Add(3 * day)},
+			{Hostname: "soon.example.com", IPAddress: ip, NotAfter: now.Add(20 * day)},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("summarize() = %+v, want %+v", got, want)
+	}
+}
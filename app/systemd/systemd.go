@@ -0,0 +1,83 @@
+// Package systemd speaks the service manager's notify protocol and takes
+// over the sockets it listens on for a socket-activated service. Outside of
+// systemd, where its environment variables aren't set, everything is a no-op.
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notify sends states such as "READY=1" or "WATCHDOG=1" to the service
+// manager, if it asked for them by setting NOTIFY_SOCKET.
+func Notify(states ...string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	// a leading @ names a socket in the abstract namespace
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(strings.Join(states, "\n")))
+	return err
+}
+
+// WatchdogInterval returns how often the service manager expects a
+// "WATCHDOG=1" notification, or zero when its watchdog isn't watching this
+// process.
+func WatchdogInterval() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// firstFD is the first file descriptor passed by socket activation.
+const firstFD = 3
+
+// Listeners returns the sockets passed to the process by socket activation,
+// by their FileDescriptorName, or none when there are none. The environment
+// variables are unset, so child processes don't take the sockets too.
+func Listeners() (map[string]net.Listener, error) {
+	defer os.Unsetenv("LISTEN_PID")
+	defer os.Unsetenv("LISTEN_FDS")
+	defer os.Unsetenv("LISTEN_FDNAMES")
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	listeners := make(map[string]net.Listener, n)
+	for i := range n {
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(firstFD+i), name)
+		// FileListener works on a duplicate of the descriptor
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("socket %q: %w", name, err)
+		}
+		listeners[name] = ln
+	}
+	return listeners, nil
+}
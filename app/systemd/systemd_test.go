@@ -0,0 +1,76 @@
+package systemd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", "")
+	if err := Notify("READY=1"); err != nil {
+		t.Errorf("Notify() without a socket error = %v", err)
+	}
+	t.Setenv("NOTIFY_SOCKET", path)
+	if err := Notify("RELOADING=1", "STATUS=reloading"); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 256)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(buf[:n]), "RELOADING=1\nSTATUS=reloading"; got != want {
+		t.Errorf("notification = %q, want %q", got, want)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	self := strconv.Itoa(os.Getpid())
+	tests := []struct {
+		name string
+		usec string
+		pid  string
+		want time.Duration
+	}{
+		{name: "not watched"},
+		{name: "watched", usec: "30000000", want: 30 * time.Second},
+		{name: "watched by pid", usec: "30000000", pid: self, want: 30 * time.Second},
+		{name: "another process", usec: "30000000", pid: "1"},
+		{name: "malformed", usec: "30s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", tt.usec)
+			t.Setenv("WATCHDOG_PID", tt.pid)
+			if got := WatchdogInterval(); got != tt.want {
+				t.Errorf("WatchdogInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListenersForAnotherProcess(t *testing.T) {
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "1")
+	t.Setenv("LISTEN_FDNAMES", "api")
+	listeners, err := Listeners()
+	if err != nil || len(listeners) > 0 {
+		t.Errorf("Listeners() = %v, %v, want none", listeners, err)
+	}
+	if _, ok := os.LookupEnv("LISTEN_FDS"); ok {
+		t.Error("LISTEN_FDS still set")
+	}
+}
@@ -0,0 +1,183 @@
+// Package tlsprobe sends handcrafted ClientHellos to learn what a server will
+// negotiate, including options crypto/tls refuses to offer.
+package tlsprobe
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+const (
+	VersionTLS10 uint16 = 0x0301
+	VersionTLS11 uint16 = 0x0302
+	VersionTLS12 uint16 = 0x0303
+	VersionTLS13 uint16 = 0x0304
+
+	recordHandshake = 0x16
+	recordAlert     = 0x15
+
+	typeClientHello = 1
+	typeServerHello = 2
+)
+
+// ErrRejected means the server refused every offered cipher suite.
+var ErrRejected = errors.New("server rejected the offered cipher suites")
+
+// ServerHello is what the server chose in answer to a ClientHello. For
+// TLS 1.3, Version comes from the supported_versions extension.
+type ServerHello struct {
+	Version     uint16
+	CipherSuite uint16
+}
+
+// Hello sends a ClientHello offering only suites at version and returns the
+// server's choice. The handshake is abandoned after the ServerHello. A TLS
+// 1.3 hello offers 1.3 alone, through the supported_versions extension.
+func Hello(address, serverName string, version uint16, suites []uint16, timeout time.Duration) (ServerHello, error) {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return ServerHello{}, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err := conn.Write(clientHello(serverName, version, suites)); err != nil {
+		return ServerHello{}, err
+	}
+	return readServerHello(conn)
+}
+
+func clientHello(serverName string, version uint16, suites []uint16) []byte {
+	var ext []byte
+	if serverName != "" {
+		name := append([]byte{0}, u16(len(serverName))...)
+		name = append(name, serverName...)
+		ext = appendExtension(ext, 0x0000, append(u16(len(name)), name...))
+	}
+	legacyVersion := version
+	if version >= VersionTLS13 {
+		// 1.3 is negotiated through extensions; the hello itself claims 1.2
+		legacyVersion = VersionTLS12
+		ext = appendExtension(ext, 0x002b, []byte{2, 3, 4})
+		// x25519 and secp256r1
+		ext = appendExtension(ext, 0x000a, []byte{0, 4, 0, 29, 0, 23})
+		// RSA-PSS, ECDSA and Ed25519
+		ext = appendExtension(ext, 0x000d, []byte{0, 12, 8, 4, 8, 5, 8, 6, 4, 3, 5, 3, 8, 7})
+		// an x25519 share; the server never gets far enough to use it
+		share := make([]byte, 32)
+		rand.Read(share)
+		entry := append([]byte{0, 29}, u16(len(share))...)
+		entry = append(entry, share...)
+		ext = appendExtension(ext, 0x0033, append(u16(len(entry)), entry...))
+	} else {
+		// secp256r1, secp384r1 and secp521r1, for the ECDH suites
+		ext = appendExtension(ext, 0x000a, []byte{0, 6, 0, 23, 0, 24, 0, 25})
+		// uncompressed points only
+		ext = appendExtension(ext, 0x000b, []byte{1, 0})
+	}
+	if version == VersionTLS12 {
+		// RSA and ECDSA with SHA-256, SHA-384 and SHA-1
+		ext = appendExtension(ext, 0x000d, []byte{0, 10, 4, 1, 5, 1, 2, 1, 4, 3, 5, 3})
+	}
+
+	body := u16(int(legacyVersion))
+	random := make([]byte, 32)
+	rand.Read(random)
+	body = append(body, random...)
+	// no session ID
+	body = append(body, 0)
+	body = append(body, u16(2*len(suites))...)
+	for _, s := range suites {
+		body = append(body, u16(int(s))...)
+	}
+	// null compression only
+	body = append(body, 1, 0)
+	body = append(body, u16(len(ext))...)
+	body = append(body, ext...)
+
+	handshake := append([]byte{typeClientHello, byte(len(body) >> 16)}, u16(len(body))...)
+	handshake = append(handshake, body...)
+	// TLS 1.0 record version, which every server accepts in a ClientHello
+	record := []byte{recordHandshake, 3, 1}
+	record = append(record, u16(len(handshake))...)
+	return append(record, handshake...)
+}
+
+func readServerHello(r io.Reader) (ServerHello, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		// many servers just hang up on a ClientHello they can't serve
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ServerHello{}, ErrRejected
+		}
+		return ServerHello{}, err
+	}
+	payload := make([]byte, binary.BigEndian.Uint16(header[3:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return ServerHello{}, err
+	}
+	switch header[0] {
+	case recordAlert:
+		return ServerHello{}, ErrRejected
+	case recordHandshake:
+	default:
+		return ServerHello{}, fmt.Errorf("unexpected TLS record type %d", header[0])
+	}
+	// type, length, version, random and session ID length
+	if len(payload) < 4+2+32+1 || payload[0] != typeServerHello {
+		return ServerHello{}, errors.New("malformed ServerHello")
+	}
+	hello := payload[4:]
+	version := binary.BigEndian.Uint16(hello)
+	sessionEnd := 2 + 32 + 1 + int(hello[34])
+	if len(hello) < sessionEnd+2 {
+		return ServerHello{}, errors.New("malformed ServerHello")
+	}
+	// after the cipher suite come the compression method, the extensions'
+	// length and the extensions, if any
+	if rest := hello[sessionEnd+2:]; len(rest) > 3 {
+		extensions := rest[3:]
+		if n := int(binary.BigEndian.Uint16(rest[1:])); n < len(extensions) {
+			// the rest of the record is the server's next messages
+			extensions = extensions[:n]
+		}
+		if v, ok := supportedVersion(extensions); ok {
+			version = v
+		}
+	}
+	return ServerHello{
+		Version:     version,
+		CipherSuite: binary.BigEndian.Uint16(hello[sessionEnd:]),
+	}, nil
+}
+
+// supportedVersion finds the version a TLS 1.3 server picked in its
+// supported_versions extension.
+func supportedVersion(extensions []byte) (uint16, bool) {
+	for len(extensions) >= 4 {
+		id, n := binary.BigEndian.Uint16(extensions), int(binary.BigEndian.Uint16(extensions[2:]))
+		if len(extensions) < 4+n {
+			break
+		}
+		if id == 0x002b && n == 2 {
+			return binary.BigEndian.Uint16(extensions[4:]), true
+		}
+		extensions = extensions[4+n:]
+	}
+	return 0, false
+}
+
+func appendExtension(b []byte, id uint16, data []byte) []byte {
+	b = append(b, u16(int(id))...)
+	b = append(b, u16(len(data))...)
+	return append(b, data...)
+}
+
+func u16(n int) []byte {
+	return binary.BigEndian.AppendUint16(nil, uint16(n))
+}
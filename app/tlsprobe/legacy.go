@@ -0,0 +1,90 @@
+package tlsprobe
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// LegacyClass groups cipher suites no server should accept.
+type LegacyClass struct {
+	Name string
+	// export suites predate TLS 1.1
+	Version uint16
+	Suites  map[uint16]string
+}
+
+var LegacyClasses = []LegacyClass{
+	{Name: "export", Version: VersionTLS10, Suites: map[uint16]string{
+		0x0003: "TLS_RSA_EXPORT_WITH_RC4_40_MD5",
+		0x0006: "TLS_RSA_EXPORT_WITH_RC2_CBC_40_MD5",
+		0x0008: "TLS_RSA_EXPORT_WITH_DES40_CBC_SHA",
+		0x000b: "TLS_DH_DSS_EXPORT_WITH_DES40_CBC_SHA",
+		0x000e: "TLS_DH_RSA_EXPORT_WITH_DES40_CBC_SHA",
+		0x0011: "TLS_DHE_DSS_EXPORT_WITH_DES40_CBC_SHA",
+		0x0014: "TLS_DHE_RSA_EXPORT_WITH_DES40_CBC_SHA",
+		0x0017: "TLS_DH_anon_EXPORT_WITH_RC4_40_MD5",
+		0x0019: "TLS_DH_anon_EXPORT_WITH_DES40_CBC_SHA",
+		0x0062: "TLS_RSA_EXPORT1024_WITH_DES_CBC_SHA",
+		0x0064: "TLS_RSA_EXPORT1024_WITH_RC4_56_SHA",
+	}},
+	{Name: "NULL", Version: VersionTLS12, Suites: map[uint16]string{
+		0x0001: "TLS_RSA_WITH_NULL_MD5",
+		0x0002: "TLS_RSA_WITH_NULL_SHA",
+		0x003b: "TLS_RSA_WITH_NULL_SHA256",
+		0xc001: "TLS_ECDH_ECDSA_WITH_NULL_SHA",
+		0xc006: "TLS_ECDHE_ECDSA_WITH_NULL_SHA",
+		0xc00b: "TLS_ECDH_RSA_WITH_NULL_SHA",
+		0xc010: "TLS_ECDHE_RSA_WITH_NULL_SHA",
+		0xc015: "TLS_ECDH_anon_WITH_NULL_SHA",
+	}},
+	{Name: "anonymous", Version: VersionTLS12, Suites: map[uint16]string{
+		0x0018: "TLS_DH_anon_WITH_RC4_128_MD5",
+		0x001a: "TLS_DH_anon_WITH_DES_CBC_SHA",
+		0x001b: "TLS_DH_anon_WITH_3DES_EDE_CBC_SHA",
+		0x0034: "TLS_DH_anon_WITH_AES_128_CBC_SHA",
+		0x003a: "TLS_DH_anon_WITH_AES_256_CBC_SHA",
+		0x006c: "TLS_DH_anon_WITH_AES_128_CBC_SHA256",
+		0x006d: "TLS_DH_anon_WITH_AES_256_CBC_SHA256",
+		0x00a6: "TLS_DH_anon_WITH_AES_128_GCM_SHA256",
+		0x00a7: "TLS_DH_anon_WITH_AES_256_GCM_SHA384",
+		0xc016: "TLS_ECDH_anon_WITH_RC4_128_SHA",
+		0xc017: "TLS_ECDH_anon_WITH_3DES_EDE_CBC_SHA",
+		0xc018: "TLS_ECDH_anon_WITH_AES_128_CBC_SHA",
+		0xc019: "TLS_ECDH_anon_WITH_AES_256_CBC_SHA",
+	}},
+}
+
+// LegacyFinding is a legacy cipher suite a server agreed to use.
+type LegacyFinding struct {
+	Class       string `json:"class"`
+	CipherSuite string `json:"cipherSuite"`
+}
+
+// LegacySuites offers each class of legacy suites on its own and reports the
+// suites the server picked.
+func LegacySuites(address, serverName string, timeout time.Duration) ([]LegacyFinding, error) {
+	var findings []LegacyFinding
+	for _, class := range LegacyClasses {
+		suites := make([]uint16, 0, len(class.Suites))
+		for s := range class.Suites {
+			suites = append(suites, s)
+		}
+		slices.Sort(suites)
+		hello, err := Hello(address, serverName, class.Version, suites, timeout)
+		if errors.Is(err, ErrRejected) {
+			continue
+		}
+		if err != nil {
+			return findings, fmt.Errorf("probing %s suites: %w", class.Name, err)
+		}
+		name, ok := class.Suites[hello.CipherSuite]
+		// a server ignoring the offer is broken, but not with these suites
+		if !ok {
+			continue
+		}
+		findings = append(findings, LegacyFinding{Class: class.Name, CipherSuite: name})
+	}
+	return findings, nil
+}
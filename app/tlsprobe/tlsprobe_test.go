@@ -0,0 +1,148 @@
+package tlsprobe
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeServer accepts the first offered suite that accept allows and rejects
+// everything else with a handshake_failure alert.
+func fakeServer(t *testing.T, accept func(suite uint16) bool) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			header := make([]byte, 5)
+			io.ReadFull(conn, header)
+			payload := make([]byte, binary.BigEndian.Uint16(header[3:]))
+			io.ReadFull(conn, payload)
+			// handshake header, version, random and empty session ID
+			suites := payload[4+2+32+1:]
+			n := int(binary.BigEndian.Uint16(suites)) / 2
+			var chosen uint16
+			for i := range n {
+				if s := binary.BigEndian.Uint16(suites[2+2*i:]); accept(s) {
+					chosen = s
+					break
+				}
+			}
+			if chosen == 0 {
+				conn.Write([]byte{recordAlert, 3, 1, 0, 2, 2, 40})
+				conn.Close()
+				continue
+			}
+			hello := binary.BigEndian.AppendUint16(nil, VersionTLS10)
+			hello = append(hello, make([]byte, 32)...)
+			hello = append(hello, 0)
+			hello = binary.BigEndian.AppendUint16(hello, chosen)
+			hello = append(hello, 0)
+			handshake := append([]byte{typeServerHello, 0, 0, byte(len(hello))}, hello...)
+			record := append([]byte{recordHandshake, 3, 1, 0, byte(len(handshake))}, handshake...)
+			conn.Write(record)
+			conn.Close()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestLegacySuites(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept func(uint16) bool
+		want   []string
+	}{
+		{name: "rejects everything", accept: func(uint16) bool { return false }},
+		{name: "accepts export", accept: func(s uint16) bool { return s == 0x0008 }, want: []string{"export"}},
+		{name: "accepts NULL and anonymous", accept: func(s uint16) bool { return s == 0x0002 || s == 0xc018 }, want: []string{"NULL", "anonymous"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := fakeServer(t, tt.accept)
+			findings, err := LegacySuites(address, "example.com", 2*time.Second)
+			if err != nil {
+				t.Fatalf("LegacySuites() error = %v", err)
+			}
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.Class)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("LegacySuites() = %+v, want classes %v", findings, tt.want)
+			}
+		})
+	}
+}
+
+func TestLegacySuitesAgainstCryptoTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	findings, err := LegacySuites(strings.TrimPrefix(server.URL, "https://"), "example.com", 2*time.Second)
+	if err != nil {
+		t.Fatalf("LegacySuites() error = %v", err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("LegacySuites() = %+v, want crypto/tls to reject every legacy suite", findings)
+	}
+}
+
+func TestHelloNegotiatesModernSuite(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	// TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+	hello, err := Hello(strings.TrimPrefix(server.URL, "https://"), "example.com", VersionTLS12, []uint16{0xc02f}, 2*time.Second)
+	if err != nil {
+		t.Fatalf("Hello() error = %v", err)
+	}
+	if hello.CipherSuite != 0xc02f || hello.Version != VersionTLS12 {
+		t.Errorf("Hello() = %+v", hello)
+	}
+}
+
+func TestAcceptedVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max uint16
+		want     []uint16
+	}{
+		{name: "modern", min: tls.VersionTLS12, max: tls.VersionTLS13, want: []uint16{VersionTLS12, VersionTLS13}},
+		{name: "1.3 only", min: tls.VersionTLS13, max: tls.VersionTLS13, want: []uint16{VersionTLS13}},
+		{name: "legacy", min: tls.VersionTLS10, max: tls.VersionTLS11, want: []uint16{VersionTLS10, VersionTLS11}},
+		{name: "everything", min: tls.VersionTLS10, max: tls.VersionTLS13, want: Versions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(http.NotFoundHandler())
+			server.TLS = &tls.Config{MinVersion: tt.min, MaxVersion: tt.max}
+			server.StartTLS()
+			defer server.Close()
+
+			got, err := AcceptedVersions(strings.TrimPrefix(server.URL, "https://"), "example.com", 2*time.Second)
+			if err != nil {
+				t.Fatalf("AcceptedVersions() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("AcceptedVersions() = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package tlsprobe
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Versions are the TLS versions AcceptedVersions probes, oldest first.
+var Versions = []uint16{VersionTLS10, VersionTLS11, VersionTLS12, VersionTLS13}
+
+// versionSuites are the suites offered when probing a version: what
+// servers commonly run at it, so that only the version decides the answer.
+var versionSuites = map[uint16][]uint16{
+	VersionTLS10: cbcSuites,
+	VersionTLS11: cbcSuites,
+	VersionTLS12: append([]uint16{
+		0xc02b, 0xc02f, 0xc02c, 0xc030, 0xcca9, 0xcca8, 0x009e, 0x009f, 0x009c, 0x009d,
+		0xc023, 0xc027, 0xc024, 0xc028, 0x003c, 0x003d,
+	}, cbcSuites...),
+	VersionTLS13: {0x1301, 0x1302, 0x1303},
+}
+
+// cbcSuites are the ECDHE, DHE and RSA suites with AES and 3DES in CBC mode
+// that every version before 1.3 can use.
+var cbcSuites = []uint16{0xc009, 0xc013, 0xc00a, 0xc014, 0x0033, 0x0039, 0x002f, 0x0035, 0xc012, 0x0016, 0x000a}
+
+// AcceptedVersions offers each of Versions on its own and returns the ones
+// the server agreed to.
+func AcceptedVersions(address, serverName string, timeout time.Duration) ([]uint16, error) {
+	var accepted []uint16
+	for _, version := range Versions {
+		hello, err := Hello(address, serverName, version, versionSuites[version], timeout)
+		if errors.Is(err, ErrRejected) {
+			continue
+		}
+		if err != nil {
+			return accepted, fmt.Errorf("probing version %#04x: %w", version, err)
+		}
+		// a server that answers with an older version doesn't speak this one
+		if hello.Version == version {
+			accepted = append(accepted, version)
+		}
+	}
+	return accepted, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/results"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// trust classes of a leaf
+const (
+	trustPublic     = "public"
+	trustPrivate    = "private"
+	trustSelfSigned = "self-signed"
+)
+
+// systemRoots are loaded once; nil if the system has none to offer.
+var systemRoots = sync.OnceValue(func() *x509.CertPool {
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		return nil
+	}
+	return roots
+})
+
+// classify says who vouches for a served chain: a public CA in roots, a CA
+// outside them, or nobody when the leaf signed itself. Expiry and names
+// don't matter here; verification judges those.
+func classify(chain []*x509.Certificate, roots *x509.CertPool) string {
+	switch {
+	case selfSigned(chain[0]):
+		return trustSelfSigned
+	case roots != nil && verifyChain(chain, roots, "", chain[0].NotBefore.Add(chain[0].NotAfter.Sub(chain[0].NotBefore)/2)) == nil:
+		return trustPublic
+	}
+	return trustPrivate
+}
+
+// verifyChain verifies chain's leaf for serverName, when set, against roots
+// through the intermediates served with it.
+func verifyChain(chain []*x509.Certificate, roots *x509.CertPool, serverName string, now time.Time) error {
+	intermediates := x509.NewCertPool()
+	for _, c := range chain[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		DNSName:       serverName,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// privateCAFor returns the private CA config expects for hostname, if any.
+func privateCAFor(config cfg.Params, hostname cfg.Hostname) *cfg.PrivateCA {
+	for i, ca := range config.PrivateCAs {
+		if covered(ca.Hostnames, hostname) {
+			return &config.PrivateCAs[i]
+		}
+	}
+	return nil
+}
+
+// acceptPrivateCA verifies the chain of a target expected to use a private
+// CA against that CA instead of the system roots, replacing the
+// verification error recorded with the result.
+func acceptPrivateCA(config cfg.Params, result *results.ScanResult, chain []*x509.Certificate) {
+	ca := privateCAFor(config, result.Hostname)
+	if ca == nil || result.Failed() || result.Chain[0].Trust == trustPublic {
+		return
+	}
+	anchors := x509.NewCertPool()
+	anchors.AddCert(chain[len(chain)-1])
+	if ca.RootFile != "" {
+		var err error
+		if anchors, err = rootFile(ca.RootFile); err != nil {
+			log.Warn("cannot load private CA roots", "rootFile", ca.RootFile, "error", err)
+			return
+		}
+	}
+	var verifyError string
+	if err := verifyChain(chain, anchors, result.ServerName, time.Now()); err != nil {
+		verifyError = "private CA: " + err.Error()
+	}
+	previous := result.Chain[0].VerifyError
+	result.Errors = slices.DeleteFunc(result.Errors, func(e string) bool { return e == previous })
+	if verifyError != "" {
+		result.Errors = append(result.Errors, verifyError)
+	}
+	for i := range result.Chain {
+		result.Chain[i].VerifyError = verifyError
+	}
+}
+
+var (
+	rootFilesMu sync.Mutex
+	rootFiles   = make(map[string]*x509.CertPool)
+)
+
+// rootFile loads the PEM certificates in path once; a restart picks up
+// changes.
+func rootFile(path string) (*x509.CertPool, error) {
+	rootFilesMu.Lock()
+	defer rootFilesMu.Unlock()
+	if pool, ok := rootFiles[path]; ok {
+		return pool, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		pool.AddCert(cert)
+	}
+	if pool.Equal(x509.NewCertPool()) {
+		return nil, errors.New("no certificates")
+	}
+	rootFiles[path] = pool
+	return pool, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/results"
+	"cert-tracker/store"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	year := time.Now().Add(365 * 24 * time.Hour)
+	publicKey, privateKey := newKey(t), newKey(t)
+	public := &testCA{issueCert(t, certTemplate("Public Root", true, year), publicKey, nil), publicKey}
+	private := &testCA{issueCert(t, certTemplate("Corp Root", true, year), privateKey, nil), privateKey}
+	roots := x509.NewCertPool()
+	roots.AddCert(public.cert)
+
+	tests := []struct {
+		name  string
+		chain []*x509.Certificate
+		want  string
+	}{
+		{name: "public", chain: []*x509.Certificate{issueCert(t, certTemplate("www.example.com", false, year), newKey(t), public)}, want: trustPublic},
+		{name: "expired public", chain: []*x509.Certificate{issueCert(t, certTemplate("www.example.com", false, time.Now().Add(-time.Hour)), newKey(t), public)}, want: trustPublic},
+		{name: "private", chain: []*x509.Certificate{issueCert(t, certTemplate("internal.example.com", false, year), newKey(t), private), private.cert}, want: trustPrivate},
+		{name: "self-signed", chain: []*x509.Certificate{issueCert(t, certTemplate("printer.example.com", false, year), newKey(t), nil)}, want: trustSelfSigned},
+	}
+	for _, tt := range tests {
+		if got := classify(tt.chain, roots); got != tt.want {
+			t.Errorf("%s: classify() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAcceptPrivateCA(t *testing.T) {
+	year := time.Now().Add(365 * 24 * time.Hour)
+	corpKey, otherKey := newKey(t), newKey(t)
+	corp := &testCA{issueCert(t, certTemplate("Corp Root", true, year), corpKey, nil), corpKey}
+	other := issueCert(t, certTemplate("Other Root", true, year), otherKey, nil)
+	rootPath := func(cert *x509.Certificate) string {
+		path := filepath.Join(t.TempDir(), "roots.pem")
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	leaf := issueCert(t, certTemplate("internal.example.com", false, year), newKey(t), corp)
+	selfSigned := issueCert(t, certTemplate("internal.example.com", false, year), newKey(t), nil)
+
+	tests := []struct {
+		name      string
+		cas       []cfg.PrivateCA
+		chain     []*x509.Certificate
+		wantError string
+	}{
+		{name: "not expected", chain: []*x509.Certificate{leaf, corp.cert}, wantError: "unknown authority"},
+		{name: "expected", cas: []cfg.PrivateCA{{Hostnames: []cfg.Hostname{"*.example.com"}}}, chain: []*x509.Certificate{leaf, corp.cert}},
+		{name: "expected self-signed", cas: []cfg.PrivateCA{{Hostnames: []cfg.Hostname{"internal.example.com"}}}, chain: []*x509.Certificate{selfSigned}},
+		{name: "issued by the root file", cas: []cfg.PrivateCA{{Hostnames: []cfg.Hostname{"internal.example.com"}, RootFile: rootPath(corp.cert)}}, chain: []*x509.Certificate{leaf}},
+		{name: "issued by another CA", cas: []cfg.PrivateCA{{Hostnames: []cfg.Hostname{"internal.example.com"}, RootFile: rootPath(other)}}, chain: []*x509.Certificate{leaf, corp.cert}, wantError: "private CA: "},
+		{name: "wrong name", cas: []cfg.PrivateCA{{Hostnames: []cfg.Hostname{"internal.example.com"}}}, chain: []*x509.Certificate{issueCert(t, certTemplate("www.example.com", false, year), newKey(t), corp), corp.cert}, wantError: "private CA: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const unknown = "x509: certificate signed by unknown authority"
+			result := results.ScanResult{Hostname: "internal.example.com", ServerName: "internal.example.com", Errors: []string{unknown}}
+			for i := range tt.chain {
+				sc := store.Certificate{Index: i, VerifyError: unknown}
+				if i == 0 {
+					sc.Trust = classify(tt.chain, nil)
+				}
+				result.Chain = append(result.Chain, sc)
+			}
+			acceptPrivateCA(cfg.Params{PrivateCAs: tt.cas}, &result, tt.chain)
+
+			got := result.Chain[0].VerifyError
+			if tt.wantError == "" && (got != "" || len(result.Errors) > 0) || !strings.Contains(got, tt.wantError) {
+				t.Errorf("VerifyError = %q, errors %q, want %q", got, result.Errors, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/dns"
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func validateConfigCommand(args []string) int {
+	flags := flag.NewFlagSet("validate-config", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: cert-tracker validate-config [flags]")
+		fmt.Fprintln(flags.Output(), "checks the config, its resolvers and hostnames, and prints every problem found")
+		flags.PrintDefaults()
+	}
+	offline := flags.Bool("offline", false, "only check the config, without contacting resolvers")
+	cfg.RegisterConfigFlag(flags)
+	cfg.RegisterOverrideFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	log = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	config, problems := cfg.Check()
+	if len(problems) == 0 && !*offline {
+		problems = checkDNS(context.Background(), config)
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		return 1
+	}
+	fmt.Printf("config OK: %d hostnames\n", len(configuredHostnames(config)))
+	return 0
+}
+
+// configuredHostnames lists the hostnames in the config by where they are,
+// leaving out discovered and imported ones.
+func configuredHostnames(config cfg.Params) map[string]cfg.Hostname {
+	paths := make(map[string]cfg.Hostname)
+	for i, h := range config.Hostnames {
+		paths[fmt.Sprintf("hostnames[%d]", i)] = h
+	}
+	for i, tenant := range config.Tenants {
+		for j, h := range tenant.Hostnames {
+			paths[fmt.Sprintf("tenants[%d].hostnames[%d]", i, j)] = h
+		}
+	}
+	return paths
+}
+
+// checkDNS asks each resolver for the root name servers, then resolves
+// every configured hostname once with the first resolver that answered.
+func checkDNS(ctx context.Context, config cfg.Params) []cfg.Problem {
+	var problems []cfg.Problem
+	var reachable net.IP
+	for i, ip := range config.DNSresolvers {
+		// any answer, even that the name doesn't exist, shows it's up
+		if _, err := dnsClient(ip, config.Timeout).Query(".", dnsmessage.TypeNS); err != nil && !errors.Is(err, dns.ErrNotFound) {
+			problems = append(problems, cfg.Problem{
+				Path: fmt.Sprintf("dnsResolvers[%d]", i),
+				Err:  fmt.Errorf("resolver %s is unreachable: %w", ip, err),
+			})
+			continue
+		}
+		if reachable == nil {
+			reachable = ip
+		}
+	}
+	if reachable == nil {
+		return problems
+	}
+
+	netResolver := resolver(reachable, config.Timeout)
+	paths := configuredHostnames(config)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for path, hostname := range paths {
+		if hostname.Wildcard() {
+			// only the subdomains found under it are resolved
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := checkResolves(ctx, netResolver, config, hostname)
+			if err == nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			problems = append(problems, cfg.Problem{Path: path, Err: err})
+		}()
+	}
+	wg.Wait()
+	slices.SortFunc(problems, func(a, b cfg.Problem) int { return cmp.Compare(a.Path, b.Path) })
+	return problems
+}
+
+func checkResolves(ctx context.Context, netResolver *net.Resolver, config cfg.Params, hostname cfg.Hostname) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.Timeout))
+	defer cancel()
+	addrs, err := netResolver.LookupIPAddr(ctx, hostname.Host())
+	if err != nil {
+		return fmt.Errorf("cannot resolve %s: %w", hostname.Host(), err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("%s has no addresses", hostname.Host())
+	}
+	for _, addr := range addrs {
+		if config.AddressFamily.Allows(addr.IP) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s has no %s addresses", hostname.Host(), config.AddressFamily)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// evaluatePolicies checks every covered target against its validity
+// policies, logging and raising an alert for each violation.
+func evaluatePolicies(policies []cfg.ValidityPolicy, snapshot store.Snapshot, inventory []store.InventoryEntry, alerts *alert.Manager, now time.Time) []store.PolicyReport {
+	var reports []store.PolicyReport
+	for _, policy := range policies {
+		matched := store.Query(snapshot, inventory, store.Filter{Tenant: policy.Tenant, Tags: policy.Tags}, now)
+		if len(policy.Hostnames) > 0 {
+			matched = slices.DeleteFunc(matched, func(c store.Certificate) bool {
+				return !covered(policy.Hostnames, c.Hostname)
+			})
+		}
+		report := store.PolicyReport{Policy: policy.Name, Compliance: 100}
+		for key, leaf := range earliestLeaves(store.Snapshot{Certificates: matched}) {
+			report.Targets++
+			remaining := leaf.NotAfter.Sub(now)
+			reasons := policyViolations(policy, leaf, now)
+			if len(reasons) > 0 {
+				report.Violations = append(report.Violations, store.PolicyViolation{
+					Tenant:    key.tenant,
+					Hostname:  leaf.Hostname,
+					NotAfter:  leaf.NotAfter,
+					Remaining: remaining,
+					Reasons:   reasons,
+				})
+				log.Warn("policy violation",
+					"policy", policy.Name,
+					"tenant", key.tenant,
+					"hostname", key.hostname,
+					"serialNumber", leaf.SerialNumber,
+					"reasons", reasons,
+				)
+			} else {
+				report.Compliant++
+			}
+			alerts.Set(len(reasons) > 0, alert.Alert{
+				Key:      alert.Key("validity-policy", key.tenant, policy.Name+"/"+key.hostname),
+				Severity: alert.Warning,
+				Summary:  fmt.Sprintf("%s violates policy %s: %s", key.hostname, policy.Name, strings.Join(reasons, "; ")),
+				Tenant:   key.tenant,
+				Labels:   map[string]string{"hostname": key.hostname, "policy": policy.Name},
+				Since:    now,
+			})
+		}
+		if report.Targets > 0 {
+			report.Compliance = 100 * float64(report.Compliant) / float64(report.Targets)
+		}
+		sort.Slice(report.Violations, func(i, j int) bool {
+			return report.Violations[i].NotAfter.Before(report.Violations[j].NotAfter)
+		})
+		log.Info("validity policy compliance",
+			"policy", policy.Name,
+			"targets", report.Targets,
+			"compliance", report.Compliance,
+			"violations", len(report.Violations),
+		)
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// policyViolations returns a reason for each rule of policy leaf breaks.
+func policyViolations(policy cfg.ValidityPolicy, leaf store.Certificate, now time.Time) []string {
+	var reasons []string
+	day := 24 * time.Hour
+	if remaining := leaf.NotAfter.Sub(now); remaining < time.Duration(policy.MinRemaining) {
+		reasons = append(reasons, fmt.Sprintf("%d days of validity left, %d required",
+			int(remaining.Hours()/24), int(time.Duration(policy.MinRemaining)/day)))
+	}
+	if len(policy.AllowedIssuers) > 0 && !slices.ContainsFunc(policy.AllowedIssuers, func(issuer string) bool {
+		return strings.Contains(leaf.Issuer, issuer)
+	}) {
+		reasons = append(reasons, fmt.Sprintf("issuer %q not allowed", leaf.Issuer))
+	}
+	for _, san := range policy.RequiredSANs {
+		if !slices.ContainsFunc(leaf.DNSNames, func(name string) bool { return strings.EqualFold(name, san) }) {
+			reasons = append(reasons, fmt.Sprintf("missing SAN %s", san))
+		}
+	}
+	// snapshots from older versions don't record keys
+	if policy.MinKeySize > 0 && leaf.KeyAlgorithm != "" && leaf.KeySize < policy.MinKeySize {
+		reasons = append(reasons, fmt.Sprintf("%s key of %d bits, %d required", leaf.KeyAlgorithm, leaf.KeySize, policy.MinKeySize))
+	}
+	if validity := leaf.NotAfter.Sub(leaf.NotBefore); policy.MaxValidity > 0 && validity > time.Duration(policy.MaxValidity) {
+		reasons = append(reasons, fmt.Sprintf("valid for %d days, at most %d allowed",
+			int(validity/day), int(time.Duration(policy.MaxValidity)/day)))
+	}
+	return reasons
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/cfg"
+	"cert-tracker/store"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestEvaluatePolicies(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	snapshot := store.Snapshot{Certificates: []store.Certificate{
+		{Hostname: "www.example.com", NotAfter: now.Add(60 * day)},
+		{Hostname: "api.example.com", NotAfter: now.Add(10 * day)},
+		{Hostname: "api.example.com", Index: 1, NotAfter: now.Add(day)},
+		{Hostname: "shop.example.com", NotAfter: now.Add(40 * day)},
+		{Hostname: "dev.example.com", NotAfter: now.Add(2 * day)},
+	}}
+	inventory := []store.InventoryEntry{
+		{Hostname: "www.example.com", Port: 443, Tags: map[string]string{"env": "prod"}},
+		{Hostname: "api.example.com", Port: 443, Tags: map[string]string{"env": "prod"}},
+		{Hostname: "shop.example.com", Port: 443, Tags: map[string]string{"env": "prod"}},
+		{Hostname: "dev.example.com", Port: 443, Tags: map[string]string{"env": "dev"}},
+	}
+
+	tests := []struct {
+		name           string
+		policy         cfg.ValidityPolicy
+		wantTargets    int
+		wantCompliance float64
+		wantViolations []cfg.Hostname
+	}{
+		{
+			name:           "tagged targets",
+			policy:         cfg.ValidityPolicy{Name: "prod", MinRemaining: cfg.Duration(21 * day), Tags: map[string]string{"env": "prod"}},
+			wantTargets:    3,
+			wantCompliance: 100 * 2.0 / 3,
+			wantViolations: []cfg.Hostname{"api.example.com"},
+		},
+		{
+			name:           "listed hostnames",
+			policy:         cfg.ValidityPolicy{Name: "shop", MinRemaining: cfg.Duration(45 * day), Hostnames: []cfg.Hostname{"shop.example.com"}},
+			wantTargets:    1,
+			wantViolations: []cfg.Hostname{"shop.example.com"},
+		},
+		{
+			name:           "nothing covered",
+			policy:         cfg.ValidityPolicy{Name: "staging", MinRemaining: cfg.Duration(day), Tags: map[string]string{"env": "staging"}},
+			wantCompliance: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := alert.NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+			reports := evaluatePolicies([]cfg.ValidityPolicy{tt.policy}, snapshot, inventory, alerts, now)
+			r := reports[0]
+			if r.Targets != tt.wantTargets || r.Compliance != tt.wantCompliance {
+				t.Errorf("report = %d targets at %v%%, want %d at %v%%", r.Targets, r.Compliance, tt.wantTargets, tt.wantCompliance)
+			}
+			if len(r.Violations) != len(tt.wantViolations) {
+				t.Fatalf("Violations = %+v, want %v", r.Violations, tt.wantViolations)
+			}
+			for i, v := range r.Violations {
+				if v.Hostname != tt.wantViolations[i] {
+					t.Errorf("Violations[%d] = %s, want %s", i, v.Hostname, tt.wantViolations[i])
+				}
+				if _, ok := alerts.Get(alert.Key("validity-policy", "", tt.policy.Name+"/"+string(v.Hostname))); !ok {
+					t.Errorf("no alert for violation by %s", v.Hostname)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicyViolations(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	leaf := store.Certificate{
+		Hostname:     "example.com",
+		Issuer:       "CN=R10,O=Let's Encrypt,C=US",
+		DNSNames:     []string{"example.com", "www.example.com"},
+		NotBefore:    now.Add(-30 * day),
+		NotAfter:     now.Add(60 * day),
+		KeyAlgorithm: "RSA",
+		KeySize:      2048,
+	}
+	tests := []struct {
+		name   string
+		policy cfg.ValidityPolicy
+		want   int
+	}{
+		{
+			name: "compliant",
+			policy: cfg.ValidityPolicy{
+				MinRemaining:   cfg.Duration(21 * day),
+				AllowedIssuers: []string{"DigiCert", "Let's Encrypt"},
+				RequiredSANs:   []string{"WWW.example.com"},
+				MinKeySize:     2048,
+				MaxValidity:    cfg.Duration(90 * day),
+			},
+		},
+		{name: "issuer not allowed", policy: cfg.ValidityPolicy{AllowedIssuers: []string{"DigiCert"}}, want: 1},
+		{name: "missing SANs", policy: cfg.ValidityPolicy{RequiredSANs: []string{"api.example.com", "shop.example.com"}}, want: 2},
+		{name: "weak key", policy: cfg.ValidityPolicy{MinKeySize: 3072}, want: 1},
+		{name: "valid too long", policy: cfg.ValidityPolicy{MaxValidity: cfg.Duration(47 * day)}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policyViolations(tt.policy, leaf, now); len(got) != tt.want {
+				t.Errorf("policyViolations() = %q, want %d reasons", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/store"
+	"fmt"
+	"time"
+)
+
+// checkVerification raises an alert for every scanned endpoint whose chain
+// fails verification against the system roots. A leaf that isn't valid for
+// its server name fails for that reason alone, so checkNames alerts on it
+// instead.
+func checkVerification(snapshot store.Snapshot, alerts *alert.Manager, now time.Time) {
+	for _, c := range snapshot.Certificates {
+		if c.Index != 0 {
+			continue
+		}
+		endpoint := string(c.Hostname) + "@" + c.IPAddress.String()
+		alerts.Set(c.VerifyError != "" && c.NameMismatch == "", alert.Alert{
+			Key:      alert.Key("verify-failed", c.Tenant, endpoint),
+			Severity: alert.Critical,
+			Summary:  fmt.Sprintf("%s serves a certificate that fails verification: %s", endpoint, c.VerifyError),
+			Tenant:   c.Tenant,
+			Labels: map[string]string{
+				"hostname":    string(c.Hostname),
+				"ipAddress":   c.IPAddress.String(),
+				"fingerprint": c.SHA256Fingerprint,
+			},
+			Since: now,
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/store"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestCheckVerification(t *testing.T) {
+	tests := []struct {
+		name        string
+		verifyError string
+		wantFiring  bool
+	}{
+		{name: "verified"},
+		{name: "unknown authority", verifyError: "x509: certificate signed by unknown authority", wantFiring: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alerts := alert.NewManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+			snapshot := store.Snapshot{Certificates: []store.Certificate{{
+				Hostname:    "example.com",
+				IPAddress:   net.ParseIP("192.0.2.1"),
+				VerifyError: tt.verifyError,
+			}}}
+			checkVerification(snapshot, alerts, time.Now())
+			if _, firing := alerts.Get("verify-failed:example.com@192.0.2.1"); firing != tt.wantFiring {
+				t.Errorf("firing = %v, want %v", firing, tt.wantFiring)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// deployCheck is what one address served after a deploy.
+type deployCheck struct {
+	IPAddress net.IP
+	Serial    string
+	Err       error
+}
+
+func (c deployCheck) ok(serial string) bool {
+	return c.Err == nil && c.Serial == serial
+}
+
+func verifyDeployCommand(args []string) int {
+	flags := flag.NewFlagSet("verify-deploy", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: cert-tracker verify-deploy -host host[:port] (-expect-serial serial | -cert file) [flags]")
+		flags.PrintDefaults()
+	}
+	host := flags.String("host", "", "`host[:port]` to check on every address it resolves to")
+	expectSerial := flags.String("expect-serial", "", "hex `serial` number the endpoint must serve, colons allowed")
+	certFile := flags.String("cert", "", "PEM `file` whose first certificate the endpoint must serve")
+	serverName := flags.String("sni", "", "server name to send, defaults to the host")
+	timeout := flags.Duration("timeout", 5*time.Second, "timeout for each handshake")
+	attempts := flags.Int("attempts", 5, "how many times to check before giving up")
+	wait := flags.Duration("wait", 2*time.Second, "pause between attempts, for servers to reload")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *host == "" || (*expectSerial == "") == (*certFile == "") || *attempts < 1 || flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	serial, err := normalizeSerial(*expectSerial)
+	if *certFile != "" {
+		serial, err = certFileSerial(*certFile)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	hostname, port, err := net.SplitHostPort(*host)
+	if err != nil {
+		hostname, port = *host, defaultPort
+	}
+	if *serverName == "" {
+		*serverName = hostname
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, hostname)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	ips := make([]net.IP, len(addrs))
+	for i, a := range addrs {
+		ips[i] = a.IP
+	}
+
+	checks := verifyDeploy(ips, port, *serverName, serial, *timeout, *attempts, *wait)
+	if err := printDeployChecks(os.Stdout, checks, serial); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	for _, c := range checks {
+		if !c.ok(serial) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// verifyDeploy checks that every address serves the certificate with serial,
+// trying again up to attempts times while any doesn't yet.
+func verifyDeploy(ips []net.IP, port, serverName, serial string, timeout time.Duration, attempts int, wait time.Duration) []deployCheck {
+	checks := make([]deployCheck, len(ips))
+	for i, ip := range ips {
+		checks[i].IPAddress = ip
+	}
+	for attempt := 1; ; attempt++ {
+		pending := false
+		for i := range checks {
+			if checks[i].ok(serial) {
+				continue
+			}
+			checks[i].Serial, checks[i].Err = servedSerial(net.JoinHostPort(checks[i].IPAddress.String(), port), serverName, timeout)
+			pending = pending || !checks[i].ok(serial)
+		}
+		if !pending || attempt == attempts {
+			return checks
+		}
+		time.Sleep(wait)
+	}
+}
+
+// servedSerial returns the hex serial number of the leaf served at address.
+// The chain isn't verified: matching the serial is the point.
+func servedSerial(address, serverName string, timeout time.Duration) (string, error) {
+	conn, err := tls.DialWithDialer(
+		&net.Dialer{Timeout: timeout},
+		"tcp",
+		address,
+		&tls.Config{
+			InsecureSkipVerify: true,
+			ServerName:         serverName,
+		})
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Text(16), nil
+}
+
+// normalizeSerial accepts a hex serial as openssl prints it, with or without
+// colons, in the form snapshots store it.
+func normalizeSerial(s string) (string, error) {
+	n, ok := new(big.Int).SetString(strings.ReplaceAll(s, ":", ""), 16)
+	if !ok {
+		return "", fmt.Errorf("invalid serial number %q", s)
+	}
+	return n.Text(16), nil
+}
+
+// certFileSerial reads the serial of the first certificate in a PEM file,
+// such as the fullchain.pem a deploy hook is given.
+func certFileSerial(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return "", errors.New("no certificate in " + path)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	return cert.SerialNumber.Text(16), nil
+}
+
+func printDeployChecks(w io.Writer, checks []deployCheck, serial string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ADDRESS\tSTATUS\tSERVED")
+	for _, c := range checks {
+		switch {
+		case c.Err != nil:
+			fmt.Fprintf(tw, "%s\terror\t%s\n", c.IPAddress, c.Err)
+		case c.Serial != serial:
+			fmt.Fprintf(tw, "%s\tstale\t%s\n", c.IPAddress, c.Serial)
+		default:
+			fmt.Fprintf(tw, "%s\tok\t%s\n", c.IPAddress, c.Serial)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeSerial(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "03:AB:0f", want: "3ab0f"},
+		{input: "03ab0f", want: "3ab0f"},
+		{input: "3AB0F", want: "3ab0f"},
+		{input: "not hex", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeSerial(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("normalizeSerial(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("normalizeSerial(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyDeploy(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "https://"))
+	ips := []net.IP{net.ParseIP("127.0.0.1")}
+
+	path := filepath.Join(t.TempDir(), "fullchain.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	serial, err := certFileSerial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checks := verifyDeploy(ips, port, "example.com", serial, time.Second, 3, time.Millisecond)
+	if len(checks) != 1 || !checks[0].ok(serial) {
+		t.Errorf("verifyDeploy() = %+v, want %s served", checks, serial)
+	}
+
+	checks = verifyDeploy(ips, port, "example.com", "abc", time.Second, 2, time.Millisecond)
+	if checks[0].ok("abc") || checks[0].Serial != serial {
+		t.Errorf("verifyDeploy() with another serial = %+v, want the stale %s", checks, serial)
+	}
+	var out strings.Builder
+	if err := printDeployChecks(&out, checks, "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "stale") {
+		t.Errorf("printDeployChecks() = %q, want a stale row", out.String())
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"cert-tracker/alert"
+	"cert-tracker/systemd"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// watchdog notices when the scan loop stops completing cycles, typically
+// because a connection or lookup hangs without a timeout.
+type watchdog struct {
+	limit     atomic.Int64
+	lastCycle atomic.Int64
+}
+
+func newWatchdog(limit time.Duration, now time.Time) *watchdog {
+	w := &watchdog{}
+	w.setLimit(limit)
+	w.beat(now)
+	return w
+}
+
+// setLimit changes how long the scan loop may go without finishing a
+// cycle, such as when a reload changes the scan interval.
+func (w *watchdog) setLimit(limit time.Duration) {
+	w.limit.Store(int64(limit))
+}
+
+func (w *watchdog) getLimit() time.Duration {
+	return time.Duration(w.limit.Load())
+}
+
+// beat records a finished cycle.
+func (w *watchdog) beat(now time.Time) {
+	w.lastCycle.Store(now.UnixNano())
+}
+
+// stalled reports how long ago the last cycle finished and whether that's
+// past the limit.
+func (w *watchdog) stalled(now time.Time) (time.Duration, bool) {
+	since := now.Sub(time.Unix(0, w.lastCycle.Load()))
+	return since, since > w.getLimit()
+}
+
+// watch checks the scan loop until the process ends. A stall raises a
+// critical alert, and exits the process when exit is set so the
+// orchestrator restarts it.
+func (w *watchdog) watch(alerts *alert.Manager, exit bool) {
+	ticker := time.NewTicker(w.getLimit() / 4)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		ticker.Reset(w.getLimit() / 4)
+		since, stalled := w.stalled(now)
+		if stalled {
+			log.Error("scan loop stalled",
+				"severity", alert.Critical,
+				"lastCycle", now.Add(-since),
+				"limit", w.getLimit().String(),
+			)
+		}
+		alerts.Set(stalled, alert.Alert{
+			Key:      alert.Key("watchdog", "", "scan-loop"),
+			Severity: alert.Critical,
+			Summary:  "no scan cycle has completed for " + since.Round(time.Second).String(),
+			Since:    now,
+		})
+		if stalled && exit {
+			os.Exit(1)
+		}
+	}
+}
+
+// ping keeps systemd's watchdog at bay, notifying it twice every interval
+// while the scan loop is healthy. Once it stalls the pings stop, and
+// systemd restarts the service.
+func (w *watchdog) ping(interval time.Duration) {
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		if _, stalled := w.stalled(now); stalled {
+			continue
+		}
+		if err := systemd.Notify("WATCHDOG=1"); err != nil {
+			log.Warn("cannot ping the systemd watchdog", "error", err)
+		}
+	}
+}
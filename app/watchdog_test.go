@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	w := newWatchdog(10*time.Minute, start)
+
+	tests := []struct {
+		name    string
+		beat    time.Duration
+		check   time.Duration
+		limit   time.Duration
+		want    time.Duration
+		stalled bool
+	}{
+		{name: "recent cycle", check: 5 * time.Minute, want: 5 * time.Minute},
+		{name: "at the limit", check: 10 * time.Minute, want: 10 * time.Minute},
+		{name: "stalled", check: 11 * time.Minute, want: 11 * time.Minute, stalled: true},
+		{name: "recovered", beat: 12 * time.Minute, check: 13 * time.Minute, want: time.Minute},
+		{name: "limit raised by a reload", limit: 30 * time.Minute, check: 35 * time.Minute, want: 23 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.beat != 0 {
+				w.beat(start.Add(tt.beat))
+			}
+			if tt.limit != 0 {
+				w.setLimit(tt.limit)
+			}
+			since, stalled := w.stalled(start.Add(tt.check))
+			if since != tt.want || stalled != tt.stalled {
+				t.Errorf("stalled() = %v, %v, want %v, %v", since, stalled, tt.want, tt.stalled)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"cert-tracker/ct"
+	"cert-tracker/dns"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// the subdomains found under wildcard targets, kept between searches
+var wildcardsDiscovered discoveredHostnames
+
+// covered reports whether any of patterns, hostnames or wildcards, covers
+// hostname.
+func covered(patterns []cfg.Hostname, hostname cfg.Hostname) bool {
+	return slices.ContainsFunc(patterns, func(p cfg.Hostname) bool { return p.Covers(hostname) })
+}
+
+// expandWildcards replaces the wildcards among hostnames with the
+// subdomains found under them, searching again every wildcards refresh.
+func expandWildcards(config cfg.Params, hostnames []cfg.Hostname, now time.Time) []cfg.Hostname {
+	var patterns []cfg.Hostname
+	hostnames = slices.DeleteFunc(hostnames, func(h cfg.Hostname) bool {
+		if h.Wildcard() {
+			patterns = append(patterns, h)
+			return true
+		}
+		return false
+	})
+	if len(patterns) == 0 {
+		return hostnames
+	}
+	list := func() ([]cfg.Hostname, error) { return findSubdomains(config, patterns) }
+	for _, hostname := range wildcardsDiscovered.refresh(time.Duration(config.Wildcards.Refresh), now, list) {
+		// the last search may predate a reload that dropped its wildcard
+		if covered(patterns, hostname) && !slices.Contains(hostnames, hostname) {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	return hostnames
+}
+
+// findSubdomains asks every wildcards source for names under patterns.
+func findSubdomains(config cfg.Params, patterns []cfg.Hostname) ([]cfg.Hostname, error) {
+	var names []string
+	for _, source := range config.Wildcards.Sources {
+		found, err := subdomainNames(config, source, patterns)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, found...)
+	}
+	var hostnames []cfg.Hostname
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if strings.Contains(name, "*") {
+			continue
+		}
+		for _, pattern := range patterns {
+			hostname, err := pattern.WithHost(name)
+			if err != nil || !pattern.Covers(hostname) || slices.Contains(hostnames, hostname) {
+				continue
+			}
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	return hostnames, nil
+}
+
+func subdomainNames(config cfg.Params, source string, patterns []cfg.Hostname) ([]string, error) {
+	var names []string
+	switch source {
+	case "ct":
+		crtsh := &ct.CrtSh{
+			Endpoint: config.Wildcards.CTEndpoint,
+			// crt.sh is often slow to answer broad queries
+			Client: &http.Client{Timeout: time.Minute},
+		}
+		for _, domain := range wildcardDomains(patterns) {
+			issued, err := crtsh.Issued(domain, true)
+			if err != nil {
+				return nil, err
+			}
+			for _, issuance := range issued {
+				names = append(names, issuance.Names...)
+			}
+		}
+	case "route53", "cloudflare":
+		list := route53Hostnames
+		if source == "cloudflare" {
+			list = cloudflareHostnames
+		}
+		hostnames, err := list(config)
+		if err != nil {
+			return nil, err
+		}
+		for _, hostname := range hostnames {
+			names = append(names, hostname.Host())
+		}
+	case "axfr":
+		client := dns.Client{Address: config.Wildcards.ZoneTransferServer, Timeout: time.Duration(config.Timeout)}
+		for _, domain := range wildcardDomains(patterns) {
+			transferred, err := client.Transfer(domain)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, transferred...)
+		}
+	}
+	return names, nil
+}
+
+// wildcardDomains returns the distinct domains patterns are wildcards of.
+func wildcardDomains(patterns []cfg.Hostname) []string {
+	var domains []string
+	for _, p := range patterns {
+		if domain := strings.TrimPrefix(p.Host(), "*."); !slices.Contains(domains, domain) {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"cert-tracker/cfg"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestExpandWildcards(t *testing.T) {
+	crtsh := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query().Get("q"); q != "%.example.com" && q != "%.pay.example.com" {
+			http.Error(w, q, http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `[
+			{"id": 1, "serial_number": "01", "name_value": "api.example.com\nwww.example.com", "not_before": "2025-01-01T00:00:00", "not_after": "2026-01-01T00:00:00"},
+			{"id": 2, "serial_number": "02", "name_value": "*.example.com\nEXAMPLE.com", "not_before": "2025-01-01T00:00:00", "not_after": "2026-01-01T00:00:00"},
+			{"id": 3, "serial_number": "03", "name_value": "v1.pay.example.com", "not_before": "2025-01-01T00:00:00", "not_after": "2026-01-01T00:00:00"}
+		]`)
+	}))
+	defer crtsh.Close()
+	wildcardsDiscovered = discoveredHostnames{}
+	defer func() { wildcardsDiscovered = discoveredHostnames{} }()
+
+	config := cfg.Params{
+		Hostnames: []cfg.Hostname{"www.example.com", "*.example.com"},
+		Tenants: []cfg.Tenant{
+			{Name: "payments", Hostnames: []cfg.Hostname{"*.pay.example.com"}},
+		},
+		Wildcards: cfg.Wildcards{Sources: []string{"ct"}, Refresh: cfg.Duration(24 * time.Hour), CTEndpoint: crtsh.URL},
+	}
+	hostnames := targets(config, nil)
+	want := []cfg.Hostname{"www.example.com", "api.example.com", "v1.pay.example.com"}
+	if !slices.Equal(hostnames, want) {
+		t.Fatalf("targets() = %v, want %v", hostnames, want)
+	}
+
+	tenants := tenantsOf(config, hostnames)
+	for hostname, want := range map[cfg.Hostname][]string{
+		"www.example.com":    {""},
+		"api.example.com":    {""},
+		"v1.pay.example.com": {"payments", ""},
+	} {
+		if !slices.Equal(tenants[hostname], want) {
+			t.Errorf("tenantsOf()[%s] = %q, want %q", hostname, tenants[hostname], want)
+		}
+	}
+
+	// a reload dropping the wildcard drops what was found under it
+	config.Hostnames = []cfg.Hostname{"www.example.com"}
+	if got, want := targets(config, nil), []cfg.Hostname{"www.example.com", "v1.pay.example.com"}; !slices.Equal(got, want) {
+		t.Errorf("targets() after reload = %v, want %v", got, want)
+	}
+}